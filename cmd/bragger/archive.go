@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdArchive hides an application from list and stats
+func cmdArchive(store *storage.Storage, id string) {
+	err := store.Archive(id)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	case errors.Is(err, storage.ErrAlreadyArchived):
+		fmt.Printf("%s is already archived.\n", id)
+		return
+	case err != nil:
+		fmt.Printf("Error archiving application: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Archived %s. It is hidden from list and stats; restore it with 'bragger unarchive %s'.\n", id, id)
+}
+
+// cmdUnarchive restores an archived application
+func cmdUnarchive(store *storage.Storage, id string) {
+	err := store.Unarchive(id)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	case errors.Is(err, storage.ErrNotArchived):
+		fmt.Printf("%s is not archived.\n", id)
+		return
+	case err != nil:
+		fmt.Printf("Error restoring application: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Restored %s.\n", id)
+}
+
+// cmdPurge removes archived applications for good, after confirmation
+func cmdPurge(store *storage.Storage, args []string) {
+	ids, yes := parsePurgeArgs("purge", args)
+
+	apps, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading applications: %v\n", err)
+		os.Exit(1)
+	}
+	var labels []string
+	for _, app := range apps {
+		if app.IsArchived() && (len(ids) == 0 || contains(ids, app.ID)) {
+			labels = append(labels, fmt.Sprintf("%s  %s at %s", app.ID, app.Role, app.Company))
+		}
+	}
+	if len(ids) == 0 && len(labels) == 0 {
+		fmt.Println("No archived applications to purge.")
+		return
+	}
+	if len(labels) > 0 && !confirmPurge(labels, "application", yes) {
+		fmt.Println("Cancelled.")
+		return
+	}
+
+	purged, err := store.Purge(ids...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		if errors.Is(err, storage.ErrNotArchived) {
+			fmt.Println("Only archived applications can be purged; archive it first with 'bragger archive <id>'.")
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("Purged %s.\n", plural(len(purged), "archived application"))
+}
+
+// cmdKBArchive retires a KB entry from the context and resumes
+func cmdKBArchive(store *storage.KBStorage, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: bragger kb archive <id>")
+		os.Exit(1)
+	}
+	id := args[0]
+	err := store.Archive(id)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Printf("Entry not found: %s\n", id)
+		os.Exit(1)
+	case errors.Is(err, storage.ErrAlreadyArchived):
+		fmt.Printf("%s is already archived.\n", id)
+		return
+	case err != nil:
+		fmt.Printf("Error archiving entry: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Archived %s. It is left out of kb context and resumes; restore it with 'bragger kb unarchive %s'.\n", id, id)
+}
+
+// cmdKBUnarchive restores an archived KB entry
+func cmdKBUnarchive(store *storage.KBStorage, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: bragger kb unarchive <id>")
+		os.Exit(1)
+	}
+	id := args[0]
+	err := store.Unarchive(id)
+	var singleton *models.SingletonError
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Printf("Entry not found: %s\n", id)
+		os.Exit(1)
+	case errors.Is(err, storage.ErrNotArchived):
+		fmt.Printf("%s is not archived.\n", id)
+		return
+	case errors.As(err, &singleton):
+		fmt.Printf("Error: %v\n", err)
+		fmt.Printf("Archive %s first, or merge the two with 'bragger kb merge'.\n", singleton.ExistingID)
+		os.Exit(1)
+	case err != nil:
+		fmt.Printf("Error restoring entry: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Restored %s.\n", id)
+}
+
+// cmdKBPurge removes archived KB entries for good, after confirmation
+func cmdKBPurge(store *storage.KBStorage, args []string) {
+	ids, yes := parsePurgeArgs("kb purge", args)
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	var labels []string
+	for _, e := range entries {
+		if e.IsArchived() && (len(ids) == 0 || contains(ids, e.ID)) {
+			summary := e.Content
+			if e.Type == models.KBTypeProfile {
+				summary = summarizeProfileData(e)
+			}
+			labels = append(labels, fmt.Sprintf("%s  %s: %s", e.ID, e.Category, truncate(summary, 50)))
+		}
+	}
+	if len(ids) == 0 && len(labels) == 0 {
+		fmt.Println("No archived entries to purge.")
+		return
+	}
+	if len(labels) > 0 && !confirmPurge(labels, "entry", yes) {
+		fmt.Println("Cancelled.")
+		return
+	}
+
+	purged, err := store.Purge(ids...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		if errors.Is(err, storage.ErrNotArchived) {
+			fmt.Println("Only archived entries can be purged; archive it first with 'bragger kb archive <id>'.")
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("Purged %s.\n", plural(len(purged), "archived entry"))
+}
+
+// parsePurgeArgs reads the IDs and --yes flag of a purge command, in any
+// order
+func parsePurgeArgs(name string, args []string) ([]string, bool) {
+	var ids []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ids, args = append(ids, args[0]), args[1:]
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	yes := fs.Bool("yes", false, "Purge without asking")
+	fs.Parse(args)
+	return append(ids, fs.Args()...), *yes
+}
+
+// confirmPurge lists the records a purge removes and asks to go ahead
+func confirmPurge(labels []string, noun string, yes bool) bool {
+	for _, label := range labels {
+		fmt.Printf("  %s\n", label)
+	}
+	if yes {
+		return true
+	}
+	fmt.Printf("Permanently delete %s? (y/N): ", plural(len(labels), "archived "+noun))
+	confirm, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	confirm = strings.TrimSpace(strings.ToLower(confirm))
+	return confirm == "y" || confirm == "yes"
+}
+
+// archivedLabel marks a list column of archived records
+func archivedLabel(s string, archived bool) string {
+	if archived {
+		return s + " (archived)"
+	}
+	return s
+}
+
+// printArchivedHint tells how many records a listing left out
+func printArchivedHint(archived int, command string) {
+	if archived > 0 {
+		fmt.Printf("(%d archived not shown; use 'bragger %s --all' to include them)\n", archived, command)
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
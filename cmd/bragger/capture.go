@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/ewurch/bragger/internal/capture"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdCaptureServer runs a local HTTP endpoint that a browser extension can
+// post job pages to
+func cmdCaptureServer(store *storage.Storage, args []string) {
+	fs := flag.NewFlagSet("capture-server", flag.ExitOnError)
+	addr := fs.String("addr", capture.DefaultAddr, "Address to listen on")
+	status := fs.String("status", string(models.StatusApplied), "Default status for captured jobs (e.g., wishlist)")
+	token := fs.String("token", os.Getenv("BRAGGER_CAPTURE_TOKEN"), "Shared secret required in the X-Bragger-Token header")
+	fs.Parse(args)
+
+	if !models.Status(*status).IsValid() {
+		fmt.Println("Error: --status must be one of: applied, interviewing, rejected, offer, wishlist")
+		os.Exit(1)
+	}
+
+	handler := capture.NewHandler(store, models.Status(*status), *token)
+
+	fmt.Printf("Capture server listening on http://%s\n", *addr)
+	fmt.Println("  POST /capture  {\"url\", \"title\", \"text\" or \"html\"}")
+	fmt.Println("  GET  /health")
+	fmt.Printf("Captured jobs are saved with status %q. Press Ctrl+C to stop.\n", *status)
+
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		fmt.Printf("Error running capture server: %v\n", err)
+		os.Exit(1)
+	}
+}
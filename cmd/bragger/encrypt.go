@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ewurch/bragger/internal/gitsync"
+	"github.com/ewurch/bragger/internal/redact"
+	"github.com/ewurch/bragger/internal/storage"
+	"github.com/ewurch/bragger/internal/vault"
+	"golang.org/x/term"
+)
+
+// errLocked is returned when an encrypted workspace's key isn't available
+var errLocked = errors.New("the workspace is encrypted; run 'bragger unlock', or set BRAGGER_KEY or BRAGGER_PASSPHRASE")
+
+// workspaceKey returns the key of an encrypted workspace, or nil if it
+// isn't encrypted. The key comes from BRAGGER_KEY, the agent 'bragger
+// unlock' starts, BRAGGER_PASSPHRASE, or else a prompt when interactive.
+func workspaceKey(interactive bool) (*vault.Key, error) {
+	params, err := vault.LoadParams("")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s := os.Getenv("BRAGGER_KEY"); s != "" {
+		key, err := vault.ParseKey(s)
+		if err == nil {
+			err = params.Verify(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("BRAGGER_KEY: %w", err)
+		}
+		return key, nil
+	}
+	if key, err := vault.AgentKey(""); err == nil && params.Verify(key) == nil {
+		return key, nil
+	}
+	if p := os.Getenv("BRAGGER_PASSPHRASE"); p != "" {
+		key, err := params.Derive(p)
+		if err != nil {
+			return nil, fmt.Errorf("BRAGGER_PASSPHRASE: %w", err)
+		}
+		return key, nil
+	}
+	if interactive && term.IsTerminal(int(os.Stdin.Fd())) {
+		p, err := readPassphrase("Passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		return params.Derive(p)
+	}
+	return nil, errLocked
+}
+
+// unlockWorkspace sets the workspace key on the stores and journal, if the
+// workspace is encrypted
+func unlockWorkspace(store *storage.Storage, kbStore *storage.KBStorage, j *storage.Journal) {
+	key, err := workspaceKey(true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	store.SetKey(key)
+	kbStore.SetKey(key)
+	j.SetKey(key)
+}
+
+// readPassphrase reads a passphrase from the terminal without echoing it
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(p), nil
+}
+
+// newPassphrase reads the passphrase for encrypting, from
+// BRAGGER_PASSPHRASE or else the terminal, asking twice
+func newPassphrase() (string, error) {
+	if p := os.Getenv("BRAGGER_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New("no terminal to read a passphrase from; set BRAGGER_PASSPHRASE")
+	}
+	p, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	again, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p != again {
+		return "", errors.New("the passphrases don't match")
+	}
+	return p, nil
+}
+
+// cmdEncrypt encrypts the data files and journal with a new passphrase
+func cmdEncrypt(store *storage.Storage, kbStore *storage.KBStorage, j *storage.Journal) {
+	if _, err := os.Stat(vault.DefaultParamsPath); err == nil {
+		fmt.Println("The workspace is already encrypted.")
+		return
+	}
+	passphrase, err := newPassphrase()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	params, key, err := vault.NewParams(passphrase)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Plaintext files still read once the parameters are saved, so an
+	// interrupted encrypt can be run again with decrypt
+	if err := params.Save(""); err != nil {
+		fmt.Printf("Error saving %s: %v\n", vault.DefaultParamsPath, err)
+		os.Exit(1)
+	}
+	if err := rekeyWorkspace(store, kbStore, j, key); err != nil {
+		fmt.Printf("Error encrypting: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Encrypted %s, %s and the change journal.\n", storage.DefaultFilePath, storage.DefaultKBFilePath)
+	fmt.Println("Keep the passphrase safe: the data can't be recovered without it.")
+	fmt.Println("Run 'bragger unlock' to keep the workspace unlocked for a while.")
+	if gitsync.Open("").IsInitialized() {
+		fmt.Println("Note: earlier commits of the sync repository still hold the data in plaintext.")
+	}
+}
+
+// cmdDecrypt rewrites the data files and journal in plaintext
+func cmdDecrypt(store *storage.Storage, kbStore *storage.KBStorage, j *storage.Journal) {
+	key, err := workspaceKey(true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if key == nil {
+		fmt.Println("The workspace is not encrypted.")
+		return
+	}
+	store.SetKey(key)
+	kbStore.SetKey(key)
+	j.SetKey(key)
+	if err := rekeyWorkspace(store, kbStore, j, nil); err != nil {
+		fmt.Printf("Error decrypting: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.Remove(vault.DefaultParamsPath); err != nil {
+		fmt.Printf("Error removing %s: %v\n", vault.DefaultParamsPath, err)
+		os.Exit(1)
+	}
+	vault.StopAgent("")
+	fmt.Printf("Decrypted %s, %s and the change journal.\n", storage.DefaultFilePath, storage.DefaultKBFilePath)
+}
+
+func rekeyWorkspace(store *storage.Storage, kbStore *storage.KBStorage, j *storage.Journal, key *vault.Key) error {
+	if err := store.Rekey(key); err != nil {
+		return err
+	}
+	// The redacted contact details are re-encrypted along with the KB
+	if m, err := redact.Load("", kbStore.Key()); err == nil {
+		if err := m.Save("", key); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := kbStore.Rekey(key); err != nil {
+		return err
+	}
+	return j.Rekey(key)
+}
+
+// cmdUnlock starts a key agent, so commands don't ask for the passphrase
+// until it times out, or prints the key for BRAGGER_KEY
+func cmdUnlock(args []string) {
+	fs := flag.NewFlagSet("unlock", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 8*time.Hour, "How long to stay unlocked")
+	printKey := fs.Bool("print", false, "Print the key for BRAGGER_KEY instead of starting an agent")
+	fs.Parse(args)
+
+	if _, err := os.Stat(vault.DefaultParamsPath); os.IsNotExist(err) {
+		fmt.Println("The workspace is not encrypted.")
+		return
+	}
+	if !*printKey {
+		if _, err := vault.AgentKey(""); err == nil {
+			fmt.Println("The workspace is already unlocked. Run 'bragger lock' to lock it.")
+			return
+		}
+	}
+	key, err := workspaceKey(true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *printKey {
+		fmt.Println(key.String())
+		return
+	}
+
+	if err := startAgent(key, *timeout); err != nil {
+		fmt.Printf("Error starting the key agent: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Unlocked for %s. Run 'bragger lock' to lock it sooner.\n", *timeout)
+}
+
+// startAgent runs 'bragger agent' in the background, handing it the key
+// over a pipe rather than the command line or environment
+func startAgent(key *vault.Key, timeout time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, "agent", "--timeout", timeout.String())
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdin, key.String())
+	stdin.Close()
+
+	// The agent says it's ready once it's listening, or why it can't
+	reply, _ := bufio.NewReader(stdout).ReadString('\n')
+	stdout.Close()
+	if reply = strings.TrimSpace(reply); reply != "ready" {
+		cmd.Wait()
+		if reply == "" {
+			reply = "the agent exited"
+		}
+		return errors.New(reply)
+	}
+	return cmd.Process.Release()
+}
+
+// cmdAgent serves the key it reads from stdin on the agent socket, until it
+// times out or 'bragger lock' stops it. 'bragger unlock' runs it.
+func cmdAgent(args []string) {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 8*time.Hour, "How long to stay unlocked")
+	fs.Parse(args)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		fmt.Printf("reading the key: %v\n", err)
+		os.Exit(1)
+	}
+	key, err := vault.ParseKey(strings.TrimSpace(line))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	agent, err := vault.Listen("", key)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Outlive the terminal 'bragger unlock' ran in
+	signal.Ignore(syscall.SIGHUP)
+	fmt.Println("ready")
+	os.Stdout.Close()
+	agent.Serve(*timeout)
+}
+
+// cmdLock stops the key agent
+func cmdLock() {
+	err := vault.StopAgent("")
+	if errors.Is(err, vault.ErrNoAgent) {
+		fmt.Println("The workspace is not unlocked.")
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Locked.")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/jd"
+	"github.com/ewurch/bragger/internal/match"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdGaps compares the JD requirements of an application against the
+// knowledge base and prints the covered requirements and the gaps
+func cmdGaps(store *storage.Storage, kbStore *storage.KBStorage, id string, args []string) {
+	fs := flag.NewFlagSet("gaps", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Output the report as JSON")
+	fs.Parse(args)
+
+	app, err := store.Get(id)
+	if err != nil {
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	}
+
+	if app.JDContent == "" {
+		fmt.Printf("Error: application %s has no job description. Add one with --jd-content, --jd-file or 'bragger jd fetch'\n", id)
+		os.Exit(1)
+	}
+
+	entries, err := kbStore.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	entries = models.Unarchived(entries)
+
+	analysis := app.JDAnalysis
+	if analysis == nil {
+		analysis = jd.Analyze(app.JDContent)
+	}
+	report := match.FindGaps(match.DefaultDictionary(), analysis, app.JDContent, entries, time.Now())
+
+	if *asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("## Gap Analysis: %s @ %s\n", app.Role, app.Company)
+	fmt.Printf("*Application ID: %s*\n", app.ID)
+
+	fmt.Println("\n### Covered Requirements")
+	if len(report.Covered) == 0 {
+		fmt.Println("*None*")
+	} else {
+		fmt.Println("| Requirement | KB Evidence | Entry ID |")
+		fmt.Println("|-------------|-------------|----------|")
+		for _, req := range report.Covered {
+			var summaries, ids []string
+			for _, ev := range req.Evidence {
+				summaries = append(summaries, ev.Summary)
+				ids = append(ids, ev.EntryID)
+			}
+			fmt.Printf("| %s | %s | %s |\n", tableCell(req.Text), tableCell(strings.Join(summaries, "; ")), strings.Join(ids, ", "))
+		}
+	}
+
+	fmt.Println("\n### Gaps (Missing from KB)")
+	if len(report.Gaps) == 0 {
+		fmt.Println("*None*")
+	} else {
+		fmt.Println("| Requirement | Type | Notes |")
+		fmt.Println("|-------------|------|-------|")
+		for _, req := range report.Gaps {
+			fmt.Printf("| %s | %s | %s |\n", tableCell(req.Text), capitalizeFirst(req.Type), tableCell(req.Notes))
+		}
+	}
+}
+
+// tableCell escapes text for use inside a markdown table cell
+func tableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
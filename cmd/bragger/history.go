@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// maxJournalCommand is the longest command line recorded in the journal;
+// --data and --jd-content values can be long
+const maxJournalCommand = 200
+
+// newJournal returns the workspace journal, recording the running command
+func newJournal() *storage.Journal {
+	j := storage.NewJournal("")
+	j.Command = "bragger " + strings.Join(os.Args[1:], " ")
+	if runes := []rune(j.Command); len(runes) > maxJournalCommand {
+		j.Command = string(runes[:maxJournalCommand]) + "..."
+	}
+	j.Actor = storage.DetectActor()
+	return j
+}
+
+// cmdHistory lists the journaled changes, newest first: every step, or the
+// changes of one record with the fields they changed
+func cmdHistory(j *storage.Journal, args []string) {
+	id := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "Number of steps to show (0 for all)")
+	jsonOutput := fs.Bool("json", false, "Output the changes as JSON")
+	fs.Parse(args)
+
+	steps, err := j.Steps()
+	if err != nil {
+		fmt.Printf("Error reading journal: %v\n", err)
+		os.Exit(1)
+	}
+
+	undone := make(map[string]bool)
+	for _, s := range steps {
+		if s.Undoes != "" {
+			undone[s.Undoes] = true
+		}
+	}
+
+	// Newest first, keeping only the record's changes when an ID is given
+	var shown []storage.Step
+	for i := len(steps) - 1; i >= 0 && (*limit <= 0 || len(shown) < *limit); i-- {
+		s := steps[i]
+		if id != "" {
+			var changes []storage.Change
+			for _, c := range s.Changes {
+				if c.ID == id {
+					changes = append(changes, c)
+				}
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			s.Changes = changes
+		}
+		shown = append(shown, s)
+	}
+
+	if *jsonOutput {
+		changes := []storage.Change{}
+		for _, s := range shown {
+			changes = append(changes, s.Changes...)
+		}
+		data, _ := json.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(shown) == 0 {
+		if id != "" {
+			fmt.Printf("No changes recorded for %s.\n", id)
+		} else {
+			fmt.Println("No changes recorded.")
+		}
+		return
+	}
+
+	for i, s := range shown {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  %s  %s  %s\n", s.ID, s.Time.Local().Format("2006-01-02 15:04"), s.Actor, s.Command)
+		switch {
+		case s.Undoes != "":
+			fmt.Printf("  (undoes %s)\n", s.Undoes)
+		case undone[s.ID]:
+			fmt.Println("  (undone)")
+		}
+		for _, c := range s.Changes {
+			fmt.Printf("  %s %s %s\n", c.Op, storeLabel(c.Store), c.ID)
+			if id == "" || c.Op != storage.OpUpdate {
+				continue
+			}
+			for _, f := range c.Fields() {
+				fmt.Printf("    %s: %s -> %s\n", f.Path, journalValue(f.Before), journalValue(f.After))
+			}
+		}
+	}
+}
+
+// cmdUndo reverts the last steps in the journal
+func cmdUndo(j *storage.Journal, store *storage.Storage, kbStore *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "Number of steps to undo")
+	fs.Parse(args)
+
+	if *steps < 1 {
+		fmt.Println("Error: --steps must be at least 1")
+		os.Exit(1)
+	}
+
+	reverted, err := j.Undo(*steps, store, kbStore)
+	if errors.Is(err, storage.ErrNothingToUndo) {
+		fmt.Println("Nothing to undo.")
+		return
+	}
+	for _, s := range reverted {
+		fmt.Printf("Undid %s (%s):\n", s.ID, s.Command)
+		for _, c := range s.Changes {
+			fmt.Printf("  %s %s %s\n", c.Op, storeLabel(c.Store), c.ID)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func storeLabel(store string) string {
+	if store == storage.StoreKB {
+		return "kb entry"
+	}
+	return "application"
+}
+
+// journalValue shows a journaled field value, truncating long ones
+func journalValue(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	if runes := []rune(v); len(runes) > 80 {
+		return string(runes[:80]) + "..."
+	}
+	return v
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/ewurch/bragger/internal/storage"
 	"github.com/ewurch/bragger/templates"
 )
 
@@ -16,9 +19,9 @@ func cmdInit() {
 		os.Exit(1)
 	}
 
-	// Check if already initialized
+	// Check if already initialized; .bragger may hold only the journal
 	bragDir := filepath.Join(cwd, ".bragger")
-	if _, err := os.Stat(bragDir); err == nil {
+	if _, err := os.Stat(filepath.Join(bragDir, "version")); err == nil {
 		fmt.Println("Workspace already initialized. Use 'bragger upgrade' to update to the latest version.")
 		return
 	}
@@ -80,35 +83,6 @@ func cmdInit() {
 		os.Exit(1)
 	}
 
-	// Copy package.json
-	if err := copyTemplateFile("package.json", filepath.Join(cwd, "package.json"), &created, &skipped); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Create scripts directory and copy html-to-pdf.js
-	scriptsDir := filepath.Join(cwd, "scripts")
-	if err := os.MkdirAll(scriptsDir, 0755); err != nil {
-		fmt.Printf("Error creating scripts directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	scriptDest := filepath.Join(cwd, "scripts", "html-to-pdf.js")
-	if _, err := os.Stat(scriptDest); os.IsNotExist(err) {
-		content, err := templates.Files.ReadFile("scripts/html-to-pdf.js")
-		if err != nil {
-			fmt.Printf("Error reading script template: %v\n", err)
-			os.Exit(1)
-		}
-		if err := os.WriteFile(scriptDest, content, 0644); err != nil {
-			fmt.Printf("Error writing script: %v\n", err)
-			os.Exit(1)
-		}
-		created = append(created, "scripts/html-to-pdf.js")
-	} else {
-		skipped = append(skipped, "scripts/html-to-pdf.js")
-	}
-
 	// Create outputs directory
 	outputsDir := filepath.Join(cwd, "outputs")
 	if _, err := os.Stat(outputsDir); os.IsNotExist(err) {
@@ -155,9 +129,8 @@ func cmdInit() {
 	}
 
 	fmt.Println("\nNext steps:")
-	fmt.Println("  1. Run 'npm install' to enable PDF generation")
-	fmt.Println("  2. Run 'bragger kb add' to add your profile information")
-	fmt.Println("  3. Run 'bragger add' to track your first application")
+	fmt.Println("  1. Run 'bragger kb add' to add your profile information")
+	fmt.Println("  2. Run 'bragger add' to track your first application")
 }
 
 func copyTemplateFile(templatePath, destPath string, created, skipped *[]string) error {
@@ -179,7 +152,11 @@ func copyTemplateFile(templatePath, destPath string, created, skipped *[]string)
 	return nil
 }
 
-func cmdUpgrade() {
+func cmdUpgrade(store *storage.Storage, kbStore *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Show what would change without writing anything")
+	fs.Parse(args)
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		fmt.Printf("Error getting current directory: %v\n", err)
@@ -200,13 +177,39 @@ func cmdUpgrade() {
 	if versionBytes, err := os.ReadFile(versionFile); err == nil {
 		currentVersion = string(versionBytes)
 	}
+	templatesOutdated := currentVersion != templates.Version
 
-	if currentVersion == templates.Version {
+	// Find the records below the current schema version
+	var pending []*storage.MigrationResult
+	for _, migrate := range []func(bool) (*storage.MigrationResult, error){store.Migrate, kbStore.Migrate} {
+		result, err := migrate(true)
+		if err != nil {
+			fmt.Printf("Error reading data: %v\n", err)
+			os.Exit(1)
+		}
+		for _, problem := range result.Problems {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", result.Path, problem)
+		}
+		if len(result.Migrated) > 0 {
+			pending = append(pending, result)
+		}
+	}
+
+	if !templatesOutdated && len(pending) == 0 {
 		fmt.Printf("Workspace is already at version %s (latest)\n", templates.Version)
 		return
 	}
 
-	fmt.Printf("Upgrading workspace from v%s to v%s\n\n", currentVersion, templates.Version)
+	if *dryRun {
+		printUpgradePlan(currentVersion, templatesOutdated, pending)
+		return
+	}
+
+	if templatesOutdated {
+		fmt.Printf("Upgrading workspace from v%s to v%s\n\n", currentVersion, templates.Version)
+	} else {
+		fmt.Printf("Migrating workspace data to v%s\n\n", templates.Version)
+	}
 
 	// Create backup directory
 	backupDir := filepath.Join(bragDir, "backup", currentVersion)
@@ -216,16 +219,69 @@ func cmdUpgrade() {
 	}
 
 	var updated []string
+	if templatesOutdated {
+		updated = upgradeTemplates(cwd, backupDir)
+	}
 
-	// Backup and update skill files
-	skillDirs := []string{
-		"resume-builder",
-		"cover-letter",
-		"candidate-kb",
-		"applications",
+	// Back up the data files before rewriting them
+	for _, result := range pending {
+		content, err := os.ReadFile(result.Path)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(backupDir, filepath.Base(result.Path)), content, 0644)
+		}
+		if err != nil {
+			fmt.Printf("Error backing up %s: %v\n", result.Path, err)
+			os.Exit(1)
+		}
+	}
+	for _, migrate := range []func(bool) (*storage.MigrationResult, error){store.Migrate, kbStore.Migrate} {
+		result, err := migrate(false)
+		if err != nil {
+			fmt.Printf("Error migrating data: %v\n", err)
+			os.Exit(1)
+		}
+		if len(result.Migrated) > 0 {
+			updated = append(updated, fmt.Sprintf("%s (%d records)", result.Path, len(result.Migrated)))
+		}
 	}
 
-	for _, skill := range skillDirs {
+	// Update version file
+	os.WriteFile(versionFile, []byte(templates.Version), 0644)
+
+	// Print results
+	fmt.Printf("Backed up to: %s\n\n", backupDir)
+
+	if len(updated) > 0 {
+		fmt.Println("Updated:")
+		for _, f := range updated {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+
+	// PDF generation no longer needs Node; leave the old files for the user
+	for _, legacy := range []string{"package.json", filepath.Join("scripts", "html-to-pdf.js")} {
+		if _, err := os.Stat(filepath.Join(cwd, legacy)); err == nil {
+			fmt.Printf("\nNote: PDFs are now generated with 'bragger pdf'. %s is no longer used and can be removed.\n", legacy)
+		}
+	}
+
+	fmt.Printf("\nUpgrade to v%s complete!\n", templates.Version)
+}
+
+// upgradeSkills are the skill files bragger upgrade replaces
+var upgradeSkills = []string{
+	"resume-builder",
+	"cover-letter",
+	"candidate-kb",
+	"applications",
+}
+
+// upgradeTemplates backs up the skill files and AGENTS.md into backupDir and
+// replaces them with the current templates. It returns the updated files.
+func upgradeTemplates(cwd, backupDir string) []string {
+	var updated []string
+
+	for _, skill := range upgradeSkills {
 		skillFile := filepath.Join(cwd, ".claude", "skills", skill, "SKILL.md")
 		backupFile := filepath.Join(backupDir, fmt.Sprintf("%s-SKILL.md", skill))
 
@@ -267,20 +323,41 @@ func cmdUpgrade() {
 		}
 	}
 
-	// Update version file
-	os.WriteFile(versionFile, []byte(templates.Version), 0644)
+	return updated
+}
 
-	// Print results
-	fmt.Printf("Backed up to: %s\n\n", backupDir)
+// printUpgradePlan lists what bragger upgrade would change, for --dry-run
+func printUpgradePlan(currentVersion string, templatesOutdated bool, pending []*storage.MigrationResult) {
+	if templatesOutdated {
+		fmt.Printf("Dry run: upgrading workspace from v%s to v%s would change:\n", currentVersion, templates.Version)
+	} else {
+		fmt.Printf("Dry run: migrating workspace data to v%s would change:\n", templates.Version)
+	}
 
-	if len(updated) > 0 {
-		fmt.Println("Updated:")
-		for _, f := range updated {
-			fmt.Printf("  %s\n", f)
+	if templatesOutdated {
+		fmt.Println("\nTemplates (replaced):")
+		for _, skill := range upgradeSkills {
+			fmt.Printf("  .claude/skills/%s/SKILL.md\n", skill)
 		}
+		fmt.Println("  AGENTS.md")
 	}
 
-	fmt.Printf("\nUpgrade to v%s complete!\n", templates.Version)
+	for _, result := range pending {
+		fmt.Printf("\n%s: %d of %d records\n", result.Path, len(result.Migrated), result.Records)
+		for _, m := range result.Migrated {
+			line := fmt.Sprintf("  line %d", m.Line)
+			if m.ID != "" {
+				line += fmt.Sprintf(" (%s)", m.ID)
+			}
+			line += fmt.Sprintf(": v%d -> v%d", m.From, m.To)
+			if len(m.Changes) > 0 {
+				line += ": " + strings.Join(m.Changes, "; ")
+			}
+			fmt.Println(line)
+		}
+	}
+
+	fmt.Printf("\nFiles would be backed up to .bragger/backup/%s/. Nothing was written.\n", currentVersion)
 }
 
 // isWorkspaceInitialized checks if the current directory is a Bragger workspace
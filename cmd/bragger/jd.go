@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ewurch/bragger/internal/capture"
+	"github.com/ewurch/bragger/internal/jd"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+func printJDUsage() {
+	fmt.Println(`Job Descriptions - Fetch and process the JD of an application
+
+Usage:
+  bragger jd <subcommand> [arguments]
+
+Subcommands:
+  fetch <id>    Download the application's --jd-url, store it as the JD content
+                and archive the raw HTML under .bragger/snapshots/
+  analyze <id>  Parse the JD into sections (responsibilities, must-have,
+                nice-to-have, benefits), seniority, location/remote policy,
+                salary and years-of-experience requirements, and store the result
+
+Examples:
+  bragger jd fetch app-a1b2c3d4
+  bragger jd analyze app-a1b2c3d4
+  bragger add --jd-url "https://boards.greenhouse.io/acme/jobs/123" --fetch`)
+}
+
+func cmdJD(store *storage.Storage, subcommand string, args []string) {
+	switch subcommand {
+	case "fetch":
+		if len(args) < 1 {
+			fmt.Println("Usage: bragger jd fetch <id>")
+			os.Exit(1)
+		}
+		cmdJDFetch(store, args[0])
+	case "analyze":
+		if len(args) < 1 {
+			fmt.Println("Usage: bragger jd analyze <id>")
+			os.Exit(1)
+		}
+		cmdJDAnalyze(store, args[0])
+	default:
+		fmt.Printf("Unknown jd subcommand: %s\n", subcommand)
+		printJDUsage()
+		os.Exit(1)
+	}
+}
+
+func cmdJDFetch(store *storage.Storage, id string) {
+	app, err := store.Get(id)
+	if err != nil {
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	}
+
+	if app.JDURL == "" {
+		fmt.Printf("Error: application %s has no JD URL. Set one with 'bragger update %s --jd-url <url>'\n", id, id)
+		os.Exit(1)
+	}
+
+	result, err := fetchJD(app)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = store.Update(id, func(a *models.Application) {
+		a.JDContent = app.JDContent
+		a.JDSnapshot = app.JDSnapshot
+		a.JDAnalysis = app.JDAnalysis
+	})
+	if err != nil {
+		fmt.Printf("Error updating application: %v\n", err)
+		os.Exit(1)
+	}
+
+	printFetchResult(app, result)
+}
+
+// fetchJD downloads app.JDURL into app.JDContent, fills in the company and
+// role when they are still empty and archives the raw page. It fails when
+// the company or role can't be found.
+func fetchJD(app *models.Application) (*jd.FetchResult, error) {
+	if !jd.IsURL(app.JDURL) {
+		return nil, fmt.Errorf("JD URL must start with http:// or https://: %s", app.JDURL)
+	}
+
+	result, err := jd.Fetch(nil, app.JDURL)
+	if err != nil {
+		return nil, err
+	}
+	if result.Text == "" {
+		return nil, fmt.Errorf("no job description text found at %s", app.JDURL)
+	}
+
+	company, role := capture.ExtractCompanyRole(app.JDURL, result.Title)
+	if result.Posting != nil {
+		if result.Posting.Company != "" {
+			company = result.Posting.Company
+		}
+		if result.Posting.Title != "" {
+			role = result.Posting.Title
+		}
+	}
+	if app.Company == "" {
+		app.Company = company
+	}
+	if app.Role == "" {
+		app.Role = role
+	}
+	// Checked before the snapshot is saved, so a failed add leaves no file
+	// behind
+	if app.Company == "" || app.Role == "" {
+		return nil, fmt.Errorf("could not determine company and role from the job posting; pass --company and --role")
+	}
+
+	snapshot, err := jd.SaveSnapshot("", app.ID, result)
+	if err != nil {
+		return nil, err
+	}
+	app.JDContent = result.Text
+	app.JDSnapshot = snapshot
+	refreshJDAnalysis(app)
+
+	return result, nil
+}
+
+func printFetchResult(app *models.Application, result *jd.FetchResult) {
+	source := "page text"
+	if result.Posting != nil && result.Posting.Description != "" {
+		source = "schema.org JobPosting"
+	}
+
+	fmt.Printf("Fetched job description for %s (%d characters from %s)\n", app.ID, len(app.JDContent), source)
+	fmt.Printf("Snapshot: %s\n", app.JDSnapshot)
+}
+
+func cmdJDAnalyze(store *storage.Storage, id string) {
+	app, err := store.Get(id)
+	if err != nil {
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	}
+
+	if app.JDContent == "" {
+		fmt.Printf("Error: application %s has no job description. Add one with --jd-content, --jd-file or 'bragger jd fetch'\n", id)
+		os.Exit(1)
+	}
+
+	analysis := jd.Analyze(app.JDContent)
+	err = store.Update(id, func(a *models.Application) {
+		a.JDAnalysis = analysis
+	})
+	if err != nil {
+		fmt.Printf("Error updating application: %v\n", err)
+		os.Exit(1)
+	}
+
+	app.JDAnalysis = analysis
+	fmt.Printf("## JD Analysis: %s @ %s\n", app.Role, app.Company)
+	fmt.Printf("*Application ID: %s*\n\n", app.ID)
+	printJDAnalysisMarkdown(analysis)
+}
+
+// refreshJDAnalysis re-runs the analysis after the JD content changed, so a
+// stored analysis never describes an old JD
+func refreshJDAnalysis(app *models.Application) {
+	if app.JDAnalysis != nil {
+		app.JDAnalysis = jd.Analyze(app.JDContent)
+	}
+}
+
+func printJDAnalysisMarkdown(a *models.JDAnalysis) {
+	if a.Seniority != "" {
+		fmt.Printf("- **Seniority:** %s\n", a.Seniority)
+	}
+	if a.Location != "" {
+		fmt.Printf("- **Location:** %s\n", a.Location)
+	}
+	if a.RemotePolicy != "" {
+		fmt.Printf("- **Remote Policy:** %s\n", a.RemotePolicy)
+	}
+	if a.Salary != nil {
+		fmt.Printf("- **Salary:** %s\n", formatSalary(a.Salary))
+	}
+	if len(a.Experience) > 0 {
+		fmt.Println("- **Experience:**")
+		for _, req := range a.Experience {
+			years := fmt.Sprintf("%d+ years", req.MinYears)
+			if req.MaxYears > 0 {
+				years = fmt.Sprintf("%d-%d years", req.MinYears, req.MaxYears)
+			}
+			if req.Subject != "" {
+				years += " " + req.Subject
+			}
+			if !req.Required {
+				years += " (nice to have)"
+			}
+			fmt.Printf("  - %s\n", years)
+		}
+	}
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Responsibilities", a.Responsibilities},
+		{"Must Have", a.MustHave},
+		{"Nice to Have", a.NiceToHave},
+		{"Benefits", a.Benefits},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Printf("\n### %s\n", section.title)
+		for _, item := range section.items {
+			fmt.Printf("- %s\n", item)
+		}
+	}
+}
+
+// formatSalary renders a salary range as "EUR 80,000 - 95,000 per year"
+func formatSalary(s *models.SalaryRange) string {
+	amount := formatThousands(s.Min)
+	if s.Max != s.Min {
+		amount += " - " + formatThousands(s.Max)
+	}
+	if s.Currency != "" {
+		amount = s.Currency + " " + amount
+	}
+	if s.Period != "" {
+		amount += " per " + s.Period
+	}
+	return amount
+}
+
+func formatThousands(n int) string {
+	digits := fmt.Sprintf("%d", n)
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digits[i])
+	}
+	return string(out)
+}
@@ -2,11 +2,19 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/ewurch/bragger/internal/jsonpatch"
+	"github.com/ewurch/bragger/internal/kbcontext"
+	"github.com/ewurch/bragger/internal/match"
 	"github.com/ewurch/bragger/internal/models"
 	"github.com/ewurch/bragger/internal/storage"
 )
@@ -20,30 +28,102 @@ Usage:
 Subcommands:
   show [profile|context]   Show knowledge base entries (all, profile only, or context only)
   context                  Export full KB in LLM-friendly markdown format
+  search <query>           Find entries mentioning words or "quoted phrases", most relevant first
   add                      Add a new KB entry
-  update <id>              Update an existing KB entry
+  update <id>              Update an existing KB entry (replace, merge-patch or patch its data)
+  highlight add <id> <text>
+                           Add a highlight to an experience, project or volunteering entry
+  highlight remove <id> <index|text>
+                           Remove a highlight by its index (from 0) or text
+  skills add|remove        Add or remove skills, e.g. --languages Rust,Go (creates the skills entry)
   remove <id>              Remove a KB entry
+  archive <id>             Retire an entry from kb context, match, gaps and resumes, keeping it
+  unarchive <id>           Restore an archived entry
+  purge [id...]            Permanently delete archived entries (--yes skips the confirmation)
+  import <file>            Import a resume file (--format jsonresume|linkedin)
+  export                   Export the KB as a resume file (--format jsonresume)
+  merge <id> <id>          Merge a duplicate contact or skills entry into the first one
+  lint                     Check for timeline gaps and overlaps, expired certifications and
+                           highlights without metrics; exits with status 1 on any issue
 
 Flags for add/update:
   --type         Entry type: "profile" or "context" (required for add)
-  --category     Category (required). Profile: contact, experience, education, skills, certifications, languages
+  --category     Category (required). Profile: contact, experience, education, skills, certifications, languages,
+                 projects, publications, awards, volunteering, talks
   --data         JSON data for profile entries (e.g., '{"name":"John","email":"john@example.com"}')
   --content      Text content for context entries
   --source       Source of information (e.g., "cv-import", "user", "app-xxx")
-
-Profile Categories & Required Fields:
+  --merge        JSON Merge Patch (RFC 7396) for profile data (update only): objects merge, null removes a field
+  --patch        JSON Patch (RFC 6902) for profile data (update only): add, remove, replace, move, copy, test
+
+Flags for highlight add:
+  --at           Index to insert the highlight at (default: the end)
+
+Flags for skills add/remove:
+  --languages, --frameworks, --tools, --databases, --cloud, --other
+                 Comma-separated skills per group (duplicates are ignored, case-insensitively)
+  --source       Source of the skills entry if it is created (default: user)
+
+Flags for import/export:
+  --format       jsonresume (import and export) or linkedin (import of a LinkedIn data export ZIP)
+  --yes          Apply a LinkedIn import without asking (the changes are still shown)
+  --output       File to export to (default: stdout)
+
+Flags for show/context:
+  --all          Include archived entries
+
+Flags for context:
+  --format       markdown (default), json or yaml; the structured formats keep entry IDs and sources
+  --redact       Replace contact details with placeholders like {{EMAIL}} and {{PHONE}};
+                 'bragger unredact <file>' puts them back into the LLM's output
+  --max-tokens   Leave out the least relevant highlights, context entries and skills to fit
+                 about this many tokens (estimated at 4 characters each); what's left out is listed
+  --app          Rank by relevance to this application's JD (keyword overlap, then recency)
+                 instead of recency alone (requires --max-tokens)
+  --categories   Comma-separated categories to include, or "profile"/"context" for a whole type
+  --since        Leave out roles, projects, certifications, etc. that ended before this date
+                 (entries without dates, like skills and context entries, are kept)
+
+Flags for search:
+  --type         Only search profile or context entries
+  --category     Only search entries in this category
+  --limit        Maximum number of results (default 20, 0 for all)
+  --json         Output the results as JSON
+  --all          Include archived entries
+
+Flags for merge:
+  --prefer       Entry whose contact fields win when they differ (default: ask for each field)
+
+Flags for lint:
+  --gap-months   Report gaps between roles longer than this many months (default: 3)
+  --json         Output the issues as JSON
+
+Profile Categories & Required Fields (a KB holds one contact and one skills entry):
   contact:        name, email (optional: phone, location, linkedin, github, website)
   experience:     company, role, start_date (optional: end_date, location, description, highlights)
   education:      institution, degree (optional: field, start_date, end_date, gpa)
   skills:         (optional: languages, frameworks, tools, databases, cloud, other)
   certifications: name (optional: issuer, date, expiry_date, credential_id)
   languages:      language (optional: proficiency)
+  projects:       name (optional: role, url, start_date, end_date, description, technologies, highlights)
+  publications:   title (optional: type, publisher, date, url, authors, description)
+  awards:         title (optional: issuer, date, description)
+  volunteering:   organization, role (optional: start_date, end_date, location, description, highlights)
+  talks:          title, event (optional: date, location, url, description)
 
 Examples:
   bragger kb show                                    # Show all KB entries
   bragger kb show profile                            # Show profile entries only
   bragger kb show context                            # Show context entries only
   bragger kb context                                 # Export full KB in markdown (for LLM context)
+  bragger kb context --redact                        # Without email, phone, location and profile links
+  bragger kb context --app app-a1b2 --max-tokens 2000 # Most relevant to the job, in ~2000 tokens
+  bragger kb context --categories experience,skills --since 2018
+  bragger kb context --format json                   # Structured, for agents
+  bragger kb search kafka                            # Where did I mention Kafka?
+  bragger kb search '"event driven" migration' --type profile
+  bragger kb lint --gap-months 6                     # Check the KB before sending a resume
+  bragger kb merge kb-abc123 kb-def456               # Merge a duplicate skills or contact entry
 
   # Add contact info
   bragger kb add --type profile --category contact --source "cv-import" \
@@ -53,12 +133,31 @@ Examples:
   bragger kb add --type profile --category experience --source "cv-import" \
     --data '{"company":"Acme Corp","role":"Senior Engineer","start_date":"2020-01","end_date":"present"}'
 
+  # Add a side project
+  bragger kb add --type profile --category projects --source "user" \
+    --data '{"name":"bragger","role":"maintainer","url":"https://github.com/ewurch/bragger","technologies":["Go"]}'
+
   # Add contextual information
   bragger kb add --type context --category achievement --source "user" \
     --content "Led migration to microservices, reducing latency by 40%"
 
   bragger kb update kb-a1b2c3d4 --content "Updated achievement description"
-  bragger kb remove kb-a1b2c3d4`)
+
+  # Change part of a profile entry without resending its data
+  bragger kb update kb-e5f6a7b8 --merge '{"end_date":"2024-06","location":null}'
+  bragger kb update kb-e5f6a7b8 --patch '[{"op":"replace","path":"/highlights/0","value":"Led a team of 6"}]'
+  bragger kb highlight add kb-e5f6a7b8 "Cut cloud costs by 30%" --at 0
+  bragger kb highlight remove kb-e5f6a7b8 2
+  bragger kb skills add --languages Rust --tools Terraform
+
+  bragger kb remove kb-a1b2c3d4
+  bragger kb archive kb-a1b2c3d4                     # Retire an old entry without forgetting it
+  bragger kb show --all                              # Include archived entries
+
+  # Import a JSON Resume (jsonresume.org) file or a LinkedIn data export, export a JSON Resume
+  bragger kb import --format jsonresume resume.json
+  bragger kb import --format linkedin Basic_LinkedInDataExport.zip
+  bragger kb export --format jsonresume --output resume.json`)
 }
 
 // kbFlags holds flags for kb add/update commands
@@ -84,12 +183,14 @@ func (f *kbFlags) hasAnyFlag() bool {
 	return f.entryType != "" || f.category != "" || f.data != "" || f.content != "" || f.source != ""
 }
 
-func cmdKB(store *storage.KBStorage, subcommand string, args []string) {
+func cmdKB(appStore *storage.Storage, store *storage.KBStorage, subcommand string, args []string) {
 	switch subcommand {
 	case "show":
 		cmdKBShow(store, args)
 	case "context":
-		cmdKBContext(store)
+		cmdKBContext(appStore, store, args)
+	case "search":
+		cmdKBSearch(store, args)
 	case "add":
 		cmdKBAdd(store, args)
 	case "update":
@@ -104,6 +205,24 @@ func cmdKB(store *storage.KBStorage, subcommand string, args []string) {
 			os.Exit(1)
 		}
 		cmdKBRemove(store, args[0])
+	case "import":
+		cmdKBImport(store, args)
+	case "export":
+		cmdKBExport(store, args)
+	case "lint":
+		cmdKBLint(store, args)
+	case "merge":
+		cmdKBMerge(store, args)
+	case "archive":
+		cmdKBArchive(store, args)
+	case "unarchive":
+		cmdKBUnarchive(store, args)
+	case "purge":
+		cmdKBPurge(store, args)
+	case "highlight":
+		cmdKBHighlight(store, args)
+	case "skills":
+		cmdKBSkills(store, args)
 	default:
 		fmt.Printf("Unknown kb subcommand: %s\n", subcommand)
 		printKBUsage()
@@ -113,32 +232,44 @@ func cmdKB(store *storage.KBStorage, subcommand string, args []string) {
 
 func cmdKBShow(store *storage.KBStorage, args []string) {
 	filter := ""
-	if len(args) > 0 {
-		filter = args[0]
-	}
-
-	var entries []*models.KBEntry
-	var err error
-
-	switch filter {
-	case "profile":
-		entries, err = store.GetProfile()
-	case "context":
-		entries, err = store.GetContext()
-	case "":
-		entries, err = store.Load()
-	default:
-		// Treat as category filter
-		entries, err = store.GetByCategory(filter)
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		filter, args = args[0], args[1:]
 	}
+	fs := flag.NewFlagSet("kb show", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include archived entries")
+	fs.Parse(args)
 
+	loaded, err := store.Load()
 	if err != nil {
 		fmt.Printf("Error loading knowledge base: %v\n", err)
 		os.Exit(1)
 	}
 
+	var entries []*models.KBEntry
+	archived := 0
+	for _, e := range loaded {
+		switch filter {
+		case "":
+		case "profile", "context":
+			if string(e.Type) != filter {
+				continue
+			}
+		default:
+			// Treat as category filter
+			if e.Category != filter {
+				continue
+			}
+		}
+		if e.IsArchived() && !*all {
+			archived++
+			continue
+		}
+		entries = append(entries, e)
+	}
+
 	if len(entries) == 0 {
 		fmt.Println("No entries found.")
+		printArchivedHint(archived, "kb show")
 		return
 	}
 
@@ -159,7 +290,7 @@ func cmdKBShow(store *storage.KBStorage, args []string) {
 		fmt.Fprintln(w, "--\t--------\t------\t-------")
 		for _, e := range profileEntries {
 			summary := summarizeProfileData(e)
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, e.Category, e.Source, truncate(summary, 50))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, archivedLabel(e.Category, e.IsArchived()), e.Source, truncate(summary, 50))
 		}
 		w.Flush()
 		fmt.Printf("\nProfile entries: %d\n", len(profileEntries))
@@ -174,288 +305,461 @@ func cmdKBShow(store *storage.KBStorage, args []string) {
 		fmt.Fprintln(w, "ID\tCATEGORY\tSOURCE\tCONTENT")
 		fmt.Fprintln(w, "--\t--------\t------\t-------")
 		for _, e := range contextEntries {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, e.Category, e.Source, truncate(e.Content, 50))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, archivedLabel(e.Category, e.IsArchived()), e.Source, truncate(e.Content, 50))
 		}
 		w.Flush()
 		fmt.Printf("\nContext entries: %d\n", len(contextEntries))
 	}
+	printArchivedHint(archived, "kb show")
 }
 
-func cmdKBContext(store *storage.KBStorage) {
-	entries, err := store.Load()
+func cmdKBContext(appStore *storage.Storage, store *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("kb context", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include archived entries")
+	redactPII := fs.Bool("redact", false, "Replace contact details with placeholders like {{EMAIL}}")
+	appID := fs.String("app", "", "Rank entries by relevance to this application's JD for --max-tokens")
+	maxTokens := fs.Int("max-tokens", 0, "Leave out the least relevant entries to fit about this many tokens")
+	categories := fs.String("categories", "", "Comma-separated categories or types to include")
+	sinceFlag := fs.String("since", "", "Leave out entries that ended or happened before this date")
+	format := fs.String("format", kbcontext.FormatMarkdown, "Output format: markdown, json or yaml")
+	fs.Parse(args)
+
+	switch *format {
+	case kbcontext.FormatMarkdown, kbcontext.FormatJSON, kbcontext.FormatYAML:
+	default:
+		fmt.Printf("Error: unknown format %q: use markdown, json or yaml\n", *format)
+		os.Exit(1)
+	}
+
+	since, err := models.ParseDate(*sinceFlag)
 	if err != nil {
-		fmt.Printf("Error loading knowledge base: %v\n", err)
+		fmt.Printf("Error: --since: %v\n", err)
+		os.Exit(1)
+	}
+	if *appID != "" && *maxTokens <= 0 {
+		fmt.Println("Error: --app ranks entries to fit a budget; set one with --max-tokens")
 		os.Exit(1)
 	}
 
-	if len(entries) == 0 {
-		fmt.Println("# Candidate Knowledge Base")
-		fmt.Println("\n*No entries found. Use `bragger kb add` to populate the knowledge base.*")
-		return
+	loaded, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	if *all {
+		// Active contact and skills entries come first, so they are used
+		sort.SliceStable(loaded, func(i, j int) bool { return !loaded[i].IsArchived() && loaded[j].IsArchived() })
+	} else {
+		loaded = models.Unarchived(loaded)
+	}
+	entries := kbcontext.Since(kbcontext.Filter(loaded, splitList(*categories)), since)
+	if *redactPII {
+		entries = redactContext(entries, loaded, store.Key())
 	}
 
-	// Group entries by type and category
-	var contact *models.KBEntry
-	var experiences []*models.KBEntry
-	var education []*models.KBEntry
-	var skills *models.KBEntry
-	var certifications []*models.KBEntry
-	var languages []*models.KBEntry
-	var contextEntries []*models.KBEntry
+	var context string
+	if *maxTokens > 0 {
+		context = fitKBContext(appStore, *appID, entries, *maxTokens, *format)
+	} else {
+		context = renderKBContext(*format, entries, nil, "")
+	}
+	fmt.Print(context)
+}
 
-	for _, e := range entries {
-		if e.Type == models.KBTypeProfile {
-			switch models.ProfileCategory(e.Category) {
-			case models.CategoryContact:
-				contact = e
-			case models.CategoryExperience:
-				experiences = append(experiences, e)
-			case models.CategoryEducation:
-				education = append(education, e)
-			case models.CategorySkills:
-				skills = e
-			case models.CategoryCertifications:
-				certifications = append(certifications, e)
-			case models.CategoryLanguages:
-				languages = append(languages, e)
-			}
-		} else {
-			contextEntries = append(contextEntries, e)
+// fitKBContext writes the context without its least relevant highlights,
+// context entries and skills, ranked against the application's JD if one
+// is given, to fit about maxTokens. What was left out is noted at the end.
+func fitKBContext(appStore *storage.Storage, appID string, entries []*models.KBEntry, maxTokens int, format string) string {
+	var jdText, target string
+	if appID != "" {
+		app, err := appStore.Get(appID)
+		if err != nil {
+			fmt.Printf("Application not found: %s\n", appID)
+			os.Exit(1)
+		}
+		if app.JDContent == "" {
+			fmt.Printf("Error: application %s has no job description. Add one with --jd-content, --jd-file or 'bragger jd fetch'\n", appID)
+			os.Exit(1)
 		}
+		jdText = app.JDContent
+		target = fmt.Sprintf(" for %s @ %s", app.Role, app.Company)
 	}
 
-	// Output in LLM-friendly markdown format
-	fmt.Println("# Candidate Knowledge Base")
-	fmt.Println()
+	ranked := kbcontext.Rank(match.DefaultDictionary(), jdText, entries, time.Now())
+	context, omitted := kbcontext.Fit(entries, ranked, maxTokens, func(entries []*models.KBEntry, omitted []kbcontext.Item) string {
+		return renderKBContext(format, entries, omitted, target)
+	})
 
-	// Contact
-	if contact != nil {
-		fmt.Println("## Contact")
-		fmt.Printf("*Entry ID: %s*\n\n", contact.ID)
-		printContactMarkdown(contact)
-		fmt.Println()
+	tokens := kbcontext.EstimateTokens(context)
+	if len(omitted) > 0 {
+		fmt.Fprintf(os.Stderr, "Left out %s to fit %d tokens (about %d used).\n", kbcontext.Summary(omitted), maxTokens, tokens)
 	}
-
-	// Experience
-	if len(experiences) > 0 {
-		fmt.Println("## Experience")
-		fmt.Println()
-		for _, e := range experiences {
-			printExperienceMarkdown(e)
-			fmt.Println()
-		}
+	if tokens > maxTokens {
+		fmt.Fprintf(os.Stderr, "Warning: the context is still about %d tokens; narrow it with --categories or --since.\n", tokens)
 	}
+	return context
+}
 
-	// Education
-	if len(education) > 0 {
-		fmt.Println("## Education")
-		fmt.Println()
-		for _, e := range education {
-			printEducationMarkdown(e)
-			fmt.Println()
+// renderKBContext writes the context in the format, with the items a
+// budget left out, if any
+func renderKBContext(format string, entries []*models.KBEntry, omitted []kbcontext.Item, target string) string {
+	if format != kbcontext.FormatMarkdown {
+		out, err := kbcontext.NewDocument(entries, omitted).Encode(format)
+		if err != nil {
+			fmt.Printf("Error encoding context: %v\n", err)
+			os.Exit(1)
 		}
+		return string(out)
 	}
+	var buf strings.Builder
+	writeKBContext(&buf, entries)
+	writeOmittedMarkdown(&buf, omitted, target)
+	return buf.String()
+}
 
-	// Skills
-	if skills != nil {
-		fmt.Println("## Skills")
-		fmt.Printf("*Entry ID: %s*\n\n", skills.ID)
-		printSkillsMarkdown(skills)
-		fmt.Println()
+// writeOmittedMarkdown notes the items left out of the context, by entry,
+// so an agent knows to ask for them with 'bragger kb show'
+func writeOmittedMarkdown(w io.Writer, omitted []kbcontext.Item, target string) {
+	if len(omitted) == 0 {
+		return
 	}
-
-	// Certifications
-	if len(certifications) > 0 {
-		fmt.Println("## Certifications")
-		fmt.Println()
-		for _, e := range certifications {
-			printCertificationMarkdown(e)
+	fmt.Fprintln(w, "## Omitted")
+	fmt.Fprintf(w, "*Left out as least relevant%s, to fit the token budget: %s.*\n\n", target, kbcontext.Summary(omitted))
+	for _, o := range kbcontext.Omissions(omitted) {
+		if o.Kind == kbcontext.KindContext {
+			fmt.Fprintf(w, "- %s: context entry\n", o.EntryID)
+		} else {
+			fmt.Fprintf(w, "- %s: %s\n", o.EntryID, plural(o.Count, string(o.Kind)))
 		}
-		fmt.Println()
 	}
+	fmt.Fprintln(w)
+}
 
-	// Languages
-	if len(languages) > 0 {
-		fmt.Println("## Languages")
-		fmt.Println()
-		for _, e := range languages {
-			printLanguageMarkdown(e)
-		}
-		fmt.Println()
+// markdownSections says how each profile section is written: its heading,
+// how an entry is printed, and whether entries are one-line list items
+// rather than blocks. Contact and skills, which a KB holds one of, are
+// written with their entry ID under the heading.
+var markdownSections = map[models.ProfileCategory]struct {
+	title string
+	print func(io.Writer, *models.KBEntry)
+	list  bool
+}{
+	models.CategoryContact:        {"Contact", printContactMarkdown, false},
+	models.CategoryExperience:     {"Experience", printExperienceMarkdown, false},
+	models.CategoryEducation:      {"Education", printEducationMarkdown, false},
+	models.CategorySkills:         {"Skills", printSkillsMarkdown, false},
+	models.CategoryCertifications: {"Certifications", printCertificationMarkdown, true},
+	models.CategoryLanguages:      {"Languages", printLanguageMarkdown, true},
+	models.CategoryProjects:       {"Projects", printProjectMarkdown, false},
+	models.CategoryPublications:   {"Publications", printPublicationMarkdown, true},
+	models.CategoryTalks:          {"Talks", printTalkMarkdown, true},
+	models.CategoryAwards:         {"Awards", printAwardMarkdown, true},
+	models.CategoryVolunteering:   {"Volunteering", printVolunteeringMarkdown, false},
+}
+
+// writeKBContext writes the KB as LLM-friendly markdown, in the order of
+// kbcontext.Sections
+func writeKBContext(w io.Writer, entries []*models.KBEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "# Candidate Knowledge Base")
+		fmt.Fprintln(w, "\n*No entries found. Use `bragger kb add` to populate the knowledge base.*")
+		return
 	}
 
-	// Context entries grouped by category
-	if len(contextEntries) > 0 {
-		fmt.Println("## Context Entries")
-		fmt.Println()
+	fmt.Fprintln(w, "# Candidate Knowledge Base")
+	fmt.Fprintln(w)
 
-		// Group by category
-		categories := make(map[string][]*models.KBEntry)
-		for _, e := range contextEntries {
-			categories[e.Category] = append(categories[e.Category], e)
+	contextHeading := false
+	for _, section := range kbcontext.Sections(entries) {
+		// Context entries grouped by category
+		if section.Type == models.KBTypeContext {
+			if !contextHeading {
+				fmt.Fprintln(w, "## Context Entries")
+				fmt.Fprintln(w)
+				contextHeading = true
+			}
+			fmt.Fprintf(w, "### %s\n\n", capitalizeFirst(section.Category))
+			for _, e := range section.Entries {
+				fmt.Fprintf(w, "- %s *(ID: %s, source: %s)*\n", e.Content, e.ID, e.Source)
+			}
+			fmt.Fprintln(w)
+			continue
 		}
 
-		for category, entries := range categories {
-			fmt.Printf("### %s\n\n", capitalizeFirst(category))
-			for _, e := range entries {
-				fmt.Printf("- %s *(ID: %s, source: %s)*\n", e.Content, e.ID, e.Source)
+		category := models.ProfileCategory(section.Category)
+		format := markdownSections[category]
+		fmt.Fprintf(w, "## %s\n", format.title)
+		if category.IsSingleton() {
+			e := section.Entries[0]
+			fmt.Fprintf(w, "*Entry ID: %s*\n\n", e.ID)
+			format.print(w, e)
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w)
+		for _, e := range section.Entries {
+			format.print(w, e)
+			if !format.list {
+				fmt.Fprintln(w)
 			}
-			fmt.Println()
+		}
+		if format.list {
+			fmt.Fprintln(w)
 		}
 	}
 }
 
-func printContactMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return
-	}
-	var c models.ContactData
-	if json.Unmarshal(dataBytes, &c) != nil {
+func printContactMarkdown(w io.Writer, e *models.KBEntry) {
+	c, ok := e.AsContact()
+	if !ok {
 		return
 	}
 
-	fmt.Printf("- **Name:** %s\n", c.Name)
-	fmt.Printf("- **Email:** %s\n", c.Email)
+	fmt.Fprintf(w, "- **Name:** %s\n", c.Name)
+	fmt.Fprintf(w, "- **Email:** %s\n", c.Email)
 	if c.Phone != "" {
-		fmt.Printf("- **Phone:** %s\n", c.Phone)
+		fmt.Fprintf(w, "- **Phone:** %s\n", c.Phone)
 	}
 	if c.Location != "" {
-		fmt.Printf("- **Location:** %s\n", c.Location)
+		fmt.Fprintf(w, "- **Location:** %s\n", c.Location)
 	}
 	if c.LinkedIn != "" {
-		fmt.Printf("- **LinkedIn:** %s\n", c.LinkedIn)
+		fmt.Fprintf(w, "- **LinkedIn:** %s\n", c.LinkedIn)
 	}
 	if c.GitHub != "" {
-		fmt.Printf("- **GitHub:** %s\n", c.GitHub)
+		fmt.Fprintf(w, "- **GitHub:** %s\n", c.GitHub)
 	}
 	if c.Website != "" {
-		fmt.Printf("- **Website:** %s\n", c.Website)
+		fmt.Fprintf(w, "- **Website:** %s\n", c.Website)
 	}
 }
 
-func printExperienceMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return
-	}
-	var exp models.ExperienceEntry
-	if json.Unmarshal(dataBytes, &exp) != nil {
+func printExperienceMarkdown(w io.Writer, e *models.KBEntry) {
+	exp, ok := e.AsExperience()
+	if !ok {
 		return
 	}
 
 	endDate := exp.EndDate
 	if endDate == "" {
-		endDate = "present"
+		endDate = models.Present
 	}
 
-	fmt.Printf("### %s @ %s\n", exp.Role, exp.Company)
-	fmt.Printf("*Entry ID: %s*\n\n", e.ID)
-	fmt.Printf("**Period:** %s - %s\n", exp.StartDate, endDate)
+	fmt.Fprintf(w, "### %s @ %s\n", exp.Role, exp.Company)
+	fmt.Fprintf(w, "*Entry ID: %s*\n\n", e.ID)
+	fmt.Fprintf(w, "**Period:** %s - %s\n", exp.StartDate, endDate)
 	if exp.Location != "" {
-		fmt.Printf("**Location:** %s\n", exp.Location)
+		fmt.Fprintf(w, "**Location:** %s\n", exp.Location)
 	}
 	if exp.Description != "" {
-		fmt.Printf("\n%s\n", exp.Description)
+		fmt.Fprintf(w, "\n%s\n", exp.Description)
 	}
 	if len(exp.Highlights) > 0 {
-		fmt.Println("\n**Highlights:**")
+		fmt.Fprintln(w, "\n**Highlights:**")
 		for _, h := range exp.Highlights {
-			fmt.Printf("- %s\n", h)
+			fmt.Fprintf(w, "- %s\n", h)
 		}
 	}
 }
 
-func printEducationMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return
-	}
-	var edu models.EducationEntry
-	if json.Unmarshal(dataBytes, &edu) != nil {
+func printEducationMarkdown(w io.Writer, e *models.KBEntry) {
+	edu, ok := e.AsEducation()
+	if !ok {
 		return
 	}
 
-	fmt.Printf("### %s", edu.Degree)
+	fmt.Fprintf(w, "### %s", edu.Degree)
 	if edu.Field != "" {
-		fmt.Printf(" in %s", edu.Field)
+		fmt.Fprintf(w, " in %s", edu.Field)
 	}
-	fmt.Printf(" - %s\n", edu.Institution)
-	fmt.Printf("*Entry ID: %s*\n", e.ID)
+	fmt.Fprintf(w, " - %s\n", edu.Institution)
+	fmt.Fprintf(w, "*Entry ID: %s*\n", e.ID)
 
 	if edu.StartDate != "" || edu.EndDate != "" {
-		fmt.Printf("**Period:** %s - %s\n", edu.StartDate, edu.EndDate)
+		fmt.Fprintf(w, "**Period:** %s - %s\n", edu.StartDate, edu.EndDate)
 	}
 	if edu.GPA != "" {
-		fmt.Printf("**GPA:** %s\n", edu.GPA)
+		fmt.Fprintf(w, "**GPA:** %s\n", edu.GPA)
 	}
 }
 
-func printSkillsMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return
-	}
-	var s models.SkillsData
-	if json.Unmarshal(dataBytes, &s) != nil {
+func printSkillsMarkdown(w io.Writer, e *models.KBEntry) {
+	s, ok := e.AsSkills()
+	if !ok {
 		return
 	}
 
 	if len(s.Languages) > 0 {
-		fmt.Printf("- **Programming Languages:** %s\n", joinStrings(s.Languages))
+		fmt.Fprintf(w, "- **Programming Languages:** %s\n", joinStrings(s.Languages))
 	}
 	if len(s.Frameworks) > 0 {
-		fmt.Printf("- **Frameworks:** %s\n", joinStrings(s.Frameworks))
+		fmt.Fprintf(w, "- **Frameworks:** %s\n", joinStrings(s.Frameworks))
 	}
 	if len(s.Tools) > 0 {
-		fmt.Printf("- **Tools:** %s\n", joinStrings(s.Tools))
+		fmt.Fprintf(w, "- **Tools:** %s\n", joinStrings(s.Tools))
 	}
 	if len(s.Databases) > 0 {
-		fmt.Printf("- **Databases:** %s\n", joinStrings(s.Databases))
+		fmt.Fprintf(w, "- **Databases:** %s\n", joinStrings(s.Databases))
 	}
 	if len(s.Cloud) > 0 {
-		fmt.Printf("- **Cloud:** %s\n", joinStrings(s.Cloud))
+		fmt.Fprintf(w, "- **Cloud:** %s\n", joinStrings(s.Cloud))
 	}
 	if len(s.Other) > 0 {
-		fmt.Printf("- **Other:** %s\n", joinStrings(s.Other))
+		fmt.Fprintf(w, "- **Other:** %s\n", joinStrings(s.Other))
 	}
 }
 
-func printCertificationMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return
-	}
-	var c models.CertificationEntry
-	if json.Unmarshal(dataBytes, &c) != nil {
+func printCertificationMarkdown(w io.Writer, e *models.KBEntry) {
+	c, ok := e.AsCertification()
+	if !ok {
 		return
 	}
 
-	fmt.Printf("- **%s**", c.Name)
+	fmt.Fprintf(w, "- **%s**", c.Name)
 	if c.Issuer != "" {
-		fmt.Printf(" by %s", c.Issuer)
+		fmt.Fprintf(w, " by %s", c.Issuer)
 	}
 	if c.Date != "" {
-		fmt.Printf(" (%s)", c.Date)
+		fmt.Fprintf(w, " (%s)", c.Date)
 	}
-	fmt.Printf(" *(ID: %s)*\n", e.ID)
+	fmt.Fprintf(w, " *(ID: %s)*\n", e.ID)
 }
 
-func printLanguageMarkdown(e *models.KBEntry) {
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
+func printLanguageMarkdown(w io.Writer, e *models.KBEntry) {
+	l, ok := e.AsLanguage()
+	if !ok {
 		return
 	}
-	var l models.LanguageEntry
-	if json.Unmarshal(dataBytes, &l) != nil {
+
+	fmt.Fprintf(w, "- **%s**", l.Language)
+	if l.Proficiency != "" {
+		fmt.Fprintf(w, " - %s", l.Proficiency)
+	}
+	fmt.Fprintf(w, " *(ID: %s)*\n", e.ID)
+}
+
+func printProjectMarkdown(w io.Writer, e *models.KBEntry) {
+	p, ok := e.AsProject()
+	if !ok {
 		return
 	}
 
-	fmt.Printf("- **%s**", l.Language)
-	if l.Proficiency != "" {
-		fmt.Printf(" - %s", l.Proficiency)
+	fmt.Fprintf(w, "### %s\n", p.Name)
+	fmt.Fprintf(w, "*Entry ID: %s*\n\n", e.ID)
+	if p.Role != "" {
+		fmt.Fprintf(w, "**Role:** %s\n", p.Role)
+	}
+	if p.StartDate != "" || p.EndDate != "" {
+		fmt.Fprintf(w, "**Period:** %s - %s\n", p.StartDate, p.EndDate)
+	}
+	if p.URL != "" {
+		fmt.Fprintf(w, "**URL:** %s\n", p.URL)
+	}
+	if len(p.Technologies) > 0 {
+		fmt.Fprintf(w, "**Technologies:** %s\n", joinStrings(p.Technologies))
+	}
+	if p.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", p.Description)
+	}
+	if len(p.Highlights) > 0 {
+		fmt.Fprintln(w, "\n**Highlights:**")
+		for _, h := range p.Highlights {
+			fmt.Fprintf(w, "- %s\n", h)
+		}
+	}
+}
+
+func printPublicationMarkdown(w io.Writer, e *models.KBEntry) {
+	p, ok := e.AsPublication()
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "- **%s**", p.Title)
+	if p.Type != "" {
+		fmt.Fprintf(w, " [%s]", p.Type)
+	}
+	if p.Publisher != "" {
+		fmt.Fprintf(w, ", %s", p.Publisher)
+	}
+	if p.Date != "" {
+		fmt.Fprintf(w, " (%s)", p.Date)
+	}
+	if len(p.Authors) > 0 {
+		fmt.Fprintf(w, " - with %s", joinStrings(p.Authors))
+	}
+	if p.URL != "" {
+		fmt.Fprintf(w, " - %s", p.URL)
+	}
+	fmt.Fprintf(w, " *(ID: %s)*\n", e.ID)
+	if p.Description != "" {
+		fmt.Fprintf(w, "  %s\n", p.Description)
+	}
+}
+
+func printTalkMarkdown(w io.Writer, e *models.KBEntry) {
+	t, ok := e.AsTalk()
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "- **%s** at %s", t.Title, t.Event)
+	if t.Date != "" && t.Location != "" {
+		fmt.Fprintf(w, " (%s, %s)", t.Location, t.Date)
+	} else if t.Date != "" || t.Location != "" {
+		fmt.Fprintf(w, " (%s%s)", t.Location, t.Date)
+	}
+	if t.URL != "" {
+		fmt.Fprintf(w, " - %s", t.URL)
+	}
+	fmt.Fprintf(w, " *(ID: %s)*\n", e.ID)
+	if t.Description != "" {
+		fmt.Fprintf(w, "  %s\n", t.Description)
+	}
+}
+
+func printAwardMarkdown(w io.Writer, e *models.KBEntry) {
+	a, ok := e.AsAward()
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "- **%s**", a.Title)
+	if a.Issuer != "" {
+		fmt.Fprintf(w, " from %s", a.Issuer)
+	}
+	if a.Date != "" {
+		fmt.Fprintf(w, " (%s)", a.Date)
+	}
+	fmt.Fprintf(w, " *(ID: %s)*\n", e.ID)
+	if a.Description != "" {
+		fmt.Fprintf(w, "  %s\n", a.Description)
+	}
+}
+
+func printVolunteeringMarkdown(w io.Writer, e *models.KBEntry) {
+	v, ok := e.AsVolunteering()
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "### %s @ %s\n", v.Role, v.Organization)
+	fmt.Fprintf(w, "*Entry ID: %s*\n\n", e.ID)
+	if v.StartDate != "" || v.EndDate != "" {
+		fmt.Fprintf(w, "**Period:** %s - %s\n", v.StartDate, v.EndDate)
+	}
+	if v.Location != "" {
+		fmt.Fprintf(w, "**Location:** %s\n", v.Location)
+	}
+	if v.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", v.Description)
+	}
+	if len(v.Highlights) > 0 {
+		fmt.Fprintln(w, "\n**Highlights:**")
+		for _, h := range v.Highlights {
+			fmt.Fprintf(w, "- %s\n", h)
+		}
 	}
-	fmt.Printf(" *(ID: %s)*\n", e.ID)
 }
 
 func joinStrings(s []string) string {
@@ -481,50 +785,72 @@ func summarizeProfileData(e *models.KBEntry) string {
 		return ""
 	}
 
-	// Convert data back to JSON for display
-	dataBytes, err := json.Marshal(e.Data)
-	if err != nil {
-		return ""
-	}
-
-	// Parse based on category to create summary
+	// Summarize based on category
 	switch models.ProfileCategory(e.Category) {
 	case models.CategoryContact:
-		var c models.ContactData
-		if json.Unmarshal(dataBytes, &c) == nil {
+		if c, ok := e.AsContact(); ok {
 			return fmt.Sprintf("%s <%s>", c.Name, c.Email)
 		}
 	case models.CategoryExperience:
-		var exp models.ExperienceEntry
-		if json.Unmarshal(dataBytes, &exp) == nil {
+		if exp, ok := e.AsExperience(); ok {
 			return fmt.Sprintf("%s @ %s (%s - %s)", exp.Role, exp.Company, exp.StartDate, exp.EndDate)
 		}
 	case models.CategoryEducation:
-		var edu models.EducationEntry
-		if json.Unmarshal(dataBytes, &edu) == nil {
+		if edu, ok := e.AsEducation(); ok {
 			return fmt.Sprintf("%s, %s", edu.Degree, edu.Institution)
 		}
 	case models.CategorySkills:
-		var s models.SkillsData
-		if json.Unmarshal(dataBytes, &s) == nil {
+		if s, ok := e.AsSkills(); ok {
 			count := len(s.Languages) + len(s.Frameworks) + len(s.Tools) + len(s.Databases) + len(s.Cloud) + len(s.Other)
 			return fmt.Sprintf("%d skills across categories", count)
 		}
 	case models.CategoryCertifications:
-		var c models.CertificationEntry
-		if json.Unmarshal(dataBytes, &c) == nil {
+		if c, ok := e.AsCertification(); ok {
 			return c.Name
 		}
 	case models.CategoryLanguages:
-		var l models.LanguageEntry
-		if json.Unmarshal(dataBytes, &l) == nil {
+		if l, ok := e.AsLanguage(); ok {
 			if l.Proficiency != "" {
 				return fmt.Sprintf("%s (%s)", l.Language, l.Proficiency)
 			}
 			return l.Language
 		}
+	case models.CategoryProjects:
+		if p, ok := e.AsProject(); ok {
+			if p.Role != "" {
+				return fmt.Sprintf("%s (%s)", p.Name, p.Role)
+			}
+			return p.Name
+		}
+	case models.CategoryPublications:
+		if p, ok := e.AsPublication(); ok {
+			if p.Publisher != "" {
+				return fmt.Sprintf("%s, %s", p.Title, p.Publisher)
+			}
+			return p.Title
+		}
+	case models.CategoryAwards:
+		if a, ok := e.AsAward(); ok {
+			if a.Issuer != "" {
+				return fmt.Sprintf("%s (%s)", a.Title, a.Issuer)
+			}
+			return a.Title
+		}
+	case models.CategoryVolunteering:
+		if v, ok := e.AsVolunteering(); ok {
+			return fmt.Sprintf("%s @ %s (%s - %s)", v.Role, v.Organization, v.StartDate, v.EndDate)
+		}
+	case models.CategoryTalks:
+		if t, ok := e.AsTalk(); ok {
+			return fmt.Sprintf("%s @ %s", t.Title, t.Event)
+		}
 	}
 
+	// Show the raw data for anything that doesn't fit its category
+	dataBytes, err := json.Marshal(e.Data)
+	if err != nil {
+		return ""
+	}
 	return string(dataBytes)
 }
 
@@ -563,7 +889,7 @@ func cmdKBAdd(store *storage.KBStorage, args []string) {
 		// Validate profile category
 		cat := models.ProfileCategory(flags.category)
 		if !cat.IsValid() {
-			fmt.Println("Error: invalid profile category. Must be one of: contact, experience, education, skills, certifications, languages")
+			fmt.Printf("Error: invalid profile category. Must be one of: %s\n", profileCategoryList())
 			os.Exit(1)
 		}
 
@@ -573,13 +899,14 @@ func cmdKBAdd(store *storage.KBStorage, args []string) {
 		}
 
 		// Parse and validate data based on category
-		data, err := parseAndValidateProfileData(cat, flags.data)
+		data, extra, err := parseAndValidateProfileData(cat, flags.data)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		entry = models.NewProfileEntry(cat, data, flags.source)
+		entry.ExtraData = extra
 	} else {
 		// Context entry
 		if flags.content == "" {
@@ -587,11 +914,17 @@ func cmdKBAdd(store *storage.KBStorage, args []string) {
 			os.Exit(1)
 		}
 
-		entry = models.NewContextEntry(flags.category, flags.content, flags.source)
+		// Categories are lower-case since schema version 2
+		category := strings.ToLower(strings.TrimSpace(flags.category))
+		entry = models.NewContextEntry(category, flags.content, flags.source)
 	}
 
 	if err := store.Add(entry); err != nil {
 		fmt.Printf("Error adding entry: %v\n", err)
+		var singleton *models.SingletonError
+		if errors.As(err, &singleton) {
+			fmt.Printf("Update that entry instead: bragger kb update %s --data '...'\n", singleton.ExistingID)
+		}
 		os.Exit(1)
 	}
 
@@ -601,71 +934,26 @@ func cmdKBAdd(store *storage.KBStorage, args []string) {
 	fmt.Printf("Category: %s\n", entry.Category)
 }
 
-func parseAndValidateProfileData(category models.ProfileCategory, jsonData string) (any, error) {
-	switch category {
-	case models.CategoryContact:
-		var data models.ContactData
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for contact: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
-
-	case models.CategoryExperience:
-		var data models.ExperienceEntry
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for experience: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
-
-	case models.CategoryEducation:
-		var data models.EducationEntry
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for education: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
-
-	case models.CategorySkills:
-		var data models.SkillsData
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for skills: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
-
-	case models.CategoryCertifications:
-		var data models.CertificationEntry
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for certification: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
-
-	case models.CategoryLanguages:
-		var data models.LanguageEntry
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			return nil, fmt.Errorf("invalid JSON for language: %v", err)
-		}
-		if err := data.Validate(); err != nil {
-			return nil, err
-		}
-		return data, nil
+func parseAndValidateProfileData(category models.ProfileCategory, jsonData string) (models.ProfileData, map[string]json.RawMessage, error) {
+	if !category.IsValid() {
+		return nil, nil, fmt.Errorf("unknown category: %s", category)
+	}
+	data, extra, err := models.SplitProfileData(category, []byte(jsonData))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON for %s: %v", category, err)
+	}
+	if err := data.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return data, extra, nil
+}
 
-	default:
-		return nil, fmt.Errorf("unknown category: %s", category)
+func profileCategoryList() string {
+	var names []string
+	for _, c := range models.ProfileCategories() {
+		names = append(names, string(c))
 	}
+	return strings.Join(names, ", ")
 }
 
 func cmdKBUpdate(store *storage.KBStorage, id string, args []string) {
@@ -677,25 +965,55 @@ func cmdKBUpdate(store *storage.KBStorage, id string, args []string) {
 
 	fs := flag.NewFlagSet("kb update", flag.ExitOnError)
 	flags := registerKBFlags(fs)
+	merge := fs.String("merge", "", "JSON Merge Patch (RFC 7396) to apply to the profile data")
+	patch := fs.String("patch", "", "JSON Patch (RFC 6902) to apply to the profile data")
 	fs.Parse(args)
 
-	if !flags.hasAnyFlag() {
+	if !flags.hasAnyFlag() && *merge == "" && *patch == "" {
 		fmt.Println("Error: at least one flag required for update")
 		os.Exit(1)
 	}
+	set := 0
+	for _, v := range []string{flags.data, *merge, *patch} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		fmt.Println("Error: use only one of --data, --merge and --patch")
+		os.Exit(1)
+	}
 
 	// Update fields based on entry type
 	if entry.Type == models.KBTypeProfile {
-		if flags.data != "" {
-			cat := models.ProfileCategory(entry.Category)
-			data, err := parseAndValidateProfileData(cat, flags.data)
-			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
-			}
+		var data models.ProfileData
+		var extra map[string]json.RawMessage
+		var err error
+		switch {
+		case flags.data != "":
+			data, extra, err = parseAndValidateProfileData(models.ProfileCategory(entry.Category), flags.data)
+		case *merge != "":
+			data, extra, err = patchProfileData(entry, func(doc []byte) ([]byte, error) {
+				return jsonpatch.MergePatch(doc, []byte(*merge))
+			})
+		case *patch != "":
+			data, extra, err = patchProfileData(entry, func(doc []byte) ([]byte, error) {
+				return jsonpatch.Apply(doc, []byte(*patch))
+			})
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if data != nil {
 			entry.Data = data
+			entry.ExtraData = extra
 		}
 	} else {
+		if *merge != "" || *patch != "" {
+			fmt.Println("Error: --merge and --patch apply to profile entries; use --content for context entries")
+			os.Exit(1)
+		}
 		if flags.content != "" {
 			entry.Content = flags.content
 		}
@@ -711,6 +1029,7 @@ func cmdKBUpdate(store *storage.KBStorage, id string, args []string) {
 	err = store.Update(id, func(e *models.KBEntry) {
 		e.Category = entry.Category
 		e.Data = entry.Data
+		e.ExtraData = entry.ExtraData
 		e.Content = entry.Content
 		e.Source = entry.Source
 	})
@@ -747,5 +1066,5 @@ func cmdKBRemove(store *storage.KBStorage, id string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("Entry removed.")
+	fmt.Println("Entry removed. Restore it with 'bragger undo'.")
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/jsonresume"
+	"github.com/ewurch/bragger/internal/kbimport"
+	"github.com/ewurch/bragger/internal/linkedin"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// The file formats kb import and export understand
+var (
+	kbImportFormats = []string{"jsonresume", "linkedin"}
+	kbExportFormats = []string{"jsonresume"}
+)
+
+func checkKBFormat(format string, supported []string) {
+	for _, f := range supported {
+		if f == format {
+			return
+		}
+	}
+	fmt.Printf("Error: unknown format %q (supported: %s)\n", format, strings.Join(supported, ", "))
+	os.Exit(1)
+}
+
+func cmdKBImport(store *storage.KBStorage, args []string) {
+	// Accept the file before or after the flags
+	var path string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("kb import", flag.ExitOnError)
+	format := fs.String("format", "jsonresume", "File format (jsonresume, linkedin)")
+	yes := fs.Bool("yes", false, "Apply a LinkedIn import without asking")
+	fs.Parse(args)
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	checkKBFormat(*format, kbImportFormats)
+	if path == "" {
+		fmt.Println("Usage: bragger kb import --format jsonresume|linkedin <file>")
+		os.Exit(1)
+	}
+
+	if *format == "linkedin" {
+		importLinkedIn(store, path, *yes)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, result, err := jsonresume.Import(entries, data)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := store.Save(entries); err != nil {
+		fmt.Printf("Error saving knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+
+	added, updated := result.Counts()
+	fmt.Printf("Imported %s: %d added, %d updated, %d unchanged\n", path, added, updated, result.Unchanged)
+	if len(result.Skipped) > 0 {
+		fmt.Println("\nNot imported as profile entries (kept as context so they export again):")
+		for _, s := range result.Skipped {
+			fmt.Printf("  - %s\n", s)
+		}
+	}
+}
+
+func cmdKBExport(store *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("kb export", flag.ExitOnError)
+	format := fs.String("format", "jsonresume", "File format")
+	output := fs.String("output", "", "Output file (default: stdout)")
+	fs.Parse(args)
+	checkKBFormat(*format, kbExportFormats)
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	entries = models.Unarchived(entries)
+
+	data, err := jsonresume.Export(entries)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Printf("Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported knowledge base to %s\n", *output)
+}
+
+// importLinkedIn imports a LinkedIn data export, showing the changes and
+// asking before it saves them
+func importLinkedIn(store *storage.KBStorage, path string, yes bool) {
+	profile, err := linkedin.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading LinkedIn export: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, result := linkedin.Import(entries, profile)
+	added, updated := result.Counts()
+
+	if len(result.Skipped) > 0 {
+		fmt.Println("Not imported:")
+		for _, s := range result.Skipped {
+			fmt.Printf("  - %s\n", s)
+		}
+		fmt.Println()
+	}
+	if len(result.Changes) == 0 {
+		fmt.Printf("Nothing to import from %s: %d entries unchanged\n", path, result.Unchanged)
+		return
+	}
+
+	fmt.Printf("Changes from %s:\n", path)
+	for _, c := range result.Changes {
+		mark := "+"
+		if c.Action == kbimport.ActionUpdate {
+			mark = "~"
+		}
+		fmt.Printf("  %s %-16s %s\n", mark, c.Entry.Category, summarizeImported(c.Entry))
+		if c.Previous != nil {
+			for _, line := range entryChanges(c.Previous, c.Entry) {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+	fmt.Printf("\n%d to add, %d to update, %d unchanged\n", added, updated, result.Unchanged)
+
+	if !yes {
+		fmt.Print("Apply these changes? (y/N): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+		if confirm != "y" && confirm != "Y" && confirm != "yes" {
+			fmt.Println("Cancelled.")
+			return
+		}
+	}
+
+	if err := store.Save(entries); err != nil {
+		fmt.Printf("Error saving knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported %s: %d added, %d updated, %d unchanged\n", path, added, updated, result.Unchanged)
+}
+
+func summarizeImported(e *models.KBEntry) string {
+	if e.Type == models.KBTypeContext {
+		return truncate(strings.ReplaceAll(e.Content, "\n", " "), 60)
+	}
+	return summarizeProfileData(e)
+}
+
+// entryChanges lists the fields an update changes, as "field: old -> new"
+func entryChanges(before, after *models.KBEntry) []string {
+	if before.Type == models.KBTypeContext {
+		return []string{fmt.Sprintf("content: %q -> %q", truncate(before.Content, 40), truncate(after.Content, 40))}
+	}
+
+	fields := func(e *models.KBEntry) map[string]any {
+		data, _ := json.Marshal(e.Data)
+		var m map[string]any
+		json.Unmarshal(data, &m)
+		return m
+	}
+	old, updated := fields(before), fields(after)
+
+	keys := make([]string, 0, len(updated))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range updated {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		o, n := fieldValue(old, k), fieldValue(updated, k)
+		if o != n {
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", k, o, n))
+		}
+	}
+	return lines
+}
+
+func fieldValue(fields map[string]any, key string) string {
+	v, ok := fields[key]
+	if !ok {
+		return "(none)"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ewurch/bragger/internal/lint"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdKBLint checks the KB for problems a resume reader would notice and exits
+// with status 1 if it finds any, for use in CI-style checks
+func cmdKBLint(store *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("kb lint", flag.ExitOnError)
+	gapMonths := fs.Int("gap-months", lint.DefaultGapMonths, "Report gaps between roles longer than this many months")
+	asJSON := fs.Bool("json", false, "Output the issues as JSON")
+	fs.Parse(args)
+
+	if *gapMonths < 0 {
+		fmt.Println("Error: --gap-months can't be negative")
+		os.Exit(1)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	// Archived entries are left out of resumes, so they can't cause issues
+	entries = models.Unarchived(entries)
+
+	var issues []lint.Issue
+	for _, p := range store.Problems() {
+		issue := lint.Issue{Check: lint.CheckInvalid, Message: fmt.Sprintf("line %d: %v", p.Line, p.Err)}
+		if p.ID != "" {
+			issue.IDs = []string{p.ID}
+		}
+		issues = append(issues, issue)
+	}
+	issues = append(issues, lint.Lint(entries, lint.Options{GapMonths: *gapMonths, Now: time.Now()})...)
+
+	if *asJSON {
+		if issues == nil {
+			issues = []lint.Issue{}
+		}
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding issues: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	} else if len(issues) == 0 {
+		fmt.Println("No issues found.")
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("Warning: %s\n", issue)
+		}
+		fmt.Printf("\n%d issue(s) found.\n", len(issues))
+	}
+
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
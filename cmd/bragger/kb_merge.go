@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdKBMerge merges the second of two contact or skills entries into the
+// first and removes it. Skills are unioned; contact fields that differ are
+// asked about, unless --prefer names the entry whose values win.
+func cmdKBMerge(store *storage.KBStorage, args []string) {
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[1], "-") {
+		fmt.Println("Usage: bragger kb merge <id> <id> [--prefer <id>]")
+		os.Exit(1)
+	}
+	keepID, mergeID := args[0], args[1]
+
+	fs := flag.NewFlagSet("kb merge", flag.ExitOnError)
+	prefer := fs.String("prefer", "", "Entry whose contact fields win when they differ (default: ask)")
+	fs.Parse(args[2:])
+
+	if keepID == mergeID {
+		fmt.Println("Error: can't merge an entry with itself")
+		os.Exit(1)
+	}
+	if *prefer != "" && *prefer != keepID && *prefer != mergeID {
+		fmt.Printf("Error: --prefer must be %s or %s\n", keepID, mergeID)
+		os.Exit(1)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	keep, merge := findEntry(entries, keepID), findEntry(entries, mergeID)
+	if keep == nil {
+		fmt.Printf("Entry not found: %s\n", keepID)
+		os.Exit(1)
+	}
+	if merge == nil {
+		fmt.Printf("Entry not found: %s\n", mergeID)
+		os.Exit(1)
+	}
+	if keep.Type != models.KBTypeProfile || keep.Category != merge.Category || !models.ProfileCategory(keep.Category).IsSingleton() {
+		fmt.Println("Error: only two contact entries or two skills entries can be merged")
+		os.Exit(1)
+	}
+
+	switch models.ProfileCategory(keep.Category) {
+	case models.CategorySkills:
+		a, okA := keep.AsSkills()
+		b, okB := merge.AsSkills()
+		if !okA || !okB {
+			fmt.Println("Error: can't read the skills of both entries; fix them with 'bragger kb update' first")
+			os.Exit(1)
+		}
+		keep.Data = models.MergeSkills(a, b)
+	case models.CategoryContact:
+		a, okA := keep.AsContact()
+		b, okB := merge.AsContact()
+		if !okA || !okB {
+			fmt.Println("Error: can't read the contact details of both entries; fix them with 'bragger kb update' first")
+			os.Exit(1)
+		}
+		reader := bufio.NewReader(os.Stdin)
+		keep.Data = models.MergeContact(a, b, func(field, ours, theirs string) string {
+			switch *prefer {
+			case keepID:
+				return ours
+			case mergeID:
+				return theirs
+			}
+			return chooseValue(reader, field, ours, keepID, theirs, mergeID)
+		})
+	}
+	keep.Source = models.MergeSources(keep.Source, merge.Source)
+	if merge.CreatedAt.Before(keep.CreatedAt) {
+		keep.CreatedAt = merge.CreatedAt
+	}
+
+	keep.UpdatedAt = time.Now()
+
+	var merged []*models.KBEntry
+	for _, e := range entries {
+		if e != merge {
+			merged = append(merged, e)
+		}
+	}
+	if err := store.Save(merged); err != nil {
+		fmt.Printf("Error saving knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Merged %s into %s.\n", mergeID, keepID)
+	fmt.Printf("%s: %s\n", keep.Category, summarizeProfileData(keep))
+	fmt.Printf("Source: %s\n", keep.Source)
+}
+
+// chooseValue asks which of two values of a field to keep; the first is the
+// default
+func chooseValue(reader *bufio.Reader, field, first, firstID, second, secondID string) string {
+	fmt.Printf("%s differs:\n  1) %s (%s)\n  2) %s (%s)\n", field, first, firstID, second, secondID)
+	for {
+		fmt.Print("Keep [1/2] (default 1): ")
+		line, err := reader.ReadString('\n')
+		switch strings.TrimSpace(line) {
+		case "", "1":
+			return first
+		case "2":
+			return second
+		}
+		if errors.Is(err, io.EOF) {
+			return first
+		}
+	}
+}
+
+func findEntry(entries []*models.KBEntry, id string) *models.KBEntry {
+	for _, e := range entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/jsonpatch"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// patchProfileData applies a patch to the JSON of a profile entry's data and
+// decodes and validates the result like --data
+func patchProfileData(entry *models.KBEntry, apply func(doc []byte) ([]byte, error)) (models.ProfileData, map[string]json.RawMessage, error) {
+	doc, err := entry.DataJSON()
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't read the data of %s: %v", entry.ID, err)
+	}
+	patched, err := apply(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseAndValidateProfileData(models.ProfileCategory(entry.Category), string(patched))
+}
+
+// highlightCategories are the profile categories with a highlights list
+var highlightCategories = []models.ProfileCategory{models.CategoryExperience, models.CategoryProjects, models.CategoryVolunteering}
+
+// cmdKBHighlight adds a highlight to, or removes one from, an experience,
+// project or volunteering entry without resending its data
+func cmdKBHighlight(store *storage.KBStorage, args []string) {
+	usage := func() {
+		fmt.Println(`Usage:
+  bragger kb highlight add <id> <text> [--at <index>]
+  bragger kb highlight remove <id> <index|text>`)
+		os.Exit(1)
+	}
+	if len(args) < 3 || (args[0] != "add" && args[0] != "remove") {
+		usage()
+	}
+	action, id, text := args[0], args[1], args[2]
+
+	fs := flag.NewFlagSet("kb highlight", flag.ExitOnError)
+	at := fs.Int("at", -1, "Index to insert the highlight at (default: the end)")
+	fs.Parse(args[3:])
+
+	entry, err := store.Get(id)
+	if err != nil {
+		fmt.Printf("Entry not found: %s\n", id)
+		os.Exit(1)
+	}
+	highlights, ok := entryHighlights(entry)
+	if !ok {
+		fmt.Printf("Error: %s is not an experience, projects or volunteering entry\n", id)
+		os.Exit(1)
+	}
+
+	var op jsonpatch.Operation
+	switch action {
+	case "add":
+		value, _ := json.Marshal(text)
+		switch {
+		case len(highlights) == 0 && *at <= 0:
+			op = jsonpatch.Operation{Op: "add", Path: "/highlights", Value: json.RawMessage("[" + string(value) + "]")}
+		case *at < 0:
+			op = jsonpatch.Operation{Op: "add", Path: "/highlights/-", Value: value}
+		default:
+			op = jsonpatch.Operation{Op: "add", Path: fmt.Sprintf("/highlights/%d", *at), Value: value}
+		}
+	case "remove":
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			i = -1
+			for j, h := range highlights {
+				if h == text {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				fmt.Printf("Error: %s has no highlight %q\n", id, text)
+				os.Exit(1)
+			}
+		}
+		op = jsonpatch.Operation{Op: "remove", Path: fmt.Sprintf("/highlights/%d", i)}
+	}
+
+	data, extra, err := patchProfileData(entry, func(doc []byte) ([]byte, error) {
+		return jsonpatch.ApplyOperations(doc, []jsonpatch.Operation{op})
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := store.Update(id, func(e *models.KBEntry) { e.Data, e.ExtraData = data, extra }); err != nil {
+		fmt.Printf("Error updating entry: %v\n", err)
+		os.Exit(1)
+	}
+
+	entry.Data = data
+	highlights, _ = entryHighlights(entry)
+	fmt.Println("Entry updated successfully!")
+	fmt.Printf("Highlights of %s (%s):\n", id, summarizeProfileData(entry))
+	if len(highlights) == 0 {
+		fmt.Println("  (none)")
+	}
+	for i, h := range highlights {
+		fmt.Printf("  %d. %s\n", i, h)
+	}
+}
+
+// entryHighlights returns the highlights of an experience, project or
+// volunteering entry
+func entryHighlights(e *models.KBEntry) ([]string, bool) {
+	if exp, ok := e.AsExperience(); ok {
+		return exp.Highlights, true
+	}
+	if p, ok := e.AsProject(); ok {
+		return p.Highlights, true
+	}
+	if v, ok := e.AsVolunteering(); ok {
+		return v.Highlights, true
+	}
+	return nil, false
+}
+
+// cmdKBSkills adds skills to, or removes them from, the KB's skills entry,
+// creating it on the first add
+func cmdKBSkills(store *storage.KBStorage, args []string) {
+	if len(args) < 1 || (args[0] != "add" && args[0] != "remove") {
+		fmt.Println("Usage: bragger kb skills add|remove [--languages Go,Rust] [--frameworks ...] [--tools ...] [--databases ...] [--cloud ...] [--other ...]")
+		os.Exit(1)
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("kb skills", flag.ExitOnError)
+	var change models.SkillsData
+	groups := []struct {
+		name   string
+		values *[]string
+	}{
+		{"languages", &change.Languages}, {"frameworks", &change.Frameworks}, {"tools", &change.Tools},
+		{"databases", &change.Databases}, {"cloud", &change.Cloud}, {"other", &change.Other},
+	}
+	lists := make([]*string, len(groups))
+	for i, g := range groups {
+		lists[i] = fs.String(g.name, "", "Comma-separated "+g.name)
+	}
+	source := fs.String("source", "user", "Source of information, if the skills entry is created")
+	fs.Parse(args[1:])
+
+	empty := true
+	for i, g := range groups {
+		*g.values = splitList(*lists[i])
+		if len(*g.values) > 0 {
+			empty = false
+		}
+	}
+	if empty {
+		fmt.Println("Error: name at least one skill, e.g. --languages Rust")
+		os.Exit(1)
+	}
+
+	entry, err := store.GetSkills()
+	if errors.Is(err, os.ErrNotExist) {
+		if action == "remove" {
+			fmt.Println("Error: the knowledge base has no skills entry")
+			os.Exit(1)
+		}
+		if err := change.Validate(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		entry = models.NewProfileEntry(models.CategorySkills, change, *source)
+		if err := store.Add(entry); err != nil {
+			fmt.Printf("Error adding entry: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Skills entry added successfully!")
+		fmt.Printf("ID: %s\n", entry.ID)
+		fmt.Printf("Skills: %s\n", summarizeProfileData(entry))
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+
+	skills, ok := entry.AsSkills()
+	if !ok {
+		fmt.Printf("Error: can't read the skills entry %s; fix it with 'bragger kb update' first\n", entry.ID)
+		os.Exit(1)
+	}
+	if action == "add" {
+		skills = models.MergeSkills(skills, change)
+	} else {
+		skills = models.RemoveSkills(skills, change)
+	}
+	if err := skills.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := store.Update(entry.ID, func(e *models.KBEntry) { e.Data = skills }); err != nil {
+		fmt.Printf("Error updating entry: %v\n", err)
+		os.Exit(1)
+	}
+
+	entry.Data = skills
+	fmt.Println("Entry updated successfully!")
+	fmt.Printf("ID: %s\n", entry.ID)
+	fmt.Printf("Skills: %s\n", summarizeProfileData(entry))
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/search"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// searchResult is a search result as 'kb search --json' prints it
+type searchResult struct {
+	ID       string             `json:"id"`
+	Type     models.KBEntryType `json:"type"`
+	Category string             `json:"category"`
+	Score    float64            `json:"score"`
+	Matches  int                `json:"matches"`
+	Field    string             `json:"field"`
+	Snippet  string             `json:"snippet"`
+}
+
+// cmdKBSearch finds the entries mentioning a query, most relevant first,
+// showing where each matched
+func cmdKBSearch(store *storage.KBStorage, args []string) {
+	var words []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		words, args = append(words, args[0]), args[1:]
+	}
+	fs := flag.NewFlagSet("kb search", flag.ExitOnError)
+	entryType := fs.String("type", "", "Only search profile or context entries")
+	category := fs.String("category", "", "Only search entries in this category")
+	all := fs.Bool("all", false, "Include archived entries")
+	limit := fs.Int("limit", 20, "Maximum number of results (0 for all)")
+	asJSON := fs.Bool("json", false, "Output the results as JSON")
+	fs.Parse(args)
+	words = append(words, fs.Args()...)
+
+	if len(words) == 0 {
+		fmt.Println("Usage: bragger kb search <query> [--type profile|context] [--category <category>] [--limit N] [--json]")
+		os.Exit(1)
+	}
+	if *entryType != "" && !models.KBEntryType(*entryType).IsValid() {
+		fmt.Printf("Error: invalid type %q: use profile or context\n", *entryType)
+		os.Exit(1)
+	}
+
+	// An argument the shell unquoted, like "event driven", is a phrase
+	for i, w := range words {
+		if strings.ContainsAny(w, " \t") && !strings.Contains(w, `"`) {
+			words[i] = `"` + w + `"`
+		}
+	}
+	text := strings.Join(words, " ")
+	query, err := search.ParseQuery(text)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	var entries []*models.KBEntry
+	for _, e := range loaded {
+		if (*entryType != "" && string(e.Type) != *entryType) || (*category != "" && e.Category != *category) {
+			continue
+		}
+		if e.IsArchived() && !*all {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	results := search.Search(entries, query)
+	total := len(results)
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+
+	if *asJSON {
+		out := make([]searchResult, len(results))
+		for i, r := range results {
+			out[i] = searchResult{
+				ID:       r.Entry.ID,
+				Type:     r.Entry.Type,
+				Category: r.Entry.Category,
+				Score:    math.Round(r.Score*100) / 100,
+				Matches:  r.Matches,
+				Field:    r.Field,
+				Snippet:  r.Snippet,
+			}
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding results: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if total == 0 {
+		fmt.Printf("No entries match '%s'.\n", text)
+		return
+	}
+	fmt.Printf("Found %s matching '%s'", plural(total, "entry"), text)
+	if len(results) < total {
+		fmt.Printf(" (showing the top %d; see more with --limit)", len(results))
+	}
+	fmt.Println(":")
+	for _, r := range results {
+		e := r.Entry
+		fmt.Printf("\n%s  %s/%s", e.ID, e.Type, archivedLabel(e.Category, e.IsArchived()))
+		if e.Type == models.KBTypeProfile {
+			fmt.Printf("  %s", summarizeProfileData(e))
+		}
+		if r.Matches == 1 {
+			fmt.Println("  (1 match)")
+		} else {
+			fmt.Printf("  (%d matches)\n", r.Matches)
+		}
+		fmt.Printf("  %s: %s\n", r.Field, r.Snippet)
+	}
+}
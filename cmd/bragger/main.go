@@ -11,6 +11,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/ewurch/bragger/internal/gitsync"
+	"github.com/ewurch/bragger/internal/jd"
 	"github.com/ewurch/bragger/internal/models"
 	"github.com/ewurch/bragger/internal/storage"
 	"github.com/ewurch/bragger/templates"
@@ -28,6 +30,7 @@ type appFlags struct {
 	companyURL string
 	resumePath string
 	notes      string
+	fetch      bool
 }
 
 // registerAppFlags registers all application flags on a FlagSet
@@ -35,14 +38,15 @@ func registerAppFlags(fs *flag.FlagSet) *appFlags {
 	f := &appFlags{}
 	fs.StringVar(&f.company, "company", "", "Company name")
 	fs.StringVar(&f.role, "role", "", "Job title")
-	fs.StringVar(&f.status, "status", "", "Application status (applied/interviewing/rejected/offer)")
-	fs.StringVar(&f.date, "date", "", "Date applied (YYYY-MM-DD format)")
+	fs.StringVar(&f.status, "status", "", "Application status (applied/interviewing/rejected/offer/wishlist)")
+	fs.StringVar(&f.date, "date", "", "Date applied (YYYY-MM-DD, or e.g. \"Jan 15 2025\")")
 	fs.StringVar(&f.jdURL, "jd-url", "", "Job description URL")
 	fs.StringVar(&f.jdContent, "jd-content", "", "Job description text (inline)")
 	fs.StringVar(&f.jdFile, "jd-file", "", "Path to file containing job description")
 	fs.StringVar(&f.companyURL, "company-url", "", "Company website")
 	fs.StringVar(&f.resumePath, "resume-path", "", "Path to resume file")
 	fs.StringVar(&f.notes, "notes", "", "Notes about application")
+	fs.BoolVar(&f.fetch, "fetch", false, "Download the job description from --jd-url")
 	return f
 }
 
@@ -50,7 +54,7 @@ func registerAppFlags(fs *flag.FlagSet) *appFlags {
 func (f *appFlags) hasAnyFlag() bool {
 	return f.company != "" || f.role != "" || f.status != "" || f.date != "" ||
 		f.jdURL != "" || f.jdContent != "" || f.jdFile != "" ||
-		f.companyURL != "" || f.resumePath != "" || f.notes != ""
+		f.companyURL != "" || f.resumePath != "" || f.notes != "" || f.fetch
 }
 
 // validate checks flag values and returns an error message if invalid
@@ -59,25 +63,40 @@ func (f *appFlags) validate() string {
 	if f.jdContent != "" && f.jdFile != "" {
 		return "Error: --jd-content and --jd-file cannot be used together"
 	}
+	if f.fetch && (f.jdContent != "" || f.jdFile != "") {
+		return "Error: --fetch cannot be used with --jd-content or --jd-file"
+	}
 
 	// Validate status if provided
 	if f.status != "" {
 		status := models.Status(f.status)
 		if !status.IsValid() {
-			return "Error: --status must be one of: applied, interviewing, rejected, offer"
+			return "Error: --status must be one of: applied, interviewing, rejected, offer, wishlist"
 		}
 	}
 
 	// Validate date format if provided
 	if f.date != "" {
-		if _, err := time.Parse("2006-01-02", f.date); err != nil {
-			return "Error: --date must be in YYYY-MM-DD format"
+		if _, err := parseDateApplied(f.date); err != nil {
+			return "Error: --date must be in YYYY-MM-DD format (or like \"Jan 15 2025\")"
 		}
 	}
 
 	return ""
 }
 
+// parseDateApplied parses an application date, which must include the day
+func parseDateApplied(s string) (models.Date, error) {
+	date, err := models.ParseDate(s)
+	if err != nil {
+		return "", err
+	}
+	if date.IsPresent() || date.Precision() != models.PrecisionDay {
+		return "", fmt.Errorf("date applied %q must include the day", s)
+	}
+	return date, nil
+}
+
 // loadJDContent reads JD content from file if jdFile is set
 func (f *appFlags) loadJDContent() (string, error) {
 	if f.jdFile != "" {
@@ -102,7 +121,11 @@ func (f *appFlags) applyToApplication(app *models.Application) error {
 		app.Status = models.Status(f.status)
 	}
 	if f.date != "" {
-		app.DateApplied = f.date
+		date, err := parseDateApplied(f.date)
+		if err != nil {
+			return fmt.Errorf("Error: %v", err)
+		}
+		app.DateApplied = date
 	}
 	if f.jdURL != "" {
 		app.JDURL = f.jdURL
@@ -137,13 +160,36 @@ func main() {
 
 	// Check for outdated workspace (skip for init/upgrade/help/version)
 	cmd := os.Args[1]
-	if cmd != "init" && cmd != "upgrade" && cmd != "help" && cmd != "version" {
+	if cmd != "init" && cmd != "upgrade" && cmd != "help" && cmd != "version" && cmd != "agent" {
 		checkAndWarnOutdatedWorkspace()
 	}
 
+	journal := newJournal()
 	store := storage.New("")
+	store.SetJournal(journal)
 
 	kbStore := storage.NewKBStorage("")
+	kbStore.SetJournal(journal)
+
+	// These handle the key themselves, or don't read the data
+	switch cmd {
+	case "init", "help", "version", "encrypt", "decrypt", "unlock", "lock", "agent", "sync":
+	default:
+		unlockWorkspace(store, kbStore, journal)
+	}
+
+	repo := gitsync.Open("")
+	autoCommitting := cmd != "sync" && repo.AutoCommit()
+	if autoCommitting {
+		setupAutoCommit(repo, journal)
+	}
+	switch cmd {
+	case "kb", "match", "gaps", "render":
+		// kb lint reports invalid entries itself
+		if cmd != "kb" || len(os.Args) < 3 || os.Args[2] != "lint" {
+			warnInvalidKBEntries(kbStore)
+		}
+	}
 
 	switch os.Args[1] {
 	case "init":
@@ -151,7 +197,7 @@ func main() {
 	case "add":
 		cmdAdd(store, os.Args[2:])
 	case "list":
-		cmdList(store)
+		cmdList(store, os.Args[2:])
 	case "update":
 		if len(os.Args) < 3 {
 			fmt.Println("Usage: bragger update <id> [flags]")
@@ -171,9 +217,55 @@ func main() {
 		}
 		cmdShow(store, os.Args[2])
 	case "stats":
-		cmdStats(store)
+		cmdStats(store, os.Args[2:])
+	case "archive", "unarchive":
+		if len(os.Args) < 3 {
+			fmt.Printf("Usage: bragger %s <id>\n", os.Args[1])
+			os.Exit(1)
+		}
+		if os.Args[1] == "archive" {
+			cmdArchive(store, os.Args[2])
+		} else {
+			cmdUnarchive(store, os.Args[2])
+		}
+	case "purge":
+		cmdPurge(store, os.Args[2:])
+	case "jd":
+		if len(os.Args) < 3 {
+			printJDUsage()
+			os.Exit(1)
+		}
+		cmdJD(store, os.Args[2], os.Args[3:])
+	case "capture-server":
+		cmdCaptureServer(store, os.Args[2:])
+	case "match":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: bragger match <id> [--resume <file.html>] [--json]")
+			os.Exit(1)
+		}
+		cmdMatch(store, kbStore, os.Args[2], os.Args[3:])
+	case "gaps":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: bragger gaps <id> [--json]")
+			os.Exit(1)
+		}
+		cmdGaps(store, kbStore, os.Args[2], os.Args[3:])
+	case "render":
+		if len(os.Args) < 3 {
+			printRenderUsage()
+			os.Exit(1)
+		}
+		cmdRender(store, kbStore, os.Args[2], os.Args[3:])
+	case "pdf":
+		cmdPDF(store, os.Args[2:])
 	case "upgrade":
-		cmdUpgrade()
+		cmdUpgrade(store, kbStore, os.Args[2:])
+	case "unredact":
+		cmdUnredact(kbStore, os.Args[2:])
+	case "history":
+		cmdHistory(journal, os.Args[2:])
+	case "undo":
+		cmdUndo(journal, store, kbStore, os.Args[2:])
 	case "version":
 		cmdVersion()
 	case "kb":
@@ -181,7 +273,19 @@ func main() {
 			printKBUsage()
 			os.Exit(1)
 		}
-		cmdKB(kbStore, os.Args[2], os.Args[3:])
+		cmdKB(store, kbStore, os.Args[2], os.Args[3:])
+	case "sync":
+		cmdSync(os.Args[2:])
+	case "encrypt":
+		cmdEncrypt(store, kbStore, journal)
+	case "decrypt":
+		cmdDecrypt(store, kbStore, journal)
+	case "unlock":
+		cmdUnlock(os.Args[2:])
+	case "lock":
+		cmdLock()
+	case "agent":
+		cmdAgent(os.Args[2:])
 	case "help":
 		printUsage()
 	default:
@@ -189,6 +293,14 @@ func main() {
 		printUsage()
 		os.Exit(1)
 	}
+
+	// These rewrite the data files and key parameters without journaling
+	switch cmd {
+	case "encrypt", "decrypt":
+		if autoCommitting {
+			autoCommit(repo, journal.Command)
+		}
+	}
 }
 
 func printUsage() {
@@ -200,37 +312,94 @@ Usage:
 Commands:
   init             Initialize a Bragger workspace in the current directory
   add              Add a new application
-  list             List all applications
+  list             List applications (--all includes archived ones)
   show <id>        Show details of an application
   update <id>      Update an application
   remove <id>      Remove an application
-  stats            Show application statistics
+  archive <id>     Hide an application from list and stats, keeping it (unarchive <id> restores it)
+  purge [id...]    Permanently delete archived applications (--yes skips the confirmation)
+  stats            Show application statistics (--all includes archived applications)
+  jd <subcommand>  Fetch and process job descriptions (run 'bragger jd' for details)
+  capture-server   Run a local endpoint for saving jobs from the browser
+  match <id>       Score JD keywords against the knowledge base (and a resume)
+  gaps <id>        Report which JD requirements the knowledge base covers
+  render <doc>     Build a resume from the KB or convert a cover letter, as HTML or Word (run 'bragger render' for details)
+  pdf <file.html>  Convert a generated resume or cover letter to PDF
+  unredact <file>  Put the contact details 'kb context --redact' replaced back into a generated file
   kb <subcommand>  Manage candidate knowledge base (run 'bragger kb' for details)
-  upgrade          Upgrade workspace to latest version
+  history [id]     Show the changes made to applications and the KB (or to one record)
+  undo             Revert the last change (run again to go further back)
+  sync <subcommand>
+                   Version the workspace in git and sync it between machines (run 'bragger sync' for details)
+  encrypt          Encrypt applications, the KB and the change journal with a passphrase
+  decrypt          Decrypt them back to plaintext
+  unlock           Keep an encrypted workspace unlocked for a while (--timeout 8h), or --print the key
+  lock             Lock the workspace again
+  upgrade          Upgrade workspace templates and migrate data to the latest version
   version          Show CLI and workspace version
   help             Show this help message
 
 Flags for add command (optional - without flags, runs interactively):
   --company        Company name (required with flags)
   --role           Job title (required with flags)
-  --status         Status: applied, interviewing, rejected, offer (default: applied)
-  --date           Date applied in YYYY-MM-DD format (default: today)
+  --status         Status: applied, interviewing, rejected, offer, wishlist (default: applied)
+  --date           Date applied, e.g. 2025-01-15 or "Jan 15 2025" (default: today)
   --jd-url         Job description URL
   --jd-content     Job description text (inline)
   --jd-file        Path to file containing job description
   --company-url    Company website
   --resume-path    Path to resume file
   --notes          Notes about application
+  --fetch          Download the JD from --jd-url (company/role are read from the posting if omitted)
 
 Flags for update command (optional - without flags, runs interactively):
   All flags from add command are supported. Only provided flags will be updated.
 
+Flags for capture-server:
+  --addr           Address to listen on (default: 127.0.0.1:7878)
+  --status         Status for captured jobs (default: applied)
+  --token          Shared secret for the X-Bragger-Token header (or BRAGGER_CAPTURE_TOKEN)
+
+Flags for match command:
+  --resume         Generated resume HTML to score against the JD
+  --json           Output the report as JSON
+
+Flags for gaps command:
+  --json           Output the report as JSON
+
+Flags for history command:
+  --limit          Number of changes to show, newest first (default: 20, 0 for all)
+  --json           Output the changes as JSON
+
+Flags for undo command:
+  --steps          Number of changes to revert (default: 1)
+
+Flags for unredact command:
+  --output         Write the result to another file (default: overwrite the file)
+
+Flags for unlock command:
+  --timeout        How long to stay unlocked (default: 8h)
+  --print          Print the key, e.g. for export BRAGGER_KEY=$(bragger unlock --print)
+
+Environment for encrypted workspaces:
+  BRAGGER_KEY          Key printed by 'bragger unlock --print'
+  BRAGGER_PASSPHRASE   Passphrase, for scripts (the key is derived on every command)
+
+Flags for upgrade command:
+  --dry-run        List the files and records that would change without writing anything
+
+Flags for pdf command:
+  --app            Convert the application's resume.html and cover_letter.html
+  --size           Page size: a4 or letter (default: from the document, else A4)
+  --output         Output file (default: the HTML path with a .pdf extension)
+
 Examples:
   bragger init
   bragger add                                            # Interactive mode
   bragger add --company "Acme" --role "Engineer"         # Flag mode (quick)
   bragger add --company "Acme" --role "Engineer" --jd-url "https://..."
   bragger add --company "Acme" --role "Engineer" --jd-file ./jd.txt
+  bragger add --jd-url "https://boards.greenhouse.io/acme/jobs/123" --fetch
   bragger add --company "Old" --role "Dev" --date "2025-01-15" --status "interviewing"
   bragger list
   bragger show app-a1b2c3d4
@@ -238,6 +407,19 @@ Examples:
   bragger update app-a1b2c3d4 --status "interviewing"    # Flag mode (quick)
   bragger update app-a1b2c3d4 --status "offer" --notes "Accepted!"
   bragger remove app-a1b2c3d4
+  bragger archive app-a1b2c3d4                           # Hide a stale application
+  bragger list --all                                     # Include archived applications
+  bragger history app-a1b2c3d4                           # What changed, when and by whom
+  bragger undo --steps 2
+  bragger encrypt                                        # Asks for a new passphrase
+  bragger unlock --timeout 2h
+  bragger capture-server --status wishlist               # Save jobs from the browser
+  bragger match app-a1b2c3d4 --resume outputs/acme_engineer/resume.html
+  bragger gaps app-a1b2c3d4 --json
+  bragger render resume --app app-a1b2c3d4 --theme classic
+  bragger render resume --app app-a1b2c3d4 --format docx
+  bragger pdf outputs/acme_engineer/resume.html
+  bragger unredact outputs/acme_engineer/resume.html
   bragger kb show                                        # Show knowledge base
   bragger kb add --type profile --category contact --data '{"name":"John"}'`)
 }
@@ -249,12 +431,18 @@ func cmdAdd(store *storage.Storage, args []string) {
 
 	var app *models.Application
 
+	var fetched *jd.FetchResult
+
 	if flags.hasAnyFlag() {
-		// Flag mode: validate required fields
-		if flags.company == "" || flags.role == "" {
+		// Flag mode: validate required fields (--fetch can fill them in from the JD)
+		if (flags.company == "" || flags.role == "") && !flags.fetch {
 			fmt.Println("Error: --company and --role are required when using flags")
 			os.Exit(1)
 		}
+		if flags.fetch && flags.jdURL == "" {
+			fmt.Println("Error: --fetch requires --jd-url")
+			os.Exit(1)
+		}
 
 		// Validate flags
 		if errMsg := flags.validate(); errMsg != "" {
@@ -267,6 +455,15 @@ func cmdAdd(store *storage.Storage, args []string) {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+
+		if flags.fetch {
+			result, err := fetchJD(app)
+			if err != nil {
+				fmt.Printf("Error fetching job description: %v\n", err)
+				os.Exit(1)
+			}
+			fetched = result
+		}
 	} else {
 		// Interactive mode (original behavior)
 		reader := bufio.NewReader(os.Stdin)
@@ -328,17 +525,33 @@ func cmdAdd(store *storage.Storage, args []string) {
 	fmt.Printf("Role: %s\n", app.Role)
 	fmt.Printf("Status: %s\n", app.Status)
 	fmt.Printf("Date Applied: %s\n", app.DateApplied)
+
+	if fetched != nil {
+		fmt.Println()
+		printFetchResult(app, fetched)
+	}
 }
 
-func cmdList(store *storage.Storage) {
+func cmdList(store *storage.Storage, args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include archived applications")
+	fs.Parse(args)
+
 	apps, err := store.Load()
 	if err != nil {
 		fmt.Printf("Error loading applications: %v\n", err)
 		os.Exit(1)
 	}
+	archived := 0
+	if !*all {
+		active := models.Unarchived(apps)
+		archived = len(apps) - len(active)
+		apps = active
+	}
 
 	if len(apps) == 0 {
 		fmt.Println("No applications found.")
+		printArchivedHint(archived, "list")
 		return
 	}
 
@@ -351,13 +564,14 @@ func cmdList(store *storage.Storage) {
 			app.ID,
 			truncate(app.Company, 20),
 			truncate(app.Role, 25),
-			app.Status,
+			archivedLabel(string(app.Status), app.IsArchived()),
 			app.DateApplied,
 		)
 	}
 	w.Flush()
 
 	fmt.Printf("\nTotal: %d applications\n", len(apps))
+	printArchivedHint(archived, "list")
 }
 
 func cmdShow(store *storage.Storage, id string) {
@@ -372,10 +586,16 @@ func cmdShow(store *storage.Storage, id string) {
 	fmt.Printf("Role:         %s\n", app.Role)
 	fmt.Printf("Status:       %s\n", app.Status)
 	fmt.Printf("Date Applied: %s\n", app.DateApplied)
+	if app.ArchivedAt != nil {
+		fmt.Printf("Archived:     %s\n", app.ArchivedAt.Format("2006-01-02"))
+	}
 
 	if app.JDURL != "" {
 		fmt.Printf("JD URL:       %s\n", app.JDURL)
 	}
+	if app.JDSnapshot != "" {
+		fmt.Printf("JD Snapshot:  %s\n", app.JDSnapshot)
+	}
 	if app.CompanyURL != "" {
 		fmt.Printf("Company URL:  %s\n", app.CompanyURL)
 	}
@@ -392,6 +612,11 @@ func cmdShow(store *storage.Storage, id string) {
 	if app.JDContent != "" {
 		fmt.Printf("\n--- Job Description ---\n%s\n", app.JDContent)
 	}
+
+	if app.JDAnalysis != nil {
+		fmt.Printf("\n--- JD Analysis (%s) ---\n", app.JDAnalysis.AnalyzedAt.Format("2006-01-02"))
+		printJDAnalysisMarkdown(app.JDAnalysis)
+	}
 }
 
 func cmdUpdate(store *storage.Storage, id string, args []string) {
@@ -412,10 +637,27 @@ func cmdUpdate(store *storage.Storage, id string, args []string) {
 			os.Exit(1)
 		}
 
+		previousJD := app.JDContent
 		if err := flags.applyToApplication(app); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		if app.JDContent != previousJD {
+			refreshJDAnalysis(app)
+		}
+
+		var fetched *jd.FetchResult
+		if flags.fetch {
+			if app.JDURL == "" {
+				fmt.Println("Error: --fetch requires a JD URL (set --jd-url)")
+				os.Exit(1)
+			}
+			fetched, err = fetchJD(app)
+			if err != nil {
+				fmt.Printf("Error fetching job description: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
 		// Save the updated application
 		err = store.Update(id, func(a *models.Application) {
@@ -425,6 +667,8 @@ func cmdUpdate(store *storage.Storage, id string, args []string) {
 			a.DateApplied = app.DateApplied
 			a.JDURL = app.JDURL
 			a.JDContent = app.JDContent
+			a.JDSnapshot = app.JDSnapshot
+			a.JDAnalysis = app.JDAnalysis
 			a.CompanyURL = app.CompanyURL
 			a.ResumePath = app.ResumePath
 			a.Notes = app.Notes
@@ -440,19 +684,24 @@ func cmdUpdate(store *storage.Storage, id string, args []string) {
 		fmt.Printf("Role: %s\n", app.Role)
 		fmt.Printf("Status: %s\n", app.Status)
 		fmt.Printf("Date Applied: %s\n", app.DateApplied)
+
+		if fetched != nil {
+			fmt.Println()
+			printFetchResult(app, fetched)
+		}
 	} else {
 		// Interactive mode
 		reader := bufio.NewReader(os.Stdin)
 
 		fmt.Printf("Current status: %s\n", app.Status)
-		fmt.Print("New status (applied/interviewing/rejected/offer) [enter to skip]: ")
+		fmt.Print("New status (applied/interviewing/rejected/offer/wishlist) [enter to skip]: ")
 		status, _ := reader.ReadString('\n')
 		status = strings.TrimSpace(status)
 
 		if status != "" {
 			newStatus := models.Status(status)
 			if !newStatus.IsValid() {
-				fmt.Println("Invalid status. Must be: applied, interviewing, rejected, or offer")
+				fmt.Println("Invalid status. Must be: applied, interviewing, rejected, offer, or wishlist")
 				os.Exit(1)
 			}
 
@@ -509,7 +758,7 @@ func cmdRemove(store *storage.Storage, id string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("Application removed.")
+	fmt.Println("Application removed. Restore it with 'bragger undo'.")
 }
 
 func truncate(s string, max int) string {
@@ -545,12 +794,19 @@ func cmdVersion() {
 }
 
 // cmdStats displays application statistics
-func cmdStats(store *storage.Storage) {
+func cmdStats(store *storage.Storage, args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include archived applications")
+	fs.Parse(args)
+
 	apps, err := store.Load()
 	if err != nil {
 		fmt.Printf("Error loading applications: %v\n", err)
 		os.Exit(1)
 	}
+	if !*all {
+		apps = models.Unarchived(apps)
+	}
 
 	// Empty state
 	if len(apps) == 0 {
@@ -565,6 +821,7 @@ func cmdStats(store *storage.Storage) {
 		models.StatusInterviewing: 0,
 		models.StatusRejected:     0,
 		models.StatusOffer:        0,
+		models.StatusWishlist:     0,
 	}
 	for _, app := range apps {
 		statusCounts[app.Status]++
@@ -573,8 +830,7 @@ func cmdStats(store *storage.Storage) {
 	// Count by month
 	monthCounts := make(map[string]int)
 	for _, app := range apps {
-		if len(app.DateApplied) >= 7 {
-			month := app.DateApplied[:7] // YYYY-MM
+		if month := app.DateApplied.Month(); month != "" {
 			monthCounts[month]++
 		}
 	}
@@ -613,6 +869,7 @@ func cmdStats(store *storage.Storage) {
 		models.StatusInterviewing,
 		models.StatusRejected,
 		models.StatusOffer,
+		models.StatusWishlist,
 	}
 
 	for _, status := range statuses {
@@ -640,18 +897,24 @@ func cmdStats(store *storage.Storage) {
 		fmt.Println()
 	}
 
-	// Calculate metrics
+	// Calculate metrics (wishlist entries haven't been submitted yet)
+	submitted := total - statusCounts[models.StatusWishlist]
+	if submitted == 0 {
+		fmt.Println("No submitted applications yet.")
+		return
+	}
+
 	responded := statusCounts[models.StatusInterviewing] + statusCounts[models.StatusRejected] + statusCounts[models.StatusOffer]
 	interviewed := statusCounts[models.StatusInterviewing] + statusCounts[models.StatusOffer]
 	offers := statusCounts[models.StatusOffer]
 
-	responseRate := float64(responded) / float64(total) * 100
-	interviewRate := float64(interviewed) / float64(total) * 100
-	offerRate := float64(offers) / float64(total) * 100
+	responseRate := float64(responded) / float64(submitted) * 100
+	interviewRate := float64(interviewed) / float64(submitted) * 100
+	offerRate := float64(offers) / float64(submitted) * 100
 
-	fmt.Printf("Response Rate: %.0f%% (%d of %d received a response)\n", responseRate, responded, total)
-	fmt.Printf("Interview Rate: %.0f%% (%d of %d reached interview stage or beyond)\n", interviewRate, interviewed, total)
-	fmt.Printf("Offer Rate: %.0f%% (%d of %d)\n", offerRate, offers, total)
+	fmt.Printf("Response Rate: %.0f%% (%d of %d received a response)\n", responseRate, responded, submitted)
+	fmt.Printf("Interview Rate: %.0f%% (%d of %d reached interview stage or beyond)\n", interviewRate, interviewed, submitted)
+	fmt.Printf("Offer Rate: %.0f%% (%d of %d)\n", offerRate, offers, submitted)
 }
 
 // renderBar creates an ASCII bar chart
@@ -692,3 +955,27 @@ func checkAndWarnOutdatedWorkspace() {
 			currentVersion, templates.Version)
 	}
 }
+
+// warnInvalidKBEntries prints the KB lines that can't be read and the
+// entries that fail validation to stderr, so they get fixed
+func warnInvalidKBEntries(kbStore *storage.KBStorage) {
+	if _, err := kbStore.Load(); err != nil {
+		return // the command reports it
+	}
+	problems := kbStore.Problems()
+	if len(problems) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: %s has %d invalid entries:\n", storage.DefaultKBFilePath, len(problems))
+	skipped := false
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "  %s\n", p)
+		skipped = skipped || p.ID == ""
+	}
+	fmt.Fprintln(os.Stderr, "Fix them with 'bragger kb update <id> --data ...' or remove them with 'bragger kb remove <id>'.")
+	if skipped {
+		fmt.Fprintf(os.Stderr, "Skipped lines are dropped the next time the KB is saved; edit %s to keep them.\n", storage.DefaultKBFilePath)
+	}
+	fmt.Fprintln(os.Stderr)
+}
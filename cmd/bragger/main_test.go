@@ -1,11 +1,22 @@
 package main
 
 import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/templates"
+	"gopkg.in/yaml.v3"
 )
 
 var binaryPath string
@@ -308,6 +319,39 @@ func TestCLIList(t *testing.T) {
 	})
 }
 
+func TestCLIStatsWishlist(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	runApp(t, workDir, "add", "--company", "Corp1", "--role", "Role1", "--status", "interviewing")
+	runApp(t, workDir, "add", "--company", "Corp2", "--role", "Role2", "--status", "wishlist")
+
+	output, err := runApp(t, workDir, "stats")
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "wishlist:") {
+		t.Errorf("expected wishlist in status breakdown, got: %s", output)
+	}
+	// Wishlist entries are not counted as submitted applications
+	if !strings.Contains(output, "Response Rate: 100% (1 of 1 received a response)") {
+		t.Errorf("expected response rate over submitted applications, got: %s", output)
+	}
+}
+
+func TestCLICaptureServerErrors(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	output, err := runApp(t, workDir, "capture-server", "--status", "maybe")
+	if err == nil {
+		t.Error("expected error for invalid status")
+	}
+	if !strings.Contains(output, "--status must be one of") {
+		t.Errorf("expected status error, got: %s", output)
+	}
+}
+
 // Helper to extract app ID from add command output
 func extractAppID(output string) string {
 	lines := strings.Split(output, "\n")
@@ -522,6 +566,495 @@ func TestCLIShowAndVerifyJDContent(t *testing.T) {
 	}
 }
 
+func TestCLIJDFetch(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/jsonld":
+			w.Write([]byte(`<html><head><title>Careers</title>
+<script type="application/ld+json">{"@type":"JobPosting","title":"Platform Engineer","hiringOrganization":{"name":"Globex"},"description":"<p>Run Kubernetes clusters.</p>"}</script>
+</head><body><p>Cookie banner</p></body></html>`))
+		case "/plain":
+			w.Write([]byte(`<html><body><h1>Backend Engineer</h1><p>Write Go services.</p></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("add with fetch fills company and role", func(t *testing.T) {
+		output, err := runApp(t, workDir, "add", "--jd-url", server.URL+"/jsonld", "--fetch")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Company: Globex") || !strings.Contains(output, "Role: Platform Engineer") {
+			t.Errorf("expected company and role from JSON-LD, got: %s", output)
+		}
+		if !strings.Contains(output, "schema.org JobPosting") {
+			t.Errorf("expected JSON-LD source in output, got: %s", output)
+		}
+
+		showOutput, _ := runApp(t, workDir, "show", extractAppID(output))
+		if !strings.Contains(showOutput, "Run Kubernetes clusters.") {
+			t.Errorf("expected fetched JD in show output, got: %s", showOutput)
+		}
+		if strings.Contains(showOutput, "Cookie banner") {
+			t.Errorf("expected page chrome to be dropped, got: %s", showOutput)
+		}
+
+		snapshots, _ := filepath.Glob(filepath.Join(workDir, ".bragger", "snapshots", "app-*.html"))
+		if len(snapshots) != 1 {
+			t.Errorf("expected one snapshot, got %v", snapshots)
+		}
+	})
+
+	t.Run("jd fetch for existing application", func(t *testing.T) {
+		addOutput, _ := runApp(t, workDir, "add", "--company", "Initech", "--role", "Backend Engineer",
+			"--jd-url", server.URL+"/plain")
+		appID := extractAppID(addOutput)
+
+		output, err := runApp(t, workDir, "jd", "fetch", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Snapshot: .bragger/snapshots/"+appID) {
+			t.Errorf("expected snapshot path, got: %s", output)
+		}
+
+		showOutput, _ := runApp(t, workDir, "show", appID)
+		if !strings.Contains(showOutput, "Write Go services.") {
+			t.Errorf("expected fetched JD, got: %s", showOutput)
+		}
+		if !strings.Contains(showOutput, "JD Snapshot:") {
+			t.Errorf("expected snapshot in show output, got: %s", showOutput)
+		}
+	})
+
+	t.Run("fetch errors", func(t *testing.T) {
+		output, err := runApp(t, workDir, "add", "--company", "X", "--role", "Y", "--fetch")
+		if err == nil || !strings.Contains(output, "--fetch requires --jd-url") {
+			t.Errorf("expected --jd-url error, got: %s", output)
+		}
+
+		output, err = runApp(t, workDir, "add", "--jd-url", server.URL+"/missing", "--fetch")
+		if err == nil || !strings.Contains(output, "Error fetching job description") {
+			t.Errorf("expected fetch error, got: %s", output)
+		}
+
+		// A posting without a company or role is refused before it's archived
+		before, _ := filepath.Glob(filepath.Join(workDir, ".bragger", "snapshots", "*"))
+		output, err = runApp(t, workDir, "add", "--jd-url", server.URL+"/plain", "--fetch")
+		if err == nil || !strings.Contains(output, "could not determine company and role") {
+			t.Errorf("expected company and role error, got: %s", output)
+		}
+		if after, _ := filepath.Glob(filepath.Join(workDir, ".bragger", "snapshots", "*")); len(after) != len(before) {
+			t.Errorf("expected no snapshot saved, got %v", after)
+		}
+
+		addOutput, _ := runApp(t, workDir, "add", "--company", "NoURL", "--role", "Dev")
+		output, err = runApp(t, workDir, "jd", "fetch", extractAppID(addOutput))
+		if err == nil || !strings.Contains(output, "has no JD URL") {
+			t.Errorf("expected missing URL error, got: %s", output)
+		}
+	})
+}
+
+func TestCLIJDAnalyze(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	jdContent := "Senior Backend Engineer\nLocation: Berlin\n\nResponsibilities\n- Build Go services\n\n" +
+		"Requirements\n- 5+ years of experience with Go\n\nNice to have\n- Kafka\n\nFully remote. €80k - €95k per year"
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Backend Engineer", "--jd-content", jdContent)
+	appID := extractAppID(addOutput)
+
+	t.Run("analyze prints and stores markdown", func(t *testing.T) {
+		output, err := runApp(t, workDir, "jd", "analyze", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		expected := []string{
+			"## JD Analysis: Backend Engineer @ Acme",
+			"- **Seniority:** senior",
+			"- **Location:** Berlin",
+			"- **Remote Policy:** remote",
+			"- **Salary:** EUR 80,000 - 95,000 per year",
+			"  - 5+ years Go",
+			"### Responsibilities\n- Build Go services",
+			"### Must Have\n- 5+ years of experience with Go",
+			"### Nice to Have\n- Kafka",
+		}
+		for _, want := range expected {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+
+		showOutput, _ := runApp(t, workDir, "show", appID)
+		if !strings.Contains(showOutput, "--- JD Analysis") || !strings.Contains(showOutput, "### Must Have") {
+			t.Errorf("expected analysis in show output, got: %s", showOutput)
+		}
+	})
+
+	t.Run("analysis refreshes when JD changes", func(t *testing.T) {
+		runApp(t, workDir, "update", appID, "--jd-content", "Junior Developer\n\nRequirements\n- Python")
+		showOutput, _ := runApp(t, workDir, "show", appID)
+		if !strings.Contains(showOutput, "- **Seniority:** junior") || strings.Contains(showOutput, "5+ years Go") {
+			t.Errorf("expected refreshed analysis, got: %s", showOutput)
+		}
+	})
+
+	t.Run("no JD content", func(t *testing.T) {
+		addOutput, _ := runApp(t, workDir, "add", "--company", "Empty", "--role", "Dev")
+		output, err := runApp(t, workDir, "jd", "analyze", extractAppID(addOutput))
+		if err == nil || !strings.Contains(output, "has no job description") {
+			t.Errorf("expected missing JD error, got: %s", output)
+		}
+	})
+}
+
+func TestCLIMatch(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	jdContent := "Backend Engineer\n- Go and k8s in production\n- Postgres, Kafka\n- Kubernetes operators"
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Backend Engineer", "--jd-content", jdContent)
+	appID := extractAppID(addOutput)
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "skills",
+		"--data", `{"languages":["Go"],"databases":["PostgreSQL"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"Old Co","role":"Engineer","start_date":"2020-01","highlights":["Ran Kafka pipelines"]}`)
+
+	t.Run("scores against KB", func(t *testing.T) {
+		output, err := runApp(t, workDir, "match", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		expected := []string{
+			"## Keyword Analysis Report: Backend Engineer @ Acme",
+			"### KB Match Score: 3/5 keywords (60%)",
+			"| Go | 1 | 1 | Technical |",
+			"| PostgreSQL | 1 | 1 | Technical |",
+			"| Kubernetes | 2 | Technical | Not in your KB",
+		}
+		for _, want := range expected {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("scores against resume", func(t *testing.T) {
+		resume := filepath.Join(workDir, "resume.html")
+		os.WriteFile(resume, []byte("<html><body><h1>Jane</h1><ul><li>Go, Kubernetes</li></ul></body></html>"), 0644)
+
+		output, err := runApp(t, workDir, "match", appID, "--resume", resume, "--json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var report struct {
+			KB     struct{ Matched int }
+			Resume struct{ Matched, Total int }
+		}
+		if err := json.Unmarshal([]byte(output), &report); err != nil {
+			t.Fatalf("invalid JSON: %v\nOutput: %s", err, output)
+		}
+		if report.KB.Matched != 3 || report.Resume.Matched != 2 || report.Resume.Total != 5 {
+			t.Errorf("unexpected scores: %+v", report)
+		}
+	})
+
+	t.Run("no JD content", func(t *testing.T) {
+		addOutput, _ := runApp(t, workDir, "add", "--company", "Empty", "--role", "Dev")
+		output, err := runApp(t, workDir, "match", extractAppID(addOutput))
+		if err == nil || !strings.Contains(output, "has no job description") {
+			t.Errorf("expected missing JD error, got: %s", output)
+		}
+	})
+}
+
+func TestCLIGaps(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	jdContent := "Backend Engineer\n\nRequirements\n- Go and Kubernetes\n- Kafka | streaming\n\nNice to have\n- Healthcare experience"
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Backend Engineer", "--jd-content", jdContent)
+	appID := extractAppID(addOutput)
+
+	kbOutput, _ := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "skills",
+		"--data", `{"languages":["Go"],"tools":["Kubernetes"]}`)
+	skillsID := extractKBID(kbOutput)
+
+	t.Run("markdown report", func(t *testing.T) {
+		output, err := runApp(t, workDir, "gaps", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		expected := []string{
+			"## Gap Analysis: Backend Engineer @ Acme",
+			"| Go and Kubernetes | Listed in skills | " + skillsID + " |",
+			"| Kafka \\| streaming | Required | Not found in KB |",
+			"| Healthcare experience | Preferred | Not found in KB |",
+		}
+		for _, want := range expected {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("json report", func(t *testing.T) {
+		output, err := runApp(t, workDir, "gaps", appID, "--json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var report struct {
+			Covered []struct {
+				Requirement string
+				Evidence    []struct {
+					EntryID string `json:"entry_id"`
+				}
+			}
+			Gaps []struct{ Requirement, Type string }
+		}
+		if err := json.Unmarshal([]byte(output), &report); err != nil {
+			t.Fatalf("invalid JSON: %v\nOutput: %s", err, output)
+		}
+		if len(report.Covered) != 1 || report.Covered[0].Evidence[0].EntryID != skillsID {
+			t.Errorf("unexpected covered requirements: %+v", report.Covered)
+		}
+		if len(report.Gaps) != 2 || report.Gaps[1].Type != "preferred" {
+			t.Errorf("unexpected gaps: %+v", report.Gaps)
+		}
+	})
+}
+
+func TestCLIRenderResume(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme Corp", "--role", "Backend Engineer")
+	appID := extractAppID(addOutput)
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Doe","email":"jane@example.com"}`)
+	expOutput, _ := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"Old Co","role":"Engineer","start_date":"2020-01","highlights":["Built APIs","Ran on-call"]}`)
+	expID := extractKBID(expOutput)
+
+	t.Run("renders to the application output directory", func(t *testing.T) {
+		output, err := runApp(t, workDir, "render", "resume", "--app", appID, "--theme", "classic")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		resumePath := filepath.Join(workDir, "outputs", "acme_corp_backend_engineer", "resume.html")
+		if !strings.Contains(output, "Resume written to outputs/acme_corp_backend_engineer/resume.html") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		content, err := os.ReadFile(resumePath)
+		if err != nil {
+			t.Fatalf("resume not written: %v", err)
+		}
+		for _, want := range []string{"Jane Doe", "Built APIs", "Ran on-call", "Jan 2020 - Present"} {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("expected %q in resume", want)
+			}
+		}
+	})
+
+	t.Run("picks up selection.json from the output directory", func(t *testing.T) {
+		selection := `{"summary":"Tailored summary","experience":[{"id":"` + expID + `","highlights":[1]}]}`
+		os.WriteFile(filepath.Join(workDir, "outputs", "acme_corp_backend_engineer", "selection.json"), []byte(selection), 0644)
+
+		output, err := runApp(t, workDir, "render", "resume", "--app", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		content, _ := os.ReadFile(filepath.Join(workDir, "outputs", "acme_corp_backend_engineer", "resume.html"))
+		if !strings.Contains(string(content), "Tailored summary") || strings.Contains(string(content), "Built APIs") {
+			t.Errorf("selection not applied: %s", content)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		output, err := runApp(t, workDir, "render", "resume")
+		if err == nil || !strings.Contains(output, "--app or --output is required") {
+			t.Errorf("expected missing flag error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "render", "resume", "--output", "x.html", "--theme", "fancy")
+		if err == nil || !strings.Contains(output, "unknown theme") {
+			t.Errorf("expected unknown theme error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "render", "resume", "--app", appID, "--format", "rtf")
+		if err == nil || !strings.Contains(output, "unknown format") {
+			t.Errorf("expected unknown format error, got: %s", output)
+		}
+	})
+}
+
+func TestCLIRenderDOCX(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme Corp", "--role", "Backend Engineer")
+	appID := extractAppID(addOutput)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Doe","email":"jane@example.com"}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"Old Co","role":"Engineer","start_date":"2020-01","highlights":["Built APIs"]}`)
+	outDir := filepath.Join(workDir, "outputs", "acme_corp_backend_engineer")
+
+	readDocument := func(t *testing.T, path string) string {
+		t.Helper()
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			t.Fatalf("not a docx file: %v", err)
+		}
+		defer zr.Close()
+		f, err := zr.Open("word/document.xml")
+		if err != nil {
+			t.Fatalf("no document part: %v", err)
+		}
+		content, _ := io.ReadAll(f)
+		return string(content)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		output, err := runApp(t, workDir, "render", "resume", "--app", appID, "--format", "docx")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Resume written to outputs/acme_corp_backend_engineer/resume.docx") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		document := readDocument(t, filepath.Join(outDir, "resume.docx"))
+		for _, want := range []string{"Jane Doe", "Engineer, Old Co", "Built APIs"} {
+			if !strings.Contains(document, want) {
+				t.Errorf("expected %q in document", want)
+			}
+		}
+	})
+
+	t.Run("format follows the output extension", func(t *testing.T) {
+		output, err := runApp(t, workDir, "render", "resume", "--output", "resume.docx")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		readDocument(t, filepath.Join(workDir, "resume.docx"))
+	})
+
+	t.Run("cover letter", func(t *testing.T) {
+		output, err := runApp(t, workDir, "render", "cover-letter", "--app", appID)
+		if err == nil || !strings.Contains(output, "Error reading cover letter") {
+			t.Errorf("expected missing cover letter error, got: %s", output)
+		}
+
+		letter := `<html><body><div class="name">Jane Doe</div><div class="body"><p>Dear Hiring Team,</p></div></body></html>`
+		os.WriteFile(filepath.Join(outDir, "cover_letter.html"), []byte(letter), 0644)
+		output, err = runApp(t, workDir, "render", "cover-letter", "--app", appID, "--format", "docx")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Cover letter written to outputs/acme_corp_backend_engineer/cover_letter.docx") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		if document := readDocument(t, filepath.Join(outDir, "cover_letter.docx")); !strings.Contains(document, "Dear Hiring Team,") {
+			t.Errorf("letter text missing: %s", document)
+		}
+
+		output, err = runApp(t, workDir, "render", "cover-letter", "--app", appID, "--format", "html")
+		if err == nil || !strings.Contains(output, "unknown format") {
+			t.Errorf("expected unknown format error, got: %s", output)
+		}
+	})
+}
+
+func TestCLIPDF(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	addOutput, _ := runApp(t, workDir, "add", "--company", "Acme Corp", "--role", "Backend Engineer")
+	appID := extractAppID(addOutput)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Doe","email":"jane@example.com"}`)
+
+	t.Run("errors without generated documents", func(t *testing.T) {
+		output, err := runApp(t, workDir, "pdf", "--app", appID)
+		if err == nil || !strings.Contains(output, "No resume.html or cover_letter.html found") {
+			t.Errorf("expected missing documents error, got: %s", output)
+		}
+	})
+
+	runApp(t, workDir, "render", "resume", "--app", appID)
+	outDir := filepath.Join(workDir, "outputs", "acme_corp_backend_engineer")
+
+	t.Run("converts a file next to the HTML", func(t *testing.T) {
+		output, err := runApp(t, workDir, "pdf", "outputs/acme_corp_backend_engineer/resume.html")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "PDF written to outputs/acme_corp_backend_engineer/resume.pdf") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		content, err := os.ReadFile(filepath.Join(outDir, "resume.pdf"))
+		if err != nil {
+			t.Fatalf("PDF not written: %v", err)
+		}
+		if !strings.HasPrefix(string(content), "%PDF-") || !strings.Contains(string(content), "/MediaBox [0 0 595.28 841.89]") {
+			t.Errorf("expected an A4 PDF, got %q", content[:min(len(content), 40)])
+		}
+	})
+
+	t.Run("honours --size and --output", func(t *testing.T) {
+		output, err := runApp(t, workDir, "pdf", "outputs/acme_corp_backend_engineer/resume.html", "--size", "letter", "--output", "letter.pdf")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		content, err := os.ReadFile(filepath.Join(workDir, "letter.pdf"))
+		if err != nil {
+			t.Fatalf("PDF not written: %v", err)
+		}
+		if !strings.Contains(string(content), "/MediaBox [0 0 612 792]") {
+			t.Error("expected a Letter page size")
+		}
+	})
+
+	t.Run("converts every document for an application", func(t *testing.T) {
+		os.WriteFile(filepath.Join(outDir, "cover_letter.html"), []byte("<p>Dear hiring manager,</p>"), 0644)
+		output, err := runApp(t, workDir, "pdf", "--app", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		for _, name := range []string{"resume.pdf", "cover_letter.pdf"} {
+			if !strings.Contains(output, name) {
+				t.Errorf("expected %s in output: %s", name, output)
+			}
+			if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+				t.Errorf("%s not written: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		output, err := runApp(t, workDir, "pdf", "missing.html")
+		if err == nil || !strings.Contains(output, "Error reading HTML") {
+			t.Errorf("expected read error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "pdf", "outputs/acme_corp_backend_engineer/resume.html", "--size", "legal")
+		if err == nil || !strings.Contains(output, "unknown page size") {
+			t.Errorf("expected page size error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "pdf", "--app", "app-00000000")
+		if err == nil || !strings.Contains(output, "Application not found") {
+			t.Errorf("expected not found error, got: %s", output)
+		}
+	})
+}
+
 func TestCLIHelp(t *testing.T) {
 	workDir, cleanup := setupIntegrationTest(t)
 	defer cleanup()
@@ -728,6 +1261,9 @@ func TestKBAddErrors(t *testing.T) {
 		if !strings.Contains(output, "invalid profile category") {
 			t.Errorf("expected category error, got: %s", output)
 		}
+		if !strings.Contains(output, "projects, publications, awards, volunteering, talks") {
+			t.Errorf("expected the valid categories to be listed, got: %s", output)
+		}
 	})
 
 	t.Run("missing data for profile", func(t *testing.T) {
@@ -779,6 +1315,19 @@ func TestKBAddErrors(t *testing.T) {
 			t.Errorf("expected email required error, got: %s", output)
 		}
 	})
+
+	t.Run("talk missing required field", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "add",
+			"--type", "profile",
+			"--category", "talks",
+			"--data", `{"title":"Go in Production"}`) // missing event
+		if err == nil {
+			t.Error("expected error for missing event")
+		}
+		if !strings.Contains(output, "event is required") {
+			t.Errorf("expected event required error, got: %s", output)
+		}
+	})
 }
 
 func TestKBShow(t *testing.T) {
@@ -839,23 +1388,60 @@ func TestKBShow(t *testing.T) {
 	})
 }
 
-func TestKBUpdate(t *testing.T) {
+func TestKBInvalidEntries(t *testing.T) {
 	workDir, cleanup := setupIntegrationTest(t)
 	defer cleanup()
 
 	kbPath := filepath.Join(workDir, "candidate-kb.jsonl")
-	os.WriteFile(kbPath, []byte{}, 0644)
-
-	// Add an entry to update
-	addOutput, _ := runApp(t, workDir, "kb", "add",
-		"--type", "context",
-		"--category", "achievement",
-		"--content", "Original content")
-	kbID := extractKBID(addOutput)
+	os.WriteFile(kbPath, []byte(`{"id":"kb-good","type":"profile","category":"contact","data":{"name":"Jane","email":"jane@example.com"}}
+{"id":"kb-bad","type":"profile","category":"experience","data":{"company":"Acme","role":"Engineer"}}
+{broken
+`), 0644)
 
-	t.Run("update content", func(t *testing.T) {
-		output, err := runApp(t, workDir, "kb", "update", kbID,
-			"--content", "Updated content")
+	output, err := runApp(t, workDir, "kb", "show", "profile")
+	if err != nil {
+		t.Fatalf("command failed: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{
+		"Warning: candidate-kb.jsonl has 2 invalid entries",
+		"line 2 (kb-bad): experience: start_date is required",
+		"line 3: invalid character",
+		"Skipped lines are dropped",
+		"Jane <jane@example.com>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output: %s", want, output)
+		}
+	}
+
+	// Once fixed, the warning goes away
+	output, err = runApp(t, workDir, "kb", "update", "kb-bad", "--data", `{"company":"Acme","role":"Engineer","start_date":"2020-01"}`)
+	if err != nil {
+		t.Fatalf("update failed: %v\nOutput: %s", err, output)
+	}
+	output, _ = runApp(t, workDir, "kb", "show")
+	if strings.Contains(output, "Warning") {
+		t.Errorf("expected no warning after the fix, got: %s", output)
+	}
+}
+
+func TestKBUpdate(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	kbPath := filepath.Join(workDir, "candidate-kb.jsonl")
+	os.WriteFile(kbPath, []byte{}, 0644)
+
+	// Add an entry to update
+	addOutput, _ := runApp(t, workDir, "kb", "add",
+		"--type", "context",
+		"--category", "achievement",
+		"--content", "Original content")
+	kbID := extractKBID(addOutput)
+
+	t.Run("update content", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "update", kbID,
+			"--content", "Updated content")
 		if err != nil {
 			t.Fatalf("command failed: %v\nOutput: %s", err, output)
 		}
@@ -1001,6 +1587,44 @@ func TestKBContext(t *testing.T) {
 			t.Errorf("expected IDs for context entries, got: %s", output)
 		}
 	})
+
+	t.Run("projects, publications, talks, awards and volunteering", func(t *testing.T) {
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "projects",
+			"--data", `{"name":"bragger","role":"maintainer","technologies":["Go"],"highlights":["500 GitHub stars"]}`)
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "publications",
+			"--data", `{"title":"Consensus at Scale","type":"paper","publisher":"ACM SIGMOD","date":"2021-06"}`)
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "talks",
+			"--data", `{"title":"Go in Production","event":"GopherCon EU","date":"2023-06","location":"Berlin"}`)
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "awards",
+			"--data", `{"title":"Engineer of the Year","issuer":"TechCorp","date":"2022-12"}`)
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "volunteering",
+			"--data", `{"organization":"Code Club","role":"Mentor","start_date":"2019-01"}`)
+
+		output, err := runApp(t, workDir, "kb", "context")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		for _, want := range []string{
+			"## Projects", "### bragger", "**Role:** maintainer", "**Technologies:** Go", "- 500 GitHub stars",
+			"## Publications", "- **Consensus at Scale** [paper], ACM SIGMOD (2021-06)",
+			"## Talks", "- **Go in Production** at GopherCon EU (Berlin, 2023-06)",
+			"## Awards", "- **Engineer of the Year** from TechCorp (2022-12)",
+			"## Volunteering", "### Mentor @ Code Club",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output: %s", want, output)
+			}
+		}
+		// Typed entries come before the context entries
+		if strings.Index(output, "## Volunteering") > strings.Index(output, "## Context Entries") {
+			t.Errorf("expected profile sections before context entries: %s", output)
+		}
+
+		output, _ = runApp(t, workDir, "kb", "show", "profile")
+		if !strings.Contains(output, "bragger (maintainer)") || !strings.Contains(output, "Go in Production @ GopherCon EU") {
+			t.Errorf("expected summaries in kb show, got: %s", output)
+		}
+	})
 }
 
 func TestKBRemove(t *testing.T) {
@@ -1041,3 +1665,1593 @@ func TestKBRemove(t *testing.T) {
 		}
 	})
 }
+
+func TestKBImportExport(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	resume := `{
+  "basics": {"name": "Jane Doe", "email": "jane@example.com", "summary": "Backend engineer."},
+  "work": [{"name": "Acme", "position": "Engineer", "startDate": "2020-01-15", "url": "https://acme.example", "highlights": ["Shipped"]}],
+  "projects": [{"name": "bragger"}]
+}`
+	os.WriteFile(filepath.Join(workDir, "resume.json"), []byte(resume), 0644)
+
+	t.Run("import", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "import", "--format", "jsonresume", "resume.json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Imported resume.json: 5 added, 0 updated, 0 unchanged") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "show", "profile")
+		if !strings.Contains(output, "Acme") || !strings.Contains(output, "Jane Doe") {
+			t.Errorf("imported entries missing: %s", output)
+		}
+	})
+
+	t.Run("re-import is idempotent", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "import", "resume.json", "--format", "jsonresume")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "0 added, 0 updated, 5 unchanged") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "export", "--format", "jsonresume", "--output", "out.json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		data, err := os.ReadFile(filepath.Join(workDir, "out.json"))
+		if err != nil {
+			t.Fatalf("export not written: %v", err)
+		}
+		var got, want map[string]any
+		json.Unmarshal(data, &got)
+		json.Unmarshal([]byte(resume), &want)
+		delete(got, "$schema")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("export does not round-trip:\n%s", data)
+		}
+
+		stdout, _ := runApp(t, workDir, "kb", "export")
+		if !strings.HasPrefix(stdout, "{\n  \"$schema\"") {
+			t.Errorf("expected JSON on stdout, got: %s", stdout)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "import", "--format", "csv", "resume.json")
+		if err == nil || !strings.Contains(output, "unknown format") {
+			t.Errorf("expected unknown format error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "kb", "import")
+		if err == nil || !strings.Contains(output, "Usage: bragger kb import") {
+			t.Errorf("expected usage error, got: %s", output)
+		}
+		os.WriteFile(filepath.Join(workDir, "bad.json"), []byte("{"), 0644)
+		output, err = runApp(t, workDir, "kb", "import", "bad.json")
+		if err == nil || !strings.Contains(output, "invalid JSON Resume") {
+			t.Errorf("expected parse error, got: %s", output)
+		}
+	})
+}
+
+func TestKBImportLinkedIn(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	files := map[string]string{
+		"Profile.csv":         "First Name,Last Name,Headline,Summary,Geo Location\nJane,Doe,Engineer,,Berlin\n",
+		"Email Addresses.csv": "Email Address,Confirmed,Primary\njane@example.com,Yes,Yes\n",
+		"Positions.csv":       "Company Name,Title,Description,Location,Started On,Finished On\nAcme,Engineer,,,Jan 2020,\n",
+		"Skills.csv":          "Name\nGo\n",
+	}
+	writeExport := func(changed map[string]string) {
+		for name, content := range changed {
+			files[name] = content
+		}
+		f, err := os.Create(filepath.Join(workDir, "export.zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zw := zip.NewWriter(f)
+		for name, content := range files {
+			w, _ := zw.Create(name)
+			w.Write([]byte(content))
+		}
+		zw.Close()
+	}
+	writeExport(nil)
+
+	importWithInput := func(input string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(binaryPath, append([]string{"kb", "import", "--format", "linkedin"}, args...)...)
+		cmd.Dir = workDir
+		cmd.Stdin = strings.NewReader(input)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		return string(output)
+	}
+
+	t.Run("preview and cancel", func(t *testing.T) {
+		output := importWithInput("n\n", "export.zip")
+		for _, want := range []string{"+ contact", "Jane Doe <jane@example.com>", "+ experience", "Engineer @ Acme (2020-01 - )", "3 to add, 0 to update", "Cancelled."} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output: %s", want, output)
+			}
+		}
+		if shown, _ := runApp(t, workDir, "kb", "show", "profile"); strings.Contains(shown, "Acme") {
+			t.Errorf("cancelled import was saved: %s", shown)
+		}
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		output := importWithInput("y\n", "export.zip")
+		if !strings.Contains(output, "Imported export.zip: 3 added, 0 updated, 0 unchanged") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		shown, _ := runApp(t, workDir, "kb", "show", "profile")
+		if !strings.Contains(shown, "Acme") || !strings.Contains(shown, "linkedin-import") {
+			t.Errorf("imported entries missing: %s", shown)
+		}
+	})
+
+	t.Run("re-import is idempotent", func(t *testing.T) {
+		output := importWithInput("", "export.zip")
+		if !strings.Contains(output, "Nothing to import from export.zip: 3 entries unchanged") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+
+	t.Run("update shows the changed fields", func(t *testing.T) {
+		writeExport(map[string]string{"Skills.csv": "Name\nGo\nKubernetes\n"})
+		output := importWithInput("", "--yes", "export.zip")
+		if strings.Contains(output, "Apply these changes?") {
+			t.Errorf("--yes should not prompt: %s", output)
+		}
+		for _, want := range []string{"~ skills", `other: ["Go"] -> ["Go","Kubernetes"]`, "Imported export.zip: 0 added, 1 updated, 2 unchanged"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "import", "--format", "linkedin", "resume.json")
+		if err == nil || !strings.Contains(output, "Error reading LinkedIn export") {
+			t.Errorf("expected read error, got: %s", output)
+		}
+		output, err = runApp(t, workDir, "kb", "export", "--format", "linkedin")
+		if err == nil || !strings.Contains(output, "unknown format") {
+			t.Errorf("expected unknown export format error, got: %s", output)
+		}
+	})
+}
+
+func TestUpgrade(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	if output, err := runApp(t, workDir, "init"); err != nil {
+		t.Fatalf("init failed: %v\nOutput: %s", err, output)
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		output, err := runApp(t, workDir, "upgrade")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "already at version") {
+			t.Errorf("expected up to date message, got: %s", output)
+		}
+	})
+
+	// Records written before schema versioning, and an outdated workspace
+	oldApp := `{"id":"app-old","company":"Old","role":"Dev","status":"Applied","date_applied":"","created_at":"2024-03-05T10:00:00Z","updated_at":"2024-03-05T10:00:00Z"}` + "\n"
+	oldKB := `{"id":"kb-old","type":"context","category":"Achievement","content":"Led a team","source":"user","created_at":"2024-03-05T10:00:00Z","updated_at":"2024-03-05T10:00:00Z"}` + "\n"
+	os.WriteFile(filepath.Join(workDir, "applications.jsonl"), []byte(oldApp), 0644)
+	os.WriteFile(filepath.Join(workDir, "candidate-kb.jsonl"), []byte(oldKB), 0644)
+	os.WriteFile(filepath.Join(workDir, ".bragger", "version"), []byte("0.4.0"), 0644)
+
+	t.Run("dry run", func(t *testing.T) {
+		output, err := runApp(t, workDir, "upgrade", "--dry-run")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		for _, want := range []string{
+			"from v0.4.0 to v" + templates.Version,
+			".claude/skills/candidate-kb/SKILL.md",
+			"applications.jsonl: 1 of 1 records",
+			fmt.Sprintf("line 1 (app-old): v1 -> v%d: normalize status and fill in date_applied", models.ApplicationSchemaVersion),
+			fmt.Sprintf("line 1 (kb-old): v1 -> v%d: lower-case category", models.KBSchemaVersion),
+			"Nothing was written",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+		if data, _ := os.ReadFile(filepath.Join(workDir, "applications.jsonl")); string(data) != oldApp {
+			t.Errorf("dry run changed applications.jsonl: %s", data)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, ".bragger", "backup", "0.4.0")); !os.IsNotExist(err) {
+			t.Errorf("dry run created a backup directory")
+		}
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		output, err := runApp(t, workDir, "upgrade")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "applications.jsonl (1 records)") || !strings.Contains(output, "candidate-kb.jsonl (1 records)") {
+			t.Errorf("expected migrated files in output, got: %s", output)
+		}
+
+		backupDir := filepath.Join(workDir, ".bragger", "backup", "0.4.0")
+		if data, _ := os.ReadFile(filepath.Join(backupDir, "applications.jsonl")); string(data) != oldApp {
+			t.Errorf("expected applications.jsonl backup, got: %s", data)
+		}
+		if data, _ := os.ReadFile(filepath.Join(backupDir, "candidate-kb.jsonl")); string(data) != oldKB {
+			t.Errorf("expected candidate-kb.jsonl backup, got: %s", data)
+		}
+
+		output, _ = runApp(t, workDir, "show", "app-old")
+		if !strings.Contains(output, "2024-03-05") || !strings.Contains(output, "applied") {
+			t.Errorf("expected migrated application, got: %s", output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "candidate-kb.jsonl"))
+		if !strings.Contains(string(data), `"category":"achievement"`) || !strings.Contains(string(data), fmt.Sprintf(`"schema_version":%d`, models.KBSchemaVersion)) {
+			t.Errorf("expected migrated KB entry, got: %s", data)
+		}
+	})
+
+	t.Run("up to date after migrating", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "upgrade")
+		if !strings.Contains(output, "already at version") {
+			t.Errorf("expected up to date message, got: %s", output)
+		}
+	})
+}
+
+func TestKBDates(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	add := func(data string) (string, error) {
+		return runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience", "--data", data)
+	}
+
+	t.Run("normalizes dates", func(t *testing.T) {
+		if output, err := add(`{"company":"Old Co","role":"Dev","start_date":"Mar 2016","end_date":"2019/12"}`); err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if output, err := add(`{"company":"Acme","role":"Lead","start_date":"2020-01","end_date":"Current"}`); err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "candidate-kb.jsonl"))
+		for _, want := range []string{`"start_date":"2016-03","end_date":"2019-12"`, `"end_date":"present"`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %s in KB, got: %s", want, data)
+			}
+		}
+	})
+
+	t.Run("rejects end before start", func(t *testing.T) {
+		output, err := add(`{"company":"Acme","role":"Dev","start_date":"2020-05","end_date":"2019-01"}`)
+		if err == nil {
+			t.Fatal("expected error for end date before start date")
+		}
+		if !strings.Contains(output, "end_date 2019-01 is before start_date 2020-05") {
+			t.Errorf("expected date range error, got: %s", output)
+		}
+	})
+
+	t.Run("rejects unknown dates", func(t *testing.T) {
+		output, err := add(`{"company":"Acme","role":"Dev","start_date":"a while ago"}`)
+		if err == nil || !strings.Contains(output, `invalid date "a while ago"`) {
+			t.Errorf("expected invalid date error, got: %v %s", err, output)
+		}
+	})
+
+	t.Run("context lists experience newest first", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		acme, old := strings.Index(output, "### Lead @ Acme"), strings.Index(output, "### Dev @ Old Co")
+		if acme < 0 || old < 0 || acme > old {
+			t.Errorf("expected Acme before Old Co, got: %s", output)
+		}
+	})
+
+	t.Run("stats count months of normalized dates", func(t *testing.T) {
+		if output, err := runApp(t, workDir, "add", "--company", "A", "--role", "Dev", "--date", "Mar 5, 2025"); err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		output, err := runApp(t, workDir, "stats")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "2025-03") {
+			t.Errorf("expected 2025-03 in stats, got: %s", output)
+		}
+	})
+}
+
+func TestKBLint(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	add := func(category, data string) {
+		t.Helper()
+		if output, err := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", category, "--data", data); err != nil {
+			t.Fatalf("kb add failed: %v\nOutput: %s", err, output)
+		}
+	}
+
+	t.Run("reports issues", func(t *testing.T) {
+		add("experience", `{"company":"Old","role":"Dev","start_date":"2015-01","end_date":"2017-12","highlights":["Built things"]}`)
+		add("experience", `{"company":"Acme","role":"Lead","start_date":"2019-01","end_date":"present"}`)
+		add("certifications", `{"name":"CKA","date":"2019-06","expiry_date":"2022-06"}`)
+
+		output, err := runApp(t, workDir, "kb", "lint")
+		if err == nil {
+			t.Fatalf("expected non-zero exit, got: %s", output)
+		}
+		for _, want := range []string{
+			"Warning: [missing] no contact entry",
+			"Warning: [missing] no skills entry",
+			"12-month gap between Old (ended 2017-12) and Acme (started 2019-01)",
+			"certification CKA expired 2022-06",
+			`Old highlight has no metric: "Built things"`,
+			"5 issue(s) found.",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("gap months", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "lint", "--gap-months", "12")
+		if strings.Contains(output, "[gap]") {
+			t.Errorf("expected no gap with --gap-months 12, got: %s", output)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cmd := exec.Command(binaryPath, "kb", "lint", "--json")
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		if err == nil {
+			t.Fatal("expected non-zero exit")
+		}
+		var issues []struct {
+			Check string   `json:"check"`
+			IDs   []string `json:"ids"`
+		}
+		if err := json.Unmarshal(out, &issues); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if len(issues) != 5 || issues[2].Check != "gap" || len(issues[2].IDs) != 2 {
+			t.Errorf("unexpected issues: %+v", issues)
+		}
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		f, _ := os.OpenFile(filepath.Join(workDir, "candidate-kb.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString("not json\n")
+		f.Close()
+
+		output, _ := runApp(t, workDir, "kb", "lint")
+		if !strings.Contains(output, "Warning: [invalid] line 4:") || strings.Contains(output, "invalid entries:") {
+			t.Errorf("expected lint to report the invalid line once, got: %s", output)
+		}
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		os.WriteFile(filepath.Join(workDir, "candidate-kb.jsonl"), nil, 0644)
+		add("contact", `{"name":"Jane","email":"jane@example.com"}`)
+		add("skills", `{"languages":["Go"]}`)
+		add("experience", `{"company":"Acme","role":"Lead","start_date":"2019-01","highlights":["Cut costs by 30%"]}`)
+
+		output, err := runApp(t, workDir, "kb", "lint")
+		if err != nil || !strings.Contains(output, "No issues found.") {
+			t.Errorf("expected a clean KB, got: %v %s", err, output)
+		}
+	})
+}
+
+func TestKBSingletonsAndMerge(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	kbPath := filepath.Join(workDir, "candidate-kb.jsonl")
+	// Duplicates written before the rule existed
+	duplicates := `{"id":"kb-c1","type":"profile","category":"contact","data":{"name":"Jane Doe","email":"jane@old.com","location":"Berlin"},"source":"cv-import","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}
+{"id":"kb-c2","type":"profile","category":"contact","data":{"name":"Jane Doe","email":"jane@new.com","phone":"+49 123"},"source":"linkedin-import","created_at":"2024-02-01T00:00:00Z","updated_at":"2024-02-01T00:00:00Z"}
+{"id":"kb-s1","type":"profile","category":"skills","data":{"languages":["Go"],"tools":["Docker"]},"source":"cv-import","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}
+{"id":"kb-s2","type":"profile","category":"skills","data":{"languages":["go","Rust"]},"source":"user","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}
+`
+	os.WriteFile(kbPath, []byte(duplicates), 0644)
+
+	t.Run("add rejects a second contact", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+			"--data", `{"name":"Jane","email":"jane@example.com"}`)
+		if err == nil {
+			t.Fatal("expected an error for a second contact")
+		}
+		if !strings.Contains(output, "already has a contact entry (kb-c1)") || !strings.Contains(output, "bragger kb update kb-c1") {
+			t.Errorf("expected singleton error, got: %s", output)
+		}
+	})
+
+	t.Run("update fixes a duplicate", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "update", "kb-c2", "--data", `{"name":"Jane Doe","email":"jane@new.com","phone":"+49 456"}`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		output, err = runApp(t, workDir, "kb", "update", "kb-c2", "--merge", `{"phone":"+49 123"}`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+	})
+
+	t.Run("merge skills", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "merge", "kb-s1", "kb-s2")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Merged kb-s2 into kb-s1.") || !strings.Contains(output, "Source: cv-import, user") {
+			t.Errorf("unexpected output: %s", output)
+		}
+		data, _ := os.ReadFile(kbPath)
+		if strings.Contains(string(data), "kb-s2") || !strings.Contains(string(data), `"languages":["Go","Rust"],"tools":["Docker"]`) {
+			t.Errorf("expected unioned skills, got: %s", data)
+		}
+	})
+
+	t.Run("merge contact asks about differing fields", func(t *testing.T) {
+		cmd := exec.Command(binaryPath, "kb", "merge", "kb-c1", "kb-c2")
+		cmd.Dir = workDir
+		cmd.Stdin = strings.NewReader("2\n")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, out)
+		}
+		output := string(out)
+		if !strings.Contains(output, "email differs:\n  1) jane@old.com (kb-c1)\n  2) jane@new.com (kb-c2)") || strings.Contains(output, "name differs") {
+			t.Errorf("expected a question about email only, got: %s", output)
+		}
+
+		data, _ := os.ReadFile(kbPath)
+		for _, want := range []string{`"email":"jane@new.com","phone":"+49 123","location":"Berlin"`, `"source":"cv-import, linkedin-import"`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %s in KB, got: %s", want, data)
+			}
+		}
+		if strings.Contains(string(data), "kb-c2") {
+			t.Errorf("expected kb-c2 to be removed, got: %s", data)
+		}
+	})
+
+	t.Run("merge errors", func(t *testing.T) {
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+			"--data", `{"company":"Acme","role":"Dev","start_date":"2020-01"}`)
+		entries, _ := os.ReadFile(kbPath)
+		var expID string
+		for _, line := range strings.Split(strings.TrimSpace(string(entries)), "\n") {
+			if strings.Contains(line, `"experience"`) {
+				var e struct{ ID string }
+				json.Unmarshal([]byte(line), &e)
+				expID = e.ID
+			}
+		}
+
+		for _, tt := range []struct {
+			args []string
+			want string
+		}{
+			{[]string{"kb-c1"}, "Usage: bragger kb merge"},
+			{[]string{"kb-c1", "kb-c1"}, "can't merge an entry with itself"},
+			{[]string{"kb-c1", "kb-missing"}, "Entry not found: kb-missing"},
+			{[]string{"kb-c1", "kb-s1"}, "only two contact entries or two skills entries"},
+			{[]string{"kb-c1", expID}, "only two contact entries or two skills entries"},
+			{[]string{"kb-c1", "kb-s1", "--prefer", "kb-x"}, "--prefer must be kb-c1 or kb-s1"},
+		} {
+			output, err := runApp(t, workDir, append([]string{"kb", "merge"}, tt.args...)...)
+			if err == nil || !strings.Contains(output, tt.want) {
+				t.Errorf("kb merge %v: expected %q, got: %v %s", tt.args, tt.want, err, output)
+			}
+		}
+	})
+}
+
+func TestKBPatch(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	kbPath := filepath.Join(workDir, "candidate-kb.jsonl")
+	entries := `{"id":"kb-exp","type":"profile","category":"experience","data":{"company":"Acme","role":"Dev","start_date":"2020-01","location":"Berlin","highlights":["Shipped v1"]},"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}
+{"id":"kb-note","type":"context","category":"achievement","content":"Led a migration","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}
+`
+	os.WriteFile(kbPath, []byte(entries), 0644)
+
+	kbData := func() string {
+		data, _ := os.ReadFile(kbPath)
+		return string(data)
+	}
+
+	t.Run("merge patch", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "update", "kb-exp", "--merge", `{"end_date":"Jun 2024","location":null}`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data := kbData(); !strings.Contains(data, `"start_date":"2020-01","end_date":"2024-06","highlights":["Shipped v1"]`) {
+			t.Errorf("expected merged data, got: %s", data)
+		}
+	})
+
+	t.Run("json patch", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "update", "kb-exp", "--patch",
+			`[{"op":"test","path":"/company","value":"Acme"},{"op":"replace","path":"/highlights/0","value":"Shipped v1 to 10k users"}]`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data := kbData(); !strings.Contains(data, `"highlights":["Shipped v1 to 10k users"]`) {
+			t.Errorf("expected patched highlight, got: %s", data)
+		}
+	})
+
+	t.Run("highlights", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "highlight", "add", "kb-exp", "Hired 3 engineers", "--at", "0")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "  0. Hired 3 engineers\n  1. Shipped v1 to 10k users") {
+			t.Errorf("expected numbered highlights, got: %s", output)
+		}
+		runApp(t, workDir, "kb", "highlight", "add", "kb-exp", "Cut costs by 20%")
+
+		output, err = runApp(t, workDir, "kb", "highlight", "remove", "kb-exp", "Shipped v1 to 10k users")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		output, err = runApp(t, workDir, "kb", "highlight", "remove", "kb-exp", "0")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data := kbData(); !strings.Contains(data, `"highlights":["Cut costs by 20%"]`) {
+			t.Errorf("expected one highlight left, got: %s", data)
+		}
+	})
+
+	t.Run("skills", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "skills", "add", "--languages", "Go, Rust", "--tools", "Docker")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Skills entry added successfully!") {
+			t.Errorf("expected a new skills entry, got: %s", output)
+		}
+
+		output, err = runApp(t, workDir, "kb", "skills", "add", "--languages", "rust,Zig")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		output, err = runApp(t, workDir, "kb", "skills", "remove", "--languages", "GO", "--tools", "Docker")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		data := kbData()
+		if !strings.Contains(data, `"data":{"languages":["Rust","Zig"]}`) || strings.Count(data, `"skills"`) != 1 {
+			t.Errorf("expected one skills entry with Rust and Zig, got: %s", data)
+		}
+	})
+
+	t.Run("data the struct has no field for", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "update", "kb-exp", "--merge", `{"team_size":6,"stack_notes":"Go"}`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		runApp(t, workDir, "kb", "add", "--type", "context", "--category", "notes", "--content", "Unrelated")
+		if data := kbData(); !strings.Contains(data, `"highlights":["Cut costs by 20%"],"stack_notes":"Go","team_size":6}`) {
+			t.Errorf("expected the extra members kept, got: %s", data)
+		}
+
+		output, err = runApp(t, workDir, "kb", "update", "kb-exp", "--merge", `{"team_size":null}`)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data := kbData(); strings.Contains(data, "team_size") || !strings.Contains(data, `"stack_notes":"Go"`) {
+			t.Errorf("expected a merge patch to remove an extra member, got: %s", data)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		before := kbData()
+		for _, tt := range []struct {
+			args []string
+			want string
+		}{
+			{[]string{"update", "kb-exp", "--merge", `{"start_date":null}`}, "start_date is required"},
+			{[]string{"update", "kb-exp", "--merge", `{"end_date":"2019-01"}`}, "end_date 2019-01 is before start_date 2020-01"},
+			{[]string{"update", "kb-exp", "--patch", `[{"op":"remove","path":"/highlights/3"}]`}, "operation 0 (remove /highlights/3)"},
+			{[]string{"update", "kb-exp", "--patch", `[{"op":"test","path":"/company","value":"Other"}]`}, "test failed"},
+			{[]string{"update", "kb-exp", "--patch", `{"op":"remove"}`}, "invalid patch"},
+			{[]string{"update", "kb-exp", "--data", `{}`, "--merge", `{}`}, "use only one of --data, --merge and --patch"},
+			{[]string{"update", "kb-note", "--merge", `{}`}, "apply to profile entries"},
+			{[]string{"highlight", "add", "kb-note", "x"}, "not an experience, projects or volunteering entry"},
+			{[]string{"highlight", "remove", "kb-exp", "Nope"}, `has no highlight "Nope"`},
+			{[]string{"highlight", "edit", "kb-exp", "x"}, "Usage:"},
+			{[]string{"skills", "add"}, "name at least one skill"},
+		} {
+			output, err := runApp(t, workDir, append([]string{"kb"}, tt.args...)...)
+			if err == nil || !strings.Contains(output, tt.want) {
+				t.Errorf("kb %v: expected %q, got: %v %s", tt.args, tt.want, err, output)
+			}
+		}
+		if after := kbData(); after != before {
+			t.Errorf("expected failed commands to leave the KB unchanged, got: %s", after)
+		}
+	})
+}
+
+func TestHistoryAndUndo(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	output, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Engineer")
+	appID := extractAppID(output)
+	runApp(t, workDir, "update", appID, "--status", "interviewing")
+	output, _ = runApp(t, workDir, "kb", "add", "--type", "context", "--category", "note", "--content", "Keep me")
+	kbID := extractKBID(output)
+
+	cmd := exec.Command(binaryPath, "kb", "remove", kbID)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err != nil || !strings.Contains(string(out), "Restore it with 'bragger undo'") {
+		t.Fatalf("kb remove failed: %v\n%s", err, out)
+	}
+
+	t.Run("history lists steps newest first", func(t *testing.T) {
+		output, err := runApp(t, workDir, "history")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		removed := strings.Index(output, "remove kb entry "+kbID)
+		added := strings.Index(output, "add application "+appID)
+		if removed < 0 || added < 0 || removed > added {
+			t.Errorf("expected the remove before the add, got: %s", output)
+		}
+		if !strings.Contains(output, "agent  bragger kb remove "+kbID) || !strings.Contains(output, "agent  bragger add --company Acme") {
+			t.Errorf("expected the actor and command, got: %s", output)
+		}
+	})
+
+	t.Run("history of a record shows fields", func(t *testing.T) {
+		output, err := runApp(t, workDir, "history", appID)
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, `status: "applied" -> "interviewing"`) || strings.Contains(output, kbID) {
+			t.Errorf("expected the status change only, got: %s", output)
+		}
+	})
+
+	t.Run("history json", func(t *testing.T) {
+		output, err := runApp(t, workDir, "history", "--json", "--limit", "1")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var changes []struct {
+			ID     string          `json:"id"`
+			Op     string          `json:"op"`
+			Before json.RawMessage `json:"before"`
+		}
+		if err := json.Unmarshal([]byte(output), &changes); err != nil || len(changes) != 1 || changes[0].ID != kbID || changes[0].Op != "remove" || !strings.Contains(string(changes[0].Before), "Keep me") {
+			t.Errorf("unexpected JSON: %v %s", err, output)
+		}
+	})
+
+	t.Run("undo restores the removed entry", func(t *testing.T) {
+		output, err := runApp(t, workDir, "undo")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "remove kb entry "+kbID) {
+			t.Errorf("unexpected output: %s", output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "candidate-kb.jsonl"))
+		if !strings.Contains(string(data), "Keep me") {
+			t.Errorf("expected the entry back, got: %s", data)
+		}
+	})
+
+	t.Run("undo steps walks further back", func(t *testing.T) {
+		output, err := runApp(t, workDir, "undo", "--steps", "2")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "applications.jsonl"))
+		if !strings.Contains(string(data), `"status":"applied"`) {
+			t.Errorf("expected the status change undone, got: %s", data)
+		}
+		data, _ = os.ReadFile(filepath.Join(workDir, "candidate-kb.jsonl"))
+		if len(data) != 0 {
+			t.Errorf("expected the KB add undone, got: %s", data)
+		}
+
+		output, _ = runApp(t, workDir, "history", "--limit", "1")
+		if !strings.Contains(output, "(undoes ") {
+			t.Errorf("expected undo steps in history, got: %s", output)
+		}
+	})
+
+	t.Run("nothing left to undo", func(t *testing.T) {
+		runApp(t, workDir, "undo")
+		output, err := runApp(t, workDir, "undo")
+		if err != nil || !strings.Contains(output, "Nothing to undo.") {
+			t.Errorf("expected nothing to undo, got: %v %s", err, output)
+		}
+	})
+}
+
+func TestArchive(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	output, _ := runApp(t, workDir, "add", "--company", "Stale Corp", "--role", "Engineer", "--status", "rejected")
+	staleID := extractAppID(output)
+	runApp(t, workDir, "add", "--company", "Fresh Inc", "--role", "Engineer")
+
+	t.Run("archived applications are hidden", func(t *testing.T) {
+		output, err := runApp(t, workDir, "archive", staleID)
+		if err != nil || !strings.Contains(output, "Archived "+staleID) {
+			t.Fatalf("archive failed: %v\n%s", err, output)
+		}
+
+		output, _ = runApp(t, workDir, "list")
+		if strings.Contains(output, "Stale Corp") || !strings.Contains(output, "Fresh Inc") || !strings.Contains(output, "1 archived not shown") {
+			t.Errorf("expected Stale Corp hidden, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "list", "--all")
+		if !strings.Contains(output, "rejected (archived)") {
+			t.Errorf("expected Stale Corp marked archived, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "stats")
+		if !strings.Contains(output, "Total:           1") {
+			t.Errorf("expected stats without the archived application, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "stats", "--all")
+		if !strings.Contains(output, "Total:           2") {
+			t.Errorf("expected stats with --all to count it, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "archive", staleID)
+		if !strings.Contains(output, "already archived") {
+			t.Errorf("expected already archived, got: %s", output)
+		}
+	})
+
+	t.Run("unarchive", func(t *testing.T) {
+		output, err := runApp(t, workDir, "unarchive", staleID)
+		if err != nil || !strings.Contains(output, "Restored "+staleID) {
+			t.Fatalf("unarchive failed: %v\n%s", err, output)
+		}
+		output, _ = runApp(t, workDir, "list")
+		if !strings.Contains(output, "Stale Corp") {
+			t.Errorf("expected Stale Corp back, got: %s", output)
+		}
+	})
+
+	t.Run("purge only removes archived applications", func(t *testing.T) {
+		output, err := runApp(t, workDir, "purge", staleID, "--yes")
+		if err == nil || !strings.Contains(output, "Only archived applications can be purged") {
+			t.Errorf("expected an error purging an active application, got: %v %s", err, output)
+		}
+
+		runApp(t, workDir, "archive", staleID)
+		cmd := exec.Command(binaryPath, "purge")
+		cmd.Dir = workDir
+		cmd.Stdin = strings.NewReader("n\n")
+		out, _ := cmd.CombinedOutput()
+		if !strings.Contains(string(out), "Permanently delete 1 archived application?") || !strings.Contains(string(out), "Cancelled.") {
+			t.Errorf("expected a confirmation, got: %s", out)
+		}
+
+		output, err = runApp(t, workDir, "purge", "--yes")
+		if err != nil || !strings.Contains(output, "Purged 1 archived application.") {
+			t.Fatalf("purge failed: %v\n%s", err, output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "applications.jsonl"))
+		if strings.Contains(string(data), "Stale Corp") || !strings.Contains(string(data), "Fresh Inc") {
+			t.Errorf("expected only Fresh Inc left, got: %s", data)
+		}
+	})
+
+	t.Run("archived kb entries leave context and resumes", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+			"--data", `{"company":"Old Job","role":"Dev","start_date":"2010-01","end_date":"2012-01"}`)
+		oldID := extractKBID(output)
+		runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+			"--data", `{"company":"New Job","role":"Dev","start_date":"2015-01"}`)
+
+		output, err := runApp(t, workDir, "kb", "archive", oldID)
+		if err != nil || !strings.Contains(output, "Archived "+oldID) {
+			t.Fatalf("kb archive failed: %v\n%s", err, output)
+		}
+		output, _ = runApp(t, workDir, "kb", "context")
+		if strings.Contains(output, "Old Job") || !strings.Contains(output, "New Job") {
+			t.Errorf("expected Old Job left out of the context, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "context", "--all")
+		if !strings.Contains(output, "Old Job") {
+			t.Errorf("expected Old Job with --all, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "show", "experience")
+		if strings.Contains(output, oldID) || !strings.Contains(output, "1 archived not shown") {
+			t.Errorf("expected Old Job hidden from kb show, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "show", "--all")
+		if !strings.Contains(output, "experience (archived)") {
+			t.Errorf("expected Old Job marked archived, got: %s", output)
+		}
+
+		output, err = runApp(t, workDir, "kb", "purge", oldID, "--yes")
+		if err != nil || !strings.Contains(output, "Purged 1 archived entry.") {
+			t.Fatalf("kb purge failed: %v\n%s", err, output)
+		}
+		output, _ = runApp(t, workDir, "kb", "purge")
+		if !strings.Contains(output, "No archived entries to purge.") {
+			t.Errorf("expected nothing to purge, got: %s", output)
+		}
+	})
+
+	t.Run("archived contact can be replaced", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+			"--data", `{"name":"Jane","email":"jane@old.com"}`)
+		oldID := extractKBID(output)
+		runApp(t, workDir, "kb", "archive", oldID)
+		if output, err := runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+			"--data", `{"name":"Jane","email":"jane@new.com"}`); err != nil {
+			t.Fatalf("expected a new contact to be allowed: %v\n%s", err, output)
+		}
+		output, err := runApp(t, workDir, "kb", "unarchive", oldID)
+		if err == nil || !strings.Contains(output, "already has a contact entry") {
+			t.Errorf("expected unarchive to be refused, got: %v %s", err, output)
+		}
+	})
+}
+
+// setupSyncRemote returns a bare repository to sync with, and sets a git
+// identity for the commits
+func setupSyncRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, name := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(name+"_NAME", "Test")
+		t.Setenv(name+"_EMAIL", "test@example.com")
+	}
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	return remote
+}
+
+func TestSync(t *testing.T) {
+	remote := setupSyncRemote(t)
+
+	laptop, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+	desktop, cleanupDesktop := setupIntegrationTest(t)
+	defer cleanupDesktop()
+
+	output, err := runApp(t, laptop, "sync", "push")
+	if err == nil || !strings.Contains(output, "sync init") {
+		t.Errorf("expected push to require sync init, got: %v %s", err, output)
+	}
+
+	output, err = runApp(t, laptop, "sync", "init", "--remote", remote)
+	if err != nil || !strings.Contains(output, "Workspace sync is set up") || !strings.Contains(output, "Nothing to pull") {
+		t.Fatalf("sync init failed: %v\n%s", err, output)
+	}
+
+	var appID string
+	t.Run("changes are committed as they are made", func(t *testing.T) {
+		output, _ := runApp(t, laptop, "add", "--company", "Acme", "--role", "Engineer")
+		appID = extractAppID(output)
+		runApp(t, laptop, "update", appID, "--status", "interviewing")
+
+		output, err := runApp(t, laptop, "sync", "log")
+		if err != nil {
+			t.Fatalf("sync log failed: %v\n%s", err, output)
+		}
+		for _, want := range []string{
+			"update " + appID + ": status applied→interviewing",
+			"add " + appID + ": Engineer at Acme",
+			"bragger sync init",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in the log, got: %s", want, output)
+			}
+		}
+
+		output, _ = runApp(t, laptop, "sync", "commit")
+		if !strings.Contains(output, "Nothing to commit.") {
+			t.Errorf("expected nothing left to commit, got: %s", output)
+		}
+		if output, err := runApp(t, laptop, "sync", "push"); err != nil {
+			t.Fatalf("sync push failed: %v\n%s", err, output)
+		}
+	})
+
+	t.Run("a second machine picks up the workspace", func(t *testing.T) {
+		runApp(t, desktop, "kb", "add", "--type", "context", "--category", "note", "--content", "Desktop note")
+		output, err := runApp(t, desktop, "sync", "init", "--remote", remote)
+		if err != nil || !strings.Contains(output, "Pulled from") {
+			t.Fatalf("sync init failed: %v\n%s", err, output)
+		}
+		output, _ = runApp(t, desktop, "list")
+		if !strings.Contains(output, "Acme") {
+			t.Errorf("expected the laptop's application, got: %s", output)
+		}
+		output, _ = runApp(t, desktop, "kb", "context")
+		if !strings.Contains(output, "Desktop note") {
+			t.Errorf("expected the desktop's own KB entry kept, got: %s", output)
+		}
+	})
+
+	t.Run("diverging changes merge by record", func(t *testing.T) {
+		output, _ := runApp(t, laptop, "add", "--company", "Laptop Co", "--role", "Engineer")
+		laptopID := extractAppID(output)
+		runApp(t, laptop, "update", appID, "--status", "offer")
+		if output, err := runApp(t, laptop, "sync", "push"); err != nil {
+			t.Fatalf("sync push failed: %v\n%s", err, output)
+		}
+
+		// The desktop changes the same application later, and adds another
+		runApp(t, desktop, "update", appID, "--notes", "Second round on Monday")
+		output, _ = runApp(t, desktop, "add", "--company", "Desktop Co", "--role", "Engineer")
+		desktopID := extractAppID(output)
+
+		output, err := runApp(t, desktop, "sync", "pull")
+		if err != nil || !strings.Contains(output, "Pulled from") {
+			t.Fatalf("sync pull failed: %v\n%s", err, output)
+		}
+		data, _ := os.ReadFile(filepath.Join(desktop, "applications.jsonl"))
+		for _, want := range []string{laptopID, desktopID, "Second round on Monday"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %q after the merge, got: %s", want, data)
+			}
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 3 {
+			t.Errorf("expected 3 applications, got %d:\n%s", lines, data)
+		}
+
+		output, err = runApp(t, desktop, "history")
+		if err != nil || !strings.Contains(output, "--status offer") || !strings.Contains(output, "Second round") {
+			t.Errorf("expected both machines' journals, got: %v\n%s", err, output)
+		}
+
+		if output, err := runApp(t, desktop, "sync", "push"); err != nil {
+			t.Fatalf("sync push failed: %v\n%s", err, output)
+		}
+		if output, err := runApp(t, laptop, "sync", "pull"); err != nil {
+			t.Fatalf("sync pull failed: %v\n%s", err, output)
+		}
+		laptopData, _ := os.ReadFile(filepath.Join(laptop, "applications.jsonl"))
+		if string(laptopData) != string(data) {
+			t.Errorf("expected both machines to agree, got:\n%s\nand:\n%s", laptopData, data)
+		}
+	})
+}
+
+func TestSyncEncryptedWorkspace(t *testing.T) {
+	remote := setupSyncRemote(t)
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+	t.Setenv("BRAGGER_PASSPHRASE", "correct horse")
+
+	output, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Go Engineer")
+	appID := extractAppID(output)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Doe","email":"jane@example.com"}`)
+	if output, err := runApp(t, workDir, "sync", "init", "--remote", remote); err != nil {
+		t.Fatalf("sync init failed: %v\n%s", err, output)
+	}
+	if output, err := runApp(t, workDir, "encrypt"); err != nil {
+		t.Fatalf("encrypt failed: %v\n%s", err, output)
+	}
+	if output, err := runApp(t, workDir, "render", "resume", "--app", appID); err != nil {
+		t.Fatalf("render failed: %v\n%s", err, output)
+	}
+	if output, err := runApp(t, workDir, "sync", "push"); err != nil {
+		t.Fatalf("sync push failed: %v\n%s", err, output)
+	}
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		out, _ := cmd.CombinedOutput()
+		return string(out)
+	}
+	if files := git("ls-files"); strings.Contains(files, "outputs/") {
+		t.Errorf("expected the rendered resume not to be tracked, got:\n%s", files)
+	}
+	if found := git("grep", "jane@example.com", "HEAD"); found != "" {
+		t.Errorf("expected no plaintext email in HEAD, got:\n%s", found)
+	}
+	if status := git("status", "--porcelain"); strings.Contains(status, "outputs") {
+		t.Errorf("expected outputs/ to be ignored, got:\n%s", status)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	output, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Engineer", "--notes", "Asked for 120k")
+	appID := extractAppID(output)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Doe","email":"jane@example.com","phone":"555-0100"}`)
+
+	t.Setenv("BRAGGER_PASSPHRASE", "")
+	output, err := runApp(t, workDir, "encrypt")
+	if err == nil || !strings.Contains(output, "set BRAGGER_PASSPHRASE") {
+		t.Errorf("expected encrypt to need a passphrase, got: %v %s", err, output)
+	}
+
+	t.Setenv("BRAGGER_PASSPHRASE", "correct horse")
+	output, err = runApp(t, workDir, "encrypt")
+	if err != nil || !strings.Contains(output, "Encrypted applications.jsonl, candidate-kb.jsonl and the change journal") {
+		t.Fatalf("encrypt failed: %v\n%s", err, output)
+	}
+	for _, name := range []string{"applications.jsonl", "candidate-kb.jsonl", filepath.Join(".bragger", "journal", "journal.jsonl")} {
+		data, _ := os.ReadFile(filepath.Join(workDir, name))
+		for _, secret := range []string{"Acme", "120k", "Jane Doe", "555-0100"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s has %q in plaintext", name, secret)
+			}
+		}
+	}
+
+	t.Run("passphrase from the environment", func(t *testing.T) {
+		output, err := runApp(t, workDir, "update", appID, "--status", "interviewing")
+		if err != nil {
+			t.Fatalf("update failed: %v\n%s", err, output)
+		}
+		output, _ = runApp(t, workDir, "show", appID)
+		if !strings.Contains(output, "Asked for 120k") || !strings.Contains(output, "interviewing") {
+			t.Errorf("expected the decrypted application, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "history", appID)
+		if !strings.Contains(output, "status") {
+			t.Errorf("expected the decrypted journal, got: %s", output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "applications.jsonl"))
+		if strings.Contains(string(data), "interviewing") {
+			t.Errorf("expected the update to be saved encrypted, got: %s", data)
+		}
+	})
+
+	t.Run("locked without a key", func(t *testing.T) {
+		t.Setenv("BRAGGER_PASSPHRASE", "")
+		output, err := runApp(t, workDir, "list")
+		if err == nil || !strings.Contains(output, "bragger unlock") || strings.Contains(output, "Acme") {
+			t.Errorf("expected list to fail while locked, got: %v %s", err, output)
+		}
+		t.Setenv("BRAGGER_PASSPHRASE", "wrong")
+		output, err = runApp(t, workDir, "list")
+		if err == nil || !strings.Contains(output, "wrong passphrase") {
+			t.Errorf("expected a wrong passphrase error, got: %v %s", err, output)
+		}
+	})
+
+	t.Run("key from the environment", func(t *testing.T) {
+		key, err := runApp(t, workDir, "unlock", "--print")
+		if err != nil {
+			t.Fatalf("unlock --print failed: %v\n%s", err, key)
+		}
+		t.Setenv("BRAGGER_PASSPHRASE", "")
+		t.Setenv("BRAGGER_KEY", strings.TrimSpace(key))
+		if output, err := runApp(t, workDir, "list"); err != nil || !strings.Contains(output, "Acme") {
+			t.Errorf("expected list to work with BRAGGER_KEY, got: %v %s", err, output)
+		}
+	})
+
+	t.Run("agent", func(t *testing.T) {
+		output, err := runApp(t, workDir, "unlock", "--timeout", "1m")
+		if err != nil || !strings.Contains(output, "Unlocked for 1m0s") {
+			t.Fatalf("unlock failed: %v\n%s", err, output)
+		}
+		defer runApp(t, workDir, "lock")
+
+		t.Setenv("BRAGGER_PASSPHRASE", "")
+		if output, err := runApp(t, workDir, "list"); err != nil || !strings.Contains(output, "Acme") {
+			t.Errorf("expected list to work while unlocked, got: %v %s", err, output)
+		}
+		output, _ = runApp(t, workDir, "unlock")
+		if !strings.Contains(output, "already unlocked") {
+			t.Errorf("expected already unlocked, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "lock")
+		if !strings.Contains(output, "Locked.") {
+			t.Errorf("expected Locked., got: %s", output)
+		}
+		if _, err := runApp(t, workDir, "list"); err == nil {
+			t.Error("expected list to fail once locked")
+		}
+		output, _ = runApp(t, workDir, "lock")
+		if !strings.Contains(output, "not unlocked") {
+			t.Errorf("expected not unlocked, got: %s", output)
+		}
+	})
+
+	t.Run("decrypt", func(t *testing.T) {
+		output, err := runApp(t, workDir, "decrypt")
+		if err != nil || !strings.Contains(output, "Decrypted") {
+			t.Fatalf("decrypt failed: %v\n%s", err, output)
+		}
+		t.Setenv("BRAGGER_PASSPHRASE", "")
+		if output, err := runApp(t, workDir, "list"); err != nil || !strings.Contains(output, "Acme") {
+			t.Errorf("expected list to work after decrypting, got: %v %s", err, output)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, ".bragger", "key.json")); !os.IsNotExist(err) {
+			t.Errorf("expected the key parameters to be removed, got %v", err)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, "candidate-kb.jsonl"))
+		if !strings.Contains(string(data), "Jane Doe") {
+			t.Errorf("expected plaintext after decrypting, got: %s", data)
+		}
+	})
+}
+
+func TestRedact(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	t.Run("no saved values", func(t *testing.T) {
+		os.WriteFile(filepath.Join(workDir, "letter.md"), []byte("{{EMAIL}}"), 0644)
+		output, err := runApp(t, workDir, "unredact", "letter.md")
+		if err == nil {
+			t.Fatalf("expected an error, got: %s", output)
+		}
+		if !strings.Contains(output, "kb context --redact") {
+			t.Errorf("expected a hint to redact first, got: %s", output)
+		}
+	})
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Smith","email":"jane@example.com","phone":"+1 555 0100","location":"Berlin","website":"https://jane.dev/?a=1&b=2"}`)
+	runApp(t, workDir, "kb", "add", "--type", "context", "--category", "preference",
+		"--source", "user", "--content", "Happy to relocate from Berlin; reach me at jane@example.com")
+
+	t.Run("redacted context", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--redact")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		for _, value := range []string{"jane@example.com", "555 0100", "Berlin", "jane.dev"} {
+			if strings.Contains(output, value) {
+				t.Errorf("expected %q to be redacted, got: %s", value, output)
+			}
+		}
+		for _, want := range []string{"**Name:** Jane Smith", "**Email:** {{EMAIL}}", "relocate from {{LOCATION}}", "{{WEBSITE}}"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q, got: %s", want, output)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(workDir, ".bragger", "redactions.json")); err != nil {
+			t.Errorf("expected the values to be saved: %v", err)
+		}
+	})
+
+	t.Run("unredact html", func(t *testing.T) {
+		path := filepath.Join(workDir, "resume.html")
+		os.WriteFile(path, []byte(`<p>{{EMAIL}} · <a href="{{WEBSITE}}">site</a> · {{GITHUB}}</p>`), 0644)
+		output, err := runApp(t, workDir, "unredact", "resume.html")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Restored 2 placeholders") {
+			t.Errorf("expected a count, got: %s", output)
+		}
+		if !strings.Contains(output, "Warning: no value for {{GITHUB}}") {
+			t.Errorf("expected a warning for the unknown placeholder, got: %s", output)
+		}
+		data, _ := os.ReadFile(path)
+		want := `<p>jane@example.com · <a href="https://jane.dev/?a=1&amp;b=2">site</a> · {{GITHUB}}</p>`
+		if string(data) != want {
+			t.Errorf("got %s, want %s", data, want)
+		}
+	})
+
+	t.Run("unredact to output", func(t *testing.T) {
+		os.WriteFile(filepath.Join(workDir, "letter.md"), []byte("Call {{PHONE}}"), 0644)
+		output, err := runApp(t, workDir, "unredact", "letter.md", "--output", "letter-final.md")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data, _ := os.ReadFile(filepath.Join(workDir, "letter-final.md")); string(data) != "Call +1 555 0100" {
+			t.Errorf("unexpected output file: %s", data)
+		}
+		if data, _ := os.ReadFile(filepath.Join(workDir, "letter.md")); string(data) != "Call {{PHONE}}" {
+			t.Errorf("expected the input to be left alone, got: %s", data)
+		}
+	})
+
+	t.Run("encrypted workspace", func(t *testing.T) {
+		t.Setenv("BRAGGER_PASSPHRASE", "correct horse")
+		if output, err := runApp(t, workDir, "encrypt"); err != nil {
+			t.Fatalf("encrypt failed: %v\nOutput: %s", err, output)
+		}
+		data, _ := os.ReadFile(filepath.Join(workDir, ".bragger", "redactions.json"))
+		if strings.Contains(string(data), "jane") {
+			t.Errorf("expected the saved values to be encrypted, got: %s", data)
+		}
+		os.WriteFile(filepath.Join(workDir, "letter.md"), []byte("{{EMAIL}}"), 0644)
+		output, err := runApp(t, workDir, "unredact", "letter.md")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if data, _ := os.ReadFile(filepath.Join(workDir, "letter.md")); string(data) != "jane@example.com" {
+			t.Errorf("unexpected output file: %s", data)
+		}
+	})
+}
+
+func TestKBContextBudgetManySkills(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	var skills []string
+	for i := 1; i <= 18; i++ {
+		skills = append(skills, fmt.Sprintf(`"Skill%d"`, i))
+	}
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"Acme","role":"Engineer","start_date":"2015-01","end_date":"2018-06","highlights":["Built the billing service","Ran the release process"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "skills",
+		"--data", `{"languages":[`+strings.Join(skills, ",")+`]}`)
+	full, _ := runApp(t, workDir, "kb", "context")
+	size := len(full) / 4
+
+	for _, budget := range []int{size - 2, size - 10, size - 25} {
+		output, err := runApp(t, workDir, "kb", "context", "--max-tokens", fmt.Sprint(budget))
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if strings.Contains(output, "Warning") || !strings.Contains(output, "## Omitted") {
+			t.Errorf("budget %d: expected the context to fit, got: %s", budget, output)
+		}
+	}
+
+	// Nothing fits: the smallest context is written, listing no skills
+	output, _ := runApp(t, workDir, "kb", "context", "--max-tokens", "5")
+	if !strings.Contains(output, "Warning: the context is still about") || strings.Contains(output, "Skill1") {
+		t.Errorf("expected the smallest context with a warning, got: %s", output)
+	}
+}
+
+func TestKBContextBudget(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Smith","email":"jane@example.com"}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"TechCorp","role":"Senior Engineer","start_date":"2020-01","end_date":"present","highlights":["Built Kafka pipelines in Go","Organized the team offsite and the quarterly planning days"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"OldCo","role":"Engineer","start_date":"2010-01","end_date":"2014-06","highlights":["Wrote Java batch jobs"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "skills",
+		"--data", `{"languages":["Go","Java","PHP"],"databases":["Redis"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "context", "--category", "preference",
+		"--source", "user", "--content", "Prefers remote-first companies with a four-day week and an async culture")
+	for _, note := range []string{
+		"Mentored three junior engineers through their first on-call rotations and wrote the team's runbook template",
+		"Gave the welcome talk at two company all-hands meetings and ran the internal newsletter for a year",
+	} {
+		runApp(t, workDir, "kb", "add", "--type", "context", "--category", "achievement", "--source", "user", "--content", note)
+	}
+	output, _ := runApp(t, workDir, "add", "--company", "Acme", "--role", "Backend Engineer",
+		"--jd-content", "We need Go and Kafka experience. Go is our main language.")
+	appID := extractAppID(output)
+
+	full, _ := runApp(t, workDir, "kb", "context")
+
+	t.Run("ranked against the JD", func(t *testing.T) {
+		budget := len(full)/4 - 60
+		output, err := runApp(t, workDir, "kb", "context", "--app", appID, "--max-tokens", fmt.Sprint(budget))
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		for _, want := range []string{"Built Kafka pipelines in Go", "**Programming Languages:** Go", "## Omitted", "for Backend Engineer @ Acme", "Left out "} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q, got: %s", want, output)
+			}
+		}
+		for _, omitted := range []string{"four-day week", "newsletter", "Wrote Java batch jobs"} {
+			if strings.Contains(output, omitted) {
+				t.Errorf("expected %q to be left out, got: %s", omitted, output)
+			}
+		}
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--max-tokens", "100000")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if output != full {
+			t.Errorf("expected the full context, got: %s", output)
+		}
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "context", "--max-tokens", "10")
+		if !strings.Contains(output, "Warning: the context is still about") {
+			t.Errorf("expected a warning, got: %s", output)
+		}
+		// Without any skills left, the skills section goes
+		if strings.Contains(output, "## Skills") || !strings.Contains(output, ": 4 skills") {
+			t.Errorf("expected no empty skills section, got: %s", output)
+		}
+	})
+
+	t.Run("categories and since", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--categories", "experience,context", "--since", "2015")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "TechCorp") || !strings.Contains(output, "four-day week") {
+			t.Errorf("expected the current role and the context entry, got: %s", output)
+		}
+		for _, omitted := range []string{"OldCo", "## Contact", "## Skills"} {
+			if strings.Contains(output, omitted) {
+				t.Errorf("expected %q to be filtered out, got: %s", omitted, output)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if output, err := runApp(t, workDir, "kb", "context", "--app", appID); err == nil || !strings.Contains(output, "--max-tokens") {
+			t.Errorf("expected --app to require a budget, got: %s", output)
+		}
+		if output, err := runApp(t, workDir, "kb", "context", "--since", "someday"); err == nil || !strings.Contains(output, "invalid date") {
+			t.Errorf("expected an invalid date error, got: %s", output)
+		}
+		if output, err := runApp(t, workDir, "kb", "context", "--app", "app-missing", "--max-tokens", "100"); err == nil || !strings.Contains(output, "Application not found") {
+			t.Errorf("expected an unknown application error, got: %s", output)
+		}
+	})
+}
+
+func TestKBContextFormats(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "contact",
+		"--data", `{"name":"Jane Smith","email":"jane@example.com"}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience", "--source", "cv-import",
+		"--data", `{"company":"TechCorp","role":"Senior Engineer","start_date":"2020-01","end_date":"present"}`)
+	for _, category := range []string{"zeta", "preference", "alpha", "achievement", "summary"} {
+		runApp(t, workDir, "kb", "add", "--type", "context", "--category", category,
+			"--source", "user", "--content", "Note about "+category)
+	}
+
+	t.Run("deterministic markdown", func(t *testing.T) {
+		first, err := runApp(t, workDir, "kb", "context")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, first)
+		}
+		for i := 0; i < 3; i++ {
+			if output, _ := runApp(t, workDir, "kb", "context"); output != first {
+				t.Fatalf("expected the same context on every run, got:\n%s\nthen:\n%s", first, output)
+			}
+		}
+		var positions []int
+		for _, heading := range []string{"## Contact", "## Experience", "### Summary", "### Achievement", "### Preference", "### Alpha", "### Zeta"} {
+			positions = append(positions, strings.Index(first, heading))
+		}
+		for i := 1; i < len(positions); i++ {
+			if positions[i-1] < 0 || positions[i] < positions[i-1] {
+				t.Fatalf("expected sections in order, got: %s", first)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--format", "json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var doc struct {
+			Sections []struct {
+				Type     string `json:"type"`
+				Category string `json:"category"`
+				Entries  []struct {
+					ID     string         `json:"id"`
+					Source string         `json:"source"`
+					Data   map[string]any `json:"data"`
+				} `json:"entries"`
+			} `json:"sections"`
+		}
+		if err := json.Unmarshal([]byte(output), &doc); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, output)
+		}
+		if len(doc.Sections) != 7 || doc.Sections[1].Category != "experience" || doc.Sections[6].Category != "zeta" {
+			t.Fatalf("unexpected sections: %s", output)
+		}
+		exp := doc.Sections[1].Entries[0]
+		if !strings.HasPrefix(exp.ID, "kb-") || exp.Source != "cv-import" || exp.Data["company"] != "TechCorp" {
+			t.Errorf("expected the entry ID, source and data, got %+v", exp)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--format", "yaml", "--categories", "summary")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "- type: context\n    category: summary\n") || !strings.Contains(output, "content: Note about summary") {
+			t.Errorf("unexpected YAML: %s", output)
+		}
+	})
+
+	t.Run("redacted json", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "context", "--format", "json", "--redact")
+		if strings.Contains(output, "jane@example.com") || !strings.Contains(output, `"email": "{{EMAIL}}"`) {
+			t.Errorf("expected the email to be redacted, got: %s", output)
+		}
+	})
+
+	t.Run("redacted yaml", func(t *testing.T) {
+		cmd := exec.Command(binaryPath, "kb", "context", "--format", "yaml", "--redact")
+		cmd.Dir = workDir
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var doc struct {
+			Sections []struct {
+				Entries []struct {
+					Data map[string]any `yaml:"data"`
+				} `yaml:"entries"`
+			} `yaml:"sections"`
+		}
+		if err := yaml.Unmarshal(output, &doc); err != nil {
+			t.Fatalf("invalid YAML: %v\n%s", err, output)
+		}
+		if email := doc.Sections[0].Entries[0].Data["email"]; email != "{{EMAIL}}" {
+			t.Errorf("expected the email to be a redacted string, got %#v in:\n%s", email, output)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "context", "--format", "xml")
+		if err == nil || !strings.Contains(output, "unknown format") {
+			t.Errorf("expected an error, got: %s", output)
+		}
+	})
+}
+
+func TestKBSearch(t *testing.T) {
+	workDir, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "experience",
+		"--data", `{"company":"TechCorp","role":"Senior Engineer","start_date":"2020-01","highlights":["Led the team offsite","Migrated billing to Kafka and built event-driven services"]}`)
+	runApp(t, workDir, "kb", "add", "--type", "profile", "--category", "skills",
+		"--data", `{"languages":["Go"],"tools":["Kafka","Docker"]}`)
+	output, _ := runApp(t, workDir, "kb", "add", "--type", "context", "--category", "achievement",
+		"--source", "user", "--content", "Cut Kafka consumer lag by 90% after migrating to Kafka Streams")
+	lagID := extractKBID(output)
+	output, _ = runApp(t, workDir, "kb", "add", "--type", "context", "--category", "preference",
+		"--source", "user", "--content", "Driven by event planning")
+	preferenceID := extractKBID(output)
+
+	t.Run("ranked with snippets", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "search", "KAFKA")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Found 3 entries matching 'KAFKA'") {
+			t.Errorf("expected a count, got: %s", output)
+		}
+		first := strings.Index(output, lagID)
+		if first < 0 || first > strings.Index(output, "profile/experience") {
+			t.Errorf("expected the entry mentioning Kafka twice first, got: %s", output)
+		}
+		for _, want := range []string{
+			"content: Cut **Kafka** consumer lag by 90% after migrating to **Kafka** Streams",
+			"highlights[1]: Migrated billing to **Kafka** and built",
+			"tools[0]: **Kafka**",
+			"Senior Engineer @ TechCorp",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q, got: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("phrase and stemming", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "search", "event driven")
+		if !strings.Contains(output, "Found 1 entry") || !strings.Contains(output, "built **event-driven** services") {
+			t.Errorf("expected the phrase to match the experience only, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "search", "event", "driven")
+		if !strings.Contains(output, "Found 2 entries") || !strings.Contains(output, preferenceID) {
+			t.Errorf("expected separate words to match both, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "search", "migration")
+		if !strings.Contains(output, "Found 2 entries") || !strings.Contains(output, "**Migrated**") {
+			t.Errorf("expected migration to match migrated and migrating, got: %s", output)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		output, _ := runApp(t, workDir, "kb", "search", "kafka", "--type", "profile")
+		if !strings.Contains(output, "Found 2 entries") || strings.Contains(output, lagID) {
+			t.Errorf("expected profile entries only, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "search", "kafka", "--category", "skills")
+		if !strings.Contains(output, "Found 1 entry") || !strings.Contains(output, "profile/skills") {
+			t.Errorf("expected the skills entry only, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "search", "kafka", "--limit", "1")
+		if !strings.Contains(output, "showing the top 1") || strings.Count(output, "kb-") != 1 {
+			t.Errorf("expected one result, got: %s", output)
+		}
+		runApp(t, workDir, "kb", "archive", lagID)
+		output, _ = runApp(t, workDir, "kb", "search", "streams")
+		if !strings.Contains(output, "No entries match 'streams'") {
+			t.Errorf("expected archived entries to be left out, got: %s", output)
+		}
+		output, _ = runApp(t, workDir, "kb", "search", "streams", "--all")
+		if !strings.Contains(output, "achievement (archived)") {
+			t.Errorf("expected the archived entry with --all, got: %s", output)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output, err := runApp(t, workDir, "kb", "search", "docker", "--json")
+		if err != nil {
+			t.Fatalf("command failed: %v\nOutput: %s", err, output)
+		}
+		var results []struct {
+			ID       string `json:"id"`
+			Category string `json:"category"`
+			Field    string `json:"field"`
+			Snippet  string `json:"snippet"`
+			Matches  int    `json:"matches"`
+		}
+		if err := json.Unmarshal([]byte(output), &results); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, output)
+		}
+		if len(results) != 1 || results[0].Field != "tools[1]" || results[0].Snippet != "**Docker**" || results[0].Matches != 1 {
+			t.Errorf("unexpected results: %s", output)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if output, err := runApp(t, workDir, "kb", "search"); err == nil || !strings.Contains(output, "Usage") {
+			t.Errorf("expected usage, got: %s", output)
+		}
+		if output, err := runApp(t, workDir, "kb", "search", "kafka", "--type", "other"); err == nil || !strings.Contains(output, "invalid type") {
+			t.Errorf("expected an invalid type error, got: %s", output)
+		}
+		if output, err := runApp(t, workDir, "kb", "search", "?!"); err == nil || !strings.Contains(output, "empty query") {
+			t.Errorf("expected an empty query error, got: %s", output)
+		}
+	})
+}
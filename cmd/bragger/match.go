@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ewurch/bragger/internal/jd"
+	"github.com/ewurch/bragger/internal/match"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// cmdMatch scores how well the knowledge base (and optionally a generated
+// resume) covers the keywords of an application's job description
+func cmdMatch(store *storage.Storage, kbStore *storage.KBStorage, id string, args []string) {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	resumePath := fs.String("resume", "", "Generated resume HTML to score against the JD")
+	asJSON := fs.Bool("json", false, "Output the report as JSON")
+	fs.Parse(args)
+
+	app, err := store.Get(id)
+	if err != nil {
+		fmt.Printf("Application not found: %s\n", id)
+		os.Exit(1)
+	}
+
+	if app.JDContent == "" {
+		fmt.Printf("Error: application %s has no job description. Add one with --jd-content, --jd-file or 'bragger jd fetch'\n", id)
+		os.Exit(1)
+	}
+
+	entries, err := kbStore.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	entries = models.Unarchived(entries)
+
+	dict := match.DefaultDictionary()
+	report := match.Compare(dict, app.JDContent, match.KBDocuments(entries))
+
+	if *resumePath != "" {
+		content, err := os.ReadFile(*resumePath)
+		if err != nil {
+			fmt.Printf("Error reading resume: %v\n", err)
+			os.Exit(1)
+		}
+		report.ScoreResume(dict, jd.HTMLToText(string(content)))
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("## Keyword Analysis Report: %s @ %s\n", app.Role, app.Company)
+	fmt.Printf("*Application ID: %s*\n\n", app.ID)
+
+	if len(report.Keywords) == 0 {
+		fmt.Println("No known skill or technology keywords found in the job description.")
+		return
+	}
+
+	fmt.Printf("### KB Match Score: %d/%d keywords (%d%%)\n", report.KB.Matched, report.KB.Total, report.KB.Percent)
+	if report.Resume != nil {
+		fmt.Printf("### Resume Match Score: %d/%d keywords (%d%%)\n", report.Resume.Matched, report.Resume.Total, report.Resume.Percent)
+	}
+
+	found := report.Found()
+	if len(found) > 0 {
+		fmt.Println("\n### Found Keywords (with frequency)")
+		if report.Resume != nil {
+			fmt.Println("| Keyword | JD | KB | Resume | Category |")
+			fmt.Println("|---------|----|----|--------|----------|")
+			for _, k := range found {
+				fmt.Printf("| %s | %d | %d | %d | %s |\n", k.Name, k.JDCount, k.KBCount, k.ResumeCount, k.Category)
+			}
+		} else {
+			fmt.Println("| Keyword | JD | KB | Category |")
+			fmt.Println("|---------|----|----|----------|")
+			for _, k := range found {
+				fmt.Printf("| %s | %d | %d | %s |\n", k.Name, k.JDCount, k.KBCount, k.Category)
+			}
+		}
+	}
+
+	missing := report.Missing()
+	if len(missing) > 0 {
+		fmt.Println("\n### Missing Keywords")
+		fmt.Println("| Keyword | JD | Category | Suggestion |")
+		fmt.Println("|---------|----|----------|------------|")
+		for _, k := range missing {
+			fmt.Printf("| %s | %d | %s | %s |\n", k.Name, k.JDCount, k.Category, missingSuggestion(k, report.Resume != nil))
+		}
+	}
+}
+
+func missingSuggestion(k match.Keyword, scoredResume bool) string {
+	if scoredResume && k.KBCount > 0 {
+		return "In your KB - add it to the resume"
+	}
+	return "Not in your KB - add it if you have this experience"
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/pdf"
+	"github.com/ewurch/bragger/internal/render"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// pdfSources are the documents converted for an application, in order
+var pdfSources = []string{"resume.html", "cover_letter.html"}
+
+func printPDFUsage() {
+	fmt.Println(`PDF - Convert generated HTML to PDF
+
+Usage:
+  bragger pdf <file.html> [flags]
+  bragger pdf --app <id> [flags]
+
+Flags:
+  --app <id>       Convert resume.html and cover_letter.html in the application's output directory
+  --size <size>    Page size: a4 or letter (default: the document's @page size, else A4)
+  --output <path>  Output file (default: the HTML path with a .pdf extension)
+
+Examples:
+  bragger pdf outputs/acme_engineer/resume.html
+  bragger pdf outputs/acme_engineer/cover_letter.html --size letter
+  bragger pdf --app app-a1b2c3d4`)
+}
+
+func cmdPDF(store *storage.Storage, args []string) {
+	// Accept the HTML file before or after the flags
+	var input string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		input, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("pdf", flag.ExitOnError)
+	appID := fs.String("app", "", "Application ID")
+	size := fs.String("size", "", "Page size (a4 or letter)")
+	output := fs.String("output", "", "Output file")
+	fs.Parse(args)
+	if input == "" {
+		input = fs.Arg(0)
+	}
+
+	var opts pdf.Options
+	opts.CreationDate = time.Now()
+	if *size != "" {
+		pageSize, err := pdf.PageSizeByName(*size)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.PageSize = &pageSize
+	}
+
+	if *appID == "" {
+		if input == "" {
+			printPDFUsage()
+			os.Exit(1)
+		}
+		outPath := *output
+		if outPath == "" {
+			outPath = strings.TrimSuffix(input, filepath.Ext(input)) + ".pdf"
+		}
+		convertToPDF(input, outPath, opts)
+		return
+	}
+
+	if input != "" || *output != "" {
+		fmt.Println("Error: --app converts the application's documents; it cannot be combined with a file or --output")
+		os.Exit(1)
+	}
+	app, err := store.Get(*appID)
+	if err != nil {
+		fmt.Printf("Application not found: %s\n", *appID)
+		os.Exit(1)
+	}
+
+	dir := render.OutputDir(app.Company, app.Role)
+	converted := 0
+	for _, name := range pdfSources {
+		htmlPath := filepath.Join(dir, name)
+		if _, err := os.Stat(htmlPath); err != nil {
+			continue
+		}
+		convertToPDF(htmlPath, strings.TrimSuffix(htmlPath, ".html")+".pdf", opts)
+		converted++
+	}
+	if converted == 0 {
+		fmt.Printf("No %s found in %s\n", strings.Join(pdfSources, " or "), dir)
+		os.Exit(1)
+	}
+}
+
+func convertToPDF(htmlPath, outPath string, opts pdf.Options) {
+	html, err := os.ReadFile(htmlPath)
+	if err != nil {
+		fmt.Printf("Error reading HTML: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := pdf.FromHTML(html, opts)
+	if err != nil {
+		fmt.Printf("Error converting %s: %v\n", htmlPath, err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(outPath, out, 0644); err != nil {
+		fmt.Printf("Error writing PDF: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("PDF written to %s\n", outPath)
+}
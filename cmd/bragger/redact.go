@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/redact"
+	"github.com/ewurch/bragger/internal/storage"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+// redactContext replaces the contact's details in the entries of the KB
+// context with placeholders, before they're written in any format, and
+// saves their values for 'bragger unredact'. The contact is looked up in
+// loaded, so details are redacted even with the contact entry filtered out.
+// Notes go to stderr, since the context is piped to an LLM.
+func redactContext(entries, loaded []*models.KBEntry, key *vault.Key) []*models.KBEntry {
+	var contact models.ContactData
+	for _, e := range loaded {
+		if c, ok := e.AsContact(); ok && e.Type == models.KBTypeProfile {
+			contact = c
+			break
+		}
+	}
+	m := redact.Contact(contact)
+	if len(m) == 0 {
+		fmt.Fprintln(os.Stderr, "No contact details to redact.")
+		return entries
+	}
+	if err := m.Save("", key); err != nil {
+		fmt.Printf("Error saving %s: %v\n", redact.DefaultMapPath, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Redacted %d contact details; their values are in %s.\n", len(m), redact.DefaultMapPath)
+	fmt.Fprintln(os.Stderr, "Put them back into the LLM's output with 'bragger unredact <file>'.")
+	return m.RedactEntries(entries)
+}
+
+// cmdUnredact substitutes the values saved by 'kb context --redact' for the
+// placeholders in a generated file, HTML-escaping them in HTML files
+func cmdUnredact(kbStore *storage.KBStorage, args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		fmt.Println("Usage: bragger unredact <file> [--output <file>]")
+		os.Exit(1)
+	}
+	path := args[0]
+	fs := flag.NewFlagSet("unredact", flag.ExitOnError)
+	output := fs.String("output", "", "Write the result here instead of over the file")
+	fs.Parse(args[1:])
+
+	m, err := redact.Load("", kbStore.Key())
+	if os.IsNotExist(err) {
+		fmt.Println("No redacted values saved; run 'bragger kb context --redact' first.")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var escape func(string) string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		escape = html.EscapeString
+	}
+	text, n := m.Unredact(string(data), escape)
+
+	if *output == "" {
+		*output = path
+	}
+	if err := os.WriteFile(*output, []byte(text), 0644); err != nil {
+		fmt.Printf("Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Restored %s in %s.\n", plural(n, "placeholder"), *output)
+	for _, p := range redact.Placeholders(text) {
+		fmt.Printf("Warning: no value for %s\n", p)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/docx"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/render"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+func printRenderUsage() {
+	fmt.Printf(`Render - Build documents from the knowledge base
+
+Usage:
+  bragger render resume [flags]
+  bragger render cover-letter --app <id> [flags]
+
+Flags for resume:
+  --app <id>       Application the resume is for; output goes to outputs/<company>_<role>/resume.<format>
+  --format <fmt>   Output format: html or docx (default: html, or docx for a .docx --output)
+  --theme <name>   Theme: %s, or a directory containing resume.html.tmpl (default: %s)
+                   (HTML only; Word documents use Word's built-in styles)
+  --select <file>  Selection file choosing experiences, highlights and skills
+                   (default: selection.json in the output directory, if present)
+  --output <path>  Write to this file instead of the application's output directory
+
+Flags for cover-letter:
+  --app <id>       Convert outputs/<company>_<role>/cover_letter.html to cover_letter.docx
+  --input <path>   Cover letter HTML to convert instead of the application's
+  --format <fmt>   Output format: docx (default: docx)
+  --output <path>  Write to this file (default: the input path with a .docx extension)
+
+Examples:
+  bragger render resume --app app-a1b2c3d4 --theme classic
+  bragger render resume --app app-a1b2c3d4 --format docx
+  bragger render resume --app app-a1b2c3d4 --select outputs/acme_engineer/selection.json
+  bragger render resume --output resume.html --theme minimal
+  bragger render cover-letter --app app-a1b2c3d4 --format docx
+`, strings.Join(render.Themes(), ", "), render.DefaultTheme)
+}
+
+func cmdRender(store *storage.Storage, kbStore *storage.KBStorage, subcommand string, args []string) {
+	switch subcommand {
+	case "resume":
+		cmdRenderResume(store, kbStore, args)
+	case "cover-letter":
+		cmdRenderCoverLetter(store, args)
+	default:
+		fmt.Printf("Unknown render subcommand: %s\n", subcommand)
+		printRenderUsage()
+		os.Exit(1)
+	}
+}
+
+func cmdRenderResume(store *storage.Storage, kbStore *storage.KBStorage, args []string) {
+	fs := flag.NewFlagSet("render resume", flag.ExitOnError)
+	appID := fs.String("app", "", "Application ID")
+	format := fs.String("format", "", "Output format (html or docx)")
+	theme := fs.String("theme", render.DefaultTheme, "Theme name or directory")
+	selectPath := fs.String("select", "", "Selection file")
+	output := fs.String("output", "", "Output file")
+	fs.Parse(args)
+
+	if *appID == "" && *output == "" {
+		fmt.Println("Error: --app or --output is required")
+		os.Exit(1)
+	}
+	if *format == "" {
+		*format = "html"
+		if strings.EqualFold(filepath.Ext(*output), ".docx") {
+			*format = "docx"
+		}
+	}
+	if *format != "html" && *format != "docx" {
+		fmt.Printf("Error: unknown format %q (use html or docx)\n", *format)
+		os.Exit(1)
+	}
+
+	outPath := *output
+	if *appID != "" {
+		app, err := store.Get(*appID)
+		if err != nil {
+			fmt.Printf("Application not found: %s\n", *appID)
+			os.Exit(1)
+		}
+		if outPath == "" {
+			outPath = filepath.Join(render.OutputDir(app.Company, app.Role), "resume."+*format)
+		}
+	}
+
+	if *selectPath == "" {
+		candidate := filepath.Join(filepath.Dir(outPath), render.DefaultSelectionFile)
+		if _, err := os.Stat(candidate); err == nil {
+			*selectPath = candidate
+		}
+	}
+
+	var sel *render.Selection
+	if *selectPath != "" {
+		var err error
+		sel, err = render.LoadSelection(*selectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	entries, err := kbStore.Load()
+	if err != nil {
+		fmt.Printf("Error loading knowledge base: %v\n", err)
+		os.Exit(1)
+	}
+	entries = models.Unarchived(entries)
+
+	resume, err := render.BuildResume(entries, sel)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if *format == "docx" {
+		err = render.RenderDOCX(&buf, resume)
+	} else {
+		err = render.RenderHTML(&buf, *theme, resume)
+	}
+	if err != nil {
+		fmt.Printf("Error rendering resume: %v\n", err)
+		os.Exit(1)
+	}
+
+	writeRendered(outPath, buf.Bytes())
+
+	fmt.Printf("Resume written to %s\n", outPath)
+	if sel != nil {
+		fmt.Printf("Selection: %s\n", *selectPath)
+	}
+}
+
+func cmdRenderCoverLetter(store *storage.Storage, args []string) {
+	fs := flag.NewFlagSet("render cover-letter", flag.ExitOnError)
+	appID := fs.String("app", "", "Application ID")
+	input := fs.String("input", "", "Cover letter HTML")
+	format := fs.String("format", "docx", "Output format (docx)")
+	output := fs.String("output", "", "Output file")
+	fs.Parse(args)
+
+	if *format != "docx" {
+		fmt.Printf("Error: unknown format %q (cover letters are written as HTML by the cover-letter skill; use docx)\n", *format)
+		os.Exit(1)
+	}
+	if *appID == "" && *input == "" {
+		fmt.Println("Error: --app or --input is required")
+		os.Exit(1)
+	}
+
+	inPath := *input
+	if inPath == "" {
+		app, err := store.Get(*appID)
+		if err != nil {
+			fmt.Printf("Application not found: %s\n", *appID)
+			os.Exit(1)
+		}
+		inPath = filepath.Join(render.OutputDir(app.Company, app.Role), "cover_letter.html")
+	}
+	outPath := *output
+	if outPath == "" {
+		outPath = strings.TrimSuffix(inPath, filepath.Ext(inPath)) + ".docx"
+	}
+
+	html, err := os.ReadFile(inPath)
+	if err != nil {
+		fmt.Printf("Error reading cover letter: %v\n", err)
+		os.Exit(1)
+	}
+	doc, err := docx.FromHTML(html)
+	if err != nil {
+		fmt.Printf("Error converting cover letter: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		fmt.Printf("Error rendering cover letter: %v\n", err)
+		os.Exit(1)
+	}
+
+	writeRendered(outPath, buf.Bytes())
+	fmt.Printf("Cover letter written to %s\n", outPath)
+}
+
+func writeRendered(path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Printf("Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Printf("Error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+}
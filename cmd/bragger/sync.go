@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/gitsync"
+	"github.com/ewurch/bragger/internal/storage"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+func printSyncUsage() {
+	fmt.Println(`Workspace Sync - Version the workspace in git and sync it between machines
+
+Usage:
+  bragger sync <subcommand> [arguments]
+
+Subcommands:
+  init      Make the workspace a git repository, committing it as it is
+  commit    Commit any changes not committed yet
+  log       Show the workspace history, newest first
+  push      Send the workspace's commits to the remote
+  pull      Fetch the remote's commits and merge them in
+
+Once sync is set up, every command that changes applications or the KB
+commits its change with a message describing it, e.g.
+"update app-1234: status applied→interviewing". Turn this off with
+'git config bragger.autocommit false'.
+
+Only the data files, the journal and their settings are committed;
+generated resumes and cover letters in outputs/ are left out.
+
+applications.jsonl and candidate-kb.jsonl are merged record by record: a
+record changed on both machines keeps the version updated last.
+
+Flags for init:
+  --remote    URL of the remote repository, e.g. a private GitHub repository
+              or a bare repository on a shared drive; its history is merged in
+
+Flags for commit:
+  -m          Commit message (default: the files changed)
+
+Flags for log:
+  --limit     Number of commits to show (default 20, 0 for all)
+
+Examples:
+  bragger sync init --remote git@github.com:me/job-search.git
+  bragger sync push
+  bragger sync pull
+  bragger sync log --limit 5`)
+}
+
+func cmdSync(args []string) {
+	if len(args) < 1 {
+		printSyncUsage()
+		os.Exit(1)
+	}
+	repo := gitsync.Open("")
+	switch args[0] {
+	case "init":
+		cmdSyncInit(repo, args[1:])
+	case "commit":
+		cmdSyncCommit(repo, args[1:])
+	case "log":
+		cmdSyncLog(repo, args[1:])
+	case "push":
+		commitPending(repo)
+		if err := repo.Push(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Pushed to %s.\n", repo.RemoteURL())
+	case "pull":
+		commitPending(repo)
+		syncPull(repo)
+	case "merge-driver":
+		cmdSyncMergeDriver(args[1:])
+	default:
+		fmt.Printf("Unknown sync subcommand: %s\n", args[0])
+		printSyncUsage()
+		os.Exit(1)
+	}
+}
+
+// cmdSyncInit sets up the workspace repository and merges in the remote's
+// history, so a second machine picks up the first one's workspace
+func cmdSyncInit(repo *gitsync.Repo, args []string) {
+	fs := flag.NewFlagSet("sync init", flag.ExitOnError)
+	remote := fs.String("remote", "", "URL of the remote repository")
+	fs.Parse(args)
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Error locating the bragger binary: %v\n", err)
+		os.Exit(1)
+	}
+	if err := repo.Init(shellQuote(exe) + " sync merge-driver"); err != nil {
+		fmt.Printf("Error setting up the repository: %v\n", err)
+		os.Exit(1)
+	}
+	if *remote != "" {
+		if err := repo.SetRemote(*remote); err != nil {
+			fmt.Printf("Error setting the remote: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if committed, err := repo.Commit("bragger sync init"); err != nil {
+		fmt.Printf("Error committing the workspace: %v\n", err)
+		os.Exit(1)
+	} else if committed {
+		fmt.Println("Committed the workspace.")
+	}
+	if *remote != "" {
+		syncPull(repo)
+	}
+
+	fmt.Println("Workspace sync is set up. Changes are committed as you make them;")
+	fmt.Println("use 'bragger sync push' and 'bragger sync pull' to sync with other machines.")
+}
+
+func cmdSyncCommit(repo *gitsync.Repo, args []string) {
+	fs := flag.NewFlagSet("sync commit", flag.ExitOnError)
+	message := fs.String("m", "", "Commit message")
+	fs.Parse(args)
+
+	committed, err := repo.Commit(*message)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !committed {
+		fmt.Println("Nothing to commit.")
+		return
+	}
+	fmt.Println("Committed the workspace.")
+}
+
+func cmdSyncLog(repo *gitsync.Repo, args []string) {
+	fs := flag.NewFlagSet("sync log", flag.ExitOnError)
+	limit := fs.Int("limit", 20, "Number of commits to show (0 for all)")
+	fs.Parse(args)
+
+	commits, err := repo.Log(*limit)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(commits) == 0 {
+		fmt.Println("No commits yet.")
+		return
+	}
+	for _, c := range commits {
+		fmt.Printf("%s  %s  %s\n", c.Hash, c.Time.Local().Format("2006-01-02 15:04"), c.Subject)
+	}
+}
+
+// syncPull merges in the remote's commits
+func syncPull(repo *gitsync.Repo) {
+	pulled, err := repo.Pull()
+	switch {
+	case errors.Is(err, gitsync.ErrConflict):
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("The pull was undone. Merge these files by hand with 'git pull', then run 'bragger sync commit'.")
+		os.Exit(1)
+	case err != nil:
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	case !pulled:
+		fmt.Printf("Nothing to pull from %s yet.\n", repo.RemoteURL())
+	default:
+		fmt.Printf("Pulled from %s.\n", repo.RemoteURL())
+	}
+}
+
+// commitPending commits changes made outside bragger before a push or pull
+func commitPending(repo *gitsync.Repo) {
+	if !repo.IsInitialized() {
+		fmt.Printf("Error: %v\n", gitsync.ErrNotInitialized)
+		os.Exit(1)
+	}
+	if _, err := repo.Commit(""); err != nil {
+		fmt.Printf("Error committing local changes: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// cmdSyncMergeDriver merges a data file for git, which runs it with the
+// base, ours and theirs versions and takes the result from ours
+func cmdSyncMergeDriver(args []string) {
+	if len(args) != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: bragger sync merge-driver <base> <ours> <theirs>")
+		os.Exit(2)
+	}
+	var versions [3][]byte
+	for i, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bragger merge: %v\n", err)
+			os.Exit(1)
+		}
+		versions[i] = data
+	}
+
+	// Encrypted versions are merged decrypted, and the result encrypted
+	var key *vault.Key
+	for i, data := range versions {
+		if !vault.IsSealed(data) {
+			continue
+		}
+		if key == nil {
+			var err error
+			if key, err = workspaceKey(false); err == nil && key == nil {
+				err = errLocked
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "bragger merge: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		opened, err := key.Open(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bragger merge: %s: %v\n", args[i], err)
+			os.Exit(1)
+		}
+		versions[i] = opened
+	}
+	merged, err := gitsync.MergeJSONL(versions[0], versions[1], versions[2])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bragger merge: %v\n", err)
+		os.Exit(1)
+	}
+	if key != nil {
+		merged = append(key.Seal(merged), '\n')
+	}
+	if err := os.WriteFile(args[1], merged, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "bragger merge: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// setupAutoCommit commits each journaled step as it's saved, when the
+// workspace has sync set up
+func setupAutoCommit(repo *gitsync.Repo, j *storage.Journal) {
+	j.OnRecord = func(changes []storage.Change) {
+		autoCommit(repo, gitsync.Message(changes))
+	}
+}
+
+// autoCommit commits the workspace, warning rather than failing the
+// command, whose change is saved either way
+func autoCommit(repo *gitsync.Repo, message string) {
+	if _, err := repo.Commit(message); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: auto-commit failed: %v\n", err)
+	}
+}
+
+// shellQuote quotes a path for the shell git runs the merge driver with
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -1,3 +1,15 @@
 module github.com/ewurch/bragger
 
-go 1.23
+go 1.23.0
+
+require (
+	golang.org/x/crypto v0.35.0
+	golang.org/x/image v0.24.0
+	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
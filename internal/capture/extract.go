@@ -0,0 +1,134 @@
+package capture
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// titleSuffixes are job board names appended to page titles
+var titleSuffixes = regexp.MustCompile(`(?i)\s*[|\-–—]\s*(linkedin|indeed(\.com)?|glassdoor|wellfound|angellist|greenhouse|lever|workable|ashby|welcome to the jungle|otta|stepstone|xing|careers?|jobs?)\s*$`)
+
+var (
+	// "Job Application for Senior Engineer at Acme" (Greenhouse)
+	greenhouseTitle = regexp.MustCompile(`(?i)^job application for (.+?) at (.+)$`)
+	// "Acme hiring Senior Engineer in Berlin, Germany" (LinkedIn)
+	linkedInTitle = regexp.MustCompile(`(?i)^(.+?) hiring (.+?)(?: in .+)?$`)
+	// "Senior Engineer at Acme" / "Senior Engineer @ Acme"
+	atTitle = regexp.MustCompile(`(?i)^(.+?)\s+(?:at|@)\s+(.+)$`)
+	// "Senior Engineer - Acme" / "Acme | Senior Engineer"
+	separatorTitle = regexp.MustCompile(`^(.+?)\s+[|\-–—]\s+(.+)$`)
+)
+
+// pathBoards host their postings at <host>/<company-slug>/...
+var pathBoards = map[string]bool{
+	"boards.greenhouse.io":     true,
+	"job-boards.greenhouse.io": true,
+	"jobs.lever.co":            true,
+	"jobs.ashbyhq.com":         true,
+	"apply.workable.com":       true,
+}
+
+// subdomainBoards host their postings at <company-slug>.<suffix>
+var subdomainBoards = []string{
+	".workable.com",
+	".recruitee.com",
+	".bamboohr.com",
+	".myworkdayjobs.com",
+	".teamtailor.com",
+	".personio.de",
+	".breezy.hr",
+}
+
+// companyFirstHosts put the company before the role in their page titles
+var companyFirstHosts = []string{"lever.co", "ashbyhq.com", "workable.com"}
+
+// ExtractCompanyRole guesses the company and role of a job posting from its
+// page title, falling back to the company slug in well-known job board URLs.
+// Either value may be empty if it cannot be determined.
+func ExtractCompanyRole(pageURL, title string) (company, role string) {
+	title = strings.TrimSpace(title)
+	for {
+		trimmed := titleSuffixes.ReplaceAllString(title, "")
+		if trimmed == title {
+			break
+		}
+		title = trimmed
+	}
+
+	host := ""
+	if u, err := url.Parse(pageURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+
+	switch {
+	case title == "":
+		// Nothing to parse
+	case greenhouseTitle.MatchString(title):
+		m := greenhouseTitle.FindStringSubmatch(title)
+		role, company = m[1], m[2]
+	case linkedInTitle.MatchString(title) && strings.Contains(host, "linkedin."):
+		m := linkedInTitle.FindStringSubmatch(title)
+		company, role = m[1], m[2]
+	case atTitle.MatchString(title):
+		m := atTitle.FindStringSubmatch(title)
+		role, company = m[1], m[2]
+	case separatorTitle.MatchString(title):
+		m := separatorTitle.FindStringSubmatch(title)
+		role, company = m[1], m[2]
+		for _, h := range companyFirstHosts {
+			if strings.HasSuffix(host, h) {
+				company, role = m[1], m[2]
+				break
+			}
+		}
+	default:
+		role = title
+	}
+
+	if company == "" {
+		company = companyFromURL(pageURL)
+	}
+
+	return strings.TrimSpace(company), strings.TrimSpace(role)
+}
+
+// companyFromURL extracts the company slug from a job board URL and turns it
+// into a display name ("acme-corp" -> "Acme Corp").
+func companyFromURL(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+
+	slug := ""
+	if pathBoards[host] {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) > 0 {
+			slug = parts[0]
+		}
+	} else {
+		for _, suffix := range subdomainBoards {
+			if strings.HasSuffix(host, suffix) {
+				slug = strings.TrimSuffix(host, suffix)
+				if i := strings.LastIndexByte(slug, '.'); i >= 0 {
+					slug = slug[i+1:]
+				}
+				break
+			}
+		}
+	}
+
+	if slug == "" || slug == "www" || slug == "apply" || slug == "jobs" {
+		return ""
+	}
+
+	words := strings.FieldsFunc(slug, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
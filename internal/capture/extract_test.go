@@ -0,0 +1,82 @@
+package capture
+
+import "testing"
+
+func TestExtractCompanyRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		title       string
+		wantCompany string
+		wantRole    string
+	}{
+		{
+			name:        "greenhouse application title",
+			url:         "https://boards.greenhouse.io/acme/jobs/123",
+			title:       "Job Application for Senior Engineer at Acme",
+			wantCompany: "Acme",
+			wantRole:    "Senior Engineer",
+		},
+		{
+			name:        "linkedin hiring title",
+			url:         "https://www.linkedin.com/jobs/view/123",
+			title:       "Globex hiring Staff Engineer in Berlin, Germany | LinkedIn",
+			wantCompany: "Globex",
+			wantRole:    "Staff Engineer",
+		},
+		{
+			name:        "role at company",
+			url:         "https://example.com/careers/1",
+			title:       "Backend Developer at Initech",
+			wantCompany: "Initech",
+			wantRole:    "Backend Developer",
+		},
+		{
+			name:        "role dash company with board suffix",
+			url:         "https://example.com/jobs/1",
+			title:       "Data Engineer - Hooli | Glassdoor",
+			wantCompany: "Hooli",
+			wantRole:    "Data Engineer",
+		},
+		{
+			name:        "lever puts company first",
+			url:         "https://jobs.lever.co/umbrella/abc-123",
+			title:       "Umbrella - Platform Engineer",
+			wantCompany: "Umbrella",
+			wantRole:    "Platform Engineer",
+		},
+		{
+			name:        "company from greenhouse url slug",
+			url:         "https://boards.greenhouse.io/wayne-enterprises/jobs/456",
+			title:       "Security Engineer",
+			wantCompany: "Wayne Enterprises",
+			wantRole:    "Security Engineer",
+		},
+		{
+			name:        "company from subdomain board",
+			url:         "https://stark.recruitee.com/o/frontend-engineer",
+			title:       "",
+			wantCompany: "Stark",
+			wantRole:    "",
+		},
+		{
+			name:        "unknown site and plain title",
+			url:         "https://example.com/job",
+			title:       "Engineering Manager",
+			wantCompany: "",
+			wantRole:    "Engineering Manager",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			company, role := ExtractCompanyRole(tt.url, tt.title)
+			if company != tt.wantCompany {
+				t.Errorf("company = %q, want %q", company, tt.wantCompany)
+			}
+			if role != tt.wantRole {
+				t.Errorf("role = %q, want %q", role, tt.wantRole)
+			}
+		})
+	}
+}
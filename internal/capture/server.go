@@ -0,0 +1,209 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ewurch/bragger/internal/jd"
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// DefaultAddr is the address the capture server listens on by default.
+// It is bound to loopback so only local clients (the browser) can reach it.
+const DefaultAddr = "127.0.0.1:7878"
+
+// maxBodySize limits the size of a captured page (full HTML can be large)
+const maxBodySize = 10 * 1024 * 1024
+
+// Request is the payload sent by the browser extension
+type Request struct {
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Text    string `json:"text,omitempty"`    // Page text (preferred when present)
+	HTML    string `json:"html,omitempty"`    // Page HTML, converted to text if Text is empty
+	Company string `json:"company,omitempty"` // Overrides extraction
+	Role    string `json:"role,omitempty"`    // Overrides extraction
+	Status  string `json:"status,omitempty"`  // Overrides the server default status
+}
+
+// Response is returned after a job has been captured
+type Response struct {
+	ID      string `json:"id"`
+	Company string `json:"company"`
+	Role    string `json:"role"`
+	Status  string `json:"status"`
+}
+
+// errorResponse is returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler receives captured job postings and stores them as applications
+type Handler struct {
+	store         *storage.Storage
+	defaultStatus models.Status
+	token         string
+	mu            sync.Mutex // Serializes Load/Save cycles on the JSONL file
+}
+
+// NewHandler creates a capture handler. Captured jobs get defaultStatus unless
+// the request asks for another one. If token is non-empty, requests must send
+// it in the X-Bragger-Token header.
+func NewHandler(store *storage.Storage, defaultStatus models.Status, token string) *Handler {
+	if defaultStatus == "" {
+		defaultStatus = models.StatusApplied
+	}
+	return &Handler{store: store, defaultStatus: defaultStatus, token: token}
+}
+
+// ServeHTTP implements http.Handler. It serves POST /capture and GET /health.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w, r)
+
+	switch {
+	case r.Method == http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/health" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	case r.URL.Path == "/capture" && r.Method == http.MethodPost:
+		h.handleCapture(w, r)
+	case r.URL.Path == "/capture" || r.URL.Path == "/health":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// setCORSHeaders allows browser extensions (but not arbitrary web pages) to
+// call the server.
+func (h *Handler) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !isExtensionOrigin(origin) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Bragger-Token")
+	w.Header().Set("Vary", "Origin")
+}
+
+func isExtensionOrigin(origin string) bool {
+	for _, scheme := range []string{"chrome-extension://", "moz-extension://", "safari-web-extension://"} {
+		if strings.HasPrefix(origin, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) handleCapture(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" && r.Header.Get("X-Bragger-Token") != h.token {
+		writeError(w, http.StatusUnauthorized, "invalid or missing X-Bragger-Token")
+		return
+	}
+
+	// Requiring JSON forces a CORS preflight, so plain web pages can't post
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+
+	app, err := h.buildApplication(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	err = h.store.Add(app)
+	h.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("saving application: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{
+		ID:      app.ID,
+		Company: app.Company,
+		Role:    app.Role,
+		Status:  string(app.Status),
+	})
+}
+
+// buildApplication turns a capture request into a new application
+func (h *Handler) buildApplication(req *Request) (*models.Application, error) {
+	if req.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	status := h.defaultStatus
+	if req.Status != "" {
+		status = models.Status(req.Status)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: %s", req.Status)
+		}
+	}
+
+	// Prefer the page's structured JobPosting data over title heuristics
+	var posting *jd.JobPosting
+	if req.HTML != "" {
+		posting = jd.ParseJobPosting(req.HTML)
+	}
+
+	company, role := ExtractCompanyRole(req.URL, req.Title)
+	if posting != nil && posting.Company != "" {
+		company = posting.Company
+	}
+	if posting != nil && posting.Title != "" {
+		role = posting.Title
+	}
+	if req.Company != "" {
+		company = req.Company
+	}
+	if req.Role != "" {
+		role = req.Role
+	}
+	if company == "" {
+		company = "Unknown"
+	}
+	if role == "" {
+		role = "Unknown"
+	}
+
+	content := jd.CleanText(req.Text)
+	if content == "" && posting != nil && posting.Description != "" {
+		content = posting.Text()
+	}
+	if content == "" && req.HTML != "" {
+		content = jd.HTMLToText(req.HTML)
+	}
+
+	app := models.NewApplication(company, role)
+	app.Status = status
+	app.JDURL = req.URL
+	app.JDContent = content
+	return app, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
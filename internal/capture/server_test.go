@@ -0,0 +1,263 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+func setupTestServer(t *testing.T, status models.Status, token string) (*httptest.Server, *storage.Storage, func()) {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "capture-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+
+	store := storage.New(filepath.Join(tmpDir, "applications.jsonl"))
+	server := httptest.NewServer(NewHandler(store, status, token))
+
+	cleanup := func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	}
+
+	return server, store, cleanup
+}
+
+func postCapture(t *testing.T, server *httptest.Server, req Request, header map[string]string) *http.Response {
+	t.Helper()
+
+	body, _ := json.Marshal(req)
+	httpReq, err := http.NewRequest(http.MethodPost, server.URL+"/capture", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestCaptureCreatesApplication(t *testing.T) {
+	server, store, cleanup := setupTestServer(t, models.StatusApplied, "")
+	defer cleanup()
+
+	resp := postCapture(t, server, Request{
+		URL:   "https://boards.greenhouse.io/acme/jobs/123",
+		Title: "Job Application for Senior Engineer at Acme",
+		HTML:  "<h2>Requirements</h2><ul><li>Go</li><li>Kubernetes</li></ul><script>track()</script>",
+	}, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", resp.StatusCode)
+	}
+
+	var out Response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(out.ID, "app-") {
+		t.Errorf("expected app ID, got %q", out.ID)
+	}
+
+	app, err := store.Get(out.ID)
+	if err != nil {
+		t.Fatalf("captured application not stored: %v", err)
+	}
+	if app.Company != "Acme" || app.Role != "Senior Engineer" {
+		t.Errorf("unexpected company/role: %q / %q", app.Company, app.Role)
+	}
+	if app.JDURL != "https://boards.greenhouse.io/acme/jobs/123" {
+		t.Errorf("unexpected JD URL: %q", app.JDURL)
+	}
+	if app.JDContent != "Requirements\n\n- Go\n- Kubernetes" {
+		t.Errorf("unexpected JD content: %q", app.JDContent)
+	}
+	if app.Status != models.StatusApplied {
+		t.Errorf("expected status applied, got %q", app.Status)
+	}
+}
+
+func TestCaptureWishlistAndOverrides(t *testing.T) {
+	server, store, cleanup := setupTestServer(t, models.StatusWishlist, "")
+	defer cleanup()
+
+	t.Run("default status and text preferred over html", func(t *testing.T) {
+		resp := postCapture(t, server, Request{
+			URL:   "https://example.com/job/1",
+			Title: "Platform Engineer at Globex",
+			Text:  "  Plain   text JD  ",
+			HTML:  "<p>ignored</p>",
+		}, nil)
+		defer resp.Body.Close()
+
+		var out Response
+		json.NewDecoder(resp.Body).Decode(&out)
+		app, err := store.Get(out.ID)
+		if err != nil {
+			t.Fatalf("captured application not stored: %v", err)
+		}
+		if app.Status != models.StatusWishlist {
+			t.Errorf("expected wishlist status, got %q", app.Status)
+		}
+		if app.JDContent != "Plain text JD" {
+			t.Errorf("unexpected JD content: %q", app.JDContent)
+		}
+	})
+
+	t.Run("explicit fields override extraction", func(t *testing.T) {
+		resp := postCapture(t, server, Request{
+			URL:     "https://example.com/job/2",
+			Title:   "Something at Somewhere",
+			Company: "Initech",
+			Role:    "SRE",
+			Status:  "applied",
+		}, nil)
+		defer resp.Body.Close()
+
+		var out Response
+		json.NewDecoder(resp.Body).Decode(&out)
+		if out.Company != "Initech" || out.Role != "SRE" || out.Status != "applied" {
+			t.Errorf("unexpected response: %+v", out)
+		}
+	})
+}
+
+func TestCaptureJobPostingJSONLD(t *testing.T) {
+	server, store, cleanup := setupTestServer(t, models.StatusWishlist, "")
+	defer cleanup()
+
+	page := `<html><head><title>Careers</title>
+<script type="application/ld+json">
+{"@context":"https://schema.org","@type":"JobPosting","title":"Site Reliability Engineer",
+ "hiringOrganization":{"@type":"Organization","name":"Hooli"},
+ "description":"<p>Keep things running.</p>"}
+</script></head><body><p>Menu</p></body></html>`
+
+	resp := postCapture(t, server, Request{URL: "https://example.com/jobs/42", Title: "Careers", HTML: page}, nil)
+	defer resp.Body.Close()
+
+	var out Response
+	json.NewDecoder(resp.Body).Decode(&out)
+	if out.Company != "Hooli" || out.Role != "Site Reliability Engineer" {
+		t.Errorf("expected JSON-LD company/role, got %+v", out)
+	}
+
+	app, err := store.Get(out.ID)
+	if err != nil {
+		t.Fatalf("captured application not stored: %v", err)
+	}
+	if !strings.Contains(app.JDContent, "Keep things running.") || strings.Contains(app.JDContent, "Menu") {
+		t.Errorf("expected JD from JSON-LD description, got %q", app.JDContent)
+	}
+}
+
+func TestCaptureErrors(t *testing.T) {
+	server, store, cleanup := setupTestServer(t, "", "secret")
+	defer cleanup()
+
+	t.Run("missing token", func(t *testing.T) {
+		resp := postCapture(t, server, Request{URL: "https://example.com"}, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("missing url", func(t *testing.T) {
+		resp := postCapture(t, server, Request{Title: "No URL"}, map[string]string{"X-Bragger-Token": "secret"})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		resp := postCapture(t, server, Request{URL: "https://example.com", Status: "maybe"},
+			map[string]string{"X-Bragger-Token": "secret"})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("non-JSON content type", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/capture", strings.NewReader(`{"url":"x"}`))
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("X-Bragger-Token", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("expected 415, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/capture")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", resp.StatusCode)
+		}
+	})
+
+	apps, _ := store.Load()
+	if len(apps) != 0 {
+		t.Errorf("expected no applications after failed requests, got %d", len(apps))
+	}
+}
+
+func TestCaptureCORS(t *testing.T) {
+	server, _, cleanup := setupTestServer(t, "", "")
+	defer cleanup()
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"chrome-extension://abcdef", true},
+		{"moz-extension://1234", true},
+		{"https://evil.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodOptions, server.URL+"/capture", nil)
+			req.Header.Set("Origin", tt.origin)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			got := resp.Header.Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("expected origin to be allowed, got %q", got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("expected origin to be rejected, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Paragraph styles defined in styles.xml. They map to Word's built-in style
+// IDs, which is what ATS parsers look for to find names, sections and lists.
+const (
+	StyleNormal     = "Normal"
+	StyleTitle      = "Title"
+	StyleSubtitle   = "Subtitle"
+	StyleHeading1   = "Heading1"
+	StyleHeading2   = "Heading2"
+	StyleListBullet = "ListBullet"
+)
+
+// bulletNumID is the numbering definition used by StyleListBullet
+const bulletNumID = "1"
+
+// Run is a piece of paragraph text with uniform formatting. A Break run is
+// a line break within the paragraph.
+type Run struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Break  bool
+}
+
+// Document is a Word document built paragraph by paragraph
+type Document struct {
+	Title   string
+	Author  string
+	Created time.Time // Recorded in the core properties when set
+	body    []paragraph
+}
+
+// Add appends a paragraph in the given style. Empty paragraphs are kept, so
+// callers can use them for spacing.
+func (d *Document) Add(style string, runs ...Run) {
+	p := paragraph{Props: &paragraphProps{Style: &value{style}}}
+	if style == StyleListBullet {
+		// Repeated from the style for parsers that don't resolve styles
+		p.Props.Numbering = &numberingProps{Level: value{"0"}, ID: value{bulletNumID}}
+	}
+	for _, r := range runs {
+		p.Runs = append(p.Runs, r.xml())
+	}
+	d.body = append(d.body, p)
+}
+
+// Write writes the document as a .docx (Office Open XML) package. The output
+// depends only on the document's contents.
+func (d *Document) Write(w io.Writer) error {
+	body, err := xml.Marshal(d.body)
+	if err != nil {
+		return fmt.Errorf("encoding document: %w", err)
+	}
+
+	var document bytes.Buffer
+	document.WriteString(xml.Header)
+	document.WriteString(`<w:document xmlns:w="` + wordNamespace + `"><w:body>`)
+	document.Write(body)
+	// A4 with 2cm margins, in twentieths of a point
+	document.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>`)
+	document.WriteString(`</w:body></w:document>`)
+
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypes)},
+		{"_rels/.rels", []byte(packageRels)},
+		{"docProps/core.xml", d.coreProperties()},
+		{"docProps/app.xml", []byte(appProperties)},
+		{"word/_rels/document.xml.rels", []byte(documentRels)},
+		{"word/document.xml", document.Bytes()},
+		{"word/styles.xml", []byte(styles)},
+		{"word/numbering.xml", []byte(numbering)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (d *Document) coreProperties() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`)
+	if d.Title != "" {
+		b.WriteString("<dc:title>" + escape(d.Title) + "</dc:title>")
+	}
+	if d.Author != "" {
+		b.WriteString("<dc:creator>" + escape(d.Author) + "</dc:creator>")
+	}
+	if !d.Created.IsZero() {
+		b.WriteString(`<dcterms:created xsi:type="dcterms:W3CDTF">` + d.Created.UTC().Format(time.RFC3339) + "</dcterms:created>")
+	}
+	b.WriteString("</cp:coreProperties>")
+	return b.Bytes()
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// The WordprocessingML elements are written with literal "w:" prefixes,
+// declared once on the root element
+
+type paragraph struct {
+	XMLName xml.Name        `xml:"w:p"`
+	Props   *paragraphProps `xml:"w:pPr,omitempty"`
+	Runs    []run           `xml:"w:r"`
+}
+
+type paragraphProps struct {
+	Style     *value          `xml:"w:pStyle,omitempty"`
+	Numbering *numberingProps `xml:"w:numPr,omitempty"`
+}
+
+type numberingProps struct {
+	Level value `xml:"w:ilvl"`
+	ID    value `xml:"w:numId"`
+}
+
+type value struct {
+	Val string `xml:"w:val,attr"`
+}
+
+type run struct {
+	Props *runProps `xml:"w:rPr,omitempty"`
+	Break *struct{} `xml:"w:br,omitempty"`
+	Text  *text     `xml:"w:t,omitempty"`
+}
+
+type runProps struct {
+	Bold   *struct{} `xml:"w:b,omitempty"`
+	Italic *struct{} `xml:"w:i,omitempty"`
+}
+
+type text struct {
+	Space string `xml:"xml:space,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+func (r Run) xml() run {
+	var out run
+	if r.Bold || r.Italic {
+		out.Props = &runProps{}
+		if r.Bold {
+			out.Props.Bold = &struct{}{}
+		}
+		if r.Italic {
+			out.Props.Italic = &struct{}{}
+		}
+	}
+	if r.Break {
+		out.Break = &struct{}{}
+	}
+	if r.Text != "" {
+		out.Text = &text{Value: r.Text}
+		// Word drops leading and trailing spaces unless told to keep them
+		if strings.TrimSpace(r.Text) != r.Text {
+			out.Text.Space = "preserve"
+		}
+	}
+	return out
+}
+
+const wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`<Override PartName="/docProps/app.xml" ContentType="application/vnd.openxmlformats-officedocument.extended-properties+xml"/>` +
+	`</Types>`
+
+const packageRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties" Target="docProps/app.xml"/>` +
+	`</Relationships>`
+
+const documentRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>` +
+	`</Relationships>`
+
+const appProperties = xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">` +
+	`<Application>Bragger</Application>` +
+	`</Properties>`
+
+const styles = xml.Header + `<w:styles xmlns:w="` + wordNamespace + `">` +
+	`<w:docDefaults>` +
+	`<w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/><w:sz w:val="21"/><w:szCs w:val="21"/><w:lang w:val="en-US"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="80" w:line="264" w:lineRule="auto"/></w:pPr></w:pPrDefault>` +
+	`</w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:spacing w:after="40"/></w:pPr><w:rPr><w:b/><w:color w:val="1A365D"/><w:sz w:val="40"/><w:szCs w:val="40"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:rPr><w:color w:val="4A5568"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="2B6CB0"/></w:pBdr><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="0"/></w:pPr>` +
+	`<w:rPr><w:b/><w:caps/><w:color w:val="1A365D"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="160" w:after="20"/><w:outlineLvl w:val="1"/></w:pPr>` +
+	`<w:rPr><w:b/><w:sz w:val="22"/><w:szCs w:val="22"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:numPr><w:numId w:val="` + bulletNumID + `"/></w:numPr><w:spacing w:after="40"/></w:pPr></w:style>` +
+	`</w:styles>`
+
+const numbering = xml.Header + `<w:numbering xmlns:w="` + wordNamespace + `">` +
+	`<w:abstractNum w:abstractNumId="0"><w:multiLevelType w:val="singleLevel"/>` +
+	`<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="•"/><w:lvlJc w:val="left"/>` +
+	`<w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr></w:lvl>` +
+	`</w:abstractNum>` +
+	`<w:num w:numId="` + bulletNumID + `"><w:abstractNumId w:val="0"/></w:num>` +
+	`</w:numbering>`
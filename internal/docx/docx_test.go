@@ -0,0 +1,102 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readParts unzips a written document
+func readParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+func TestWrite(t *testing.T) {
+	d := &Document{Title: "Jane Doe - Resume", Author: "Jane & Co"}
+	d.Add(StyleTitle, Run{Text: "Jane Doe"})
+	d.Add(StyleHeading1, Run{Text: "Skills"})
+	d.Add(StyleNormal, Run{Text: "Languages: ", Bold: true}, Run{Text: "Go, <C>"})
+	d.Add(StyleListBullet, Run{Text: "Cut latency"}, Run{Break: true}, Run{Text: "by 40%", Italic: true})
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	parts := readParts(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml", "word/styles.xml", "word/numbering.xml", "docProps/core.xml"} {
+		content, ok := parts[name]
+		if !ok {
+			t.Errorf("missing part %s", name)
+			continue
+		}
+		// Every part must be well-formed XML
+		dec := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v", name, err)
+				break
+			}
+		}
+	}
+
+	document := parts["word/document.xml"]
+	for _, want := range []string{
+		`<w:p><w:pPr><w:pStyle w:val="Title"></w:pStyle></w:pPr><w:r><w:t>Jane Doe</w:t></w:r></w:p>`,
+		`<w:pStyle w:val="Heading1">`,
+		`<w:r><w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">Languages: </w:t></w:r>`,
+		`<w:t>Go, &lt;C&gt;</w:t>`,
+		`<w:numPr><w:ilvl w:val="0"></w:ilvl><w:numId w:val="1"></w:numId></w:numPr>`,
+		`<w:r><w:br></w:br></w:r>`,
+		`<w:rPr><w:i></w:i></w:rPr><w:t>by 40%</w:t>`,
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+	for _, style := range []string{"Title", "Subtitle", "Heading1", "Heading2", "ListBullet"} {
+		if !strings.Contains(parts["word/styles.xml"], `w:styleId="`+style+`"`) {
+			t.Errorf("styles.xml missing %s", style)
+		}
+	}
+	if core := parts["docProps/core.xml"]; !strings.Contains(core, "<dc:title>Jane Doe - Resume</dc:title>") || !strings.Contains(core, "<dc:creator>Jane &amp; Co</dc:creator>") {
+		t.Errorf("unexpected core properties: %s", core)
+	}
+	if strings.Contains(parts["docProps/core.xml"], "dcterms:created") {
+		t.Error("no creation date should be written unless set")
+	}
+
+	var again bytes.Buffer
+	d.Write(&again)
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("output should be deterministic")
+	}
+
+	d.Created = time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	buf.Reset()
+	d.Write(&buf)
+	if !strings.Contains(readParts(t, buf.Bytes())["docProps/core.xml"], ">2025-03-01T12:30:00Z</dcterms:created>") {
+		t.Error("creation date not written")
+	}
+}
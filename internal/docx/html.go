@@ -0,0 +1,200 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	titlePattern      = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title\s*>`)
+	skippedPattern    = regexp.MustCompile(`(?is)<(style|script|title)[^>]*>.*?</(style|script|title)\s*>`)
+	commentPattern    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Paragraph styles for elements, and for the class names the resume and
+// cover letter templates use
+var (
+	styleByTag   = map[string]string{"h1": StyleTitle, "h2": StyleHeading1, "h3": StyleHeading2, "h4": StyleHeading2, "li": StyleListBullet}
+	styleByClass = map[string]string{"name": StyleTitle, "section-title": StyleHeading1}
+)
+
+// blockTags end the current paragraph when they open or close
+var blockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "ul": true, "ol": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "section": true, "header": true,
+	"footer": true, "article": true, "main": true, "table": true, "tr": true,
+	"blockquote": true, "hr": true, "body": true,
+}
+
+// FromHTML converts an HTML document, such as a handwritten cover letter,
+// into paragraphs. Block elements become paragraphs and <br> a line break;
+// h1 (or class "name") is the title, h2 and h3 are headings and list items
+// are bullets. Bold and italic text keep their formatting; other styling is
+// dropped in favour of the document styles.
+func FromHTML(html []byte) (*Document, error) {
+	doc := string(html)
+	d := &Document{}
+	if m := titlePattern.FindStringSubmatch(doc); m != nil {
+		d.Title = strings.TrimSpace(whitespacePattern.ReplaceAllString(m[1], " "))
+	}
+	doc = skippedPattern.ReplaceAllString(doc, "")
+	doc = commentPattern.ReplaceAllString(doc, "")
+
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	c := &converter{doc: d}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing HTML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			c.open(t)
+		case xml.EndElement:
+			c.close(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			c.text(string(t))
+		}
+	}
+	c.flush()
+	return d, nil
+}
+
+// element is an open element and the formatting it applies to its text
+type element struct {
+	tag    string
+	style  string
+	bold   bool
+	italic bool
+}
+
+type converter struct {
+	doc   *Document
+	stack []element
+	runs  []Run
+	style string
+	space bool // Whitespace is pending before the next text
+}
+
+func (c *converter) open(t xml.StartElement) {
+	tag := strings.ToLower(t.Name.Local)
+	el := element{tag: tag, style: styleByTag[tag]}
+	if n := len(c.stack); n > 0 {
+		parent := c.stack[n-1]
+		el.bold, el.italic = parent.bold, parent.italic
+		if el.style == "" {
+			el.style = parent.style
+		}
+	}
+	for _, a := range t.Attr {
+		if strings.ToLower(a.Name.Local) != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(a.Value) {
+			if style, ok := styleByClass[class]; ok {
+				el.style = style
+			}
+			if class == "signature" {
+				el.bold = true
+			}
+		}
+	}
+	switch tag {
+	case "b", "strong", "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		el.bold = true
+	case "i", "em":
+		el.italic = true
+	}
+
+	if blockTags[tag] {
+		c.flush()
+	}
+	if tag == "br" {
+		if len(c.runs) > 0 {
+			c.runs = append(c.runs, Run{Break: true})
+		}
+		c.space = false
+		return
+	}
+	if tag == "td" || tag == "th" {
+		c.space = true
+	}
+	c.stack = append(c.stack, el)
+}
+
+func (c *converter) close(tag string) {
+	if tag == "br" {
+		return
+	}
+	// Unwind to the matching element, closing anything left open inside it
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].tag == tag {
+			c.stack = c.stack[:i]
+			break
+		}
+	}
+	if blockTags[tag] {
+		c.flush()
+	}
+}
+
+func (c *converter) text(s string) {
+	if strings.TrimSpace(s) == "" {
+		if s != "" {
+			c.space = true
+		}
+		return
+	}
+	var el element
+	if n := len(c.stack); n > 0 {
+		el = c.stack[n-1]
+	}
+
+	leading := s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r'
+	trailing := strings.TrimRight(s, " \t\r\n") != s
+	s = whitespacePattern.ReplaceAllString(strings.TrimSpace(s), " ")
+	if len(c.runs) == 0 {
+		c.style = el.style
+	} else if last := &c.runs[len(c.runs)-1]; !last.Break {
+		// The space between words stays with the text before it, so it
+		// isn't underlined or bolded with the next word
+		if c.space || leading {
+			last.Text += " "
+		}
+		if last.Bold == el.bold && last.Italic == el.italic {
+			last.Text += s
+			c.space = trailing
+			return
+		}
+	}
+	c.space = trailing
+	c.runs = append(c.runs, Run{Text: s, Bold: el.bold, Italic: el.italic})
+}
+
+// flush ends the current paragraph
+func (c *converter) flush() {
+	// Trailing breaks, as in "Hiring Team<br>", add nothing
+	for len(c.runs) > 0 && c.runs[len(c.runs)-1].Break {
+		c.runs = c.runs[:len(c.runs)-1]
+	}
+	if len(c.runs) > 0 {
+		style := c.style
+		if style == "" {
+			style = StyleNormal
+		}
+		c.doc.Add(style, c.runs...)
+	}
+	c.runs, c.style, c.space = nil, "", false
+}
@@ -0,0 +1,86 @@
+package docx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromHTML(t *testing.T) {
+	html := `<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><title>Jane Doe - Cover Letter</title>
+<style>.name { font-size: 22pt; } /* <p>not text</p> */</style>
+</head>
+<body>
+    <div class="header">
+        <div class="name">Jane Doe</div>
+        <div class="contact-info">
+            jane@example.com | +49 123<br>
+            linkedin.com/in/jane<br>
+        </div>
+    </div>
+    <!-- <p>commented out</p> -->
+    <div class="salutation">Dear Hiring Team,</div>
+    <div class="body">
+        <p>I am applying for the <strong>Backend Engineer</strong> role at
+           Acme&nbsp;Corp, where <span class="highlight">latency</span> matters.</p>
+        <ul><li>Cut latency by 40%<li>Led a <em>team</em> of 4</ul>
+    </div>
+    <div class="closing">
+        Best regards,
+        <div class="signature">Jane Doe</div>
+    </div>
+</body></html>`
+
+	d, err := FromHTML([]byte(html))
+	if err != nil {
+		t.Fatalf("FromHTML failed: %v", err)
+	}
+	if d.Title != "Jane Doe - Cover Letter" {
+		t.Errorf("Title = %q", d.Title)
+	}
+
+	type para struct {
+		style string
+		runs  []Run
+	}
+	var got []para
+	for _, p := range d.body {
+		var runs []Run
+		for _, r := range p.Runs {
+			run := Run{Bold: r.Props != nil && r.Props.Bold != nil, Italic: r.Props != nil && r.Props.Italic != nil, Break: r.Break != nil}
+			if r.Text != nil {
+				run.Text = r.Text.Value
+			}
+			runs = append(runs, run)
+		}
+		got = append(got, para{p.Props.Style.Val, runs})
+	}
+
+	want := []para{
+		{StyleTitle, []Run{{Text: "Jane Doe"}}},
+		{StyleNormal, []Run{{Text: "jane@example.com | +49 123"}, {Break: true}, {Text: "linkedin.com/in/jane"}}},
+		{StyleNormal, []Run{{Text: "Dear Hiring Team,"}}},
+		{StyleNormal, []Run{{Text: "I am applying for the "}, {Text: "Backend Engineer ", Bold: true}, {Text: "role at Acme Corp, where latency matters."}}},
+		{StyleListBullet, []Run{{Text: "Cut latency by 40%"}}},
+		{StyleListBullet, []Run{{Text: "Led a "}, {Text: "team ", Italic: true}, {Text: "of 4"}}},
+		{StyleNormal, []Run{{Text: "Best regards,"}}},
+		{StyleNormal, []Run{{Text: "Jane Doe", Bold: true}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paragraphs:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestFromHTMLHeadings(t *testing.T) {
+	d, err := FromHTML([]byte(`<h1>Jane</h1><h2>Experience</h2><h3>Engineer, Acme</h3><p>Text</p>`))
+	if err != nil {
+		t.Fatalf("FromHTML failed: %v", err)
+	}
+	var styles []string
+	for _, p := range d.body {
+		styles = append(styles, p.Props.Style.Val)
+	}
+	if want := []string{StyleTitle, StyleHeading1, StyleHeading2, StyleNormal}; !reflect.DeepEqual(styles, want) {
+		t.Errorf("styles = %q, want %q", styles, want)
+	}
+}
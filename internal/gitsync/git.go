@@ -0,0 +1,334 @@
+package gitsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/redact"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+// Remote is the name of the remote bragger pushes to and pulls from
+const Remote = "origin"
+
+// DefaultBranch is the branch a new sync repository starts on
+const DefaultBranch = "main"
+
+// DataFiles are merged record by record by the bragger merge driver
+var DataFiles = []string{"applications.jsonl", "candidate-kb.jsonl"}
+
+// JournalFile is append-only, so both sides' lines are kept when merging
+const JournalFile = ".bragger/journal/journal.jsonl"
+
+// SyncedFiles are the files Commit commits: the records and their journal,
+// what another machine needs to read them, and the repository's own setup.
+// Generated resumes and cover letters aren't encrypted, so they stay out.
+var SyncedFiles = append(append([]string{}, DataFiles...),
+	JournalFile, vault.DefaultParamsPath, redact.DefaultMapPath, ".gitattributes", ".gitignore")
+
+// ignored are the workspace files .gitignore keeps out of the repository
+var ignored = []string{"outputs/", ".bragger/backup/", vault.DefaultAgentSocket}
+
+var (
+	ErrNotInitialized = errors.New("workspace sync is not set up; run 'bragger sync init' first")
+	ErrNoRemote       = errors.New("no remote set; run 'bragger sync init --remote <url>' first")
+	// ErrConflict is returned by Pull when files need to be merged by hand
+	ErrConflict = errors.New("merge conflict")
+)
+
+// Repo is a git repository at the root of a workspace
+type Repo struct {
+	dir string
+}
+
+// Open returns the workspace repository in dir; "" is the current
+// directory
+func Open(dir string) *Repo {
+	if dir == "" {
+		dir = "."
+	}
+	return &Repo{dir: dir}
+}
+
+// IsInitialized reports whether the workspace has a repository of its own,
+// not just a parent directory's
+func (r *Repo) IsInitialized() bool {
+	_, err := os.Stat(filepath.Join(r.dir, ".git"))
+	return err == nil
+}
+
+// git runs a git command in the workspace and returns its output. A failing
+// command's error carries git's message.
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// Init creates the workspace repository if needed and sets it up for
+// bragger: the merge driver for the data files, given as the command git
+// runs with the base, ours and theirs paths appended, and auto-commit
+func (r *Repo) Init(driver string) error {
+	if !r.IsInitialized() {
+		if _, err := r.git("init", "-q", "-b", DefaultBranch); err != nil {
+			return err
+		}
+	}
+	var attributes []string
+	for _, f := range DataFiles {
+		attributes = append(attributes, f+" merge=bragger")
+	}
+	attributes = append(attributes, JournalFile+" merge=union")
+	if err := r.addLines(".gitattributes", attributes); err != nil {
+		return err
+	}
+	if err := r.addLines(".gitignore", ignored); err != nil {
+		return err
+	}
+	config := [][2]string{
+		{"merge.bragger.name", "bragger JSONL merge by record ID"},
+		{"merge.bragger.driver", driver + " %O %A %B"},
+		{"bragger.autocommit", "true"},
+	}
+	for _, kv := range config {
+		if _, err := r.git("config", kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addLines adds lines to a file of the workspace, such as .gitattributes,
+// keeping any lines already there
+func (r *Repo) addLines(name string, lines []string) error {
+	path := filepath.Join(r.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, l := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(l)] = true
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	added := false
+	for _, l := range lines {
+		if !existing[l] {
+			content += l + "\n"
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// AutoCommit reports whether changes are committed as they're made, which
+// 'git config bragger.autocommit false' turns off
+func (r *Repo) AutoCommit() bool {
+	if !r.IsInitialized() {
+		return false
+	}
+	out, err := r.git("config", "--bool", "bragger.autocommit")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// Commit commits the changes to SyncedFiles, with a message naming the
+// changed files if none is given. It returns false if there was nothing to
+// commit.
+func (r *Repo) Commit(message string) (bool, error) {
+	if !r.IsInitialized() {
+		return false, ErrNotInitialized
+	}
+	paths, err := r.syncedPaths()
+	if err != nil {
+		return false, err
+	}
+	if len(paths) > 0 {
+		if _, err := r.git(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+			return false, err
+		}
+	}
+	if _, err := r.git("diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if message == "" {
+		out, err := r.git("diff", "--cached", "--name-only")
+		if err != nil {
+			return false, err
+		}
+		message = filesMessage(strings.Fields(out))
+	}
+	if _, err := r.git("commit", "-q", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// syncedPaths returns the SyncedFiles git can stage: those in the workspace,
+// and those removed from it since they were committed
+func (r *Repo) syncedPaths() ([]string, error) {
+	out, err := r.git(append([]string{"ls-files", "--"}, SyncedFiles...)...)
+	if err != nil {
+		return nil, err
+	}
+	tracked := make(map[string]bool)
+	for _, f := range strings.Fields(out) {
+		tracked[f] = true
+	}
+	var paths []string
+	for _, f := range SyncedFiles {
+		if _, err := os.Stat(filepath.Join(r.dir, f)); err == nil || tracked[f] {
+			paths = append(paths, f)
+		}
+	}
+	return paths, nil
+}
+
+// filesMessage describes a commit by the files it changes
+func filesMessage(files []string) string {
+	if len(files) > maxFields {
+		return fmt.Sprintf("update %s and %d more", strings.Join(files[:maxFields], ", "), len(files)-maxFields)
+	}
+	return "update " + strings.Join(files, ", ")
+}
+
+// Commit is one entry of the workspace history
+type Commit struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+}
+
+// Log returns the latest commits, newest first; limit 0 returns all
+func (r *Repo) Log(limit int) ([]Commit, error) {
+	if !r.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if !r.hasCommits() {
+		return nil, nil
+	}
+	args := []string{"log", "--format=%h%x00%ct%x00%s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(l, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[1], 10, 64)
+		commits = append(commits, Commit{Hash: parts[0], Time: time.Unix(secs, 0), Subject: parts[2]})
+	}
+	return commits, nil
+}
+
+func (r *Repo) hasCommits() bool {
+	_, err := r.git("rev-parse", "--verify", "-q", "HEAD")
+	return err == nil
+}
+
+func (r *Repo) branch() (string, error) {
+	out, err := r.git("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// SetRemote points the workspace at a remote repository
+func (r *Repo) SetRemote(url string) error {
+	if _, err := r.git("remote", "get-url", Remote); err == nil {
+		_, err = r.git("remote", "set-url", Remote, url)
+		return err
+	}
+	_, err := r.git("remote", "add", Remote, url)
+	return err
+}
+
+// RemoteURL returns the URL of the remote, or "" if none is set
+func (r *Repo) RemoteURL() string {
+	out, err := r.git("remote", "get-url", Remote)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// Push sends the workspace's commits to the remote
+func (r *Repo) Push() error {
+	if !r.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if r.RemoteURL() == "" {
+		return ErrNoRemote
+	}
+	branch, err := r.branch()
+	if err != nil {
+		return err
+	}
+	_, err = r.git("push", "-q", "-u", Remote, branch)
+	return err
+}
+
+// Pull fetches the remote's commits and merges them in, the data files
+// record by record. It returns false if the remote has nothing yet. On
+// ErrConflict the merge is aborted, leaving the workspace as it was.
+func (r *Repo) Pull() (bool, error) {
+	if !r.IsInitialized() {
+		return false, ErrNotInitialized
+	}
+	if r.RemoteURL() == "" {
+		return false, ErrNoRemote
+	}
+	branch, err := r.branch()
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.git("fetch", "-q", Remote); err != nil {
+		return false, err
+	}
+	ref := Remote + "/" + branch
+	if _, err := r.git("rev-parse", "--verify", "-q", ref); err != nil {
+		return false, nil
+	}
+
+	if _, err := r.git("merge", "-q", "--no-edit", "--allow-unrelated-histories", ref); err != nil {
+		out, _ := r.git("diff", "--name-only", "--diff-filter=U")
+		if files := strings.Fields(out); len(files) > 0 {
+			r.git("merge", "--abort")
+			return false, fmt.Errorf("%w in %s", ErrConflict, strings.Join(files, ", "))
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,186 @@
+package gitsync
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/redact"
+)
+
+// setupRemote returns a bare repository to sync with, and sets a git
+// identity for the commits
+func setupRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, name := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(name+"_NAME", "Test")
+		t.Setenv(name+"_EMAIL", "test@example.com")
+	}
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	return remote
+}
+
+// setupWorkspace returns a workspace repository syncing with remote
+func setupWorkspace(t *testing.T, remote string) (*Repo, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo := Open(dir)
+	if err := repo.Init("false"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetRemote(remote); err != nil {
+		t.Fatal(err)
+	}
+	return repo, dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInit(t *testing.T) {
+	setupRemote(t)
+	dir := t.TempDir()
+	writeFile(t, dir, ".gitattributes", "*.pdf binary")
+	repo := Open(dir)
+	if repo.IsInitialized() || repo.AutoCommit() {
+		t.Fatal("expected no repository yet")
+	}
+	if err := repo.Init("bragger sync merge-driver"); err != nil {
+		t.Fatal(err)
+	}
+	// Init again keeps the attributes as they are
+	if err := repo.Init("bragger sync merge-driver"); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.IsInitialized() || !repo.AutoCommit() {
+		t.Error("expected an auto-committing repository")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	want := "*.pdf binary\napplications.jsonl merge=bragger\ncandidate-kb.jsonl merge=bragger\n" + JournalFile + " merge=union\n"
+	if string(data) != want {
+		t.Errorf(".gitattributes = %q, want %q", data, want)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if string(data) != "outputs/\n.bragger/backup/\n.bragger/agent.sock\n" {
+		t.Errorf(".gitignore = %q", data)
+	}
+	if driver, _ := repo.git("config", "merge.bragger.driver"); strings.TrimSpace(driver) != "bragger sync merge-driver %O %A %B" {
+		t.Errorf("merge driver = %q", driver)
+	}
+}
+
+func TestCommitAndLog(t *testing.T) {
+	remote := setupRemote(t)
+	repo, dir := setupWorkspace(t, remote)
+
+	if commits, err := repo.Log(0); err != nil || len(commits) != 0 {
+		t.Fatalf("Log() on an empty repository = %v, %v", commits, err)
+	}
+	writeFile(t, dir, "applications.jsonl", `{"id":"app-a"}`+"\n")
+	if committed, err := repo.Commit(""); err != nil || !committed {
+		t.Fatalf("Commit() = %v, %v", committed, err)
+	}
+	if committed, err := repo.Commit("again"); err != nil || committed {
+		t.Errorf("expected nothing to commit, got %v, %v", committed, err)
+	}
+	// Only the synced files are committed
+	os.MkdirAll(filepath.Join(dir, "outputs", "acme"), 0755)
+	writeFile(t, dir, filepath.Join("outputs", "acme", "resume.html"), "jane@example.com\n")
+	writeFile(t, dir, "notes.md", "hello\n")
+	if committed, err := repo.Commit("add notes"); err != nil || committed {
+		t.Errorf("expected nothing to commit, got %v, %v", committed, err)
+	}
+	writeFile(t, dir, "candidate-kb.jsonl", `{"id":"kb-a"}`+"\n")
+	repo.Commit("add kb")
+	os.Remove(filepath.Join(dir, "candidate-kb.jsonl"))
+	if committed, err := repo.Commit("remove kb"); err != nil || !committed {
+		t.Errorf("expected the removal committed, got %v, %v", committed, err)
+	}
+	if files, _ := repo.git("ls-files"); files != ".gitattributes\n.gitignore\napplications.jsonl\n" {
+		t.Errorf("tracked files = %q", files)
+	}
+
+	commits, err := repo.Log(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject)
+	}
+	want := []string{"remove kb", "add kb", "update .gitattributes, .gitignore, applications.jsonl"}
+	if strings.Join(subjects, "|") != strings.Join(want, "|") {
+		t.Errorf("subjects = %v, want %v", subjects, want)
+	}
+	if commits, _ := repo.Log(1); len(commits) != 1 {
+		t.Errorf("expected 1 commit with limit 1, got %d", len(commits))
+	}
+}
+
+func TestPushAndPull(t *testing.T) {
+	remote := setupRemote(t)
+	first, firstDir := setupWorkspace(t, remote)
+	second, secondDir := setupWorkspace(t, remote)
+	// Each commits its own history first, as 'bragger sync init' does
+	first.Commit("")
+	second.Commit("")
+
+	if pulled, err := second.Pull(); err != nil || pulled {
+		t.Fatalf("expected nothing to pull from an empty remote, got %v, %v", pulled, err)
+	}
+
+	// The redaction map is merged line by line
+	os.MkdirAll(filepath.Join(firstDir, ".bragger"), 0755)
+	os.MkdirAll(filepath.Join(secondDir, ".bragger"), 0755)
+	writeFile(t, firstDir, redact.DefaultMapPath, "one\n")
+	first.Commit("")
+	if err := first.Push(); err != nil {
+		t.Fatal(err)
+	}
+	if pulled, err := second.Pull(); err != nil || !pulled {
+		t.Fatalf("Pull() = %v, %v", pulled, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(secondDir, redact.DefaultMapPath)); string(data) != "one\n" {
+		t.Errorf("expected the pulled map, got %q", data)
+	}
+
+	// Both change the same line of a file that isn't merged by record
+	writeFile(t, firstDir, redact.DefaultMapPath, "two\n")
+	first.Commit("")
+	first.Push()
+	writeFile(t, secondDir, redact.DefaultMapPath, "three\n")
+	second.Commit("")
+	_, err := second.Pull()
+	if !errors.Is(err, ErrConflict) || !strings.Contains(err.Error(), redact.DefaultMapPath) {
+		t.Fatalf("expected a conflict in the redaction map, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(secondDir, redact.DefaultMapPath)); string(data) != "three\n" {
+		t.Errorf("expected the merge to be aborted, got %q", data)
+	}
+}
+
+func TestPushWithoutRemote(t *testing.T) {
+	setupRemote(t)
+	repo := Open(t.TempDir())
+	if err := repo.Push(); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("expected ErrNotInitialized, got %v", err)
+	}
+	repo.Init("false")
+	if err := repo.Push(); !errors.Is(err, ErrNoRemote) {
+		t.Errorf("expected ErrNoRemote, got %v", err)
+	}
+}
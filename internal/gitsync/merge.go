@@ -0,0 +1,129 @@
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// line is one record of a JSONL data file
+type line struct {
+	id        string
+	updatedAt time.Time
+	raw       []byte
+}
+
+// parseLines reads the records of a JSONL data file by ID. Merging by ID
+// needs every line, so a malformed one is an error rather than skipped.
+func parseLines(name string, data []byte) ([]line, map[string]line, error) {
+	var lines []line
+	byID := make(map[string]line)
+	for i, raw := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		var head struct {
+			ID        string    `json:"id"`
+			UpdatedAt time.Time `json:"updated_at"`
+		}
+		if err := json.Unmarshal(raw, &head); err != nil {
+			return nil, nil, fmt.Errorf("%s line %d: %w", name, i+1, err)
+		}
+		if head.ID == "" {
+			return nil, nil, fmt.Errorf("%s line %d: record has no id", name, i+1)
+		}
+		if _, dup := byID[head.ID]; dup {
+			return nil, nil, fmt.Errorf("%s line %d: duplicate id %s", name, i+1, head.ID)
+		}
+		l := line{id: head.ID, updatedAt: head.UpdatedAt, raw: raw}
+		lines = append(lines, l)
+		byID[head.ID] = l
+	}
+	return lines, byID, nil
+}
+
+// MergeJSONL merges two versions of a JSONL data file that diverged from a
+// common base, record by record:
+//
+//   - a record changed on one side only takes that side's version
+//   - a record changed on both sides takes the version updated last, going
+//     by updated_at, and ours on a tie
+//   - a record added on either side is kept
+//   - a record removed on one side is removed, unless the other side changed
+//     it, in which case the change is kept
+//
+// Records keep our order, with the ones only they have appended in their
+// order. Malformed lines are an error, so the file is left to merge by hand.
+func MergeJSONL(base, ours, theirs []byte) ([]byte, error) {
+	_, baseByID, err := parseLines("base", base)
+	if err != nil {
+		return nil, err
+	}
+	ourLines, ourByID, err := parseLines("ours", ours)
+	if err != nil {
+		return nil, err
+	}
+	theirLines, theirByID, err := parseLines("theirs", theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	write := func(l line) {
+		out.Write(l.raw)
+		out.WriteByte('\n')
+	}
+	for _, o := range ourLines {
+		b, inBase := baseByID[o.id]
+		t, inTheirs := theirByID[o.id]
+		switch {
+		case inTheirs:
+			write(pick(b, o, t, inBase))
+		case !inBase || !sameRecord(b.raw, o.raw):
+			// Added by us, or removed by them after we changed it
+			write(o)
+		}
+	}
+	for _, t := range theirLines {
+		if _, inOurs := ourByID[t.id]; inOurs {
+			continue
+		}
+		b, inBase := baseByID[t.id]
+		if !inBase || !sameRecord(b.raw, t.raw) {
+			// Added by them, or removed by us after they changed it
+			write(t)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// pick chooses between our and their version of a record both sides have
+func pick(base, ours, theirs line, inBase bool) line {
+	switch {
+	case sameRecord(ours.raw, theirs.raw):
+		return ours
+	case inBase && sameRecord(base.raw, ours.raw):
+		return theirs
+	case inBase && sameRecord(base.raw, theirs.raw):
+		return ours
+	case theirs.updatedAt.After(ours.updatedAt):
+		return theirs
+	default:
+		return ours
+	}
+}
+
+// sameRecord compares JSON records, ignoring formatting and member order
+func sameRecord(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return bytes.Equal(xs, ys)
+}
@@ -0,0 +1,97 @@
+package gitsync
+
+import (
+	"strings"
+	"testing"
+)
+
+func jsonl(lines ...string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func TestMergeJSONL(t *testing.T) {
+	const (
+		a       = `{"id":"app-a","status":"applied","updated_at":"2026-01-01T00:00:00Z"}`
+		aOurs   = `{"id":"app-a","status":"interviewing","updated_at":"2026-01-02T00:00:00Z"}`
+		aTheirs = `{"id":"app-a","status":"rejected","updated_at":"2026-01-03T00:00:00Z"}`
+		b       = `{"id":"app-b","status":"applied","updated_at":"2026-01-01T00:00:00Z"}`
+		bTheirs = `{"id":"app-b","status":"offer","updated_at":"2026-01-02T00:00:00Z"}`
+		c       = `{"id":"app-c","status":"applied","updated_at":"2026-01-01T00:00:00Z"}`
+		cOurs   = `{"id":"app-c","status":"wishlist","updated_at":"2026-01-05T00:00:00Z"}`
+		d       = `{"id":"app-d","updated_at":"2026-01-01T00:00:00Z"}`
+		e       = `{"id":"app-e","updated_at":"2026-01-04T00:00:00Z"}`
+		f       = `{"id":"app-f","updated_at":"2026-01-04T00:00:00Z"}`
+	)
+
+	tests := []struct {
+		name               string
+		base, ours, theirs []byte
+		want               []byte
+	}{
+		{
+			name:   "changes to different records",
+			base:   jsonl(a, b),
+			ours:   jsonl(aOurs, b),
+			theirs: jsonl(a, bTheirs),
+			want:   jsonl(aOurs, bTheirs),
+		},
+		{
+			name:   "both changed: last updated wins",
+			base:   jsonl(a),
+			ours:   jsonl(aOurs),
+			theirs: jsonl(aTheirs),
+			want:   jsonl(aTheirs),
+		},
+		{
+			name:   "added on both sides",
+			base:   jsonl(a),
+			ours:   jsonl(a, e),
+			theirs: jsonl(a, f),
+			want:   jsonl(a, e, f),
+		},
+		{
+			name:   "removed on one side",
+			base:   jsonl(a, d),
+			ours:   jsonl(a),
+			theirs: jsonl(a, d),
+			want:   jsonl(a),
+		},
+		{
+			name:   "removed by them, changed by us",
+			base:   jsonl(a, c),
+			ours:   jsonl(a, cOurs),
+			theirs: jsonl(a),
+			want:   jsonl(a, cOurs),
+		},
+		{
+			name:   "no common base",
+			base:   nil,
+			ours:   jsonl(e, aOurs),
+			theirs: jsonl(aTheirs, f),
+			want:   jsonl(e, aTheirs, f),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeJSONL(tt.base, tt.ours, tt.theirs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeJSONLMalformed(t *testing.T) {
+	if _, err := MergeJSONL(nil, jsonl(`{"id":"app-a"}`), jsonl(`not json`)); err == nil || !strings.Contains(err.Error(), "theirs line 1") {
+		t.Errorf("expected an error for their malformed line, got %v", err)
+	}
+	if _, err := MergeJSONL(nil, jsonl(`{"status":"applied"}`), nil); err == nil {
+		t.Error("expected an error for a record without an id")
+	}
+}
@@ -0,0 +1,126 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+// maxSubject is the longest commit subject Message writes before falling
+// back to a count of the changes
+const maxSubject = 72
+
+// maxFields is how many changed fields a change's summary names
+const maxFields = 3
+
+// maxValue is the longest field value a summary shows
+const maxValue = 30
+
+// Message describes journaled changes as a commit message, e.g.
+// "update app-1234: status applied→interviewing". Several changes get a
+// subject naming them, or counting them if that's too long, and a line each
+// in the body.
+func Message(changes []storage.Change) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = describeChange(c)
+	}
+	prefix := ""
+	if changes[0].Undoes != "" {
+		prefix = "undo: "
+	}
+	if len(lines) == 1 {
+		return prefix + lines[0]
+	}
+
+	short := make([]string, len(changes))
+	for i, c := range changes {
+		short[i] = c.Op + " " + c.ID
+	}
+	subject := prefix + strings.Join(short, ", ")
+	if len(subject) > maxSubject {
+		subject = fmt.Sprintf("%s%d changes", prefix, len(changes))
+		if c := changes[0].Command; c != "" {
+			subject += ": " + c
+		}
+		if runes := []rune(subject); len(runes) > maxSubject {
+			subject = string(runes[:maxSubject-3]) + "..."
+		}
+	}
+	return subject + "\n\n" + strings.Join(lines, "\n")
+}
+
+// describeChange summarizes one change on a line
+func describeChange(c storage.Change) string {
+	s := c.Op + " " + c.ID
+	switch c.Op {
+	case storage.OpAdd:
+		if label := recordLabel(c.Store, c.After); label != "" {
+			s += ": " + label
+		}
+	case storage.OpUpdate:
+		fields := c.Fields()
+		var parts []string
+		for i, f := range fields {
+			if i == maxFields {
+				parts = append(parts, fmt.Sprintf("%d more", len(fields)-maxFields))
+				break
+			}
+			parts = append(parts, describeField(f))
+		}
+		if len(parts) > 0 {
+			s += ": " + strings.Join(parts, ", ")
+		}
+	}
+	return s
+}
+
+func describeField(f storage.FieldChange) string {
+	switch {
+	case f.Before == "":
+		return fmt.Sprintf("%s set to %s", f.Path, shortValue(f.After))
+	case f.After == "":
+		return f.Path + " cleared"
+	default:
+		return fmt.Sprintf("%s %s→%s", f.Path, shortValue(f.Before), shortValue(f.After))
+	}
+}
+
+// shortValue shows a JSON value on one line: strings unquoted, and long
+// values cut short
+func shortValue(raw string) string {
+	var s string
+	if json.Unmarshal([]byte(raw), &s) != nil {
+		s = raw
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxValue {
+		s = string(runes[:maxValue-3]) + "..."
+	}
+	return s
+}
+
+// recordLabel names an added record: an application's role and company, or
+// a KB entry's category
+func recordLabel(store string, raw json.RawMessage) string {
+	var r struct {
+		Company  string `json:"company"`
+		Role     string `json:"role"`
+		Category string `json:"category"`
+	}
+	if json.Unmarshal(raw, &r) != nil {
+		return ""
+	}
+	if store == storage.StoreKB {
+		return r.Category
+	}
+	if r.Role != "" && r.Company != "" {
+		return r.Role + " at " + r.Company
+	}
+	return r.Company
+}
@@ -0,0 +1,68 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/storage"
+)
+
+func TestMessage(t *testing.T) {
+	update := storage.Change{
+		Store:  storage.StoreApplications,
+		ID:     "app-1234",
+		Op:     storage.OpUpdate,
+		Before: json.RawMessage(`{"id":"app-1234","status":"applied","updated_at":"2026-01-01T00:00:00Z"}`),
+		After:  json.RawMessage(`{"id":"app-1234","status":"interviewing","notes":"call on Friday","updated_at":"2026-01-02T00:00:00Z"}`),
+	}
+	add := storage.Change{
+		Store: storage.StoreApplications,
+		ID:    "app-5678",
+		Op:    storage.OpAdd,
+		After: json.RawMessage(`{"id":"app-5678","company":"Acme","role":"Engineer"}`),
+	}
+	remove := storage.Change{Store: storage.StoreKB, ID: "kb-1234", Op: storage.OpRemove}
+
+	tests := []struct {
+		name    string
+		changes []storage.Change
+		want    string
+	}{
+		{"update", []storage.Change{update}, "update app-1234: notes set to call on Friday, status applied→interviewing"},
+		{"add", []storage.Change{add}, "add app-5678: Engineer at Acme"},
+		{"remove", []storage.Change{remove}, "remove kb-1234"},
+		{
+			"undo",
+			[]storage.Change{{Store: storage.StoreKB, ID: "kb-1234", Op: storage.OpAdd, After: json.RawMessage(`{"id":"kb-1234","category":"note"}`), Undoes: "step-1"}},
+			"undo: add kb-1234: note",
+		},
+		{
+			"several",
+			[]storage.Change{add, remove},
+			"add app-5678, remove kb-1234\n\nadd app-5678: Engineer at Acme\nremove kb-1234",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Message(tt.changes); got != tt.want {
+				t.Errorf("Message() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageManyChanges(t *testing.T) {
+	var changes []storage.Change
+	for i := 0; i < 10; i++ {
+		changes = append(changes, storage.Change{ID: "kb-0000000" + string(rune('0'+i)), Op: storage.OpAdd, Command: "bragger kb import resume.json"})
+	}
+	got := Message(changes)
+	subject, body, _ := strings.Cut(got, "\n\n")
+	if subject != "10 changes: bragger kb import resume.json" {
+		t.Errorf("subject = %q", subject)
+	}
+	if strings.Count(body, "\n") != 9 {
+		t.Errorf("expected a line per change, got %q", body)
+	}
+}
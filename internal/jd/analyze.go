@@ -0,0 +1,364 @@
+package jd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// section identifies which part of a job description a line belongs to
+type section int
+
+const (
+	sectionNone section = iota
+	sectionResponsibilities
+	sectionMustHave
+	sectionNiceToHave
+	sectionBenefits
+)
+
+// sectionHeadings maps heading phrases to sections. Order matters: more
+// specific phrases ("preferred qualifications") must come before generic
+// ones ("qualifications").
+var sectionHeadings = []struct {
+	phrases []string
+	section section
+}{
+	{[]string{"nice to have", "nice-to-have", "preferred", "bonus", "plus if", "pluses", "desirable", "good to have", "would be great", "extra credit"}, sectionNiceToHave},
+	{[]string{"benefit", "perks", "what we offer", "we offer", "compensation", "why join", "why you'll love", "what's in it for you", "what you'll get", "what you get"}, sectionBenefits},
+	{[]string{"responsibilit", "what you'll do", "what you will do", "what you'll be doing", "your role", "the role", "duties", "in this role", "your impact", "day to day", "day-to-day", "your mission", "your tasks"}, sectionResponsibilities},
+	{[]string{"requirement", "qualification", "must have", "must-have", "what you'll bring", "what you bring", "what we're looking for", "what we are looking for", "who you are", "about you", "you have", "you should have", "skills", "experience", "your profile"}, sectionMustHave},
+}
+
+var (
+	bulletPrefix = regexp.MustCompile(`^(?:[-*•·▪◦‣–]|\d{1,2}[.)])\s+`)
+	markdownHead = regexp.MustCompile(`^#{1,6}\s+`)
+	locationLine = regexp.MustCompile(`(?i)^(?:location|based in|office)\s*:\s*(.+)$`)
+)
+
+// Analyze extracts a structured analysis from a plain-text job description
+func Analyze(text string) *models.JDAnalysis {
+	a := &models.JDAnalysis{AnalyzedAt: time.Now()}
+	lines := strings.Split(CleanText(text), "\n")
+
+	current := sectionNone
+	// A section of bullets ends at the first line of prose after them, such
+	// as an equal opportunity statement
+	listed := false
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		// Location and salary lines are details of the job, not items
+		if m := locationLine.FindStringSubmatch(line); m != nil {
+			if a.Location == "" {
+				a.Location = strings.TrimSpace(m[1])
+			}
+			continue
+		}
+		if a.Salary == nil {
+			if a.Salary = parseSalary(line); a.Salary != nil {
+				continue
+			}
+		}
+
+		if heading, ok := parseHeading(line); ok {
+			current, listed = heading, false
+			continue
+		}
+
+		isBullet := bulletPrefix.MatchString(line)
+		item := strings.TrimSpace(bulletPrefix.ReplaceAllString(line, ""))
+
+		// Inline "Nice to have: Rust" / "Bonus: Kafka" style lines
+		inline := false
+		if !isBullet && i > 0 {
+			if s, rest, ok := inlineSection(line); ok {
+				item, current, inline = rest, s, true
+			}
+		}
+		if !isBullet && !inline && listed {
+			current, listed = sectionNone, false
+		}
+		listed = listed || isBullet || inline
+
+		switch current {
+		case sectionResponsibilities:
+			a.Responsibilities = append(a.Responsibilities, item)
+		case sectionMustHave:
+			a.MustHave = append(a.MustHave, item)
+		case sectionNiceToHave:
+			a.NiceToHave = append(a.NiceToHave, item)
+		case sectionBenefits:
+			a.Benefits = append(a.Benefits, item)
+		}
+
+		for _, req := range parseExperience(item) {
+			req.Required = current != sectionNiceToHave
+			a.Experience = append(a.Experience, req)
+		}
+	}
+
+	a.Seniority = detectSeniority(lines)
+	a.RemotePolicy = detectRemotePolicy(text)
+	if a.Salary == nil {
+		// A salary split over lines
+		a.Salary = parseSalary(text)
+	}
+
+	return a
+}
+
+// parseHeading reports whether line is a section heading and which section
+// it starts. Headings that don't match a known section end the current one.
+func parseHeading(line string) (section, bool) {
+	if bulletPrefix.MatchString(line) || len(line) > 60 {
+		return sectionNone, false
+	}
+
+	explicit := markdownHead.MatchString(line) || strings.HasSuffix(line, ":")
+	text := strings.ToLower(strings.TrimRight(markdownHead.ReplaceAllString(line, ""), ":"))
+
+	// Lines ending in sentence punctuation are prose, and "Label: value"
+	// lines are handled by inlineSection
+	if !explicit && (strings.ContainsAny(text[len(text)-1:], ".!?,;") || strings.Contains(text, ":")) {
+		return sectionNone, false
+	}
+
+	for _, h := range sectionHeadings {
+		for _, phrase := range h.phrases {
+			if strings.Contains(text, phrase) {
+				// Unmarked headings must be short, like "Requirements", so
+				// that "5 years experience with Go" stays an item
+				if explicit || (len(strings.Fields(text)) <= 4 && !strings.ContainsAny(text, "0123456789")) {
+					return h.section, true
+				}
+			}
+		}
+	}
+
+	if explicit {
+		return sectionNone, true
+	}
+	return sectionNone, false
+}
+
+// inlineSection handles "Heading: content" lines such as "Nice to have: Rust"
+func inlineSection(line string) (section, string, bool) {
+	idx := strings.Index(line, ":")
+	if idx <= 0 || idx > 30 || idx == len(line)-1 {
+		return sectionNone, "", false
+	}
+	s, ok := parseHeading(line[:idx+1])
+	if !ok || s == sectionNone {
+		return sectionNone, "", false
+	}
+	return s, strings.TrimSpace(line[idx+1:]), true
+}
+
+var experiencePattern = regexp.MustCompile(`(?i)(?:(?:at least|minimum(?: of)?|min\.?|over|more than)\s+)?(\d{1,2})\s*\+?\s*(?:(?:-|–|to)\s*(\d{1,2})\s*\+?\s*)?years?(?:['’]s?)?\s*(?:of\s+)?(?:(?:professional|relevant|hands-on|industry|commercial|proven|practical|working|production)\s+)*(?:experience|exp\.?)?\s*(?:(?:with|in|of|using|building|as|on|developing|working with)\s+)?([^.;()\n]*)`)
+
+// parseExperience extracts years-of-experience requirements from a line
+func parseExperience(line string) []models.ExperienceRequirement {
+	var reqs []models.ExperienceRequirement
+	for _, m := range experiencePattern.FindAllStringSubmatch(line, -1) {
+		minYears, _ := strconv.Atoi(m[1])
+		maxYears, _ := strconv.Atoi(m[2])
+		if minYears == 0 || minYears > 30 {
+			continue
+		}
+		reqs = append(reqs, models.ExperienceRequirement{
+			MinYears: minYears,
+			MaxYears: maxYears,
+			Subject:  trimSubject(m[3]),
+			Text:     line,
+		})
+	}
+	return reqs
+}
+
+// trimSubject shortens the text after "N years of experience" to its first
+// clause, e.g. "Go and distributed systems, ideally in fintech" -> "Go and distributed systems"
+func trimSubject(s string) string {
+	s = strings.TrimSpace(s)
+	for _, sep := range []string{",", " ideally", " preferably", " including", " such as", " and a ", " or equivalent"} {
+		if i := strings.Index(strings.ToLower(s), sep); i > 0 {
+			s = s[:i]
+		}
+	}
+	words := strings.Fields(s)
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	return strings.Join(words, " ")
+}
+
+// seniorityLevels are checked in order; the first match wins
+var seniorityLevels = []struct {
+	level   string
+	pattern *regexp.Regexp
+}{
+	{"intern", regexp.MustCompile(`(?i)\b(intern|internship|werkstudent|working student)\b`)},
+	{"principal", regexp.MustCompile(`(?i)\bprincipal\b`)},
+	{"staff", regexp.MustCompile(`(?i)\bstaff\b`)},
+	{"director", regexp.MustCompile(`(?i)\b(director|vp|vice president|head of)\b`)},
+	{"senior", regexp.MustCompile(`(?i)\b(senior|sr\.?)\b`)},
+	{"lead", regexp.MustCompile(`(?i)\blead\b`)},
+	{"manager", regexp.MustCompile(`(?i)\bmanager\b`)},
+	{"junior", regexp.MustCompile(`(?i)\b(junior|jr\.?|entry[- ]level|graduate)\b`)},
+	{"mid", regexp.MustCompile(`(?i)\b(mid[- ]level|intermediate)\b`)},
+}
+
+var explicitSeniority = regexp.MustCompile(`(?i)\b(junior|mid|senior|staff|principal)[- ]level\b|\bseniority:\s*(\w+)`)
+
+// detectSeniority looks at the title (first line) first, then the whole text
+func detectSeniority(lines []string) string {
+	if len(lines) > 0 {
+		for _, s := range seniorityLevels {
+			if s.pattern.MatchString(lines[0]) {
+				return s.level
+			}
+		}
+	}
+	// In body text only explicit level phrases are trusted, since "work with
+	// senior engineers" says nothing about the role itself
+	if m := explicitSeniority.FindStringSubmatch(strings.Join(lines, "\n")); m != nil {
+		return strings.ToLower(m[1] + m[2])
+	}
+	return ""
+}
+
+var (
+	hybridPattern = regexp.MustCompile(`(?i)\bhybrid\b|\d\s*days?\s*(?:a|per)\s*week\s*(?:in|at)\s*(?:the\s*)?office`)
+	remotePattern = regexp.MustCompile(`(?i)\b(fully remote|100% remote|remote[- ]first|remote[- ]friendly|work from anywhere|remote)\b`)
+	onsitePattern = regexp.MustCompile(`(?i)\b(on[- ]?site|in[- ]office|office[- ]based|in person)\b`)
+	notRemote     = regexp.MustCompile(`(?i)\b(not|no)\s+(a\s+)?remote\b`)
+)
+
+// detectRemotePolicy classifies the work arrangement mentioned in the JD
+func detectRemotePolicy(text string) models.RemotePolicy {
+	switch {
+	case hybridPattern.MatchString(text):
+		return models.RemotePolicyHybrid
+	case remotePattern.MatchString(text) && !notRemote.MatchString(text):
+		return models.RemotePolicyRemote
+	case onsitePattern.MatchString(text) || notRemote.MatchString(text):
+		return models.RemotePolicyOnsite
+	}
+	return ""
+}
+
+var currencySymbols = map[string]string{
+	"€": "EUR", "$": "USD", "£": "GBP", "¥": "JPY", "₹": "INR",
+	"eur": "EUR", "usd": "USD", "gbp": "GBP", "chf": "CHF", "cad": "CAD",
+	"aud": "AUD", "sek": "SEK", "nok": "NOK", "dkk": "DKK", "pln": "PLN",
+}
+
+const (
+	currencyRe = `(€|\$|£|¥|₹|EUR|USD|GBP|CHF|CAD|AUD|SEK|NOK|DKK|PLN)`
+	amountRe   = `(\d{1,3}(?:[,.]\d{3})+|\d+(?:\.\d+)?)\s*([kK])?`
+)
+
+var (
+	// "€80k - €95k", "USD 120,000 to 150,000"
+	salaryPrefixed = regexp.MustCompile(`(?i)` + currencyRe + `\s?` + amountRe + `\s*(?:-|–|—|to)\s*` + currencyRe + `?\s?` + amountRe)
+	// "80.000 - 95.000 EUR", "80k-95k €"
+	salarySuffixed = regexp.MustCompile(`(?i)` + amountRe + `\s*(?:-|–|—|to)\s*` + amountRe + `\s*` + currencyRe)
+	// "€90k", "USD 120,000"
+	salarySingle = regexp.MustCompile(`(?i)` + currencyRe + `\s?` + amountRe)
+
+	periodYear  = regexp.MustCompile(`(?i)per\s+(year|annum)|/\s*(year|yr)|annual|yearly|p\.a\.|a year`)
+	periodMonth = regexp.MustCompile(`(?i)per\s+month|/\s*(month|mo)\b|monthly|a month`)
+	periodHour  = regexp.MustCompile(`(?i)per\s+hour|/\s*(hour|hr)\b|hourly|an hour`)
+)
+
+// parseSalary finds the first salary range (or single amount) in the text
+func parseSalary(text string) *models.SalaryRange {
+	var s *models.SalaryRange
+	var end int
+
+	if loc := salaryPrefixed.FindStringSubmatchIndex(text); loc != nil {
+		m := submatches(text, loc)
+		s = &models.SalaryRange{
+			Currency: currencySymbols[strings.ToLower(m[1])],
+			Min:      parseAmount(m[2], m[3]),
+			Max:      parseAmount(m[5], m[6]),
+		}
+		end = loc[1]
+	} else if loc := salarySuffixed.FindStringSubmatchIndex(text); loc != nil {
+		m := submatches(text, loc)
+		s = &models.SalaryRange{
+			Currency: currencySymbols[strings.ToLower(m[5])],
+			Min:      parseAmount(m[1], m[2]),
+			Max:      parseAmount(m[3], m[4]),
+		}
+		end = loc[1]
+	} else if loc := salarySingle.FindStringSubmatchIndex(text); loc != nil {
+		m := submatches(text, loc)
+		amount := parseAmount(m[2], m[3])
+		if amount < 1000 && m[3] == "" {
+			return nil // Probably not a salary ("$5 lunch budget")
+		}
+		s = &models.SalaryRange{
+			Currency: currencySymbols[strings.ToLower(m[1])],
+			Min:      amount,
+			Max:      amount,
+		}
+		end = loc[1]
+	}
+
+	if s == nil {
+		return nil
+	}
+	// Ranges given in k apply the suffix to both ends: "80-95k"
+	if s.Min < 1000 && s.Max >= 1000 {
+		s.Min *= 1000
+	}
+
+	// The pay period usually follows the amount
+	tail := text[end:min(len(text), end+30)]
+	switch {
+	case periodHour.MatchString(tail):
+		s.Period = "hour"
+	case periodMonth.MatchString(tail):
+		s.Period = "month"
+	case periodYear.MatchString(tail):
+		s.Period = "year"
+	case s.Max >= 10000:
+		s.Period = "year"
+	}
+
+	return s
+}
+
+func submatches(text string, loc []int) []string {
+	m := make([]string, len(loc)/2)
+	for i := range m {
+		if loc[2*i] >= 0 {
+			m[i] = text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return m
+}
+
+var thousandsSeparated = regexp.MustCompile(`^\d{1,3}(?:[,.]\d{3})+$`)
+
+// parseAmount converts "80,000", "80.000", "95.5" or "80" + "k" to an integer
+func parseAmount(num, suffix string) int {
+	if thousandsSeparated.MatchString(num) {
+		num = strings.NewReplacer(",", "", ".", "").Replace(num)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if suffix != "" {
+		f *= 1000
+	}
+	return int(f)
+}
@@ -0,0 +1,176 @@
+package jd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+const sampleJD = `Senior Backend Engineer
+Location: Berlin, Germany
+
+About us
+We build payment infrastructure for 2,000 merchants.
+
+What you'll do:
+- Design and operate Go microservices
+- Mentor engineers on the team
+
+Requirements
+- 5+ years of professional experience with Go
+- Experience running Kubernetes in production
+- 2-4 years working with PostgreSQL, ideally at scale
+
+Nice to have
+- Experience with Kafka
+- 3 years in fintech
+
+What we offer
+- Hybrid setup: 2 days per week in the office
+- €80k - €95k per year
+
+Bonus: open source contributions`
+
+func TestAnalyzeSections(t *testing.T) {
+	a := Analyze(sampleJD)
+
+	wantResp := []string{"Design and operate Go microservices", "Mentor engineers on the team"}
+	if !reflect.DeepEqual(a.Responsibilities, wantResp) {
+		t.Errorf("Responsibilities = %q, want %q", a.Responsibilities, wantResp)
+	}
+
+	wantMust := []string{
+		"5+ years of professional experience with Go",
+		"Experience running Kubernetes in production",
+		"2-4 years working with PostgreSQL, ideally at scale",
+	}
+	if !reflect.DeepEqual(a.MustHave, wantMust) {
+		t.Errorf("MustHave = %q, want %q", a.MustHave, wantMust)
+	}
+
+	wantNice := []string{"Experience with Kafka", "3 years in fintech", "open source contributions"}
+	if !reflect.DeepEqual(a.NiceToHave, wantNice) {
+		t.Errorf("NiceToHave = %q, want %q", a.NiceToHave, wantNice)
+	}
+
+	// The salary line is the salary, not a benefit
+	if len(a.Benefits) != 1 || a.Salary == nil || a.Salary.Max != 95000 {
+		t.Errorf("expected 1 benefit and the salary, got %q, %+v", a.Benefits, a.Salary)
+	}
+
+	if a.Seniority != "senior" {
+		t.Errorf("Seniority = %q, want senior", a.Seniority)
+	}
+	if a.Location != "Berlin, Germany" {
+		t.Errorf("Location = %q", a.Location)
+	}
+	if a.RemotePolicy != models.RemotePolicyHybrid {
+		t.Errorf("RemotePolicy = %q, want hybrid", a.RemotePolicy)
+	}
+	if a.AnalyzedAt.IsZero() {
+		t.Error("expected AnalyzedAt to be set")
+	}
+}
+
+func TestAnalyzeProseEndsList(t *testing.T) {
+	a := Analyze("Senior Engineer\n\nNice to have:\n- Rust\n\nAcme is an equal opportunity employer and values diversity.\n" +
+		"Salary: 80,000 - 100,000 EUR. Hybrid, Berlin.\nWe ask for 5 years of experience with Go.")
+
+	if want := []string{"Rust"}; !reflect.DeepEqual(a.NiceToHave, want) {
+		t.Errorf("NiceToHave = %q, want %q", a.NiceToHave, want)
+	}
+	if a.Salary == nil || a.Salary.Min != 80000 || a.Salary.Max != 100000 || a.Salary.Currency != "EUR" {
+		t.Errorf("unexpected salary %+v", a.Salary)
+	}
+	// Years in the prose after the list aren't nice to have
+	if len(a.Experience) != 1 || !a.Experience[0].Required {
+		t.Errorf("expected a required experience, got %+v", a.Experience)
+	}
+}
+
+func TestAnalyzeExperience(t *testing.T) {
+	a := Analyze(sampleJD)
+
+	want := []models.ExperienceRequirement{
+		{MinYears: 5, Subject: "Go", Required: true, Text: "5+ years of professional experience with Go"},
+		{MinYears: 2, MaxYears: 4, Subject: "PostgreSQL", Required: true, Text: "2-4 years working with PostgreSQL, ideally at scale"},
+		{MinYears: 3, Subject: "fintech", Required: false, Text: "3 years in fintech"},
+	}
+	if !reflect.DeepEqual(a.Experience, want) {
+		t.Errorf("Experience =\n%+v\nwant\n%+v", a.Experience, want)
+	}
+	if a.MinYears() != 5 {
+		t.Errorf("MinYears() = %d, want 5", a.MinYears())
+	}
+}
+
+func TestParseSalary(t *testing.T) {
+	tests := []struct {
+		text string
+		want *models.SalaryRange
+	}{
+		{"Pay: €80k - €95k per year", &models.SalaryRange{Min: 80000, Max: 95000, Currency: "EUR", Period: "year"}},
+		{"USD 120,000 to 150,000 annually", &models.SalaryRange{Min: 120000, Max: 150000, Currency: "USD", Period: "year"}},
+		{"Salary: 65.000 – 75.000 EUR brutto", &models.SalaryRange{Min: 65000, Max: 75000, Currency: "EUR", Period: "year"}},
+		{"$60-80 per hour", &models.SalaryRange{Min: 60, Max: 80, Currency: "USD", Period: "hour"}},
+		{"£90k base", &models.SalaryRange{Min: 90000, Max: 90000, Currency: "GBP", Period: "year"}},
+		{"Salary: EUR 80000-95000 per YEAR", &models.SalaryRange{Min: 80000, Max: 95000, Currency: "EUR", Period: "year"}},
+		{"A $5 coffee budget", nil},
+		{"No salary here", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := parseSalary(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSalary(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectRemotePolicy(t *testing.T) {
+	tests := []struct {
+		text string
+		want models.RemotePolicy
+	}{
+		{"This is a fully remote position", models.RemotePolicyRemote},
+		{"We are remote-first", models.RemotePolicyRemote},
+		{"Hybrid, 3 days in Munich", models.RemotePolicyHybrid},
+		{"This role is not remote; you will work on-site", models.RemotePolicyOnsite},
+		{"Office-based in London", models.RemotePolicyOnsite},
+		{"Great team", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := detectRemotePolicy(tt.text); got != tt.want {
+				t.Errorf("detectRemotePolicy(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSeniority(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"Staff Software Engineer"}, "staff"},
+		{[]string{"Sr. Data Engineer"}, "senior"},
+		{[]string{"Senior Engineering Manager"}, "senior"},
+		{[]string{"Engineering Manager"}, "manager"},
+		{[]string{"Junior Frontend Developer"}, "junior"},
+		{[]string{"Software Engineer", "You will work with senior engineers"}, ""},
+		{[]string{"Software Engineer", "This is a mid-level position"}, "mid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lines[0], func(t *testing.T) {
+			if got := detectSeniority(tt.lines); got != tt.want {
+				t.Errorf("detectSeniority(%q) = %q, want %q", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package jd
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultSnapshotDir is where raw job posting HTML is archived
+const DefaultSnapshotDir = ".bragger/snapshots"
+
+// maxPageSize limits how much of a job posting page is downloaded
+const maxPageSize = 10 * 1024 * 1024
+
+// userAgent identifies bragger to job boards; some reject Go's default agent
+const userAgent = "Mozilla/5.0 (compatible; bragger; +https://github.com/ewurch/bragger)"
+
+// FetchResult is a downloaded job posting
+type FetchResult struct {
+	URL       string
+	Raw       []byte      // Response body as downloaded
+	Title     string      // HTML <title>, if any
+	Text      string      // Clean plain-text job description
+	Posting   *JobPosting // schema.org JobPosting, nil if the page has none
+	FetchedAt time.Time
+}
+
+// Fetch downloads a job posting and converts it to plain text. When the page
+// embeds a schema.org JobPosting, its fields are used for the text; otherwise
+// the whole page is converted.
+func Fetch(client *http.Client, url string) (*FetchResult, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+
+	// One byte over the limit tells a page that's too big from one that fits
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %v", err)
+	}
+	if len(raw) > maxPageSize {
+		return nil, fmt.Errorf("fetching %s: the page is over %d MB", url, maxPageSize/(1024*1024))
+	}
+
+	result := &FetchResult{URL: url, Raw: raw, FetchedAt: time.Now()}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/plain" {
+		result.Text = CleanText(string(raw))
+		return result, nil
+	}
+
+	doc := string(raw)
+	result.Title = HTMLTitle(doc)
+	result.Posting = ParseJobPosting(doc)
+	if result.Posting != nil && result.Posting.Description != "" {
+		result.Text = result.Posting.Text()
+	} else {
+		result.Text = HTMLToText(doc)
+	}
+
+	return result, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// SaveSnapshot archives the raw page under dir as <id>-<timestamp>.html and
+// returns the path written.
+func SaveSnapshot(dir, id string, result *FetchResult) (string, error) {
+	if dir == "" {
+		dir = DefaultSnapshotDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating snapshot directory: %v", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.html",
+		unsafeFileChars.ReplaceAllString(id, "_"),
+		result.FetchedAt.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, result.Raw, 0644); err != nil {
+		return "", fmt.Errorf("writing snapshot: %v", err)
+	}
+	return path, nil
+}
+
+// IsURL reports whether s looks like an http(s) URL
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
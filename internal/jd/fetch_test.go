@@ -0,0 +1,142 @@
+package jd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jsonld", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>SRE at Globex</title>
+<script type="application/ld+json">{"@type":"JobPosting","title":"SRE","hiringOrganization":{"name":"Globex"},"description":"<p>On-call rotation.</p>"}</script>
+</head><body><nav>Jobs</nav><p>Page chrome</p></body></html>`))
+	})
+	mux.HandleFunc("/plain-html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Engineer</title></head><body><h1>Engineer</h1><p>Write Go.</p></body></html>`))
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("  Plain <b>JD</b>  \n"))
+	})
+	mux.HandleFunc("/agent", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("User-Agent")))
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", maxPageSize+1)))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("uses JSON-LD posting", func(t *testing.T) {
+		result, err := Fetch(nil, server.URL+"/jsonld")
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if result.Posting == nil || result.Posting.Company != "Globex" {
+			t.Fatalf("expected JSON-LD posting, got %+v", result.Posting)
+		}
+		if !strings.Contains(result.Text, "On-call rotation.") || strings.Contains(result.Text, "Page chrome") {
+			t.Errorf("unexpected text: %q", result.Text)
+		}
+		if result.Title != "SRE at Globex" {
+			t.Errorf("Title = %q", result.Title)
+		}
+		if !strings.Contains(string(result.Raw), "<nav>Jobs</nav>") {
+			t.Error("expected raw HTML to be kept")
+		}
+	})
+
+	t.Run("falls back to page text", func(t *testing.T) {
+		result, err := Fetch(nil, server.URL+"/plain-html")
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if result.Posting != nil {
+			t.Errorf("expected no posting, got %+v", result.Posting)
+		}
+		if result.Text != "Engineer\n\nWrite Go." {
+			t.Errorf("Text = %q", result.Text)
+		}
+	})
+
+	t.Run("plain text is not parsed as HTML", func(t *testing.T) {
+		result, err := Fetch(nil, server.URL+"/text")
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if result.Text != "Plain <b>JD</b>" {
+			t.Errorf("Text = %q", result.Text)
+		}
+	})
+
+	t.Run("sends user agent", func(t *testing.T) {
+		result, err := Fetch(nil, server.URL+"/agent")
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if !strings.Contains(string(result.Raw), "bragger") {
+			t.Errorf("expected bragger user agent, got %q", result.Raw)
+		}
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		if _, err := Fetch(nil, server.URL+"/missing"); err == nil {
+			t.Error("expected error for 404")
+		}
+	})
+
+	t.Run("page over the size limit", func(t *testing.T) {
+		_, err := Fetch(nil, server.URL+"/huge")
+		if err == nil || !strings.Contains(err.Error(), "over 10 MB") {
+			t.Errorf("expected a size error, got %v", err)
+		}
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		if _, err := Fetch(nil, "://nope"); err == nil {
+			t.Error("expected error for invalid URL")
+		}
+	})
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "jd-snapshot-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<p>snapshot</p>"))
+	}))
+	defer server.Close()
+
+	result, err := Fetch(nil, server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+
+	dir := filepath.Join(tmpDir, ".bragger", "snapshots")
+	path, err := SaveSnapshot(dir, "app-1234", result)
+	if err != nil {
+		t.Fatalf("SaveSnapshot() error: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "app-1234-") {
+		t.Errorf("unexpected snapshot path: %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if string(content) != "<p>snapshot</p>" {
+		t.Errorf("unexpected snapshot content: %q", content)
+	}
+}
@@ -0,0 +1,249 @@
+package jd
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JobPosting holds the fields of a schema.org JobPosting that bragger uses
+type JobPosting struct {
+	Title          string `json:"title"`
+	Company        string `json:"company,omitempty"`
+	Description    string `json:"description,omitempty"` // Plain text
+	Location       string `json:"location,omitempty"`
+	Remote         bool   `json:"remote,omitempty"`
+	EmploymentType string `json:"employment_type,omitempty"`
+	DatePosted     string `json:"date_posted,omitempty"`
+	ValidThrough   string `json:"valid_through,omitempty"`
+	Salary         string `json:"salary,omitempty"`
+}
+
+var jsonLDScript = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+
+var titleTag = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// HTMLTitle returns the text of the document's <title> element
+func HTMLTitle(doc string) string {
+	m := titleTag.FindStringSubmatch(doc)
+	if m == nil {
+		return ""
+	}
+	return CleanText(HTMLToText(m[1]))
+}
+
+// ParseJobPosting looks for a schema.org JobPosting in the JSON-LD blocks of
+// an HTML document. It returns nil if the page has none.
+func ParseJobPosting(doc string) *JobPosting {
+	for _, m := range jsonLDScript.FindAllStringSubmatch(doc, -1) {
+		var data any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(m[1])), &data); err != nil {
+			continue // Sites often ship broken JSON-LD; try the next block
+		}
+		if obj := findJobPosting(data); obj != nil {
+			return jobPostingFromMap(obj)
+		}
+	}
+	return nil
+}
+
+// findJobPosting walks a decoded JSON-LD value (object, array or @graph)
+// and returns the first object whose @type is JobPosting.
+func findJobPosting(v any) map[string]any {
+	switch val := v.(type) {
+	case []any:
+		for _, item := range val {
+			if obj := findJobPosting(item); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		if hasType(val["@type"], "JobPosting") {
+			return val
+		}
+		if graph, ok := val["@graph"]; ok {
+			return findJobPosting(graph)
+		}
+	}
+	return nil
+}
+
+func hasType(v any, want string) bool {
+	switch t := v.(type) {
+	case string:
+		return t == want
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jobPostingFromMap(obj map[string]any) *JobPosting {
+	// Some sites HTML-escape the description markup a second time
+	description := stringField(obj["description"])
+	if !strings.Contains(description, "<") && strings.Contains(description, "&lt;") {
+		description = html.UnescapeString(description)
+	}
+
+	p := &JobPosting{
+		Title:          stringField(obj["title"]),
+		Description:    HTMLToText(description),
+		EmploymentType: joinField(obj["employmentType"]),
+		DatePosted:     stringField(obj["datePosted"]),
+		ValidThrough:   stringField(obj["validThrough"]),
+	}
+
+	if org, ok := obj["hiringOrganization"].(map[string]any); ok {
+		p.Company = stringField(org["name"])
+	} else {
+		p.Company = stringField(obj["hiringOrganization"])
+	}
+
+	p.Location = locationField(obj["jobLocation"])
+	p.Remote = stringField(obj["jobLocationType"]) == "TELECOMMUTE"
+	p.Salary = salaryField(obj["baseSalary"])
+
+	return p
+}
+
+// stringField returns v if it is a string, or the "name"/"@value" of an object
+func stringField(v any) string {
+	switch val := v.(type) {
+	case string:
+		return strings.TrimSpace(val)
+	case map[string]any:
+		if s := stringField(val["name"]); s != "" {
+			return s
+		}
+		return stringField(val["@value"])
+	}
+	return ""
+}
+
+// joinField joins a string or list of strings with ", "
+func joinField(v any) string {
+	if list, ok := v.([]any); ok {
+		var parts []string
+		for _, item := range list {
+			if s := stringField(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return stringField(v)
+}
+
+// locationField formats a Place (or list of places) as "City, Region, Country"
+func locationField(v any) string {
+	if list, ok := v.([]any); ok {
+		var parts []string
+		for _, item := range list {
+			if s := locationField(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "; ")
+	}
+
+	place, ok := v.(map[string]any)
+	if !ok {
+		return stringField(v)
+	}
+	addr, ok := place["address"].(map[string]any)
+	if !ok {
+		return stringField(place["address"])
+	}
+
+	var parts []string
+	for _, key := range []string{"addressLocality", "addressRegion", "addressCountry"} {
+		if s := stringField(addr[key]); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// salaryField formats a MonetaryAmount as "EUR 80000-95000 per YEAR"
+func salaryField(v any) string {
+	amount, ok := v.(map[string]any)
+	if !ok {
+		return ""
+	}
+	currency := stringField(amount["currency"])
+
+	value, ok := amount["value"].(map[string]any)
+	if !ok {
+		if n := numberField(amount["value"]); n != "" {
+			return strings.TrimSpace(currency + " " + n)
+		}
+		return ""
+	}
+
+	var rng string
+	minV, maxV, exact := numberField(value["minValue"]), numberField(value["maxValue"]), numberField(value["value"])
+	switch {
+	case minV != "" && maxV != "":
+		rng = minV + "-" + maxV
+	case exact != "":
+		rng = exact
+	case minV != "":
+		rng = minV + "+"
+	case maxV != "":
+		rng = "up to " + maxV
+	default:
+		return ""
+	}
+
+	s := strings.TrimSpace(currency + " " + rng)
+	if unit := stringField(value["unitText"]); unit != "" {
+		s += " per " + unit
+	}
+	return s
+}
+
+func numberField(v any) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		return strings.TrimSpace(n)
+	}
+	return ""
+}
+
+// Text renders the posting as a plain-text job description
+func (p *JobPosting) Text() string {
+	var b strings.Builder
+	if p.Title != "" {
+		b.WriteString(p.Title + "\n")
+	}
+	if p.Company != "" {
+		b.WriteString("Company: " + p.Company + "\n")
+	}
+	location := p.Location
+	if p.Remote && location != "" {
+		location = "Remote (" + location + ")"
+	} else if p.Remote {
+		location = "Remote"
+	}
+	if location != "" {
+		b.WriteString("Location: " + location + "\n")
+	}
+	if p.EmploymentType != "" {
+		b.WriteString("Employment type: " + p.EmploymentType + "\n")
+	}
+	if p.Salary != "" {
+		b.WriteString("Salary: " + p.Salary + "\n")
+	}
+	if p.Description != "" {
+		b.WriteString("\n" + p.Description)
+	}
+	return CleanText(b.String())
+}
@@ -0,0 +1,85 @@
+package jd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJobPosting(t *testing.T) {
+	t.Run("full posting", func(t *testing.T) {
+		doc := `<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Organization","name":"Ignored"}</script>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@type": "JobPosting",
+  "title": "Senior Backend Engineer",
+  "description": "&lt;p&gt;Build &lt;b&gt;APIs&lt;/b&gt;.&lt;/p&gt;&lt;ul&gt;&lt;li&gt;Go&lt;/li&gt;&lt;/ul&gt;",
+  "datePosted": "2025-01-10",
+  "employmentType": ["FULL_TIME", "CONTRACTOR"],
+  "hiringOrganization": {"@type": "Organization", "name": "Acme Corp"},
+  "jobLocation": {"@type": "Place", "address": {"addressLocality": "Berlin", "addressCountry": "DE"}},
+  "jobLocationType": "TELECOMMUTE",
+  "baseSalary": {"@type": "MonetaryAmount", "currency": "EUR",
+    "value": {"@type": "QuantitativeValue", "minValue": 80000, "maxValue": 95000, "unitText": "YEAR"}}
+}
+</script></head><body></body></html>`
+
+		p := ParseJobPosting(doc)
+		if p == nil {
+			t.Fatal("expected a job posting")
+		}
+		if p.Title != "Senior Backend Engineer" {
+			t.Errorf("Title = %q", p.Title)
+		}
+		if p.Company != "Acme Corp" {
+			t.Errorf("Company = %q", p.Company)
+		}
+		if p.Description != "Build APIs.\n\n- Go" {
+			t.Errorf("Description = %q", p.Description)
+		}
+		if p.Location != "Berlin, DE" || !p.Remote {
+			t.Errorf("Location = %q, Remote = %v", p.Location, p.Remote)
+		}
+		if p.EmploymentType != "FULL_TIME, CONTRACTOR" {
+			t.Errorf("EmploymentType = %q", p.EmploymentType)
+		}
+		if p.Salary != "EUR 80000-95000 per YEAR" {
+			t.Errorf("Salary = %q", p.Salary)
+		}
+
+		text := p.Text()
+		for _, want := range []string{"Senior Backend Engineer", "Company: Acme Corp", "Location: Remote (Berlin, DE)", "Salary: EUR 80000-95000 per YEAR", "Build APIs."} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected Text() to contain %q, got:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("posting inside graph", func(t *testing.T) {
+		doc := `<script type='application/ld+json'>{"@graph":[{"@type":"WebPage"},{"@type":["JobPosting"],"title":"SRE","hiringOrganization":"Globex"}]}</script>`
+		p := ParseJobPosting(doc)
+		if p == nil {
+			t.Fatal("expected a job posting")
+		}
+		if p.Title != "SRE" || p.Company != "Globex" {
+			t.Errorf("unexpected posting: %+v", p)
+		}
+	})
+
+	t.Run("broken JSON and no posting", func(t *testing.T) {
+		doc := `<script type="application/ld+json">{broken</script><p>Hello</p>`
+		if p := ParseJobPosting(doc); p != nil {
+			t.Errorf("expected nil, got %+v", p)
+		}
+	})
+}
+
+func TestHTMLTitle(t *testing.T) {
+	if got := HTMLTitle(`<html><head><TITLE> Staff Engineer &amp; Lead </TITLE></head></html>`); got != "Staff Engineer & Lead" {
+		t.Errorf("HTMLTitle() = %q", got)
+	}
+	if got := HTMLTitle(`<p>No title</p>`); got != "" {
+		t.Errorf("HTMLTitle() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,153 @@
+package jd
+
+import (
+	"html"
+	"strings"
+)
+
+// skippedTags are elements whose content is never part of the readable text
+var skippedTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"head":     true,
+	"nav":      true,
+	"footer":   true,
+	"button":   true,
+	"form":     true,
+	"iframe":   true,
+}
+
+// blockTags are elements that start a new line in the extracted text
+var blockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"header": true, "aside": true, "br": true, "hr": true, "tr": true,
+	"ul": true, "ol": true, "dl": true, "dt": true, "dd": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"table": true, "blockquote": true, "pre": true,
+}
+
+// paragraphTags are block elements followed by a blank line when closed
+var paragraphTags = map[string]bool{
+	"p": true, "ul": true, "ol": true, "dl": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "section": true, "article": true,
+}
+
+// HTMLToText converts an HTML document or fragment into plain text.
+// Block elements become line breaks, list items become "- " bullets, and
+// scripts, styles and page chrome (nav, footer, forms) are dropped.
+func HTMLToText(doc string) string {
+	var b strings.Builder
+	skipDepth := 0
+	skipTag := ""
+
+	for i := 0; i < len(doc); {
+		if doc[i] != '<' {
+			next := strings.IndexByte(doc[i:], '<')
+			if next < 0 {
+				next = len(doc) - i
+			}
+			if skipDepth == 0 {
+				b.WriteString(doc[i : i+next])
+			}
+			i += next
+			continue
+		}
+
+		// Comments and doctype
+		if strings.HasPrefix(doc[i:], "<!--") {
+			end := strings.Index(doc[i+4:], "-->")
+			if end < 0 {
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		end := strings.IndexByte(doc[i:], '>')
+		if end < 0 {
+			break
+		}
+		name, closing := tagName(doc[i+1 : i+end])
+		i += end + 1
+
+		if skipDepth > 0 {
+			if name == skipTag {
+				if closing {
+					skipDepth--
+				} else {
+					skipDepth++
+				}
+			}
+			continue
+		}
+
+		if skippedTags[name] && !closing {
+			// Raw text elements can contain '<', so jump straight to the end tag
+			if name == "script" || name == "style" {
+				closeIdx := strings.Index(strings.ToLower(doc[i:]), "</"+name)
+				if closeIdx < 0 {
+					break
+				}
+				i += closeIdx
+				continue
+			}
+			skipDepth = 1
+			skipTag = name
+			continue
+		}
+
+		switch {
+		case name == "li":
+			if !closing {
+				b.WriteString("\n- ")
+			}
+		case closing && paragraphTags[name]:
+			b.WriteString("\n\n")
+		case blockTags[name]:
+			b.WriteString("\n")
+		case name == "td" || name == "th":
+			b.WriteString(" ")
+		}
+	}
+
+	return CleanText(html.UnescapeString(b.String()))
+}
+
+// tagName returns the lowercase element name of a tag body (the text between
+// '<' and '>') and whether it is a closing tag.
+func tagName(body string) (string, bool) {
+	closing := strings.HasPrefix(body, "/")
+	body = strings.TrimPrefix(body, "/")
+	end := strings.IndexAny(body, " \t\r\n/")
+	if end >= 0 {
+		body = body[:end]
+	}
+	return strings.ToLower(body), closing
+}
+
+// CleanText normalizes whitespace: runs of spaces collapse to one, lines are
+// trimmed, and consecutive blank lines collapse to a single blank line.
+func CleanText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var lines []string
+	blank := true // Suppress leading blank lines
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" || line == "-" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
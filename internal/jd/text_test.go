@@ -0,0 +1,67 @@
+package jd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	t.Run("headings paragraphs and lists", func(t *testing.T) {
+		doc := `<html><head><title>Ignored</title></head><body>
+<h2>About the role</h2><p>We are hiring a <strong>Senior Engineer</strong>.</p>
+<h2>Requirements</h2><ul><li>5+ years of Go</li><li>Kubernetes &amp; AWS</li></ul>
+</body></html>`
+
+		got := HTMLToText(doc)
+		want := "About the role\n\nWe are hiring a Senior Engineer.\n\nRequirements\n\n- 5+ years of Go\n- Kubernetes & AWS"
+		if got != want {
+			t.Errorf("HTMLToText() =\n%q\nwant\n%q", got, want)
+		}
+	})
+
+	t.Run("drops scripts styles and page chrome", func(t *testing.T) {
+		doc := `<nav><a href="/">Home</a></nav>
+<script>var x = "<p>not text</p>";</script>
+<style>p { color: red; }</style>
+<p>Visible</p>
+<footer>Copyright</footer>`
+
+		got := HTMLToText(doc)
+		if got != "Visible" {
+			t.Errorf("HTMLToText() = %q, want %q", got, "Visible")
+		}
+	})
+
+	t.Run("nested skipped elements", func(t *testing.T) {
+		doc := `<nav><nav>inner</nav>still nav</nav><p>Body</p>`
+		if got := HTMLToText(doc); got != "Body" {
+			t.Errorf("HTMLToText() = %q, want %q", got, "Body")
+		}
+	})
+
+	t.Run("comments and entities", func(t *testing.T) {
+		doc := `<!-- hidden --><p>Salary: &euro;80k&nbsp;&ndash;&nbsp;&euro;95k</p>`
+		got := HTMLToText(doc)
+		if got != "Salary: €80k – €95k" {
+			t.Errorf("HTMLToText() = %q", got)
+		}
+	})
+
+	t.Run("plain text passes through", func(t *testing.T) {
+		if got := HTMLToText("Just text"); got != "Just text" {
+			t.Errorf("HTMLToText() = %q, want %q", got, "Just text")
+		}
+	})
+}
+
+func TestCleanText(t *testing.T) {
+	input := "\n\n  Title  \r\n\r\n\r\n  line   with   spaces\n-\n\nend  "
+	got := CleanText(input)
+	want := "Title\n\nline with spaces\n\nend"
+	if got != want {
+		t.Errorf("CleanText() = %q, want %q", got, want)
+	}
+	if strings.Contains(got, "\n\n\n") {
+		t.Error("expected consecutive blank lines to collapse")
+	}
+}
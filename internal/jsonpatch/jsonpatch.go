@@ -0,0 +1,382 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation is one operation of a JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func (o Operation) String() string {
+	if o.From != "" {
+		return fmt.Sprintf("%s %s -> %s", o.Op, o.From, o.Path)
+	}
+	return fmt.Sprintf("%s %s", o.Op, o.Path)
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a JSON document:
+// objects in the patch are merged member by member, null removes a member,
+// and any other value replaces the target
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// Apply applies a JSON Patch (RFC 6902) to a JSON document. The operations
+// are applied in order, and the document is unchanged if any fails.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return ApplyOperations(doc, ops)
+}
+
+// ApplyOperations applies JSON Patch operations to a JSON document
+func ApplyOperations(doc []byte, ops []Operation) ([]byte, error) {
+	node, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	for i, op := range ops {
+		if node, err = applyOp(node, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op, err)
+		}
+	}
+	return json.Marshal(node)
+}
+
+func applyOp(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "copy" {
+			return add(doc, path, clone(value))
+		}
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, fmt.Errorf("can't move a value into itself")
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func get(node any, path []string) (any, error) {
+	for i, token := range path {
+		child, err := member(node, token)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pointerString(path[:i+1]), err)
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// member returns the member of an object or array
+func member(node any, token string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		v, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("no such member")
+		}
+		return v, nil
+	case []any:
+		i, err := index(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	default:
+		return nil, fmt.Errorf("not an object or array")
+	}
+}
+
+// update calls fn with the container of the path's last token and returns the
+// document with the container fn returns in its place
+func update(doc any, path []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := member(doc, path[0])
+	if err != nil {
+		return nil, fmt.Errorf("/%s: %w", path[0], err)
+	}
+	child, err = update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch n := doc.(type) {
+	case map[string]any:
+		n[path[0]] = child
+	case []any:
+		i, _ := index(path[0], len(n)-1)
+		n[i] = child
+	}
+	return doc, nil
+}
+
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			n[token] = value
+			return n, nil
+		case []any:
+			i := len(n)
+			if token != "-" {
+				var err error
+				if i, err = index(token, len(n)); err != nil {
+					return nil, fmt.Errorf("%s: %w", pointerString(path), err)
+				}
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		default:
+			return nil, fmt.Errorf("%s: parent is not an object or array", pointerString(path))
+		}
+	})
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can't remove the whole document")
+	}
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			if _, ok := n[token]; !ok {
+				return nil, fmt.Errorf("%s: no such member", pointerString(path))
+			}
+			delete(n, token)
+			return n, nil
+		case []any:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pointerString(path), err)
+			}
+			return append(n[:i], n[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%s: parent is not an object or array", pointerString(path))
+		}
+	})
+}
+
+func replace(doc any, path []string, value any) (any, error) {
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			n[token] = value
+			return n, nil
+		case []any:
+			i, _ := index(token, len(n)-1)
+			n[i] = value
+			return n, nil
+		}
+		return container, nil
+	})
+}
+
+// index parses an array index no greater than max
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > max {
+		return 0, fmt.Errorf("array index %s out of range", token)
+	}
+	return i, nil
+}
+
+func pointerString(path []string) string {
+	var b strings.Builder
+	for _, token := range path {
+		b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares JSON values, with numbers compared by value
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	default:
+		return a == b
+	}
+}
+
+func clone(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, v := range x {
+			out[k] = clone(v)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, v := range x {
+			out[i] = clone(v)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// decode reads a JSON value, keeping numbers as written
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return v, nil
+}
@@ -0,0 +1,127 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// sameJSON reports whether two JSON documents are equal, ignoring formatting
+// and member order
+func sameJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatalf("invalid result %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &b); err != nil {
+		t.Fatalf("invalid expectation %s: %v", want, err)
+	}
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+func TestMergePatch(t *testing.T) {
+	// From RFC 7396, appendix A
+	tests := []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !sameJSON(t, got, tt.want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); err == nil {
+		t.Error("expected error for invalid patch")
+	}
+}
+
+func TestApply(t *testing.T) {
+	// Mostly from RFC 6902, appendix A
+	tests := []struct{ doc, patch, want string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{`{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a/b","path":"/c"},{"op":"add","path":"/c/-","value":2}]`, `{"a":{"b":[1]},"c":[1,2]}`},
+		{`{"foo":null}`, `[{"op":"replace","path":"/foo","value":"x"}]`, `{"foo":"x"}`},
+	}
+	for _, tt := range tests {
+		got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("Apply(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !sameJSON(t, got, tt.want) {
+			t.Errorf("Apply(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct{ doc, patch, want string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "/baz: no such member"},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, "no such member"},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, "no such member"},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":1}]`, "out of range"},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/1"}]`, "out of range"},
+		{`{"foo":[1,2]}`, `[{"op":"remove","path":"/foo/01"}]`, "invalid array index"},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/-"}]`, "invalid array index"},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "test failed"},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, "missing value"},
+		{`{"foo":"bar"}`, `[{"op":"frob","path":"/foo"}]`, `unknown op "frob"`},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`, "must start with /"},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, "into itself"},
+		{`{"foo":"bar"}`, `{"op":"remove","path":"/foo"}`, "invalid patch"},
+	}
+	for _, tt := range tests {
+		_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Apply(%s, %s) = %v, want error containing %q", tt.doc, tt.patch, err, tt.want)
+		}
+	}
+}
+
+func TestApplyOperationsAtomic(t *testing.T) {
+	// A failing operation leaves no partial result
+	doc := []byte(`{"highlights":["a"]}`)
+	_, err := ApplyOperations(doc, []Operation{
+		{Op: "add", Path: "/highlights/-", Value: json.RawMessage(`"b"`)},
+		{Op: "remove", Path: "/highlights/5"},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "operation 1 (remove /highlights/5)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(doc) != `{"highlights":["a"]}` {
+		t.Errorf("document was modified: %s", doc)
+	}
+}
@@ -0,0 +1,386 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/kbimport"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Export converts the KB profile to a JSON Resume document. Work is listed
+// newest first; other sections keep the KB's order. Data preserved by an
+// earlier import is merged back in, and the latest summary context entry
+// becomes basics.summary.
+func Export(entries []*models.KBEntry) ([]byte, error) {
+	ex := &exporter{preserved: make(map[string]preserved)}
+	var contact *models.ContactData
+	var skills *models.SkillsData
+	var summaryEntry *models.KBEntry
+
+	for _, e := range entries {
+		switch e.Type {
+		case models.KBTypeContext:
+			switch e.Category {
+			case PreservedCategory:
+				var p preserved
+				if err := json.Unmarshal([]byte(e.Content), &p); err == nil && p.Section != "" {
+					ex.preserved[p.Section] = p
+				}
+			case SummaryCategory:
+				if summaryEntry == nil || !e.UpdatedAt.Before(summaryEntry.UpdatedAt) {
+					summaryEntry = e
+				}
+			}
+			continue
+		case models.KBTypeProfile:
+		default:
+			continue
+		}
+
+		ok := true
+		switch models.ProfileCategory(e.Category) {
+		case models.CategoryContact:
+			if contact == nil {
+				var c models.ContactData
+				c, ok = e.AsContact()
+				contact = &c
+			}
+		case models.CategoryExperience:
+			var exp models.ExperienceEntry
+			exp, ok = e.AsExperience()
+			ex.work = append(ex.work, exp)
+		case models.CategoryEducation:
+			var edu models.EducationEntry
+			edu, ok = e.AsEducation()
+			ex.education = append(ex.education, edu)
+		case models.CategorySkills:
+			if skills == nil {
+				var s models.SkillsData
+				s, ok = e.AsSkills()
+				skills = &s
+			}
+		case models.CategoryCertifications:
+			var cert models.CertificationEntry
+			cert, ok = e.AsCertification()
+			ex.certificates = append(ex.certificates, cert)
+		case models.CategoryLanguages:
+			var lang models.LanguageEntry
+			lang, ok = e.AsLanguage()
+			ex.languages = append(ex.languages, lang)
+		}
+		if !ok {
+			return nil, fmt.Errorf("entry %s: can't read its %s data", e.ID, e.Category)
+		}
+	}
+
+	summary := ""
+	if summaryEntry != nil {
+		summary = summaryEntry.Content
+	}
+
+	doc := object{}
+	doc.set("$schema", SchemaURL)
+	sections := map[string]any{
+		"basics":       ex.basics(contact, summary),
+		"work":         ex.workSection(),
+		"education":    ex.educationSection(),
+		"skills":       ex.skillsSection(skills),
+		"certificates": ex.certificatesSection(),
+		"languages":    ex.languagesSection(),
+	}
+	for _, section := range sectionOrder {
+		if value, ok := sections[section]; ok {
+			doc.set(section, value)
+		} else if p, ok := ex.preserved[section]; ok {
+			doc.set(section, p.Data)
+		}
+	}
+
+	// Sections from newer or extended schemas come last
+	var others []string
+	for section := range ex.preserved {
+		if sectionRank(section) == len(sectionOrder) {
+			others = append(others, section)
+		}
+	}
+	sort.Strings(others)
+	for _, section := range others {
+		doc.set(section, ex.preserved[section].Data)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type exporter struct {
+	preserved    map[string]preserved
+	work         []models.ExperienceEntry
+	education    []models.EducationEntry
+	certificates []models.CertificationEntry
+	languages    []models.LanguageEntry
+}
+
+// fields returns the preserved fields of a section's entry
+func (ex *exporter) fields(section, key string) map[string]json.RawMessage {
+	for _, it := range ex.preserved[section].Items {
+		if it.Key == key {
+			return it.Fields
+		}
+	}
+	return nil
+}
+
+// list builds a section array from the KB entries, followed by entries an
+// import could not map. It returns nil for an empty section.
+func (ex *exporter) list(section string, items []any) any {
+	var invalid []json.RawMessage
+	json.Unmarshal(ex.preserved[section].Data, &invalid)
+	for _, it := range invalid {
+		items = append(items, it)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+func (ex *exporter) basics(c *models.ContactData, summary string) any {
+	if c == nil {
+		if p, ok := ex.preserved["basics"]; ok && len(p.Data) > 0 {
+			return p.Data
+		}
+		if summary == "" {
+			return nil
+		}
+		c = &models.ContactData{}
+	}
+	fields := ex.fields("basics", "")
+
+	b := object{}
+	b.set("name", c.Name)
+	b.set("label", fields["label"])
+	b.set("image", fields["image"])
+	b.set("email", c.Email)
+	b.set("phone", c.Phone)
+	b.set("url", c.Website)
+	b.set("summary", summary)
+
+	if c.Location != "" {
+		var loc item
+		if json.Unmarshal(fields["location"], &loc) == nil && formatLocation(loc) == c.Location {
+			b.set("location", fields["location"])
+		} else {
+			b.set("location", object{{"city", c.Location}})
+		}
+	}
+
+	// Keep preserved profiles, with LinkedIn and GitHub updated from the KB
+	var profiles []any
+	var preservedProfiles []item
+	json.Unmarshal(fields["profiles"], &preservedProfiles)
+	urls := map[string]string{"linkedin": c.LinkedIn, "github": c.GitHub}
+	seen := make(map[string]bool)
+	for _, p := range preservedProfiles {
+		network := strings.ToLower(p.str("network"))
+		if url, ok := urls[network]; ok {
+			seen[network] = true
+			if url == "" {
+				continue
+			}
+			if profileURL(p) != url {
+				p["url"], _ = json.Marshal(url)
+				delete(p, "username")
+			}
+		}
+		profiles = append(profiles, p)
+	}
+	for _, n := range []struct{ key, name string }{{"linkedin", "LinkedIn"}, {"github", "GitHub"}} {
+		if !seen[n.key] && urls[n.key] != "" {
+			profiles = append(profiles, object{{"network", n.name}, {"url", urls[n.key]}})
+		}
+	}
+	if len(profiles) > 0 {
+		b.set("profiles", profiles)
+	}
+
+	extra := make(map[string]json.RawMessage)
+	for k, v := range fields {
+		if k != "location" && k != "profiles" {
+			extra[k] = v
+		}
+	}
+	b.setExtra(extra)
+	return b
+}
+
+func (ex *exporter) workSection() any {
+	work := append([]models.ExperienceEntry(nil), ex.work...)
+	sort.SliceStable(work, func(i, j int) bool {
+		return work[j].StartDate.Before(work[i].StartDate)
+	})
+
+	var items []any
+	for _, e := range work {
+		fields := ex.fields("work", kbimport.ExperienceKey(e))
+		o := object{}
+		o.set("name", e.Company)
+		o.set("position", e.Role)
+		o.set("location", e.Location)
+		o.set("startDate", exportDate(e.StartDate, fields["startDate"]))
+		if !e.EndDate.IsPresent() {
+			o.set("endDate", exportDate(e.EndDate, fields["endDate"]))
+		}
+		o.set("summary", e.Description)
+		o.set("highlights", e.Highlights)
+		o.setExtra(withoutKeys(fields, "startDate", "endDate"))
+		items = append(items, o)
+	}
+	return ex.list("work", items)
+}
+
+func (ex *exporter) educationSection() any {
+	var items []any
+	for _, e := range ex.education {
+		fields := ex.fields("education", kbimport.EducationKey(e))
+		o := object{}
+		o.set("institution", e.Institution)
+		o.set("area", e.Field)
+		o.set("studyType", e.Degree)
+		o.set("startDate", exportDate(e.StartDate, fields["startDate"]))
+		o.set("endDate", exportDate(e.EndDate, fields["endDate"]))
+		o.set("score", e.GPA)
+		o.setExtra(withoutKeys(fields, "startDate", "endDate"))
+		items = append(items, o)
+	}
+	return ex.list("education", items)
+}
+
+func (ex *exporter) skillsSection(s *models.SkillsData) any {
+	if s == nil {
+		return nil
+	}
+	byKey := make(map[string]map[string]json.RawMessage)
+	var groups []item
+	for _, it := range ex.preserved["skills"].Items {
+		if it.Key == "" {
+			groups = append(groups, it.Fields)
+		} else {
+			byKey[it.Key] = it.Fields
+		}
+	}
+
+	var items []any
+	category := func(key, name string, keywords []string) {
+		if len(keywords) == 0 {
+			return
+		}
+		fields := byKey[key]
+		o := object{}
+		o.set("name", name)
+		if raw, ok := fields["name"]; ok {
+			o.set("name", raw)
+		}
+		o.set("level", fields["level"])
+		o.set("keywords", keywords)
+		o.setExtra(fields)
+		items = append(items, o)
+	}
+	for _, c := range skillCategories {
+		if c.key != "other" {
+			category(c.key, c.names[0], *c.field(s))
+		}
+	}
+
+	// Regroup "other" skills into the groups they were imported from
+	other := append([]string(nil), s.Other...)
+	take := func(skill string) bool {
+		for i, o := range other {
+			if strings.EqualFold(o, skill) {
+				other = append(other[:i], other[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	for _, g := range groups {
+		o := object{}
+		if keywords := g.strs("keywords"); len(keywords) > 0 {
+			var present []string
+			for _, k := range keywords {
+				if take(k) {
+					present = append(present, k)
+				}
+			}
+			if len(present) == 0 {
+				continue
+			}
+			o.set("name", g["name"])
+			o.set("level", g["level"])
+			o.set("keywords", present)
+		} else if take(g.str("name")) {
+			o.set("name", g["name"])
+			o.set("level", g["level"])
+		} else {
+			continue
+		}
+		o.setExtra(g)
+		items = append(items, o)
+	}
+	category("other", "Other", other)
+
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+func (ex *exporter) certificatesSection() any {
+	var items []any
+	for _, c := range ex.certificates {
+		fields := ex.fields("certificates", kbimport.CertificationKey(c))
+		o := object{}
+		o.set("name", c.Name)
+		o.set("date", exportDate(c.Date, fields["date"]))
+		o.set("issuer", c.Issuer)
+		o.set("expiryDate", exportDate(c.ExpiryDate, fields["expiryDate"]))
+		o.set("credentialId", c.CredentialID)
+		o.setExtra(withoutKeys(fields, "date", "expiryDate"))
+		items = append(items, o)
+	}
+	return ex.list("certificates", items)
+}
+
+func (ex *exporter) languagesSection() any {
+	var items []any
+	for _, l := range ex.languages {
+		o := object{}
+		o.set("language", l.Language)
+		o.set("fluency", l.Proficiency)
+		o.setExtra(ex.fields("languages", kbimport.LanguageKey(l)))
+		items = append(items, o)
+	}
+	return ex.list("languages", items)
+}
+
+// exportDate returns the KB date, or the full date it was imported from if
+// the KB date hasn't changed since
+func exportDate(date models.Date, original json.RawMessage) string {
+	var full string
+	json.Unmarshal(original, &full)
+	if full != "" && kbDate(full) == date {
+		return full
+	}
+	return string(date)
+}
+
+func withoutKeys(fields map[string]json.RawMessage, keys ...string) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage)
+	for k, v := range fields {
+		if !contains(keys, k) {
+			out[k] = v
+		}
+	}
+	return out
+}
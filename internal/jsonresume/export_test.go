@@ -0,0 +1,155 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	entries, _, err := Import(nil, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	out, err := Export(entries)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var got, want any
+	json.Unmarshal(out, &got)
+	json.Unmarshal([]byte(sampleResume), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the document:\n%s", out)
+	}
+
+	// Sections follow the schema's order
+	if i, j := strings.Index(string(out), `"work"`), strings.Index(string(out), `"volunteer"`); i < 0 || j < i {
+		t.Errorf("sections out of order:\n%s", out)
+	}
+}
+
+func TestExportFromKB(t *testing.T) {
+	entries := []*models.KBEntry{
+		models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane Doe", Email: "jane@example.com", Location: "Berlin", LinkedIn: "linkedin.com/in/jane"}, "user"),
+		models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{Company: "Old Co", Role: "Developer", StartDate: "2016-03", EndDate: "2019-12"}, "user"),
+		models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2020-01", EndDate: "present", Highlights: []string{"Shipped"}}, "user"),
+		models.NewProfileEntry(models.CategorySkills, models.SkillsData{Languages: []string{"Go"}, Other: []string{"Leadership"}}, "user"),
+		models.NewProfileEntry(models.CategoryCertifications, models.CertificationEntry{Name: "CKA", ExpiryDate: "2027-06", CredentialID: "X1"}, "user"),
+		models.NewContextEntry("notes", "Not exported", "user"),
+	}
+
+	out, err := Export(entries)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	expected := `{
+  "$schema": "` + SchemaURL + `",
+  "basics": {
+    "name": "Jane Doe",
+    "email": "jane@example.com",
+    "location": {
+      "city": "Berlin"
+    },
+    "profiles": [
+      {
+        "network": "LinkedIn",
+        "url": "linkedin.com/in/jane"
+      }
+    ]
+  },
+  "work": [
+    {
+      "name": "Acme",
+      "position": "Engineer",
+      "startDate": "2020-01",
+      "highlights": [
+        "Shipped"
+      ]
+    },
+    {
+      "name": "Old Co",
+      "position": "Developer",
+      "startDate": "2016-03",
+      "endDate": "2019-12"
+    }
+  ],
+  "certificates": [
+    {
+      "name": "CKA",
+      "expiryDate": "2027-06",
+      "credentialId": "X1"
+    }
+  ],
+  "skills": [
+    {
+      "name": "Languages",
+      "keywords": [
+        "Go"
+      ]
+    },
+    {
+      "name": "Other",
+      "keywords": [
+        "Leadership"
+      ]
+    }
+  ]
+}`
+	if string(out) != expected {
+		t.Errorf("Export =\n%s\nwant\n%s", out, expected)
+	}
+
+	// Importing the export into an empty KB gives the same profile
+	imported, _, err := Import(nil, out)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	var profile []*models.KBEntry
+	for _, e := range imported {
+		if e.Type == models.KBTypeProfile {
+			profile = append(profile, e)
+		}
+	}
+	if len(profile) != 5 {
+		t.Fatalf("got %d profile entries, want 5", len(profile))
+	}
+	again, _ := Export(imported)
+	if string(again) != expected {
+		t.Errorf("export of the imported KB differs:\n%s", again)
+	}
+}
+
+func TestExportUsesKBChanges(t *testing.T) {
+	entries, _, err := Import(nil, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	for _, e := range entries {
+		if e.Category == string(models.CategoryContact) {
+			e.Data = models.ContactData{Name: "Jane Doe", Email: "jane@example.com", Location: "Munich", GitHub: "https://github.com/jane2"}
+		}
+	}
+
+	out, _ := Export(entries)
+	var doc struct {
+		Basics struct {
+			Location map[string]string `json:"location"`
+			Profiles []map[string]string
+		} `json:"basics"`
+	}
+	json.Unmarshal(out, &doc)
+	if !reflect.DeepEqual(doc.Basics.Location, map[string]string{"city": "Munich"}) {
+		t.Errorf("location = %v, want the KB's", doc.Basics.Location)
+	}
+	want := []map[string]string{
+		{"network": "GitHub", "url": "https://github.com/jane2"},
+		{"network": "Mastodon", "url": "https://hachyderm.io/@jane"},
+	}
+	if !reflect.DeepEqual(doc.Basics.Profiles, want) {
+		t.Errorf("profiles = %v, want %v", doc.Basics.Profiles, want)
+	}
+}
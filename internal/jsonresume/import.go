@@ -0,0 +1,407 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/kbimport"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Import merges a JSON Resume document into the KB entries and returns the
+// new list. Entries already in the KB are matched and updated as
+// kbimport.Merger matches them, so importing the same file again changes
+// nothing. Records that could not become KB entries are listed in the
+// result's Skipped, and their data is preserved so an export still includes
+// them. Fields the KB has no place for, and sections it has
+// no category for, are kept in PreservedCategory context entries.
+func Import(entries []*models.KBEntry, data []byte) ([]*models.KBEntry, *kbimport.Result, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON Resume: %w", err)
+	}
+
+	sections := make([]string, 0, len(doc))
+	for key := range doc {
+		sections = append(sections, key)
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sectionRank(sections[i]) < sectionRank(sections[j]) ||
+			sectionRank(sections[i]) == sectionRank(sections[j]) && sections[i] < sections[j]
+	})
+
+	im := &importer{kbimport.NewMerger(entries, Source)}
+	for _, section := range sections {
+		raw := doc[section]
+		var err error
+		switch section {
+		case "$schema":
+			continue
+		case "basics":
+			err = im.basics(raw)
+		case "work":
+			err = im.work(raw)
+		case "education":
+			err = im.education(raw)
+		case "skills":
+			err = im.skills(raw)
+		case "certificates":
+			err = im.certificates(raw)
+		case "languages":
+			err = im.languages(raw)
+		default:
+			im.preserve(section, nil, raw)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", section, err)
+		}
+	}
+	return im.Entries(), im.Result(), nil
+}
+
+// sectionRank orders known sections by the schema, then everything else
+func sectionRank(section string) int {
+	for i, s := range sectionOrder {
+		if s == section {
+			return i
+		}
+	}
+	return len(sectionOrder)
+}
+
+type importer struct {
+	*kbimport.Merger
+}
+
+func (im *importer) basics(raw json.RawMessage) error {
+	var b item
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return err
+	}
+
+	imported := models.ContactData{
+		Name:    b.str("name"),
+		Email:   b.str("email"),
+		Phone:   b.str("phone"),
+		Website: b.str("url"),
+	}
+	var loc item
+	json.Unmarshal(b["location"], &loc)
+	imported.Location = formatLocation(loc)
+	var profiles []item
+	json.Unmarshal(b["profiles"], &profiles)
+	for _, p := range profiles {
+		switch strings.ToLower(p.str("network")) {
+		case "linkedin":
+			imported.LinkedIn = profileURL(p)
+		case "github":
+			imported.GitHub = profileURL(p)
+		}
+	}
+
+	// Fill in the existing contact rather than replacing it, so fields the
+	// file leaves out are kept
+	var contact models.ContactData
+	if existing := im.Singleton(models.CategoryContact); existing != nil {
+		contact, _ = existing.AsContact()
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&contact.Name, imported.Name}, {&contact.Email, imported.Email}, {&contact.Phone, imported.Phone},
+		{&contact.Location, imported.Location}, {&contact.LinkedIn, imported.LinkedIn},
+		{&contact.GitHub, imported.GitHub}, {&contact.Website, imported.Website},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if err := contact.Validate(); err != nil {
+		im.Skip(fmt.Sprintf("basics: %v", err))
+		im.preserve("basics", nil, raw)
+	} else {
+		im.SetSingleton(models.CategoryContact, contact)
+		// Location and profiles are kept whole, so details like the postal
+		// code or a profile's username come back on export
+		fields := b.without("name", "email", "phone", "url", "summary")
+		im.preserve("basics", itemsOf("", fields), nil)
+	}
+
+	if summary := b.str("summary"); summary != "" {
+		im.Summary(SummaryCategory, summary)
+	}
+	return nil
+}
+
+// profileURL returns a profile's URL, building it from the username for
+// the networks the KB stores
+func profileURL(p item) string {
+	if url := p.str("url"); url != "" {
+		return url
+	}
+	username := p.str("username")
+	if username == "" {
+		return ""
+	}
+	switch strings.ToLower(p.str("network")) {
+	case "linkedin":
+		return "https://www.linkedin.com/in/" + username
+	case "github":
+		return "https://github.com/" + username
+	}
+	return ""
+}
+
+func (im *importer) work(raw json.RawMessage) error {
+	var items []item
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+
+	var imported []models.ExperienceEntry
+	for _, it := range items {
+		imported = append(imported, workExperience(it))
+	}
+
+	var kept []preservedItem
+	var invalid []item
+	for _, it := range items {
+		exp := workExperience(it)
+		if err := exp.Validate(); err != nil {
+			im.Skip(fmt.Sprintf("work %q: %v", exp.Company+" "+exp.Role, err))
+			invalid = append(invalid, it)
+			continue
+		}
+
+		im.Experience(exp, imported)
+		key := kbimport.ExperienceKey(exp)
+		fields := it.without("name", "company", "position", "startDate", "endDate", "location", "summary", "highlights")
+		keepShortenedDates(fields, it, "startDate", "endDate")
+		kept = append(kept, itemsOf(key, fields)...)
+	}
+	im.preserve("work", kept, marshalInvalid(invalid))
+	return nil
+}
+
+// workExperience maps a work item to an experience entry
+func workExperience(it item) models.ExperienceEntry {
+	exp := models.ExperienceEntry{
+		Company:     it.str("name"),
+		Role:        it.str("position"),
+		StartDate:   kbDate(it.str("startDate")),
+		EndDate:     kbDate(it.str("endDate")),
+		Location:    it.str("location"),
+		Description: it.str("summary"),
+		Highlights:  it.strs("highlights"),
+	}
+	if exp.Company == "" {
+		// Older versions of the schema call it "company"
+		exp.Company = it.str("company")
+	}
+	return exp
+}
+
+func (im *importer) education(raw json.RawMessage) error {
+	var items []item
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+
+	var kept []preservedItem
+	var invalid []item
+	for _, it := range items {
+		edu := models.EducationEntry{
+			Institution: it.str("institution"),
+			Degree:      it.str("studyType"),
+			Field:       it.str("area"),
+			StartDate:   kbDate(it.str("startDate")),
+			EndDate:     kbDate(it.str("endDate")),
+			GPA:         it.str("score"),
+		}
+		if err := edu.Validate(); err != nil {
+			im.Skip(fmt.Sprintf("education %q: %v", edu.Institution, err))
+			invalid = append(invalid, it)
+			continue
+		}
+
+		im.Education(edu)
+		key := kbimport.EducationKey(edu)
+		fields := it.without("institution", "studyType", "area", "startDate", "endDate", "score")
+		keepShortenedDates(fields, it, "startDate", "endDate")
+		kept = append(kept, itemsOf(key, fields)...)
+	}
+	im.preserve("education", kept, marshalInvalid(invalid))
+	return nil
+}
+
+func (im *importer) skills(raw json.RawMessage) error {
+	var groups []item
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		return err
+	}
+
+	var skills models.SkillsData
+	if existing := im.Singleton(models.CategorySkills); existing != nil {
+		skills, _ = existing.AsSkills()
+	}
+
+	var kept []preservedItem
+	for _, g := range groups {
+		name := g.str("name")
+		keywords := g.strs("keywords")
+		if i := skillCategory(name); i >= 0 {
+			category := skillCategories[i]
+			addSkills(category.field(&skills), keywords)
+			fields := g.without("name", "keywords")
+			if name != category.names[0] {
+				fields["name"] = g["name"]
+			}
+			kept = append(kept, itemsOf(category.key, fields)...)
+			continue
+		}
+
+		// Groups the KB has no category for, like "Web Development" or a
+		// single skill with a level, go to "other". The group is kept so the
+		// export can regroup them.
+		if len(keywords) == 0 && name != "" {
+			keywords = []string{name}
+		}
+		addSkills(&skills.Other, keywords)
+		if name != "" {
+			kept = append(kept, preservedItem{Fields: g})
+		}
+	}
+
+	if !skills.IsEmpty() {
+		im.SetSingleton(models.CategorySkills, skills)
+	}
+	im.preserve("skills", kept, nil)
+	return nil
+}
+
+// addSkills appends the skills not already in list, ignoring case
+func addSkills(list *[]string, skills []string) {
+	for _, s := range skills {
+		found := false
+		for _, existing := range *list {
+			if strings.EqualFold(existing, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*list = append(*list, s)
+		}
+	}
+}
+
+func (im *importer) certificates(raw json.RawMessage) error {
+	var items []item
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+
+	var kept []preservedItem
+	var invalid []item
+	for _, it := range items {
+		cert := models.CertificationEntry{
+			Name:         it.str("name"),
+			Issuer:       it.str("issuer"),
+			Date:         kbDate(it.str("date")),
+			ExpiryDate:   kbDate(it.str("expiryDate")),
+			CredentialID: it.str("credentialId"),
+		}
+		if err := cert.Validate(); err != nil {
+			im.Skip(fmt.Sprintf("certificates: %v", err))
+			invalid = append(invalid, it)
+			continue
+		}
+
+		im.Certification(cert)
+		key := kbimport.CertificationKey(cert)
+		fields := it.without("name", "issuer", "date", "expiryDate", "credentialId")
+		keepShortenedDates(fields, it, "date", "expiryDate")
+		kept = append(kept, itemsOf(key, fields)...)
+	}
+	im.preserve("certificates", kept, marshalInvalid(invalid))
+	return nil
+}
+
+func (im *importer) languages(raw json.RawMessage) error {
+	var items []item
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+
+	var kept []preservedItem
+	var invalid []item
+	for _, it := range items {
+		lang := models.LanguageEntry{Language: it.str("language"), Proficiency: it.str("fluency")}
+		if err := lang.Validate(); err != nil {
+			im.Skip(fmt.Sprintf("languages: %v", err))
+			invalid = append(invalid, it)
+			continue
+		}
+
+		im.Language(lang)
+		key := kbimport.LanguageKey(lang)
+		kept = append(kept, itemsOf(key, it.without("language", "fluency"))...)
+	}
+	im.preserve("languages", kept, marshalInvalid(invalid))
+	return nil
+}
+
+// keepShortenedDates keeps the original of dates kbDate shortened, so the
+// day comes back on export
+func keepShortenedDates(fields map[string]json.RawMessage, it item, keys ...string) {
+	for _, key := range keys {
+		if date := it.str(key); string(kbDate(date)) != date {
+			fields[key] = it[key]
+		}
+	}
+}
+
+func itemsOf(key string, fields map[string]json.RawMessage) []preservedItem {
+	if len(fields) == 0 {
+		return nil
+	}
+	return []preservedItem{{Key: key, Fields: fields}}
+}
+
+func marshalInvalid(items []item) json.RawMessage {
+	if len(items) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(items)
+	return data
+}
+
+// preserve stores a section's unmapped data in its PreservedCategory entry,
+// replacing what an earlier import stored
+func (im *importer) preserve(section string, items []preservedItem, data json.RawMessage) {
+	var previous *models.KBEntry
+	for _, e := range im.Entries() {
+		if e.Type == models.KBTypeContext && e.Category == PreservedCategory && preservedSection(e) == section {
+			previous = e
+			break
+		}
+	}
+
+	if len(items) == 0 && len(data) == 0 {
+		if previous != nil {
+			im.Remove(previous)
+		}
+		return
+	}
+	content, _ := json.Marshal(preserved{Section: section, Items: items, Data: data})
+	im.SetContext(previous, PreservedCategory, string(content))
+}
+
+func preservedSection(e *models.KBEntry) string {
+	var p preserved
+	json.Unmarshal([]byte(e.Content), &p)
+	return p.Section
+}
@@ -0,0 +1,249 @@
+package jsonresume
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+const sampleResume = `{
+  "$schema": "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json",
+  "basics": {
+    "name": "Jane Doe",
+    "label": "Backend Engineer",
+    "email": "jane@example.com",
+    "phone": "+49 123",
+    "url": "https://jane.dev",
+    "summary": "Backend engineer focused on distributed systems.",
+    "location": {"city": "Berlin", "countryCode": "DE", "postalCode": "10115"},
+    "profiles": [
+      {"network": "LinkedIn", "username": "janedoe"},
+      {"network": "GitHub", "url": "https://github.com/janedoe"},
+      {"network": "Mastodon", "url": "https://hachyderm.io/@jane"}
+    ]
+  },
+  "work": [
+    {
+      "name": "Acme",
+      "position": "Senior Engineer",
+      "url": "https://acme.example",
+      "startDate": "2020-01-15",
+      "summary": "Payments platform.",
+      "highlights": ["Cut latency by 40%", "Led a team of 4"]
+    },
+    {
+      "name": "Old Co",
+      "position": "Developer",
+      "location": "Hamburg",
+      "startDate": "2016-03",
+      "endDate": "2019-12"
+    }
+  ],
+  "volunteer": [{"organization": "Code Club", "position": "Mentor"}],
+  "education": [
+    {"institution": "TU Berlin", "area": "Computer Science", "studyType": "BSc", "endDate": "2016", "courses": ["Compilers"]}
+  ],
+  "certificates": [{"name": "CKA", "issuer": "CNCF", "date": "2021-06-01", "url": "https://cncf.io/cka"}],
+  "skills": [
+    {"name": "Programming Languages", "level": "Expert", "keywords": ["Go", "Python"]},
+    {"name": "Web Development", "keywords": ["HTML", "CSS"]},
+    {"name": "Kubernetes", "level": "Advanced"}
+  ],
+  "languages": [{"language": "English", "fluency": "Native speaker"}],
+  "projects": [{"name": "bragger", "description": "Job search CLI"}]
+}`
+
+func TestImport(t *testing.T) {
+	entries, result, err := Import(nil, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(result.Skipped) != 0 {
+		t.Errorf("unexpected skips: %v", result.Skipped)
+	}
+
+	byCategory := make(map[string][]*models.KBEntry)
+	for _, e := range entries {
+		byCategory[string(e.Type)+"/"+e.Category] = append(byCategory[string(e.Type)+"/"+e.Category], e)
+		if e.Source != Source {
+			t.Errorf("entry %s has source %q", e.ID, e.Source)
+		}
+	}
+
+	contact, _ := byCategory["profile/contact"][0].AsContact()
+	want := models.ContactData{
+		Name: "Jane Doe", Email: "jane@example.com", Phone: "+49 123", Location: "Berlin, DE",
+		LinkedIn: "https://www.linkedin.com/in/janedoe", GitHub: "https://github.com/janedoe", Website: "https://jane.dev",
+	}
+	if contact != want {
+		t.Errorf("contact = %+v, want %+v", contact, want)
+	}
+
+	if n := len(byCategory["profile/experience"]); n != 2 {
+		t.Fatalf("got %d experience entries, want 2", n)
+	}
+	exp, _ := byCategory["profile/experience"][0].AsExperience()
+	if exp.Company != "Acme" || exp.StartDate != "2020-01" || exp.EndDate != "" || exp.Description != "Payments platform." || len(exp.Highlights) != 2 {
+		t.Errorf("unexpected experience: %+v", exp)
+	}
+
+	skills, _ := byCategory["profile/skills"][0].AsSkills()
+	if strings.Join(skills.Languages, ",") != "Go,Python" || strings.Join(skills.Other, ",") != "HTML,CSS,Kubernetes" {
+		t.Errorf("unexpected skills: %+v", skills)
+	}
+
+	edu, _ := byCategory["profile/education"][0].AsEducation()
+	if edu.Degree != "BSc" || edu.Field != "Computer Science" || edu.EndDate != "2016" {
+		t.Errorf("unexpected education: %+v", edu)
+	}
+
+	if s := byCategory["context/summary"]; len(s) != 1 || s[0].Content != "Backend engineer focused on distributed systems." {
+		t.Errorf("summary not imported as context: %v", s)
+	}
+
+	// basics, work, volunteer, education, certificates, skills and projects
+	// have data the KB has no field for
+	sections := make(map[string]bool)
+	for _, e := range byCategory["context/"+PreservedCategory] {
+		sections[preservedSection(e)] = true
+	}
+	for _, s := range []string{"basics", "work", "volunteer", "education", "certificates", "skills", "projects"} {
+		if !sections[s] {
+			t.Errorf("no preserved entry for %s", s)
+		}
+	}
+	if sections["languages"] {
+		t.Error("languages map completely and need no preserved entry")
+	}
+
+	if added, updated := result.Counts(); added != len(entries) || updated != 0 {
+		t.Errorf("result = %+v for %d entries", result, len(entries))
+	}
+}
+
+func TestImportIdempotent(t *testing.T) {
+	entries, _, err := Import(nil, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	count := len(entries)
+
+	entries, result, err := Import(entries, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("second Import failed: %v", err)
+	}
+	if len(entries) != count {
+		t.Errorf("re-import changed the entry count from %d to %d", count, len(entries))
+	}
+	if added, updated := result.Counts(); added != 0 || updated != 0 || result.Unchanged != count {
+		t.Errorf("result = %+v, want %d unchanged", result, count)
+	}
+
+	// A changed highlight updates the entry in place
+	changed := strings.Replace(sampleResume, "Led a team of 4", "Led a team of 5", 1)
+	entries, result, err = Import(entries, []byte(changed))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if added, updated := result.Counts(); len(entries) != count || updated != 1 || added != 0 {
+		t.Errorf("result = %+v with %d entries, want 1 update", result, len(entries))
+	}
+}
+
+func TestImportChangedTitle(t *testing.T) {
+	entries, _, err := Import(nil, []byte(sampleResume))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	count := len(entries)
+
+	// A corrected title updates the experience at the same company and start
+	changed := strings.Replace(sampleResume, `"position": "Senior Engineer"`, `"position": "Staff Engineer"`, 1)
+	entries, result, err := Import(entries, []byte(changed))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if added, updated := result.Counts(); len(entries) != count || added != 0 || updated != 2 {
+		t.Fatalf("result = %+v with %d entries, want the experience and its preserved fields updated", result, len(entries))
+	}
+	var roles []string
+	for _, e := range entries {
+		if exp, ok := e.AsExperience(); ok && e.Category == string(models.CategoryExperience) {
+			roles = append(roles, exp.Role)
+		}
+	}
+	if strings.Join(roles, ",") != "Staff Engineer,Developer" {
+		t.Errorf("roles = %q", roles)
+	}
+
+	// Two roles starting the same month at one company are told apart by
+	// title
+	twoRoles := `{"work": [
+  {"name": "Acme", "position": "Engineer", "startDate": "2020-01"},
+  {"name": "Acme", "position": "On-call Lead", "startDate": "2020-01"}
+]}`
+	entries, result, err = Import(nil, []byte(twoRoles))
+	if added, _ := result.Counts(); err != nil || added != 2 {
+		t.Fatalf("result = %+v, %v, want both roles added", result, err)
+	}
+	if _, result, _ = Import(entries, []byte(twoRoles)); len(result.Changes) != 0 {
+		t.Errorf("result = %+v, want the re-import unchanged", result)
+	}
+}
+
+func TestImportKeepsExistingEntries(t *testing.T) {
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane Doe", Email: "old@example.com", GitHub: "https://github.com/jd"}, "user")
+	exp := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{Company: "ACME", Role: "senior engineer", StartDate: "2020-01"}, "user")
+	note := models.NewContextEntry("notes", "Prefers remote", "user")
+
+	entries, result, err := Import([]*models.KBEntry{contact, exp, note}, []byte(`{
+  "basics": {"name": "Jane Doe", "email": "jane@example.com"},
+  "work": [{"name": "Acme", "position": "Senior Engineer", "startDate": "2020-01-15", "highlights": ["Shipped"]}]
+}`))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if _, updated := result.Counts(); len(entries) != 4 || updated != 2 {
+		t.Fatalf("result = %+v with %d entries, want both entries updated plus preserved work dates", result, len(entries))
+	}
+
+	c, _ := entries[0].AsContact()
+	if c.Email != "jane@example.com" || c.GitHub != "https://github.com/jd" {
+		t.Errorf("contact should take new fields and keep the rest: %+v", c)
+	}
+	if entries[0].ID != contact.ID || entries[1].ID != exp.ID || entries[2] != note {
+		t.Error("existing entries should be updated in place")
+	}
+}
+
+func TestImportSkipsInvalidEntries(t *testing.T) {
+	entries, result, err := Import(nil, []byte(`{
+  "basics": {"name": "Jane Doe"},
+  "work": [{"name": "Acme", "position": "Engineer"}]
+}`))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(result.Skipped) != 2 || !strings.Contains(result.Skipped[0], "email is required") || !strings.Contains(result.Skipped[1], "start_date is required") {
+		t.Errorf("Skipped = %q", result.Skipped)
+	}
+	// Nothing becomes a profile entry, but both are preserved
+	for _, e := range entries {
+		if e.Type == models.KBTypeProfile {
+			t.Errorf("unexpected profile entry %+v", e)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d entries, want 2 preserved sections", len(entries))
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	if _, _, err := Import(nil, []byte(`not json`)); err == nil || !strings.Contains(err.Error(), "invalid JSON Resume") {
+		t.Errorf("expected invalid JSON error, got %v", err)
+	}
+	if _, _, err := Import(nil, []byte(`{"work": {"name": "Acme"}}`)); err == nil || !strings.Contains(err.Error(), "work:") {
+		t.Errorf("expected section error, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package jsonresume
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Source is recorded on KB entries created by an import
+const Source = "jsonresume"
+
+// PreservedCategory is the context category holding JSON Resume data the KB
+// has no field for. There is one entry per section, so an export can put the
+// data back.
+const PreservedCategory = "jsonresume"
+
+// SummaryCategory is the context category basics.summary is imported as
+const SummaryCategory = "summary"
+
+// SchemaURL is written as "$schema" on export
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// sectionOrder is the order of the top-level sections in the schema
+var sectionOrder = []string{
+	"basics", "work", "volunteer", "education", "awards", "certificates",
+	"publications", "skills", "languages", "interests", "references", "projects", "meta",
+}
+
+// preserved is the content of a PreservedCategory entry
+type preserved struct {
+	Section string `json:"section"`
+	// Items are the unmapped fields of entries that were imported, matched
+	// back to the KB entry by key on export
+	Items []preservedItem `json:"items,omitempty"`
+	// Data is a section with no KB category, or the entries of a mapped
+	// section that could not be imported, exported as is
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type preservedItem struct {
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// item is a JSON Resume object with its fields left undecoded, so fields the
+// KB doesn't map survive unchanged
+type item map[string]json.RawMessage
+
+// str returns a string field, or "" when it is missing or not a string
+func (it item) str(key string) string {
+	var s string
+	json.Unmarshal(it[key], &s)
+	return strings.TrimSpace(s)
+}
+
+// strs returns a string array field, skipping non-string values
+func (it item) strs(key string) []string {
+	var raw []any
+	json.Unmarshal(it[key], &raw)
+	var out []string
+	for _, v := range raw {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+// without returns the fields not in mapped
+func (it item) without(mapped ...string) map[string]json.RawMessage {
+	extra := make(map[string]json.RawMessage)
+	for k, v := range it {
+		if !contains(mapped, k) {
+			extra[k] = v
+		}
+	}
+	return extra
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// kbDate converts a JSON Resume date to the KB's YYYY-MM ("2020-01-15"
+// becomes "2020-01"). Years are kept, and values that aren't dates are kept
+// as they are.
+func kbDate(date string) models.Date {
+	d, err := models.ParseDate(date)
+	if err != nil {
+		return models.Date(strings.TrimSpace(date))
+	}
+	if !d.IsPresent() && d.Precision() == models.PrecisionDay {
+		return models.Date(d.Month())
+	}
+	return d
+}
+
+// formatLocation flattens a JSON Resume location into the KB's single
+// location string, e.g. "Berlin, DE"
+func formatLocation(loc item) string {
+	var parts []string
+	for _, key := range []string{"city", "region", "countryCode"} {
+		if s := loc.str(key); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return loc.str("address")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// skillCategories maps skill group names to the KB's skills fields. The
+// first name of each is the one used on export.
+var skillCategories = []struct {
+	key   string
+	names []string
+	field func(*models.SkillsData) *[]string
+}{
+	{"languages", []string{"Languages", "Programming Languages"}, func(s *models.SkillsData) *[]string { return &s.Languages }},
+	{"frameworks", []string{"Frameworks", "Frameworks & Libraries", "Libraries"}, func(s *models.SkillsData) *[]string { return &s.Frameworks }},
+	{"tools", []string{"Tools"}, func(s *models.SkillsData) *[]string { return &s.Tools }},
+	{"databases", []string{"Databases"}, func(s *models.SkillsData) *[]string { return &s.Databases }},
+	{"cloud", []string{"Cloud", "Cloud Platforms"}, func(s *models.SkillsData) *[]string { return &s.Cloud }},
+	{"other", []string{"Other"}, func(s *models.SkillsData) *[]string { return &s.Other }},
+}
+
+// skillCategory returns the index in skillCategories for a group name, or -1
+func skillCategory(name string) int {
+	for i, c := range skillCategories {
+		for _, n := range c.names {
+			if strings.EqualFold(n, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// object is a JSON object that keeps its fields in insertion order, so
+// exports read in schema order
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+// set adds a field, replacing any existing one with the same key. Empty and
+// nil values are skipped.
+func (o *object) set(key string, value any) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	case json.RawMessage:
+		if len(v) == 0 {
+			return
+		}
+	case nil:
+		return
+	}
+	for i := range *o {
+		if (*o)[i].key == key {
+			(*o)[i].value = value
+			return
+		}
+	}
+	*o = append(*o, field{key, value})
+}
+
+// has reports whether the object has a field
+func (o object) has(key string) bool {
+	for _, f := range o {
+		if f.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setExtra adds preserved fields in key order, without overriding fields
+// that came from the KB
+func (o *object) setExtra(fields map[string]json.RawMessage) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !o.has(k) {
+			o.set(k, fields[k])
+		}
+	}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
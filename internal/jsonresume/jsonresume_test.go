@@ -0,0 +1,72 @@
+package jsonresume
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestKBDate(t *testing.T) {
+	tests := map[string]models.Date{
+		"2020-01-15": "2020-01",
+		"2020-01":    "2020-01",
+		"2020":       "2020",
+		" 2020-01 ":  "2020-01",
+		"present":    "present",
+		"":           "",
+		"2020/1":     "2020-01",
+		"soon":       "soon",
+	}
+	for in, want := range tests {
+		if got := kbDate(in); got != want {
+			t.Errorf("kbDate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatLocation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"city": "Berlin", "countryCode": "DE", "postalCode": "10115"}`, "Berlin, DE"},
+		{`{"city": "Austin", "region": "Texas", "countryCode": "US"}`, "Austin, Texas, US"},
+		{`{"address": "Remote"}`, "Remote"},
+		{`{}`, ""},
+	}
+	for _, tt := range tests {
+		var loc item
+		json.Unmarshal([]byte(tt.in), &loc)
+		if got := formatLocation(loc); got != tt.want {
+			t.Errorf("formatLocation(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSkillCategory(t *testing.T) {
+	if i := skillCategory("programming languages"); i < 0 || skillCategories[i].key != "languages" {
+		t.Errorf("skillCategory(programming languages) = %d", i)
+	}
+	if i := skillCategory("Web Development"); i != -1 {
+		t.Errorf("skillCategory(Web Development) = %d, want -1", i)
+	}
+}
+
+func TestObject(t *testing.T) {
+	o := object{}
+	o.set("name", "Jane")
+	o.set("empty", "")
+	o.set("none", json.RawMessage(nil))
+	o.set("list", []string{"a"})
+	o.set("name", "Janet")
+	o.setExtra(map[string]json.RawMessage{"z": json.RawMessage(`1`), "name": json.RawMessage(`"ignored"`), "a": json.RawMessage(`true`)})
+
+	out, err := json.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := `{"name":"Janet","list":["a"],"a":true,"z":1}`; string(out) != want {
+		t.Errorf("object = %s, want %s", out, want)
+	}
+}
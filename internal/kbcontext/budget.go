@@ -0,0 +1,248 @@
+package kbcontext
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ewurch/bragger/internal/match"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// ItemKind is the kind of KB item that can be left out of the context
+type ItemKind string
+
+const (
+	KindHighlight ItemKind = "highlight"
+	KindContext   ItemKind = "context"
+	KindSkill     ItemKind = "skill"
+)
+
+// Item is a piece of the KB the context can do without to fit a budget:
+// one highlight of an experience entry, a context entry, or one skill
+type Item struct {
+	Kind    ItemKind `json:"kind"`
+	EntryID string   `json:"entry_id"`
+	Text    string   `json:"text"`
+	Score   float64  `json:"score"`
+}
+
+// EstimateTokens estimates the number of tokens in text, at about four
+// characters per token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Rank scores the KB items by relevance to a JD and returns them most
+// relevant first. An item scores the JD frequency of each dictionary
+// keyword it shares with the JD, plus up to 1 for recency, so without a JD,
+// or among items matching equally, newer items rank first.
+func Rank(d *match.Dictionary, jdText string, entries []*models.KBEntry, now time.Time) []Item {
+	jdCounts := d.Count(jdText)
+	score := func(text string, recency float64) float64 {
+		s := recency
+		for term := range d.Count(text) {
+			s += float64(jdCounts[term])
+		}
+		return s
+	}
+
+	var items []Item
+	for _, e := range entries {
+		switch {
+		case e.Type == models.KBTypeContext:
+			items = append(items, Item{Kind: KindContext, EntryID: e.ID, Text: e.Content,
+				Score: score(e.Content, recency(e.UpdatedAt, now))})
+		case e.Category == string(models.CategoryExperience):
+			exp, ok := e.AsExperience()
+			if !ok {
+				continue
+			}
+			ended := now
+			if exp.EndDate != "" && !exp.EndDate.IsPresent() {
+				ended, _ = exp.EndDate.Time()
+			}
+			for _, h := range exp.Highlights {
+				items = append(items, Item{Kind: KindHighlight, EntryID: e.ID, Text: h, Score: score(h, recency(ended, now))})
+			}
+		case e.Category == string(models.CategorySkills):
+			s, ok := e.AsSkills()
+			if !ok {
+				continue
+			}
+			// Skills are current, so they all get full recency
+			for _, list := range skillLists(&s) {
+				for _, skill := range *list {
+					items = append(items, Item{Kind: KindSkill, EntryID: e.ID, Text: skill, Score: score(skill, 1)})
+				}
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	return items
+}
+
+// recency is 1 for now, halving every three years before it
+func recency(t time.Time, now time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	years := now.Sub(t).Hours() / (24 * 365)
+	if years < 0 {
+		years = 0
+	}
+	return math.Pow(0.5, years/3)
+}
+
+func skillLists(s *models.SkillsData) []*[]string {
+	return []*[]string{&s.Languages, &s.Frameworks, &s.Tools, &s.Databases, &s.Cloud, &s.Other}
+}
+
+// Omit returns the entries without the items, leaving the entries passed
+// in as they are. Experience entries stay, with fewer highlights; skills
+// entries stay with fewer skills, unless none are left.
+func Omit(entries []*models.KBEntry, items []Item) []*models.KBEntry {
+	if len(items) == 0 {
+		return entries
+	}
+	type key struct {
+		kind ItemKind
+		id   string
+		text string
+	}
+	omitted := make(map[key]bool, len(items))
+	for _, it := range items {
+		omitted[key{it.Kind, it.EntryID, it.Text}] = true
+	}
+
+	out := make([]*models.KBEntry, 0, len(entries))
+	for _, e := range entries {
+		switch {
+		case e.Type == models.KBTypeContext:
+			if omitted[key{KindContext, e.ID, e.Content}] {
+				continue
+			}
+		case e.Category == string(models.CategoryExperience):
+			if exp, ok := e.AsExperience(); ok {
+				var kept []string
+				for _, h := range exp.Highlights {
+					if !omitted[key{KindHighlight, e.ID, h}] {
+						kept = append(kept, h)
+					}
+				}
+				if len(kept) != len(exp.Highlights) {
+					exp.Highlights = kept
+					copied := *e
+					copied.Data = exp
+					e = &copied
+				}
+			}
+		case e.Category == string(models.CategorySkills):
+			if s, ok := e.AsSkills(); ok {
+				changed := false
+				for _, list := range skillLists(&s) {
+					var kept []string
+					for _, skill := range *list {
+						if !omitted[key{KindSkill, e.ID, skill}] {
+							kept = append(kept, skill)
+						}
+					}
+					changed = changed || len(kept) != len(*list)
+					*list = kept
+				}
+				if changed && s.IsEmpty() {
+					continue
+				}
+				if changed {
+					copied := *e
+					copied.Data = s
+					e = &copied
+				}
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Fit leaves out the least relevant of the ranked items until the context
+// render writes for the rest fits in maxTokens. render is passed the items
+// left out, so it can note what they were; the note's cost comes off the
+// budget before choosing what to leave out, since an item is only worth
+// leaving out if it saves more than noting it costs. Fit returns the
+// context and the items left out, least relevant first; if the context
+// doesn't fit however many are left out, the smallest one is returned.
+func Fit(entries []*models.KBEntry, ranked []Item, maxTokens int, render func([]*models.KBEntry, []Item) string) (string, []Item) {
+	dropped := func(n int) []Item {
+		out := make([]Item, n)
+		for i := range out {
+			out[i] = ranked[len(ranked)-1-i]
+		}
+		return out
+	}
+	try := func(n int) string {
+		items := dropped(n)
+		return render(Omit(entries, items), items)
+	}
+
+	text := try(0)
+	if EstimateTokens(text) <= maxTokens {
+		return text, nil
+	}
+	// The note is largest when every item is left out
+	all := dropped(len(ranked))
+	rest := Omit(entries, all)
+	budget := maxTokens - (EstimateTokens(render(rest, all)) - EstimateTokens(render(rest, nil)))
+
+	smallest, smallestN := text, 0
+	for n := 1; n <= len(ranked); n++ {
+		if EstimateTokens(render(Omit(entries, dropped(n)), nil)) > budget {
+			continue
+		}
+		if text := try(n); EstimateTokens(text) <= maxTokens {
+			return text, dropped(n)
+		}
+	}
+	for n := 1; n <= len(ranked); n++ {
+		if text := try(n); EstimateTokens(text) < EstimateTokens(smallest) {
+			smallest, smallestN = text, n
+		}
+	}
+	return smallest, dropped(smallestN)
+}
+
+// Summary describes the items left out, e.g. "3 highlights, 1 context
+// entry and 2 skills"
+func Summary(items []Item) string {
+	counts := make(map[ItemKind]int)
+	for _, it := range items {
+		counts[it.Kind]++
+	}
+	var parts []string
+	for _, k := range []struct {
+		kind           ItemKind
+		single, plural string
+	}{
+		{KindHighlight, "highlight", "highlights"},
+		{KindContext, "context entry", "context entries"},
+		{KindSkill, "skill", "skills"},
+	} {
+		switch n := counts[k.kind]; n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+k.single)
+		default:
+			parts = append(parts, strconv.Itoa(n)+" "+k.plural)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return "nothing"
+	case 1:
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
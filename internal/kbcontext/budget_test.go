@@ -0,0 +1,237 @@
+package kbcontext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ewurch/bragger/internal/match"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+var now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func budgetEntries() []*models.KBEntry {
+	note := contextEntry("note", "achievement", "Organized the office move")
+	note.UpdatedAt = now.AddDate(-1, 0, 0)
+	kafka := contextEntry("kafka", "achievement", "Cut Kafka consumer lag by 90%")
+	kafka.UpdatedAt = now.AddDate(-4, 0, 0)
+	return []*models.KBEntry{
+		profile("contact", models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@example.com"}),
+		profile("current", models.CategoryExperience, models.ExperienceEntry{
+			Company: "Acme", Role: "Engineer", StartDate: "2021-03", EndDate: models.Present,
+			Highlights: []string{"Ran the book club", "Built Kafka pipelines in Go"},
+		}),
+		profile("old", models.CategoryExperience, models.ExperienceEntry{
+			Company: "Initech", Role: "Engineer", StartDate: "2012-01", EndDate: "2014-06",
+			Highlights: []string{"Wrote PHP reports", "Tuned Go services"},
+		}),
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: []string{"PHP", "Go"}, Databases: []string{"Redis"}}),
+		note,
+		kafka,
+	}
+}
+
+func texts(items []Item) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.Text
+	}
+	return out
+}
+
+func TestRank(t *testing.T) {
+	d := match.DefaultDictionary()
+	entries := budgetEntries()
+
+	ranked := Rank(d, "Go and Kafka. Go everywhere.", entries, now)
+	want := []string{
+		"Built Kafka pipelines in Go",   // Go twice and Kafka in the JD, current
+		"Go",                            // Go, current
+		"Tuned Go services",             // Go, but years ago
+		"Cut Kafka consumer lag by 90%", // Kafka
+		"Ran the book club",             // Current, no keywords
+		"PHP",
+		"Redis",
+		"Organized the office move",
+		"Wrote PHP reports",
+	}
+	if got := texts(ranked); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Rank() = %q\nwant %q", got, want)
+	}
+
+	// Without a JD, newer items come first
+	ranked = Rank(d, "", entries, now)
+	if first, last := ranked[0].Text, ranked[len(ranked)-1].Text; first != "Ran the book club" || last != "Tuned Go services" {
+		t.Errorf("expected the current role first and the old one last, got %q", texts(ranked))
+	}
+}
+
+func TestOmit(t *testing.T) {
+	entries := budgetEntries()
+	out := Omit(entries, []Item{
+		{Kind: KindHighlight, EntryID: "current", Text: "Ran the book club"},
+		{Kind: KindSkill, EntryID: "skills", Text: "PHP"},
+		{Kind: KindContext, EntryID: "note", Text: "Organized the office move"},
+	})
+
+	if !sameIDs(out, "contact", "current", "old", "skills", "kafka") {
+		t.Fatalf("Omit() = %v", ids(out))
+	}
+	if exp, _ := out[1].AsExperience(); len(exp.Highlights) != 1 || exp.Highlights[0] != "Built Kafka pipelines in Go" {
+		t.Errorf("unexpected highlights %v", exp.Highlights)
+	}
+	if s, _ := out[3].AsSkills(); len(s.Languages) != 1 || s.Languages[0] != "Go" || len(s.Databases) != 1 {
+		t.Errorf("unexpected skills %+v", s)
+	}
+	if out[2] != entries[2] {
+		t.Error("expected an entry without omissions to be passed through")
+	}
+	if exp, _ := entries[1].AsExperience(); len(exp.Highlights) != 2 {
+		t.Error("expected the original entry to be left alone")
+	}
+
+	// A skills entry without any skills left is dropped
+	out = Omit(entries, []Item{
+		{Kind: KindSkill, EntryID: "skills", Text: "PHP"},
+		{Kind: KindSkill, EntryID: "skills", Text: "Go"},
+		{Kind: KindSkill, EntryID: "skills", Text: "Redis"},
+	})
+	if !sameIDs(out, "contact", "current", "old", "note", "kafka") {
+		t.Errorf("expected the empty skills entry to be dropped, got %v", ids(out))
+	}
+}
+
+func TestFit(t *testing.T) {
+	entries := budgetEntries()
+	ranked := Rank(match.DefaultDictionary(), "Go and Kafka", entries, now)
+	render := func(entries []*models.KBEntry, omitted []Item) string {
+		var b strings.Builder
+		for _, e := range entries {
+			fmt.Fprintf(&b, "%s %v %s\n", e.ID, e.Data, e.Content)
+		}
+		fmt.Fprintf(&b, "omitted %d\n", len(omitted))
+		return b.String()
+	}
+
+	full := render(entries, nil)
+	text, omitted := Fit(entries, ranked, EstimateTokens(full), render)
+	if text != full || omitted != nil {
+		t.Errorf("expected a context within budget to be left alone, got %d omitted", len(omitted))
+	}
+
+	budget := EstimateTokens(full) - 10
+	text, omitted = Fit(entries, ranked, budget, render)
+	if EstimateTokens(text) > budget {
+		t.Errorf("expected the context to fit %d tokens, got %d", budget, EstimateTokens(text))
+	}
+	if len(omitted) == 0 || omitted[0].Text != ranked[len(ranked)-1].Text {
+		t.Fatalf("expected the least relevant items to be left out first, got %q", texts(omitted))
+	}
+	// Leaving out one item fewer would be over budget
+	if fewer := omitted[:len(omitted)-1]; EstimateTokens(render(Omit(entries, fewer), fewer)) <= budget {
+		t.Errorf("expected the fewest items to be left out, got %q", texts(omitted))
+	}
+
+	// When leaving an item out costs more than it saves, the context still
+	// fits if any count of items left out does, and is the smallest if not
+	costly := func(entries []*models.KBEntry, omitted []Item) string {
+		return render(entries, omitted) + strings.Repeat("omitted item\n", len(omitted))
+	}
+	for budget := 1; budget <= EstimateTokens(full); budget += 5 {
+		text, omitted := Fit(entries, ranked, budget, costly)
+		for n := 0; n <= len(ranked); n++ {
+			dropped := ranked[len(ranked)-n:]
+			size := EstimateTokens(costly(Omit(entries, dropped), dropped))
+			if size <= budget && EstimateTokens(text) > budget {
+				t.Errorf("budget %d: %d items left out don't fit, but %d would", budget, len(omitted), n)
+			}
+			if size < EstimateTokens(text) && EstimateTokens(text) > budget {
+				t.Errorf("budget %d: expected the smallest context, but %d items left out is smaller", budget, n)
+			}
+		}
+	}
+
+	text, omitted = Fit(entries, ranked, 1, render)
+	if len(omitted) != len(ranked) || !strings.Contains(text, "contact") {
+		t.Errorf("expected everything rankable left out of an impossible budget, got %d of %d", len(omitted), len(ranked))
+	}
+}
+
+func TestFitJustUnderBudget(t *testing.T) {
+	skills := make([]string, 18)
+	for i := range skills {
+		skills[i] = fmt.Sprintf("Skill%d", i)
+	}
+	entries := []*models.KBEntry{
+		profile("exp", models.CategoryExperience, models.ExperienceEntry{
+			Company: "Acme", Role: "Engineer", StartDate: "2015-01", EndDate: "2018-01",
+			Highlights: []string{"Built the billing service", "Ran the release process"},
+		}),
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: skills}),
+	}
+	ranked := Rank(match.DefaultDictionary(), "", entries, now)
+	// Notes what was left out the way 'kb context' does, at a fixed cost
+	render := func(entries []*models.KBEntry, omitted []Item) string {
+		var b strings.Builder
+		for _, e := range entries {
+			if exp, ok := e.AsExperience(); ok {
+				fmt.Fprintf(&b, "%s @ %s\n- %s\n", exp.Role, exp.Company, strings.Join(exp.Highlights, "\n- "))
+			} else if s, ok := e.AsSkills(); ok {
+				fmt.Fprintf(&b, "Skills: %s\n", strings.Join(s.Languages, ", "))
+			}
+		}
+		if len(omitted) > 0 {
+			fmt.Fprintf(&b, "## Omitted\nLeft out as least relevant, to fit the token budget: %s.\n", Summary(omitted))
+			for _, o := range Omissions(omitted) {
+				fmt.Fprintf(&b, "- %s: %d %s\n", o.EntryID, o.Count, o.Kind)
+			}
+		}
+		return b.String()
+	}
+
+	budget := EstimateTokens(render(entries, nil)) - 1
+	text, omitted := Fit(entries, ranked, budget, render)
+	if EstimateTokens(text) > budget {
+		t.Fatalf("expected the context to fit %d tokens, got %d", budget, EstimateTokens(text))
+	}
+	if len(omitted) == 0 || len(omitted) == len(ranked) {
+		t.Fatalf("expected some items left out, got %d of %d", len(omitted), len(ranked))
+	}
+	for i, it := range omitted {
+		if it != ranked[len(ranked)-1-i] {
+			t.Errorf("expected only the lowest ranked items left out, got %q", texts(omitted))
+			break
+		}
+	}
+	if !strings.Contains(text, ranked[0].Text) {
+		t.Errorf("expected the highest ranked item kept, got:\n%s", text)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		items []Item
+		want  string
+	}{
+		{nil, "nothing"},
+		{[]Item{{Kind: KindSkill}}, "1 skill"},
+		{[]Item{{Kind: KindSkill}, {Kind: KindHighlight}, {Kind: KindHighlight}}, "2 highlights and 1 skill"},
+		{[]Item{{Kind: KindContext}, {Kind: KindContext}, {Kind: KindHighlight}, {Kind: KindSkill}}, "1 highlight, 2 context entries and 1 skill"},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.items); got != tt.want {
+			t.Errorf("Summary(%v) = %q, want %q", tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Errorf("EstimateTokens(\"\") = %d", got)
+	}
+	if got := EstimateTokens("héllo"); got != 2 {
+		t.Errorf("expected runes to be counted, got %d", got)
+	}
+}
@@ -0,0 +1,97 @@
+package kbcontext
+
+import (
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Filter returns the entries in any of the categories, or all of them if
+// categories is empty. A category can also be an entry type, "profile" or
+// "context", for all the entries of that type.
+func Filter(entries []*models.KBEntry, categories []string) []*models.KBEntry {
+	if len(categories) == 0 {
+		return entries
+	}
+	want := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		want[c] = true
+	}
+	var out []*models.KBEntry
+	for _, e := range entries {
+		if want[e.Category] || want[string(e.Type)] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Since returns the entries dated on or after since: roles, projects and
+// the like that ended then or are ongoing, and certifications, talks and
+// the like from then. Entries without a date, like the contact, skills and
+// context entries, are kept.
+func Since(entries []*models.KBEntry, since models.Date) []*models.KBEntry {
+	if since == "" {
+		return entries
+	}
+	var out []*models.KBEntry
+	for _, e := range entries {
+		if date, ok := entryDate(e); !ok || date.Compare(since) >= 0 {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// entryDate returns the date an entry last applied: the end date of a
+// period, Present for one that's ongoing, or the date of a one-off. It
+// returns false for entries without a date.
+func entryDate(e *models.KBEntry) (models.Date, bool) {
+	period := func(start, end models.Date) (models.Date, bool) {
+		switch {
+		case end != "":
+			return end, true
+		case start != "":
+			return models.Present, true
+		}
+		return "", false
+	}
+	dated := func(d models.Date) (models.Date, bool) {
+		return d, d != ""
+	}
+
+	switch models.ProfileCategory(e.Category) {
+	case models.CategoryExperience:
+		if exp, ok := e.AsExperience(); ok {
+			return period(exp.StartDate, exp.EndDate)
+		}
+	case models.CategoryEducation:
+		if edu, ok := e.AsEducation(); ok {
+			// A degree without an end date may well be finished
+			return dated(edu.EndDate)
+		}
+	case models.CategoryProjects:
+		if p, ok := e.AsProject(); ok {
+			return period(p.StartDate, p.EndDate)
+		}
+	case models.CategoryVolunteering:
+		if v, ok := e.AsVolunteering(); ok {
+			return period(v.StartDate, v.EndDate)
+		}
+	case models.CategoryCertifications:
+		if c, ok := e.AsCertification(); ok {
+			return dated(c.Date)
+		}
+	case models.CategoryPublications:
+		if p, ok := e.AsPublication(); ok {
+			return dated(p.Date)
+		}
+	case models.CategoryAwards:
+		if a, ok := e.AsAward(); ok {
+			return dated(a.Date)
+		}
+	case models.CategoryTalks:
+		if t, ok := e.AsTalk(); ok {
+			return dated(t.Date)
+		}
+	}
+	return "", false
+}
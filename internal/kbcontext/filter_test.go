@@ -0,0 +1,89 @@
+package kbcontext
+
+import (
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func ids(entries []*models.KBEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.ID
+	}
+	return out
+}
+
+func sameIDs(got []*models.KBEntry, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i, e := range got {
+		if e.ID != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func profile(id string, category models.ProfileCategory, data any) *models.KBEntry {
+	e := models.NewProfileEntry(category, data, "user")
+	e.ID = id
+	return e
+}
+
+func contextEntry(id, category, content string) *models.KBEntry {
+	e := models.NewContextEntry(category, content, "user")
+	e.ID = id
+	return e
+}
+
+func sampleEntries() []*models.KBEntry {
+	return []*models.KBEntry{
+		profile("contact", models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@example.com"}),
+		profile("current", models.CategoryExperience, models.ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2021-03", EndDate: models.Present}),
+		profile("old", models.CategoryExperience, models.ExperienceEntry{Company: "Initech", Role: "Intern", StartDate: "2012-01", EndDate: "2014-06"}),
+		profile("ongoing", models.CategoryProjects, models.ProjectEntry{Name: "bragger", StartDate: "2015-01"}),
+		profile("degree", models.CategoryEducation, models.EducationEntry{Institution: "MIT", Degree: "BS", EndDate: "2012"}),
+		profile("cert", models.CategoryCertifications, models.CertificationEntry{Name: "CKA", Date: "2020-05"}),
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: []string{"Go"}}),
+		contextEntry("note", "achievement", "Shipped it"),
+	}
+}
+
+func TestFilter(t *testing.T) {
+	entries := sampleEntries()
+
+	if got := Filter(entries, nil); len(got) != len(entries) {
+		t.Errorf("expected no categories to keep everything, got %v", ids(got))
+	}
+	if got := Filter(entries, []string{"experience", "skills"}); !sameIDs(got, "current", "old", "skills") {
+		t.Errorf("Filter(experience, skills) = %v", ids(got))
+	}
+	if got := Filter(entries, []string{"context", "contact"}); !sameIDs(got, "contact", "note") {
+		t.Errorf("Filter(context, contact) = %v", ids(got))
+	}
+	if got := Filter(entries, []string{"nope"}); len(got) != 0 {
+		t.Errorf("expected an unknown category to match nothing, got %v", ids(got))
+	}
+}
+
+func TestSince(t *testing.T) {
+	entries := sampleEntries()
+
+	if got := Since(entries, ""); len(got) != len(entries) {
+		t.Errorf("expected no date to keep everything, got %v", ids(got))
+	}
+	// The old role and degree ended before 2015; the project started
+	// before it but is ongoing
+	if got := Since(entries, "2015"); !sameIDs(got, "contact", "current", "ongoing", "cert", "skills", "note") {
+		t.Errorf("Since(2015) = %v", ids(got))
+	}
+	if got := Since(entries, "2020-06"); !sameIDs(got, "contact", "current", "ongoing", "skills", "note") {
+		t.Errorf("Since(2020-06) = %v", ids(got))
+	}
+	// Dates are compared at the precision they share
+	if got := Since(entries, "2014"); !sameIDs(got, "contact", "current", "old", "ongoing", "cert", "skills", "note") {
+		t.Errorf("Since(2014) = %v", ids(got))
+	}
+}
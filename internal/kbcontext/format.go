@@ -0,0 +1,149 @@
+package kbcontext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ewurch/bragger/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats the context can be written in
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+)
+
+// Document is the context in a structured format, for agents
+type Document struct {
+	Sections []DocumentSection `json:"sections"`
+	Omitted  []Omission        `json:"omitted,omitempty"`
+}
+
+// DocumentSection is a section of a Document
+type DocumentSection struct {
+	Type     models.KBEntryType `json:"type"`
+	Category string             `json:"category"`
+	Entries  []DocumentEntry    `json:"entries"`
+}
+
+// DocumentEntry is an entry of a DocumentSection, with its ID and source
+// so an agent can cite and update it
+type DocumentEntry struct {
+	ID      string `json:"id"`
+	Source  string `json:"source,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// Omission is what a budget left out of one entry. Items are counted rather
+// than listed, so noting them costs about the same however many there are.
+type Omission struct {
+	EntryID string   `json:"entry_id"`
+	Kind    ItemKind `json:"kind"`
+	Count   int      `json:"count"`
+}
+
+// Omissions groups the items left out by entry, in order of entry ID
+func Omissions(items []Item) []Omission {
+	byEntry := make(map[string]*Omission)
+	var ids []string
+	for _, it := range items {
+		o, ok := byEntry[it.EntryID]
+		if !ok {
+			o = &Omission{EntryID: it.EntryID, Kind: it.Kind}
+			byEntry[it.EntryID] = o
+			ids = append(ids, it.EntryID)
+		}
+		o.Count++
+	}
+	sort.Strings(ids)
+
+	out := make([]Omission, len(ids))
+	for i, id := range ids {
+		out[i] = *byEntry[id]
+	}
+	return out
+}
+
+// NewDocument builds the structured context from the entries and the items
+// a budget left out of them
+func NewDocument(entries []*models.KBEntry, omitted []Item) *Document {
+	doc := &Document{Sections: []DocumentSection{}, Omitted: Omissions(omitted)}
+	for _, s := range Sections(entries) {
+		section := DocumentSection{Type: s.Type, Category: s.Category}
+		for _, e := range s.Entries {
+			section.Entries = append(section.Entries, DocumentEntry{ID: e.ID, Source: e.Source, Data: documentData(e), Content: e.Content})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc
+}
+
+// documentData returns the data of an entry as it is stored, so members the
+// struct for its category has no field for are included
+func documentData(e *models.KBEntry) any {
+	if e.Type != models.KBTypeProfile || e.Data == nil {
+		return e.Data
+	}
+	data, err := e.DataJSON()
+	if err != nil {
+		return e.Data
+	}
+	return json.RawMessage(data)
+}
+
+// Encode writes the document as JSON or YAML
+func (d *Document) Encode(format string) ([]byte, error) {
+	// Without HTML escaping, values like "R&D" read as they're stored
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return buf.Bytes(), nil
+	case FormatYAML:
+		return jsonToYAML(buf.Bytes())
+	}
+	return nil, fmt.Errorf("unknown format %q: use %s, %s or %s", format, FormatMarkdown, FormatJSON, FormatYAML)
+}
+
+// jsonToYAML converts JSON to block-style YAML. JSON is YAML, so it's
+// decoded into a node, which keeps the order of the fields as the JSON
+// tags give them, rather than sorting them like a map would.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	var unflow func(n *yaml.Node)
+	unflow = func(n *yaml.Node) {
+		n.Style &^= yaml.FlowStyle
+		if n.Kind == yaml.ScalarNode && n.Style&yaml.DoubleQuotedStyle != 0 {
+			// Strings are quoted only where YAML needs it
+			n.Style &^= yaml.DoubleQuotedStyle
+		}
+		for _, c := range n.Content {
+			unflow(c)
+		}
+	}
+	unflow(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,128 @@
+package kbcontext
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+func formatEntries() []*models.KBEntry {
+	exp := profile("exp", models.CategoryExperience, models.ExperienceEntry{
+		Company: "Acme", Role: "Engineer", StartDate: "2021-03", EndDate: models.Present,
+		Highlights: []string{"Cut costs: 40%", "Shipped R&D tools"},
+	})
+	exp.Source = "cv-import"
+	exp.ExtraData = map[string]json.RawMessage{"team_size": json.RawMessage("12")}
+	return []*models.KBEntry{
+		contextEntry("note", "achievement", "Won the hackathon"),
+		exp,
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: []string{"Go", "Rust"}}),
+	}
+}
+
+func TestOmissions(t *testing.T) {
+	got := Omissions([]Item{
+		{Kind: KindSkill, EntryID: "skills", Text: "Rust"},
+		{Kind: KindHighlight, EntryID: "exp", Text: "Shipped R&D tools"},
+		{Kind: KindSkill, EntryID: "skills", Text: "Go"},
+		{Kind: KindHighlight, EntryID: "exp", Text: "Cut costs: 40%"},
+	})
+	if len(got) != 2 || got[0].EntryID != "exp" || got[0].Count != 2 {
+		t.Fatalf("Omissions() = %+v", got)
+	}
+	if got[1].Kind != KindSkill || got[1].Count != 2 {
+		t.Errorf("expected the skills counted, got %+v", got[1])
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	out, err := NewDocument(formatEntries(), []Item{{Kind: KindSkill, EntryID: "skills", Text: "Rust"}}).Encode(FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Sections []struct {
+			Type     string `json:"type"`
+			Category string `json:"category"`
+			Entries  []struct {
+				ID      string         `json:"id"`
+				Source  string         `json:"source"`
+				Data    map[string]any `json:"data"`
+				Content string         `json:"content"`
+			} `json:"entries"`
+		} `json:"sections"`
+		Omitted []Omission `json:"omitted"`
+	}
+	if !strings.Contains(string(out), `"Shipped R&D tools"`) {
+		t.Errorf("expected no HTML escaping, got: %s", out)
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(doc.Sections) != 3 || doc.Sections[0].Category != "experience" || doc.Sections[2].Type != "context" {
+		t.Fatalf("unexpected sections: %s", out)
+	}
+	exp := doc.Sections[0].Entries[0]
+	if exp.ID != "exp" || exp.Source != "cv-import" || exp.Data["start_date"] != "2021-03" {
+		t.Errorf("unexpected experience entry %+v", exp)
+	}
+	if exp.Data["team_size"] != 12.0 {
+		t.Errorf("expected the extra field kept, got %+v", exp.Data)
+	}
+	if note := doc.Sections[2].Entries[0]; note.ID != "note" || note.Content != "Won the hackathon" || note.Source != "user" {
+		t.Errorf("unexpected context entry %+v", note)
+	}
+	if len(doc.Omitted) != 1 || doc.Omitted[0].Count != 1 {
+		t.Errorf("unexpected omissions %+v", doc.Omitted)
+	}
+}
+
+func TestEncodeYAML(t *testing.T) {
+	out, err := NewDocument(formatEntries(), nil).Encode(FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(out)
+
+	// Block style, fields in the order of the JSON tags, and strings quoted
+	// only where they would otherwise not be strings
+	for _, want := range []string{
+		"sections:\n  - type: profile\n    category: experience\n    entries:\n      - id: exp\n        source: cv-import\n",
+		"company: Acme",
+		"- 'Cut costs: 40%'",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "omitted") {
+		t.Errorf("expected no omissions, got:\n%s", text)
+	}
+
+	var doc struct {
+		Sections []struct {
+			Entries []struct {
+				Data map[string]any `yaml:"data"`
+			} `yaml:"entries"`
+		} `yaml:"sections"`
+	}
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	if got := doc.Sections[0].Entries[0].Data["start_date"]; got != "2021-03" {
+		t.Errorf("expected the date to stay a string, got %#v", got)
+	}
+	if got := doc.Sections[0].Entries[0].Data["team_size"]; got != 12 {
+		t.Errorf("expected the extra field kept, got %#v", got)
+	}
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	if _, err := NewDocument(nil, nil).Encode("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
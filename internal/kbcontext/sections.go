@@ -0,0 +1,92 @@
+package kbcontext
+
+import (
+	"sort"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Section is a group of entries the context lists together: the entries
+// of a profile category, or of a context category
+type Section struct {
+	Type     models.KBEntryType
+	Category string
+	Entries  []*models.KBEntry
+}
+
+// profileOrder is the order of the profile sections
+var profileOrder = []models.ProfileCategory{
+	models.CategoryContact,
+	models.CategoryExperience,
+	models.CategoryEducation,
+	models.CategorySkills,
+	models.CategoryCertifications,
+	models.CategoryLanguages,
+	models.CategoryProjects,
+	models.CategoryPublications,
+	models.CategoryTalks,
+	models.CategoryAwards,
+	models.CategoryVolunteering,
+}
+
+// contextOrder is the order of the usual context categories, which come
+// after the profile sections; other categories follow alphabetically
+var contextOrder = []string{"summary", "achievement", "preference"}
+
+// Sections groups the entries into sections, profile sections first, so
+// the same KB always gives the same context. Experience is newest first,
+// and other entries keep their order in the KB. Only the first of any
+// duplicate contact or skills entries is kept, as in GetContact.
+func Sections(entries []*models.KBEntry) []Section {
+	profile := make(map[models.ProfileCategory][]*models.KBEntry)
+	context := make(map[string][]*models.KBEntry)
+	var contextCategories []string
+	for _, e := range entries {
+		if e.Type != models.KBTypeProfile {
+			if _, seen := context[e.Category]; !seen {
+				contextCategories = append(contextCategories, e.Category)
+			}
+			context[e.Category] = append(context[e.Category], e)
+			continue
+		}
+		category := models.ProfileCategory(e.Category)
+		if category.IsSingleton() && len(profile[category]) > 0 {
+			continue
+		}
+		profile[category] = append(profile[category], e)
+	}
+
+	experiences := profile[models.CategoryExperience]
+	sort.SliceStable(experiences, func(i, j int) bool {
+		a, _ := experiences[i].AsExperience()
+		b, _ := experiences[j].AsExperience()
+		return models.CompareExperience(a, b) < 0
+	})
+
+	var sections []Section
+	for _, category := range profileOrder {
+		if len(profile[category]) > 0 {
+			sections = append(sections, Section{Type: models.KBTypeProfile, Category: string(category), Entries: profile[category]})
+		}
+	}
+
+	rank := func(category string) int {
+		for i, c := range contextOrder {
+			if c == category {
+				return i
+			}
+		}
+		return len(contextOrder)
+	}
+	sort.Slice(contextCategories, func(i, j int) bool {
+		a, b := contextCategories[i], contextCategories[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a < b
+	})
+	for _, category := range contextCategories {
+		sections = append(sections, Section{Type: models.KBTypeContext, Category: category, Entries: context[category]})
+	}
+	return sections
+}
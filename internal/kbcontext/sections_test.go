@@ -0,0 +1,50 @@
+package kbcontext
+
+import (
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestSections(t *testing.T) {
+	entries := []*models.KBEntry{
+		contextEntry("pref", "preference", "Remote first"),
+		contextEntry("misc", "zeta", "Last of the rest"),
+		profile("old", models.CategoryExperience, models.ExperienceEntry{Company: "Initech", Role: "Intern", StartDate: "2012-01", EndDate: "2014-06"}),
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: []string{"Go"}}),
+		contextEntry("win", "achievement", "Shipped it"),
+		profile("talk", models.CategoryTalks, models.TalkEntry{Title: "Go", Event: "GopherCon"}),
+		profile("current", models.CategoryExperience, models.ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2021-03", EndDate: models.Present}),
+		profile("dup-skills", models.CategorySkills, models.SkillsData{Languages: []string{"Rust"}}),
+		contextEntry("other", "alpha", "First of the rest"),
+		profile("award", models.CategoryAwards, models.AwardEntry{Title: "MVP"}),
+		contextEntry("win2", "achievement", "Shipped it again"),
+		profile("contact", models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@example.com"}),
+	}
+
+	want := []struct {
+		category string
+		ids      []string
+	}{
+		{"contact", []string{"contact"}},
+		{"experience", []string{"current", "old"}},
+		{"skills", []string{"skills"}},
+		{"talks", []string{"talk"}},
+		{"awards", []string{"award"}},
+		{"achievement", []string{"win", "win2"}},
+		{"preference", []string{"pref"}},
+		{"alpha", []string{"other"}},
+		{"zeta", []string{"misc"}},
+	}
+	for run := 0; run < 5; run++ {
+		sections := Sections(entries)
+		if len(sections) != len(want) {
+			t.Fatalf("got %d sections, want %d", len(sections), len(want))
+		}
+		for i, s := range sections {
+			if s.Category != want[i].category || !sameIDs(s.Entries, want[i].ids...) {
+				t.Errorf("section %d = %s %v, want %s %v", i, s.Category, ids(s.Entries), want[i].category, want[i].ids)
+			}
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package kbimport
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Action is what an import does to a KB entry
+type Action string
+
+const (
+	ActionAdd    Action = "add"
+	ActionUpdate Action = "update"
+)
+
+// Change is a KB entry an import adds or updates
+type Change struct {
+	Action Action
+	// Entry is the entry as it will be saved
+	Entry *models.KBEntry
+	// Previous is the entry before an update
+	Previous *models.KBEntry
+}
+
+// Result reports what an import changes
+type Result struct {
+	Changes   []Change
+	Unchanged int
+	// Skipped lists imported records that could not become KB entries and
+	// why
+	Skipped []string
+}
+
+// Counts returns the number of entries added and updated
+func (r *Result) Counts() (added, updated int) {
+	for _, c := range r.Changes {
+		if c.Action == ActionAdd {
+			added++
+		} else {
+			updated++
+		}
+	}
+	return added, updated
+}
+
+// Merger merges imported records into a copy of the KB entries, leaving
+// the entries it was given untouched so the changes can be previewed before
+// they are saved. Profile entries already in the KB are matched and updated
+// in place: experience by company and start month, and by role too when
+// that matches more than one; education by institution and degree;
+// certifications and languages by name.
+type Merger struct {
+	source  string
+	entries []*models.KBEntry
+	result  *Result
+}
+
+// NewMerger returns a Merger adding entries with the source
+func NewMerger(entries []*models.KBEntry, source string) *Merger {
+	return &Merger{source: source, entries: append([]*models.KBEntry(nil), entries...), result: &Result{}}
+}
+
+// Entries returns the KB entries with the merged records
+func (m *Merger) Entries() []*models.KBEntry {
+	return m.entries
+}
+
+// Result returns what the merge changed
+func (m *Merger) Result() *Result {
+	return m.result
+}
+
+// Skip records a record that could not become a KB entry
+func (m *Merger) Skip(reason string) {
+	m.result.Skipped = append(m.result.Skipped, reason)
+}
+
+// Singleton returns the entry of a category the KB holds one of, like
+// contact or skills, or nil if there is none
+func (m *Merger) Singleton(category models.ProfileCategory) *models.KBEntry {
+	return m.find(category, "", noKey)
+}
+
+// SetSingleton updates the entry of a category the KB holds one of, or adds
+// it
+func (m *Merger) SetSingleton(category models.ProfileCategory, data any) {
+	m.upsert(category, "", data, noKey)
+}
+
+// Experience merges an experience. imported is every experience of the
+// import, so roles at one company starting the same month are told apart.
+func (m *Merger) Experience(exp models.ExperienceEntry, imported []models.ExperienceEntry) {
+	key := experienceMatchKey(exp)
+	keyOf := func(e *models.KBEntry) string {
+		x, _ := e.AsExperience()
+		return experienceMatchKey(x)
+	}
+	same := 0
+	for _, x := range imported {
+		if experienceMatchKey(x) == key {
+			same++
+		}
+	}
+	if same > 1 || m.count(models.CategoryExperience, key, keyOf) > 1 {
+		key, keyOf = ExperienceKey(exp), func(e *models.KBEntry) string {
+			x, _ := e.AsExperience()
+			return ExperienceKey(x)
+		}
+	}
+	m.upsert(models.CategoryExperience, key, exp, keyOf)
+}
+
+// Education merges an education entry
+func (m *Merger) Education(edu models.EducationEntry) {
+	m.upsert(models.CategoryEducation, EducationKey(edu), edu, func(e *models.KBEntry) string {
+		x, _ := e.AsEducation()
+		return EducationKey(x)
+	})
+}
+
+// Certification merges a certification
+func (m *Merger) Certification(cert models.CertificationEntry) {
+	m.upsert(models.CategoryCertifications, CertificationKey(cert), cert, func(e *models.KBEntry) string {
+		x, _ := e.AsCertification()
+		return CertificationKey(x)
+	})
+}
+
+// Language merges a language
+func (m *Merger) Language(lang models.LanguageEntry) {
+	m.upsert(models.CategoryLanguages, LanguageKey(lang), lang, func(e *models.KBEntry) string {
+		x, _ := e.AsLanguage()
+		return LanguageKey(x)
+	})
+}
+
+// Summary adds a summary as a context entry of the category, unless the KB
+// already has it. A summary from an earlier import is replaced.
+func (m *Merger) Summary(category, summary string) {
+	var previous *models.KBEntry
+	for _, e := range m.entries {
+		if e.Type != models.KBTypeContext || e.Category != category {
+			continue
+		}
+		if e.Content == summary {
+			m.result.Unchanged++
+			return
+		}
+		if e.Source == m.source {
+			previous = e
+		}
+	}
+	m.SetContext(previous, category, summary)
+}
+
+// SetContext updates previous with the content, or adds a context entry of
+// the category if previous is nil
+func (m *Merger) SetContext(previous *models.KBEntry, category, content string) {
+	switch {
+	case previous == nil:
+		m.add(models.NewContextEntry(category, content, m.source))
+	case previous.Content == content:
+		m.result.Unchanged++
+	default:
+		updated := *previous
+		updated.Content = content
+		m.update(previous, &updated)
+	}
+}
+
+// Remove removes an entry, along with the change that added or updated it
+// earlier in the import
+func (m *Merger) Remove(target *models.KBEntry) {
+	var kept []*models.KBEntry
+	for _, e := range m.entries {
+		if e != target {
+			kept = append(kept, e)
+		}
+	}
+	m.entries = kept
+	for i, c := range m.result.Changes {
+		if c.Entry == target {
+			m.result.Changes = append(m.result.Changes[:i], m.result.Changes[i+1:]...)
+			return
+		}
+	}
+}
+
+func noKey(*models.KBEntry) string { return "" }
+
+// find returns the profile entry of a category whose key matches
+func (m *Merger) find(category models.ProfileCategory, key string, keyOf func(*models.KBEntry) string) *models.KBEntry {
+	for _, e := range m.entries {
+		if e.Type == models.KBTypeProfile && e.Category == string(category) && keyOf(e) == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// count returns how many profile entries of a category have the key
+func (m *Merger) count(category models.ProfileCategory, key string, keyOf func(*models.KBEntry) string) int {
+	n := 0
+	for _, e := range m.entries {
+		if e.Type == models.KBTypeProfile && e.Category == string(category) && keyOf(e) == key {
+			n++
+		}
+	}
+	return n
+}
+
+// upsert updates the matching profile entry, or adds one
+func (m *Merger) upsert(category models.ProfileCategory, key string, data any, keyOf func(*models.KBEntry) string) {
+	e := m.find(category, key, keyOf)
+	if e == nil {
+		m.add(models.NewProfileEntry(category, data, m.source))
+		return
+	}
+	if sameData(e.Data, data) {
+		m.result.Unchanged++
+		return
+	}
+	updated := *e
+	updated.Data = data
+	m.update(e, &updated)
+}
+
+func (m *Merger) add(e *models.KBEntry) {
+	m.entries = append(m.entries, e)
+	m.result.Changes = append(m.result.Changes, Change{Action: ActionAdd, Entry: e})
+}
+
+// update replaces previous with a copy holding the new data. An entry added
+// or updated earlier in the same import is replaced without a second change.
+func (m *Merger) update(previous, updated *models.KBEntry) {
+	updated.UpdatedAt = time.Now()
+	for i, e := range m.entries {
+		if e == previous {
+			m.entries[i] = updated
+		}
+	}
+	for i, c := range m.result.Changes {
+		if c.Entry == previous {
+			m.result.Changes[i].Entry = updated
+			return
+		}
+	}
+	m.result.Changes = append(m.result.Changes, Change{Action: ActionUpdate, Entry: updated, Previous: previous})
+}
+
+// sameData compares entry data by its JSON, since data loaded from the KB
+// file is a map rather than the typed struct
+func sameData(a, b any) bool {
+	return canonicalJSON(a) == canonicalJSON(b)
+}
+
+func canonicalJSON(v any) string {
+	data, _ := json.Marshal(v)
+	var generic any
+	json.Unmarshal(data, &generic)
+	data, _ = json.Marshal(generic)
+	return string(data)
+}
+
+// ExperienceKey identifies an experience by company, role and start month
+func ExperienceKey(e models.ExperienceEntry) string {
+	return strings.ToLower(e.Company) + "|" + strings.ToLower(e.Role) + "|" + month(e.StartDate)
+}
+
+// experienceMatchKey leaves out the role, so a re-import with a corrected
+// title updates the entry instead of adding a second one
+func experienceMatchKey(e models.ExperienceEntry) string {
+	return strings.ToLower(e.Company) + "|" + month(e.StartDate)
+}
+
+// EducationKey identifies an education entry by institution and degree
+func EducationKey(e models.EducationEntry) string {
+	return strings.ToLower(e.Institution) + "|" + strings.ToLower(e.Degree)
+}
+
+// CertificationKey identifies a certification by name
+func CertificationKey(c models.CertificationEntry) string {
+	return strings.ToLower(c.Name)
+}
+
+// LanguageKey identifies a language by name
+func LanguageKey(l models.LanguageEntry) string {
+	return strings.ToLower(l.Language)
+}
+
+// month shortens a date with a day to its month, as imports store dates
+func month(d models.Date) string {
+	parsed, err := models.ParseDate(string(d))
+	if err != nil {
+		return strings.TrimSpace(string(d))
+	}
+	if !parsed.IsPresent() && parsed.Precision() == models.PrecisionDay {
+		return parsed.Month()
+	}
+	return string(parsed)
+}
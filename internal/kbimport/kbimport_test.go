@@ -0,0 +1,104 @@
+package kbimport
+
+import (
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func experiences(entries []*models.KBEntry) []models.ExperienceEntry {
+	var out []models.ExperienceEntry
+	for _, e := range entries {
+		if x, ok := e.AsExperience(); ok && e.Category == string(models.CategoryExperience) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func TestMergerExperience(t *testing.T) {
+	existing := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{Company: "Acme", Role: "SWE", StartDate: "2020-01-15"}, "user")
+	entries := []*models.KBEntry{existing}
+
+	// A corrected title updates the entry at the same company and start month
+	senior := models.ExperienceEntry{Company: "ACME", Role: "Senior SWE", StartDate: "2020-01"}
+	m := NewMerger(entries, "test")
+	m.Experience(senior, []models.ExperienceEntry{senior})
+	if added, updated := m.Result().Counts(); added != 0 || updated != 1 {
+		t.Fatalf("Counts() = %d, %d, want 1 update", added, updated)
+	}
+	if got := experiences(m.Entries()); len(got) != 1 || got[0].Role != "Senior SWE" {
+		t.Errorf("unexpected experience %+v", got)
+	}
+	if x, _ := existing.AsExperience(); x.Role != "SWE" {
+		t.Error("expected the entries given to be left alone")
+	}
+	if c := m.Result().Changes[0]; c.Action != ActionUpdate || c.Previous != existing || c.Entry.ID != existing.ID {
+		t.Errorf("unexpected change %+v", c)
+	}
+
+	// Roles starting the same month at one company are told apart by title
+	lead := models.ExperienceEntry{Company: "Acme", Role: "Team Lead", StartDate: "2020-01"}
+	both := []models.ExperienceEntry{senior, lead}
+	m = NewMerger(m.Entries(), "test")
+	for _, exp := range both {
+		m.Experience(exp, both)
+	}
+	if added, updated := m.Result().Counts(); added != 1 || updated != 0 || m.Result().Unchanged != 1 {
+		t.Fatalf("Counts() = %d, %d, want the lead role added", added, updated)
+	}
+	m = NewMerger(m.Entries(), "test")
+	for _, exp := range both {
+		m.Experience(exp, both)
+	}
+	if len(m.Result().Changes) != 0 || len(experiences(m.Entries())) != 2 {
+		t.Errorf("expected a re-import to change nothing, got %+v", m.Result().Changes)
+	}
+}
+
+func TestMergerKeys(t *testing.T) {
+	entries := []*models.KBEntry{
+		models.NewProfileEntry(models.CategoryEducation, models.EducationEntry{Institution: "TU Berlin", Degree: "BSc"}, "user"),
+		models.NewProfileEntry(models.CategoryCertifications, models.CertificationEntry{Name: "CKA", Issuer: "CNCF"}, "user"),
+		models.NewProfileEntry(models.CategoryLanguages, models.LanguageEntry{Language: "German", Proficiency: "B2"}, "user"),
+	}
+	m := NewMerger(entries, "test")
+	m.Education(models.EducationEntry{Institution: "tu berlin", Degree: "bsc", Field: "CS"})
+	m.Certification(models.CertificationEntry{Name: "cka", Issuer: "CNCF"})
+	m.Language(models.LanguageEntry{Language: "German", Proficiency: "B2"})
+	m.Language(models.LanguageEntry{Language: "English", Proficiency: "C1"})
+
+	if added, updated := m.Result().Counts(); added != 1 || updated != 2 || m.Result().Unchanged != 1 {
+		t.Errorf("Counts() = %d, %d with %d unchanged", added, updated, m.Result().Unchanged)
+	}
+}
+
+func TestMergerContext(t *testing.T) {
+	own := models.NewContextEntry("summary", "Old summary", "test")
+	other := models.NewContextEntry("summary", "Written by hand", "user")
+	m := NewMerger([]*models.KBEntry{own, other}, "test")
+
+	// The summary of an earlier import is replaced
+	m.Summary("summary", "New summary")
+	if len(m.Entries()) != 2 || m.Entries()[0].Content != "New summary" || m.Entries()[1] != other {
+		t.Errorf("unexpected entries %v", m.Entries())
+	}
+	m.Summary("summary", "Written by hand")
+	if m.Result().Unchanged != 1 {
+		t.Error("expected a summary the KB has to be unchanged")
+	}
+
+	// An entry added and then removed leaves no change behind
+	m.SetContext(nil, "notes", "temporary")
+	added := m.Entries()[len(m.Entries())-1]
+	m.Remove(added)
+	if len(m.Entries()) != 2 || len(m.Result().Changes) != 1 {
+		t.Errorf("expected only the summary update, got %+v", m.Result().Changes)
+	}
+
+	m.SetSingleton(models.CategorySkills, models.SkillsData{Languages: []string{"Go"}})
+	m.SetSingleton(models.CategorySkills, models.SkillsData{Languages: []string{"Go", "Rust"}})
+	if s, _ := m.Singleton(models.CategorySkills).AsSkills(); len(s.Languages) != 2 || len(m.Result().Changes) != 2 {
+		t.Errorf("expected one skills entry added, got %+v and %d changes", s, len(m.Result().Changes))
+	}
+}
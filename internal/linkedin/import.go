@@ -0,0 +1,104 @@
+package linkedin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/kbimport"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Import merges a LinkedIn profile into the KB entries and returns the new
+// list, leaving the given entries untouched so the changes can be previewed
+// before they are saved. Entries already in the KB are matched as
+// kbimport.Merger matches them, the same way as a JSON Resume import, so
+// importing the same export again changes nothing. Skills are added to
+// "other" unless the KB already lists them in any category.
+func Import(entries []*models.KBEntry, p *Profile) ([]*models.KBEntry, *kbimport.Result) {
+	m := kbimport.NewMerger(entries, Source)
+
+	if p.Contact != nil {
+		contact(m, *p.Contact)
+	}
+	if p.Summary != "" {
+		m.Summary(SummaryCategory, p.Summary)
+	}
+	for _, exp := range p.Experience {
+		if err := exp.Validate(); err != nil {
+			m.Skip(fmt.Sprintf("position %q: %v", strings.TrimSpace(exp.Company+" "+exp.Role), err))
+			continue
+		}
+		m.Experience(exp, p.Experience)
+	}
+	for _, edu := range p.Education {
+		if err := edu.Validate(); err != nil {
+			m.Skip(fmt.Sprintf("education %q: %v", edu.Institution, err))
+			continue
+		}
+		m.Education(edu)
+	}
+	if len(p.Skills) > 0 {
+		skills(m, p.Skills)
+	}
+	for _, cert := range p.Certifications {
+		if err := cert.Validate(); err != nil {
+			m.Skip(fmt.Sprintf("certification: %v", err))
+			continue
+		}
+		m.Certification(cert)
+	}
+	for _, lang := range p.Languages {
+		if err := lang.Validate(); err != nil {
+			m.Skip(fmt.Sprintf("language: %v", err))
+			continue
+		}
+		m.Language(lang)
+	}
+	return m.Entries(), m.Result()
+}
+
+// contact fills in the existing contact rather than replacing it, so fields
+// the export doesn't have (like the LinkedIn URL) are kept
+func contact(m *kbimport.Merger, imported models.ContactData) {
+	var contact models.ContactData
+	if existing := m.Singleton(models.CategoryContact); existing != nil {
+		contact, _ = existing.AsContact()
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&contact.Name, imported.Name}, {&contact.Email, imported.Email}, {&contact.Phone, imported.Phone},
+		{&contact.Location, imported.Location}, {&contact.GitHub, imported.GitHub}, {&contact.Website, imported.Website},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if err := contact.Validate(); err != nil {
+		m.Skip(fmt.Sprintf("profile: %v", err))
+		return
+	}
+	m.SetSingleton(models.CategoryContact, contact)
+}
+
+func skills(m *kbimport.Merger, names []string) {
+	var skills models.SkillsData
+	if existing := m.Singleton(models.CategorySkills); existing != nil {
+		skills, _ = existing.AsSkills()
+	}
+
+	known := make(map[string]bool)
+	for _, list := range [][]string{skills.Languages, skills.Frameworks, skills.Tools, skills.Databases, skills.Cloud, skills.Other} {
+		for _, s := range list {
+			known[strings.ToLower(s)] = true
+		}
+	}
+	for _, name := range names {
+		if !known[strings.ToLower(name)] {
+			known[strings.ToLower(name)] = true
+			skills.Other = append(skills.Other, name)
+		}
+	}
+	m.SetSingleton(models.CategorySkills, skills)
+}
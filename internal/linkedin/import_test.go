@@ -0,0 +1,86 @@
+package linkedin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/kbimport"
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestImport(t *testing.T) {
+	entries, result := Import(nil, readSample(t, sampleExport))
+
+	// contact, summary, 2 positions, 1 education, skills, 1 certification,
+	// 1 language
+	if len(entries) != 8 {
+		t.Fatalf("got %d entries, want 8", len(entries))
+	}
+	added, updated := result.Counts()
+	if added != 8 || updated != 0 || result.Unchanged != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0], `education "Online Course": degree is required`) {
+		t.Errorf("Skipped = %q", result.Skipped)
+	}
+	for _, e := range entries {
+		if e.Source != Source {
+			t.Errorf("entry %s has source %q", e.ID, e.Source)
+		}
+	}
+	if e := entries[1]; e.Type != models.KBTypeContext || e.Category != SummaryCategory {
+		t.Errorf("summary not imported as context: %+v", e)
+	}
+}
+
+func TestImportIdempotent(t *testing.T) {
+	p := readSample(t, sampleExport)
+	entries, _ := Import(nil, p)
+	count := len(entries)
+
+	entries, result := Import(entries, p)
+	if len(entries) != count || len(result.Changes) != 0 || result.Unchanged != count {
+		t.Errorf("re-import gave %d entries, result %+v", len(entries), result)
+	}
+}
+
+func TestImportDoesNotModifyEntries(t *testing.T) {
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane Doe", Email: "jane@example.com", LinkedIn: "https://www.linkedin.com/in/janedoe"}, "user")
+	skills := models.NewProfileEntry(models.CategorySkills, models.SkillsData{Languages: []string{"go"}}, "user")
+	before := []*models.KBEntry{contact, skills}
+
+	entries, result := Import(before, &Profile{
+		Contact: &models.ContactData{Name: "Jane Doe", Phone: "+49 123"},
+		Skills:  []string{"Go", "Kubernetes"},
+	})
+
+	if before[0] != contact || before[1] != skills || contact.Data.(models.ContactData).Phone != "" {
+		t.Error("Import modified the entries it was given")
+	}
+	if len(entries) != 2 || len(result.Changes) != 2 {
+		t.Fatalf("got %d entries and %d changes, want 2 updates", len(entries), len(result.Changes))
+	}
+	for _, c := range result.Changes {
+		if c.Action != kbimport.ActionUpdate || c.Previous == nil || c.Entry.ID != c.Previous.ID {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+
+	c, _ := entries[0].AsContact()
+	if c.Phone != "+49 123" || c.Email != "jane@example.com" || c.LinkedIn == "" {
+		t.Errorf("contact should take new fields and keep the rest: %+v", c)
+	}
+
+	// "Go" is already known, whatever its case and category
+	s, _ := entries[1].AsSkills()
+	if strings.Join(s.Languages, ",") != "go" || strings.Join(s.Other, ",") != "Kubernetes" {
+		t.Errorf("unexpected skills: %+v", s)
+	}
+}
+
+func TestImportSkipsContactWithoutEmail(t *testing.T) {
+	entries, result := Import(nil, &Profile{Contact: &models.ContactData{Name: "Jane Doe"}})
+	if len(entries) != 0 || len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0], "email is required") {
+		t.Errorf("got %d entries, Skipped = %q", len(entries), result.Skipped)
+	}
+}
@@ -0,0 +1,302 @@
+package linkedin
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Source is recorded on KB entries created by an import
+const Source = "linkedin-import"
+
+// SummaryCategory is the context category the profile summary is imported as
+const SummaryCategory = "summary"
+
+// Profile is the part of a LinkedIn data export the KB can use
+type Profile struct {
+	// Contact is nil when the export has no profile. Its email is empty
+	// when the export has no email addresses.
+	Contact        *models.ContactData
+	Summary        string
+	Experience     []models.ExperienceEntry
+	Education      []models.EducationEntry
+	Skills         []string
+	Certifications []models.CertificationEntry
+	Languages      []models.LanguageEntry
+}
+
+// ReadFile reads a LinkedIn data export ZIP file
+func ReadFile(name string) (*Profile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, info.Size())
+}
+
+// Read reads a LinkedIn data export archive. Only the CSV files the KB has
+// categories for are read; the rest of the export is ignored. An archive
+// with none of them is an error, since it is most likely not an export.
+func Read(r io.ReaderAt, size int64) (*Profile, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a LinkedIn data export: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[strings.ToLower(path.Base(f.Name))] = f
+	}
+
+	p := &Profile{}
+	found := false
+	for _, s := range sections {
+		f, ok := files[strings.ToLower(s.file)]
+		if !ok {
+			continue
+		}
+		found = true
+		rows, err := readCSV(f, s.header)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.file, err)
+		}
+		s.parse(p, rows)
+	}
+	if !found {
+		return nil, fmt.Errorf("not a LinkedIn data export: no Profile.csv, Positions.csv or other known files")
+	}
+	return p, nil
+}
+
+// sections are the export's CSV files, with a column that identifies the
+// header row
+var sections = []struct {
+	file   string
+	header string
+	parse  func(*Profile, []row)
+}{
+	{"Profile.csv", "First Name", parseProfile},
+	{"Email Addresses.csv", "Email Address", parseEmails},
+	{"PhoneNumbers.csv", "Number", parsePhoneNumbers},
+	{"Positions.csv", "Company Name", parsePositions},
+	{"Education.csv", "School Name", parseEducation},
+	{"Skills.csv", "Name", parseSkills},
+	{"Certifications.csv", "Name", parseCertifications},
+	{"Languages.csv", "Name", parseLanguages},
+}
+
+// row is a CSV record keyed by column name
+type row map[string]string
+
+func (r row) get(column string) string {
+	return strings.TrimSpace(r[column])
+}
+
+// readCSV reads a CSV file into rows. Some exports start with a few lines of
+// notes, so everything before the row containing the header column is
+// skipped.
+func readCSV(f *zip.File, header string) ([]row, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	cr := csv.NewReader(rc)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var columns []string
+	var rows []row
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if columns == nil {
+			for i := range record {
+				record[i] = strings.TrimSpace(strings.TrimPrefix(record[i], "\ufeff"))
+			}
+			for _, c := range record {
+				if c == header {
+					columns = record
+					break
+				}
+			}
+			continue
+		}
+
+		r := make(row, len(columns))
+		for i, value := range record {
+			if i < len(columns) {
+				r[columns[i]] = value
+			}
+		}
+		rows = append(rows, r)
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("no %q column", header)
+	}
+	return rows, nil
+}
+
+func parseProfile(p *Profile, rows []row) {
+	if len(rows) == 0 {
+		return
+	}
+	r := rows[0]
+	if p.Contact == nil {
+		p.Contact = &models.ContactData{}
+	}
+	p.Contact.Name = strings.TrimSpace(r.get("First Name") + " " + r.get("Last Name"))
+	p.Contact.Location = r.get("Geo Location")
+	for _, url := range urlPattern.FindAllString(r.get("Websites"), -1) {
+		if strings.Contains(url, "github.com/") {
+			if p.Contact.GitHub == "" {
+				p.Contact.GitHub = url
+			}
+		} else if p.Contact.Website == "" {
+			p.Contact.Website = url
+		}
+	}
+	p.Summary = r.get("Summary")
+}
+
+// urlPattern finds the URLs in the Websites column, which looks like
+// "[PERSONAL:https://jane.dev,OTHER:https://github.com/jane]"
+var urlPattern = regexp.MustCompile(`https?://[^\s,\]]+`)
+
+// parseEmails takes the primary address, or the first one
+func parseEmails(p *Profile, rows []row) {
+	email := ""
+	for _, r := range rows {
+		if email == "" || strings.EqualFold(r.get("Primary"), "Yes") {
+			email = r.get("Email Address")
+		}
+		if strings.EqualFold(r.get("Primary"), "Yes") {
+			break
+		}
+	}
+	if email == "" {
+		return
+	}
+	if p.Contact == nil {
+		p.Contact = &models.ContactData{}
+	}
+	p.Contact.Email = email
+}
+
+func parsePhoneNumbers(p *Profile, rows []row) {
+	for _, r := range rows {
+		if number := r.get("Number"); number != "" {
+			if p.Contact == nil {
+				p.Contact = &models.ContactData{}
+			}
+			p.Contact.Phone = number
+			return
+		}
+	}
+}
+
+func parsePositions(p *Profile, rows []row) {
+	for _, r := range rows {
+		description, highlights := splitDescription(r.get("Description"))
+		p.Experience = append(p.Experience, models.ExperienceEntry{
+			Company:     r.get("Company Name"),
+			Role:        r.get("Title"),
+			StartDate:   kbDate(r.get("Started On")),
+			EndDate:     kbDate(r.get("Finished On")),
+			Location:    r.get("Location"),
+			Description: description,
+			Highlights:  highlights,
+		})
+	}
+}
+
+func parseEducation(p *Profile, rows []row) {
+	for _, r := range rows {
+		p.Education = append(p.Education, models.EducationEntry{
+			Institution: r.get("School Name"),
+			Degree:      r.get("Degree Name"),
+			StartDate:   kbDate(r.get("Start Date")),
+			EndDate:     kbDate(r.get("End Date")),
+		})
+	}
+}
+
+func parseSkills(p *Profile, rows []row) {
+	for _, r := range rows {
+		if name := r.get("Name"); name != "" {
+			p.Skills = append(p.Skills, name)
+		}
+	}
+}
+
+func parseCertifications(p *Profile, rows []row) {
+	for _, r := range rows {
+		p.Certifications = append(p.Certifications, models.CertificationEntry{
+			Name:         r.get("Name"),
+			Issuer:       r.get("Authority"),
+			Date:         kbDate(r.get("Started On")),
+			ExpiryDate:   kbDate(r.get("Finished On")),
+			CredentialID: r.get("License Number"),
+		})
+	}
+}
+
+func parseLanguages(p *Profile, rows []row) {
+	for _, r := range rows {
+		p.Languages = append(p.Languages, models.LanguageEntry{
+			Language:    r.get("Name"),
+			Proficiency: r.get("Proficiency"),
+		})
+	}
+}
+
+// splitDescription separates bullet lines, which become highlights, from
+// the rest of a position's description
+func splitDescription(text string) (string, []string) {
+	var paragraphs, highlights []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if bullet := strings.TrimLeft(line, "•·▪*-– "); bullet != line && bullet != "" {
+			highlights = append(highlights, bullet)
+			continue
+		}
+		paragraphs = append(paragraphs, line)
+	}
+	return strings.Join(paragraphs, "\n"), highlights
+}
+
+// dateLayouts are the date formats found in LinkedIn exports
+// kbDate converts a LinkedIn date to the KB's YYYY-MM ("Jan 2020" becomes
+// "2020-01"). Years are kept as they are, as is anything unrecognized.
+func kbDate(date string) models.Date {
+	d, err := models.ParseDate(date)
+	if err != nil {
+		return models.Date(strings.TrimSpace(date))
+	}
+	if !d.IsPresent() && d.Precision() == models.PrecisionDay {
+		return models.Date(d.Month())
+	}
+	return d
+}
@@ -0,0 +1,151 @@
+package linkedin
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// sampleExport is a trimmed LinkedIn data export, with the notes preamble
+// and byte order mark some files have
+var sampleExport = map[string]string{
+	"Profile.csv": "First Name,Last Name,Maiden Name,Address,Birth Date,Headline,Summary,Industry,Zip Code,Geo Location,Twitter Handles,Websites,Instant Messengers\n" +
+		"Jane,Doe,,,,Backend Engineer,Backend engineer focused on distributed systems.,Software,,\"Berlin, Germany\",,\"[PERSONAL:https://jane.dev,OTHER:https://github.com/janedoe]\",\n",
+	"Email Addresses.csv": "Email Address,Confirmed,Primary,Updated On\n" +
+		"old@example.com,Yes,No,1/2/16\n" +
+		"jane@example.com,Yes,Yes,3/4/20\n",
+	"PhoneNumbers.csv": "Extension,Number,Type\n,+49 123,Mobile\n",
+	"Positions.csv": "\ufeffCompany Name,Title,Description,Location,Started On,Finished On\n" +
+		"Acme,Senior Engineer,\"Payments platform.\n• Cut latency by 40%\n• Led a team of 4\",\"Berlin, Germany\",Jan 2020,\n" +
+		"Old Co,Developer,,Hamburg,Mar 2016,Dec 2019\n",
+	"Education.csv": "School Name,Start Date,End Date,Notes,Degree Name,Activities\n" +
+		"TU Berlin,2012,2016,,BSc Computer Science,\n" +
+		"Online Course,,,,,\n",
+	"Skills.csv": "Name\nGo\nKubernetes\n",
+	"Certifications.csv": "Name,Url,Authority,Started On,Finished On,License Number\n" +
+		"CKA,https://cncf.io/cka,CNCF,Jun 2021,Jun 2024,X1\n",
+	"Languages.csv":   "Name,Proficiency\nEnglish,Native or bilingual proficiency\n",
+	"Connections.csv": "Notes:\n\"When exporting your connection data, you may notice...\"\n\nFirst Name,Last Name,URL\nJohn,Smith,https://www.linkedin.com/in/jsmith\n",
+}
+
+func makeZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func readSample(t *testing.T, files map[string]string) *Profile {
+	t.Helper()
+	r := makeZip(t, files)
+	p, err := Read(r, r.Size())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return p
+}
+
+func TestRead(t *testing.T) {
+	p := readSample(t, sampleExport)
+
+	wantContact := models.ContactData{
+		Name: "Jane Doe", Email: "jane@example.com", Phone: "+49 123", Location: "Berlin, Germany",
+		GitHub: "https://github.com/janedoe", Website: "https://jane.dev",
+	}
+	if p.Contact == nil || *p.Contact != wantContact {
+		t.Errorf("Contact = %+v, want %+v", p.Contact, wantContact)
+	}
+	if p.Summary != "Backend engineer focused on distributed systems." {
+		t.Errorf("Summary = %q", p.Summary)
+	}
+
+	wantExp := []models.ExperienceEntry{
+		{Company: "Acme", Role: "Senior Engineer", StartDate: "2020-01", Location: "Berlin, Germany",
+			Description: "Payments platform.", Highlights: []string{"Cut latency by 40%", "Led a team of 4"}},
+		{Company: "Old Co", Role: "Developer", StartDate: "2016-03", EndDate: "2019-12", Location: "Hamburg"},
+	}
+	if !reflect.DeepEqual(p.Experience, wantExp) {
+		t.Errorf("Experience = %+v, want %+v", p.Experience, wantExp)
+	}
+
+	if len(p.Education) != 2 || p.Education[0] != (models.EducationEntry{Institution: "TU Berlin", Degree: "BSc Computer Science", StartDate: "2012", EndDate: "2016"}) {
+		t.Errorf("Education = %+v", p.Education)
+	}
+	if strings.Join(p.Skills, ",") != "Go,Kubernetes" {
+		t.Errorf("Skills = %v", p.Skills)
+	}
+	wantCert := models.CertificationEntry{Name: "CKA", Issuer: "CNCF", Date: "2021-06", ExpiryDate: "2024-06", CredentialID: "X1"}
+	if len(p.Certifications) != 1 || p.Certifications[0] != wantCert {
+		t.Errorf("Certifications = %+v", p.Certifications)
+	}
+	if len(p.Languages) != 1 || p.Languages[0] != (models.LanguageEntry{Language: "English", Proficiency: "Native or bilingual proficiency"}) {
+		t.Errorf("Languages = %+v", p.Languages)
+	}
+}
+
+func TestReadSubdirectory(t *testing.T) {
+	p := readSample(t, map[string]string{"Basic_LinkedInDataExport/Skills.csv": "Name\nGo\n"})
+	if len(p.Skills) != 1 || p.Contact != nil {
+		t.Errorf("unexpected profile: %+v", p)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	r := bytes.NewReader([]byte("not a zip"))
+	if _, err := Read(r, r.Size()); err == nil || !strings.Contains(err.Error(), "not a LinkedIn data export") {
+		t.Errorf("expected zip error, got %v", err)
+	}
+
+	r = makeZip(t, map[string]string{"notes.txt": "hello"})
+	if _, err := Read(r, r.Size()); err == nil || !strings.Contains(err.Error(), "no Profile.csv") {
+		t.Errorf("expected missing files error, got %v", err)
+	}
+
+	r = makeZip(t, map[string]string{"Positions.csv": "Company,Role\nAcme,Engineer\n"})
+	if _, err := Read(r, r.Size()); err == nil || !strings.Contains(err.Error(), `Positions.csv: no "Company Name" column`) {
+		t.Errorf("expected header error, got %v", err)
+	}
+}
+
+func TestKBDate(t *testing.T) {
+	tests := map[string]models.Date{
+		"Jan 2020":       "2020-01",
+		"September 2018": "2018-09",
+		"Mar 5, 2021":    "2021-03",
+		"03/2019":        "2019-03",
+		"2020-01-15":     "2020-01",
+		"2016":           "2016",
+		" Dec 2019 ":     "2019-12",
+		"":               "",
+		"someday":        "someday",
+	}
+	for in, want := range tests {
+		if got := kbDate(in); got != want {
+			t.Errorf("kbDate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSplitDescription(t *testing.T) {
+	description, highlights := splitDescription("Led the platform team.\n\n- Built CI\n* Cut costs\n• Hired 3\nMore context.")
+	if description != "Led the platform team.\nMore context." {
+		t.Errorf("description = %q", description)
+	}
+	if want := []string{"Built CI", "Cut costs", "Hired 3"}; !reflect.DeepEqual(highlights, want) {
+		t.Errorf("highlights = %q, want %q", highlights, want)
+	}
+}
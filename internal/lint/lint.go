@@ -0,0 +1,297 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Checks
+const (
+	CheckMissing   = "missing"   // No contact or skills entry
+	CheckDuplicate = "duplicate" // More than one contact or skills entry
+	CheckGap       = "gap"       // Unexplained gap between roles
+	CheckOverlap   = "overlap"   // Full-time roles that overlap
+	CheckPresent   = "present"   // More than one ongoing role
+	CheckExpired   = "expired"   // Certification past its expiry date
+	CheckMetric    = "metric"    // Highlight without a number
+	CheckInvalid   = "invalid"   // Entry that can't be read or fails validation
+)
+
+// DefaultGapMonths is the longest gap between roles not reported by default
+const DefaultGapMonths = 3
+
+// Issue is one problem found in the KB
+type Issue struct {
+	Check   string   `json:"check"`
+	IDs     []string `json:"ids,omitempty"`
+	Message string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	if len(i.IDs) == 0 {
+		return fmt.Sprintf("[%s] %s", i.Check, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Check, strings.Join(i.IDs, ", "), i.Message)
+}
+
+// Options configure the checks
+type Options struct {
+	// GapMonths is the longest gap between roles that isn't reported
+	GapMonths int
+	// Now is the date ongoing roles end and certifications expire against
+	Now time.Time
+}
+
+// role is a dated experience entry, as a range of month numbers
+type role struct {
+	id       string
+	exp      models.ExperienceEntry
+	from, to int
+	fullTime bool
+}
+
+// Lint checks the KB entries for problems a reader of the resume would
+// notice, in a fixed order: missing and duplicate entries, the career
+// timeline, certifications, then highlights.
+func Lint(entries []*models.KBEntry, opts Options) []Issue {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	now := month(opts.Now)
+
+	var issues []Issue
+	var roles []role
+	var covered [][2]int // Education, volunteering and projects explain gaps
+	singletons := make(map[models.ProfileCategory][]string)
+
+	for _, e := range entries {
+		if e.Type != models.KBTypeProfile {
+			continue
+		}
+		category := models.ProfileCategory(e.Category)
+		if category.IsSingleton() {
+			singletons[category] = append(singletons[category], e.ID)
+		}
+		switch category {
+		case models.CategoryExperience:
+			if exp, ok := e.AsExperience(); ok {
+				if from, to, ok := span(exp.StartDate, exp.EndDate, now); ok {
+					roles = append(roles, role{id: e.ID, exp: exp, from: from, to: to, fullTime: isFullTime(exp)})
+				}
+			}
+		case models.CategoryEducation:
+			if edu, ok := e.AsEducation(); ok {
+				if from, to, ok := span(edu.StartDate, edu.EndDate, now); ok {
+					covered = append(covered, [2]int{from, to})
+				}
+			}
+		case models.CategoryVolunteering:
+			if v, ok := e.AsVolunteering(); ok {
+				if from, to, ok := span(v.StartDate, v.EndDate, now); ok {
+					covered = append(covered, [2]int{from, to})
+				}
+			}
+		case models.CategoryProjects:
+			if p, ok := e.AsProject(); ok {
+				if from, to, ok := span(p.StartDate, p.EndDate, now); ok {
+					covered = append(covered, [2]int{from, to})
+				}
+			}
+		}
+	}
+
+	for _, category := range []models.ProfileCategory{models.CategoryContact, models.CategorySkills} {
+		switch ids := singletons[category]; {
+		case len(ids) == 0:
+			issues = append(issues, Issue{Check: CheckMissing, Message: fmt.Sprintf("no %s entry; add one with 'bragger kb add --type profile --category %s'", category, category)})
+		case len(ids) > 1:
+			issues = append(issues, Issue{Check: CheckDuplicate, IDs: ids, Message: fmt.Sprintf("%d %s entries; merge them with 'bragger kb merge %s %s'", len(ids), category, ids[0], ids[1])})
+		}
+	}
+
+	sort.SliceStable(roles, func(i, j int) bool { return roles[i].from < roles[j].from })
+	issues = append(issues, gaps(roles, covered, opts.GapMonths)...)
+	issues = append(issues, overlaps(roles)...)
+	issues = append(issues, ongoing(entries)...)
+	issues = append(issues, expired(entries, now)...)
+	issues = append(issues, metrics(entries)...)
+	return issues
+}
+
+// gaps reports the stretches between roles longer than maxMonths that no
+// education, volunteering or project covers. The time since the last role
+// isn't a gap.
+func gaps(roles []role, covered [][2]int, maxMonths int) []Issue {
+	var issues []Issue
+	for i := 1; i < len(roles); i++ {
+		// The latest end among the earlier roles
+		prev := roles[0]
+		for _, r := range roles[:i] {
+			if r.to > prev.to {
+				prev = r
+			}
+		}
+		next := roles[i]
+		if next.from <= prev.to+1 {
+			continue
+		}
+
+		uncovered := 0
+		for m := prev.to + 1; m < next.from; m++ {
+			if !within(m, covered) {
+				uncovered++
+			}
+		}
+		if uncovered > maxMonths {
+			issues = append(issues, Issue{
+				Check: CheckGap,
+				IDs:   []string{prev.id, next.id},
+				Message: fmt.Sprintf("%d-month gap between %s (ended %s) and %s (started %s)",
+					uncovered, prev.exp.Company, prev.exp.EndDate, next.exp.Company, next.exp.StartDate),
+			})
+		}
+	}
+	return issues
+}
+
+// overlaps reports full-time roles that overlap by more than a month, which
+// allows for a notice period
+func overlaps(roles []role) []Issue {
+	var issues []Issue
+	for i := range roles {
+		for j := i + 1; j < len(roles); j++ {
+			a, b := roles[i], roles[j]
+			if !a.fullTime || !b.fullTime {
+				continue
+			}
+			if months := min(a.to, b.to) - max(a.from, b.from) + 1; months > 1 {
+				issues = append(issues, Issue{
+					Check:   CheckOverlap,
+					IDs:     []string{a.id, b.id},
+					Message: fmt.Sprintf("full-time roles at %s and %s overlap for %d months", a.exp.Company, b.exp.Company, months),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// ongoing reports more than one experience entry without an end date
+func ongoing(entries []*models.KBEntry) []Issue {
+	var ids, companies []string
+	for _, e := range entries {
+		if exp, ok := e.AsExperience(); ok && (exp.EndDate == "" || exp.EndDate.IsPresent()) {
+			ids = append(ids, e.ID)
+			companies = append(companies, exp.Company)
+		}
+	}
+	if len(ids) < 2 {
+		return nil
+	}
+	return []Issue{{
+		Check:   CheckPresent,
+		IDs:     ids,
+		Message: fmt.Sprintf("%d roles are ongoing (%s); set an end_date on the ones that ended", len(ids), strings.Join(companies, ", ")),
+	}}
+}
+
+// expired reports certifications whose expiry date has passed
+func expired(entries []*models.KBEntry, now int) []Issue {
+	var issues []Issue
+	for _, e := range entries {
+		cert, ok := e.AsCertification()
+		if !ok || cert.ExpiryDate == "" || cert.ExpiryDate.IsPresent() {
+			continue
+		}
+		if _, to, ok := span(cert.ExpiryDate, cert.ExpiryDate, now); ok && to < now {
+			issues = append(issues, Issue{
+				Check:   CheckExpired,
+				IDs:     []string{e.ID},
+				Message: fmt.Sprintf("certification %s expired %s", cert.Name, cert.ExpiryDate),
+			})
+		}
+	}
+	return issues
+}
+
+// metricPattern matches a number, in digits or words, in a highlight. Digits
+// inside a word, like S3 or EC2, aren't a number.
+var metricPattern = regexp.MustCompile(`(?i)\b\d+([.,]\d+)?\s*(%|x|k|m|ms|s)?\b|\b(one|two|three|four|five|six|seven|eight|nine|ten|twelve|dozens?|hundreds?|thousands?|millions?|billions?|double[ds]?|tripled?|half|twice)\b`)
+
+// metrics reports highlights of experience, projects and volunteering that
+// don't quantify their impact
+func metrics(entries []*models.KBEntry) []Issue {
+	var issues []Issue
+	for _, e := range entries {
+		var name string
+		var highlights []string
+		if exp, ok := e.AsExperience(); ok {
+			name, highlights = exp.Company, exp.Highlights
+		} else if p, ok := e.AsProject(); ok {
+			name, highlights = p.Name, p.Highlights
+		} else if v, ok := e.AsVolunteering(); ok {
+			name, highlights = v.Organization, v.Highlights
+		}
+		for _, h := range highlights {
+			if !metricPattern.MatchString(h) {
+				issues = append(issues, Issue{
+					Check:   CheckMetric,
+					IDs:     []string{e.ID},
+					Message: fmt.Sprintf("%s highlight has no metric: %q", name, h),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// notFullTime matches words in a role or description that mark a role as
+// not full-time, so it may overlap others
+var notFullTime = regexp.MustCompile(`(?i)\b(part[- ]time|freelance|freelancer|contract|contractor|advisor|adviser|intern|internship|side project)\b`)
+
+func isFullTime(exp models.ExperienceEntry) bool {
+	return !notFullTime.MatchString(exp.Role + " " + exp.Description)
+}
+
+// month converts a time to a month number
+func month(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// span converts a date range to month numbers, both inclusive. An empty or
+// present end is now, and a year-only end is that December.
+func span(start, end models.Date, now int) (int, int, bool) {
+	startTime, ok := start.Time()
+	if !ok || start.IsPresent() {
+		return 0, 0, false
+	}
+	from, to := month(startTime), now
+	if end != "" && !end.IsPresent() {
+		endTime, ok := end.Time()
+		if !ok {
+			return 0, 0, false
+		}
+		to = month(endTime)
+		if end.Precision() == models.PrecisionYear {
+			to += 11
+		}
+	}
+	if to < from {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+func within(m int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if m >= r[0] && m <= r[1] {
+			return true
+		}
+	}
+	return false
+}
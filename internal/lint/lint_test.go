@@ -0,0 +1,178 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+var now = time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+func entry(id string, category models.ProfileCategory, data models.ProfileData) *models.KBEntry {
+	e := models.NewProfileEntry(category, data, "test")
+	e.ID = id
+	return e
+}
+
+// baseEntries have no issues
+func baseEntries() []*models.KBEntry {
+	return []*models.KBEntry{
+		entry("kb-contact", models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@example.com"}),
+		entry("kb-skills", models.CategorySkills, models.SkillsData{Languages: []string{"Go"}}),
+	}
+}
+
+// checks returns the issues found, by check
+func checks(issues []Issue) map[string][]Issue {
+	m := make(map[string][]Issue)
+	for _, i := range issues {
+		m[i.Check] = append(m[i.Check], i)
+	}
+	return m
+}
+
+func TestLintClean(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-old", models.CategoryExperience, models.ExperienceEntry{Company: "Old", Role: "Dev", StartDate: "2015-01", EndDate: "2019-12",
+			Highlights: []string{"Cut build times by 40%", "Mentored two juniors"}}),
+		entry("kb-new", models.CategoryExperience, models.ExperienceEntry{Company: "New", Role: "Lead", StartDate: "2020-02", EndDate: models.Present}),
+		entry("kb-cert", models.CategoryCertifications, models.CertificationEntry{Name: "CKA", ExpiryDate: "2025-06"}),
+		models.NewContextEntry("note", "no tests for this", "user"),
+	)
+	if issues := Lint(entries, Options{GapMonths: DefaultGapMonths, Now: now}); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestLintMissing(t *testing.T) {
+	issues := checks(Lint(nil, Options{Now: now}))[CheckMissing]
+	if len(issues) != 2 || !strings.Contains(issues[0].Message, "no contact entry") || !strings.Contains(issues[1].Message, "no skills entry") {
+		t.Errorf("expected missing contact and skills, got %v", issues)
+	}
+}
+
+func TestLintGaps(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-a", models.CategoryExperience, models.ExperienceEntry{Company: "A", Role: "Dev", StartDate: "2012-01", EndDate: "2014-12"}),
+		// Five months between A and B
+		entry("kb-b", models.CategoryExperience, models.ExperienceEntry{Company: "B", Role: "Dev", StartDate: "2015-06", EndDate: "2016-12"}),
+		// A year between B and C, covered by a degree
+		entry("kb-c", models.CategoryExperience, models.ExperienceEntry{Company: "C", Role: "Dev", StartDate: "2018-01", EndDate: "2018-12"}),
+		entry("kb-edu", models.CategoryEducation, models.EducationEntry{Institution: "TU", Degree: "MSc", StartDate: "2017", EndDate: "2017"}),
+		// Two months between C and D
+		entry("kb-d", models.CategoryExperience, models.ExperienceEntry{Company: "D", Role: "Dev", StartDate: "2019-03"}),
+	)
+
+	issues := checks(Lint(entries, Options{GapMonths: 3, Now: now}))[CheckGap]
+	if len(issues) != 1 {
+		t.Fatalf("expected one gap, got %v", issues)
+	}
+	if issues[0].IDs[0] != "kb-a" || issues[0].IDs[1] != "kb-b" || !strings.HasPrefix(issues[0].Message, "5-month gap between A (ended 2014-12) and B") {
+		t.Errorf("unexpected gap: %v", issues[0])
+	}
+
+	if issues := checks(Lint(entries, Options{GapMonths: 1, Now: now}))[CheckGap]; len(issues) != 2 {
+		t.Errorf("expected two gaps over one month, got %v", issues)
+	}
+}
+
+func TestLintOverlapsAndOngoing(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-a", models.CategoryExperience, models.ExperienceEntry{Company: "A", Role: "Dev", StartDate: "2020-01", EndDate: "2021-06"}),
+		entry("kb-b", models.CategoryExperience, models.ExperienceEntry{Company: "B", Role: "Dev", StartDate: "2021-01"}),
+		// A notice period and a part-time role don't count
+		entry("kb-c", models.CategoryExperience, models.ExperienceEntry{Company: "C", Role: "Dev", StartDate: "2018-01", EndDate: "2020-01"}),
+		entry("kb-d", models.CategoryExperience, models.ExperienceEntry{Company: "D", Role: "Part-time instructor", StartDate: "2021-03", EndDate: models.Present}),
+	)
+
+	found := checks(Lint(entries, Options{GapMonths: 3, Now: now}))
+	overlaps := found[CheckOverlap]
+	if len(overlaps) != 1 || overlaps[0].IDs[0] != "kb-a" || overlaps[0].IDs[1] != "kb-b" || !strings.Contains(overlaps[0].Message, "overlap for 6 months") {
+		t.Errorf("expected A and B to overlap, got %v", overlaps)
+	}
+	ongoing := found[CheckPresent]
+	if len(ongoing) != 1 || strings.Join(ongoing[0].IDs, ",") != "kb-b,kb-d" {
+		t.Errorf("expected B and D to be ongoing, got %v", ongoing)
+	}
+}
+
+func TestLintExpired(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-old", models.CategoryCertifications, models.CertificationEntry{Name: "CKA", Date: "2021-06", ExpiryDate: "2024-06"}),
+		entry("kb-year", models.CategoryCertifications, models.CertificationEntry{Name: "CKAD", ExpiryDate: "2025"}),
+		entry("kb-none", models.CategoryCertifications, models.CertificationEntry{Name: "AWS"}),
+	)
+	issues := checks(Lint(entries, Options{Now: now}))[CheckExpired]
+	if len(issues) != 1 || issues[0].IDs[0] != "kb-old" || issues[0].Message != "certification CKA expired 2024-06" {
+		t.Errorf("expected CKA to be expired, got %v", issues)
+	}
+}
+
+func TestLintMetrics(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-exp", models.CategoryExperience, models.ExperienceEntry{Company: "Acme", Role: "Dev", StartDate: "2020-01",
+			Highlights: []string{"Reduced latency by 40%", "Improved the deploy process", "Saved $2M a year", "Doubled throughput"}}),
+		entry("kb-proj", models.CategoryProjects, models.ProjectEntry{Name: "bragger", Highlights: []string{"Wrote the CLI"}}),
+	)
+	issues := checks(Lint(entries, Options{Now: now}))[CheckMetric]
+	if len(issues) != 2 {
+		t.Fatalf("expected two highlights without metrics, got %v", issues)
+	}
+	if issues[0].IDs[0] != "kb-exp" || !strings.Contains(issues[0].Message, `"Improved the deploy process"`) {
+		t.Errorf("unexpected issue: %v", issues[0])
+	}
+	if issues[1].IDs[0] != "kb-proj" {
+		t.Errorf("unexpected issue: %v", issues[1])
+	}
+}
+
+func TestMetricPattern(t *testing.T) {
+	for _, h := range []string{"Reduced latency by 40%", "Saved $2M a year", "Cut p99 to 200ms", "Served 1.5k requests a second", "10x faster builds", "Led a team of five"} {
+		if !metricPattern.MatchString(h) {
+			t.Errorf("expected a metric in %q", h)
+		}
+	}
+	// Digits in names aren't metrics
+	for _, h := range []string{"Migrated to S3", "Ran on EC2", "Web3 work"} {
+		if metricPattern.MatchString(h) {
+			t.Errorf("expected no metric in %q", h)
+		}
+	}
+}
+
+func TestIsFullTime(t *testing.T) {
+	tests := map[string]bool{
+		"Senior Engineer":          true,
+		"Internal Tools Engineer":  true,
+		"Software Engineer Intern": false,
+		"Freelance Developer":      false,
+		"Contract Engineer":        false,
+		"Advisor":                  false,
+	}
+	for role, want := range tests {
+		if got := isFullTime(models.ExperienceEntry{Role: role}); got != want {
+			t.Errorf("isFullTime(%q) = %v, want %v", role, got, want)
+		}
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	i := Issue{Check: CheckGap, IDs: []string{"kb-a", "kb-b"}, Message: "5-month gap"}
+	if got := i.String(); got != "[gap] kb-a, kb-b: 5-month gap" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Issue{Check: CheckMissing, Message: "no skills entry"}).String(); got != "[missing] no skills entry" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestLintDuplicate(t *testing.T) {
+	entries := append(baseEntries(),
+		entry("kb-contact2", models.CategoryContact, models.ContactData{Name: "Jane", Email: "j@example.com"}))
+	issues := checks(Lint(entries, Options{Now: now}))[CheckDuplicate]
+	if len(issues) != 1 || strings.Join(issues[0].IDs, ",") != "kb-contact,kb-contact2" || !strings.Contains(issues[0].Message, "bragger kb merge kb-contact kb-contact2") {
+		t.Errorf("expected duplicate contact, got %v", issues)
+	}
+}
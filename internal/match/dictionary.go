@@ -0,0 +1,305 @@
+package match
+
+import "sort"
+
+// Keyword categories, matching the skill's keyword analysis table
+const (
+	CategoryTechnical     = "Technical"
+	CategoryTool          = "Tool"
+	CategoryMethodology   = "Methodology"
+	CategorySoftSkill     = "Soft Skill"
+	CategoryDomain        = "Domain"
+	CategoryQualification = "Qualification"
+)
+
+// Term is a canonical keyword and the spellings that normalize to it.
+// Aliases are matched case-insensitively, except CaseSensitive ones, which
+// are common English words in lowercase ("go", "react", "rest").
+type Term struct {
+	Name          string
+	Category      string
+	Aliases       []string
+	CaseSensitive []string
+}
+
+// defaultTerms is the built-in skills and technology dictionary. The canonical
+// name is always matched too (case-insensitively unless listed in CaseSensitive).
+var defaultTerms = []Term{
+	// Programming languages
+	{Name: "Go", Category: CategoryTechnical, Aliases: []string{"golang"}, CaseSensitive: []string{"Go"}},
+	{Name: "Python", Category: CategoryTechnical},
+	{Name: "Java", Category: CategoryTechnical},
+	{Name: "JavaScript", Category: CategoryTechnical, Aliases: []string{"ecmascript"}, CaseSensitive: []string{"JS"}},
+	{Name: "TypeScript", Category: CategoryTechnical, CaseSensitive: []string{"TS"}},
+	{Name: "Rust", Category: CategoryTechnical},
+	{Name: "C++", Category: CategoryTechnical, Aliases: []string{"cpp"}},
+	{Name: "C#", Category: CategoryTechnical, Aliases: []string{"csharp"}},
+	{Name: "C", Category: CategoryTechnical, CaseSensitive: []string{"C"}},
+	{Name: "Ruby", Category: CategoryTechnical},
+	{Name: "PHP", Category: CategoryTechnical},
+	{Name: "Kotlin", Category: CategoryTechnical},
+	{Name: "Swift", Category: CategoryTechnical, CaseSensitive: []string{"Swift"}},
+	{Name: "Scala", Category: CategoryTechnical},
+	{Name: "Elixir", Category: CategoryTechnical},
+	{Name: "R", Category: CategoryTechnical, CaseSensitive: []string{"R"}},
+	{Name: "SQL", Category: CategoryTechnical},
+	{Name: "Bash", Category: CategoryTechnical, Aliases: []string{"shell scripting"}},
+	{Name: "HTML", Category: CategoryTechnical, Aliases: []string{"html5"}},
+	{Name: "CSS", Category: CategoryTechnical, Aliases: []string{"css3"}},
+
+	// Frameworks and libraries
+	{Name: "React", Category: CategoryTechnical, Aliases: []string{"react.js", "reactjs"}, CaseSensitive: []string{"React"}},
+	{Name: "React Native", Category: CategoryTechnical},
+	{Name: "Next.js", Category: CategoryTechnical, Aliases: []string{"nextjs"}},
+	{Name: "Vue", Category: CategoryTechnical, Aliases: []string{"vue.js", "vuejs"}},
+	{Name: "Angular", Category: CategoryTechnical, Aliases: []string{"angularjs"}},
+	{Name: "Svelte", Category: CategoryTechnical},
+	{Name: "Node.js", Category: CategoryTechnical, Aliases: []string{"nodejs"}, CaseSensitive: []string{"Node"}},
+	{Name: "Express", Category: CategoryTechnical, Aliases: []string{"express.js", "expressjs"}, CaseSensitive: []string{"Express"}},
+	{Name: "Django", Category: CategoryTechnical},
+	{Name: "Flask", Category: CategoryTechnical},
+	{Name: "FastAPI", Category: CategoryTechnical},
+	{Name: "Spring Boot", Category: CategoryTechnical, CaseSensitive: []string{"Spring"}},
+	{Name: "Ruby on Rails", Category: CategoryTechnical, CaseSensitive: []string{"Rails"}},
+	{Name: "Laravel", Category: CategoryTechnical},
+	{Name: ".NET", Category: CategoryTechnical, Aliases: []string{"dotnet", "asp.net"}},
+	{Name: "GraphQL", Category: CategoryTechnical},
+	{Name: "REST", Category: CategoryTechnical, Aliases: []string{"restful", "rest api", "rest apis"}, CaseSensitive: []string{"REST"}},
+	{Name: "gRPC", Category: CategoryTechnical},
+	{Name: "Tailwind", Category: CategoryTechnical, Aliases: []string{"tailwindcss", "tailwind css"}},
+	{Name: "Flutter", Category: CategoryTechnical},
+	{Name: "PyTorch", Category: CategoryTechnical},
+	{Name: "TensorFlow", Category: CategoryTechnical},
+	{Name: "Pandas", Category: CategoryTechnical, CaseSensitive: []string{"pandas"}},
+	{Name: "NumPy", Category: CategoryTechnical},
+
+	// Databases and data
+	{Name: "PostgreSQL", Category: CategoryTechnical, Aliases: []string{"postgres", "psql"}},
+	{Name: "MySQL", Category: CategoryTechnical},
+	{Name: "SQLite", Category: CategoryTechnical},
+	{Name: "MongoDB", Category: CategoryTechnical, Aliases: []string{"mongo"}},
+	{Name: "Redis", Category: CategoryTechnical},
+	{Name: "Elasticsearch", Category: CategoryTechnical, Aliases: []string{"elastic search", "opensearch"}},
+	{Name: "DynamoDB", Category: CategoryTechnical},
+	{Name: "Cassandra", Category: CategoryTechnical},
+	{Name: "ClickHouse", Category: CategoryTechnical},
+	{Name: "NoSQL", Category: CategoryTechnical},
+	{Name: "Kafka", Category: CategoryTechnical, Aliases: []string{"apache kafka"}},
+	{Name: "RabbitMQ", Category: CategoryTechnical},
+	{Name: "Spark", Category: CategoryTechnical, Aliases: []string{"apache spark", "pyspark"}, CaseSensitive: []string{"Spark"}},
+	{Name: "Airflow", Category: CategoryTool, Aliases: []string{"apache airflow"}},
+	{Name: "dbt", Category: CategoryTool, CaseSensitive: []string{"dbt"}},
+	{Name: "Snowflake", Category: CategoryTool},
+	{Name: "BigQuery", Category: CategoryTool},
+
+	// Cloud and infrastructure
+	{Name: "AWS", Category: CategoryTechnical, Aliases: []string{"amazon web services"}},
+	{Name: "GCP", Category: CategoryTechnical, Aliases: []string{"google cloud", "google cloud platform"}},
+	{Name: "Azure", Category: CategoryTechnical, Aliases: []string{"microsoft azure"}},
+	{Name: "Kubernetes", Category: CategoryTechnical, Aliases: []string{"k8s", "kube", "eks", "gke", "aks"}},
+	{Name: "Docker", Category: CategoryTechnical, Aliases: []string{"containers", "containerization"}},
+	{Name: "Helm", Category: CategoryTool},
+	{Name: "Terraform", Category: CategoryTool, Aliases: []string{"opentofu"}},
+	{Name: "Ansible", Category: CategoryTool},
+	{Name: "Serverless", Category: CategoryTechnical, Aliases: []string{"aws lambda"}},
+	{Name: "Linux", Category: CategoryTechnical, Aliases: []string{"unix"}},
+	{Name: "Nginx", Category: CategoryTool},
+	{Name: "Microservices", Category: CategoryTechnical, Aliases: []string{"microservice", "micro-services", "service-oriented architecture"}},
+	{Name: "Distributed Systems", Category: CategoryTechnical, Aliases: []string{"distributed system"}},
+	{Name: "Observability", Category: CategoryTechnical, Aliases: []string{"monitoring"}},
+	{Name: "Prometheus", Category: CategoryTool},
+	{Name: "Grafana", Category: CategoryTool},
+	{Name: "Datadog", Category: CategoryTool},
+	{Name: "OpenTelemetry", Category: CategoryTool, Aliases: []string{"otel"}},
+
+	// Engineering practices
+	{Name: "CI/CD", Category: CategoryMethodology, Aliases: []string{"ci-cd", "cicd", "continuous integration", "continuous delivery", "continuous deployment"}},
+	{Name: "DevOps", Category: CategoryMethodology},
+	{Name: "SRE", Category: CategoryMethodology, Aliases: []string{"site reliability engineering", "site reliability"}},
+	{Name: "Agile", Category: CategoryMethodology},
+	{Name: "Scrum", Category: CategoryMethodology},
+	{Name: "Kanban", Category: CategoryMethodology},
+	{Name: "TDD", Category: CategoryMethodology, Aliases: []string{"test-driven development", "test driven development"}},
+	{Name: "DDD", Category: CategoryMethodology, Aliases: []string{"domain-driven design", "domain driven design"}},
+	{Name: "Code Review", Category: CategoryMethodology, Aliases: []string{"code reviews"}},
+	{Name: "System Design", Category: CategoryMethodology, Aliases: []string{"systems design", "software architecture"}},
+	{Name: "Testing", Category: CategoryMethodology, Aliases: []string{"unit testing", "integration testing", "automated testing"}},
+	{Name: "Security", Category: CategoryMethodology, Aliases: []string{"application security", "appsec"}},
+
+	// Tools and platforms
+	{Name: "Git", Category: CategoryTool},
+	{Name: "GitHub", Category: CategoryTool},
+	{Name: "GitHub Actions", Category: CategoryTool},
+	{Name: "GitLab", Category: CategoryTool},
+	{Name: "Jenkins", Category: CategoryTool},
+	{Name: "Jira", Category: CategoryTool},
+	{Name: "Confluence", Category: CategoryTool},
+	{Name: "Figma", Category: CategoryTool},
+	{Name: "Salesforce", Category: CategoryTool},
+	{Name: "Tableau", Category: CategoryTool},
+	{Name: "Looker", Category: CategoryTool},
+	{Name: "Power BI", Category: CategoryTool, Aliases: []string{"powerbi"}},
+
+	// AI and data science
+	{Name: "Machine Learning", Category: CategoryDomain, CaseSensitive: []string{"ML"}},
+	{Name: "Deep Learning", Category: CategoryDomain},
+	{Name: "LLM", Category: CategoryDomain, Aliases: []string{"llms", "large language model", "large language models", "genai", "generative ai"}},
+	{Name: "NLP", Category: CategoryDomain, Aliases: []string{"natural language processing"}},
+	{Name: "MLOps", Category: CategoryDomain},
+	{Name: "Data Engineering", Category: CategoryDomain, Aliases: []string{"data pipelines", "etl"}},
+	{Name: "Data Science", Category: CategoryDomain},
+
+	// Domains and product areas
+	{Name: "Backend", Category: CategoryDomain, Aliases: []string{"back-end", "back end"}},
+	{Name: "Frontend", Category: CategoryDomain, Aliases: []string{"front-end", "front end"}},
+	{Name: "Full Stack", Category: CategoryDomain, Aliases: []string{"full-stack", "fullstack"}},
+	{Name: "Mobile", Category: CategoryDomain, Aliases: []string{"ios", "android"}},
+	{Name: "FinTech", Category: CategoryDomain, Aliases: []string{"financial services", "banking"}},
+	{Name: "Payments", Category: CategoryDomain},
+	{Name: "E-commerce", Category: CategoryDomain, Aliases: []string{"ecommerce"}},
+	{Name: "SaaS", Category: CategoryDomain},
+	{Name: "B2B", Category: CategoryDomain},
+	{Name: "Healthcare", Category: CategoryDomain, Aliases: []string{"healthtech", "health tech"}},
+	{Name: "Blockchain", Category: CategoryDomain, Aliases: []string{"web3", "crypto"}},
+
+	// Soft skills
+	{Name: "Leadership", Category: CategorySoftSkill, Aliases: []string{"led a team", "team lead", "tech lead", "leading teams", "lead a team"}},
+	{Name: "Mentoring", Category: CategorySoftSkill, Aliases: []string{"mentored", "mentor", "mentorship", "coaching"}},
+	{Name: "Communication", Category: CategorySoftSkill, Aliases: []string{"communication skills", "communicator"}},
+	{Name: "Collaboration", Category: CategorySoftSkill, Aliases: []string{"cross-functional", "cross functional", "collaborative"}},
+	{Name: "Problem Solving", Category: CategorySoftSkill, Aliases: []string{"problem-solving", "problem solver"}},
+	{Name: "Stakeholder Management", Category: CategorySoftSkill, Aliases: []string{"stakeholders"}},
+	{Name: "Ownership", Category: CategorySoftSkill, Aliases: []string{"end-to-end ownership"}},
+
+	// Qualifications
+	{Name: "Bachelor's Degree", Category: CategoryQualification, Aliases: []string{"bachelor's", "bachelors", "bsc", "b.sc.", "b.s."}},
+	{Name: "Master's Degree", Category: CategoryQualification, Aliases: []string{"master's", "masters", "msc", "m.sc.", "m.s."}},
+	{Name: "PhD", Category: CategoryQualification, Aliases: []string{"ph.d.", "doctorate"}},
+	{Name: "Computer Science", Category: CategoryQualification},
+}
+
+// Dictionary normalizes keywords found in free text to canonical terms
+type Dictionary struct {
+	terms    map[string]Term
+	patterns []pattern // Longest first, so "Spring Boot" wins over "Spring"
+}
+
+type pattern struct {
+	text          string
+	term          string
+	caseSensitive bool
+}
+
+// NewDictionary builds a dictionary from terms
+func NewDictionary(terms []Term) *Dictionary {
+	d := &Dictionary{terms: make(map[string]Term)}
+	for _, t := range terms {
+		d.terms[t.Name] = t
+
+		caseSensitiveName := false
+		for _, cs := range t.CaseSensitive {
+			d.patterns = append(d.patterns, pattern{text: cs, term: t.Name, caseSensitive: true})
+			if cs == t.Name {
+				caseSensitiveName = true
+			}
+		}
+		if !caseSensitiveName {
+			d.patterns = append(d.patterns, pattern{text: asciiLower(t.Name), term: t.Name})
+		}
+		for _, alias := range t.Aliases {
+			d.patterns = append(d.patterns, pattern{text: asciiLower(alias), term: t.Name})
+		}
+	}
+
+	sort.SliceStable(d.patterns, func(i, j int) bool {
+		return len(d.patterns[i].text) > len(d.patterns[j].text)
+	})
+	return d
+}
+
+// DefaultDictionary returns the built-in skills and technology dictionary
+func DefaultDictionary() *Dictionary {
+	return NewDictionary(defaultTerms)
+}
+
+// Category returns the category of a canonical term
+func (d *Dictionary) Category(term string) string {
+	return d.terms[term].Category
+}
+
+// Count finds dictionary terms in text and returns how often each canonical
+// term occurs. Synonyms are normalized ("k8s" counts as "Kubernetes") and a
+// span of text is only counted once, for its longest matching spelling.
+func (d *Dictionary) Count(text string) map[string]int {
+	counts := make(map[string]int)
+	original := []byte(text)
+	lower := []byte(asciiLower(text))
+
+	for _, p := range d.patterns {
+		haystack := lower
+		if p.caseSensitive {
+			haystack = original
+		}
+
+		for start := 0; start <= len(haystack)-len(p.text); {
+			idx := indexFrom(haystack, p.text, start)
+			if idx < 0 {
+				break
+			}
+			end := idx + len(p.text)
+			if isBoundary(haystack, idx-1, p.text[0]) && isBoundary(haystack, end, p.text[len(p.text)-1]) {
+				counts[p.term]++
+				// Mask the match so shorter patterns can't count it again
+				for i := idx; i < end; i++ {
+					original[i] = ' '
+					lower[i] = ' '
+				}
+			}
+			start = end
+		}
+	}
+
+	return counts
+}
+
+func indexFrom(haystack []byte, needle string, start int) int {
+	n := len(needle)
+	for i := start; i+n <= len(haystack); i++ {
+		if string(haystack[i:i+n]) == needle {
+			return i
+		}
+	}
+	return -1
+}
+
+// isBoundary reports whether position i (just outside a match) separates
+// words. Letters and digits continue a word; so do "+" and "#" after an
+// alphanumeric match, so "C" doesn't match inside "C++" or "C#".
+func isBoundary(text []byte, i int, edge byte) bool {
+	if i < 0 || i >= len(text) {
+		return true
+	}
+	c := text[i]
+	if isAlnum(c) {
+		return !isAlnum(edge)
+	}
+	if (c == '+' || c == '#') && isAlnum(edge) {
+		return false
+	}
+	return true
+}
+
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// asciiLower lowercases ASCII letters only, keeping byte offsets stable
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
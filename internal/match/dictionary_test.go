@@ -0,0 +1,58 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountSynonyms(t *testing.T) {
+	d := DefaultDictionary()
+
+	got := d.Count("We run k8s and Kubernetes clusters backed by Postgres and PostgreSQL.")
+	want := map[string]int{"Kubernetes": 2, "PostgreSQL": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Count() = %v, want %v", got, want)
+	}
+}
+
+func TestCountBoundaries(t *testing.T) {
+	d := DefaultDictionary()
+
+	tests := []struct {
+		text string
+		want map[string]int
+	}{
+		{"Experience with C++ and C#", map[string]int{"C++": 1, "C#": 1}},
+		{"Strong C and Go skills", map[string]int{"C": 1, "Go": 1}},
+		{"We go to Google offices", map[string]int{}},
+		{"Spring Boot services", map[string]int{"Spring Boot": 1}},
+		{"Node.js and Node", map[string]int{"Node.js": 2}},
+		{"react to incidents", map[string]int{}},
+		{"Build REST APIs; rest of the team", map[string]int{"REST": 1}},
+		{"Front-end and back end work", map[string]int{"Frontend": 1, "Backend": 1}},
+		{"CI/CD pipelines with GitHub Actions", map[string]int{"CI/CD": 1, "GitHub Actions": 1}},
+		{"PostgreSQL, not SQL", map[string]int{"PostgreSQL": 1, "SQL": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := d.Count(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Count(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	d := DefaultDictionary()
+
+	if got := d.Category("Kubernetes"); got != CategoryTechnical {
+		t.Errorf("Category(Kubernetes) = %q", got)
+	}
+	if got := d.Category("Agile"); got != CategoryMethodology {
+		t.Errorf("Category(Agile) = %q", got)
+	}
+	if got := d.Category("Unknown"); got != "" {
+		t.Errorf("Category(Unknown) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,232 @@
+package match
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Requirement types
+const (
+	RequirementRequired  = "required"
+	RequirementPreferred = "preferred"
+)
+
+// Evidence is a KB entry that supports a requirement
+type Evidence struct {
+	EntryID string `json:"entry_id"`
+	Summary string `json:"summary"`
+}
+
+// Requirement is one JD requirement and the KB evidence found for it
+type Requirement struct {
+	Text     string     `json:"requirement"`
+	Type     string     `json:"type"`
+	Keywords []string   `json:"keywords,omitempty"`
+	Missing  []string   `json:"missing,omitempty"` // Keywords with no KB evidence
+	Covered  bool       `json:"covered"`
+	Evidence []Evidence `json:"evidence,omitempty"`
+	Notes    string     `json:"notes,omitempty"`
+}
+
+// GapReport splits the JD requirements into covered ones and gaps
+type GapReport struct {
+	Covered []Requirement `json:"covered"`
+	Gaps    []Requirement `json:"gaps"`
+}
+
+// FindGaps checks each must-have and nice-to-have requirement of a JD
+// analysis against the KB. A requirement is covered when every keyword it
+// mentions appears in some KB entry (any one of them for "X or Y"
+// requirements) and the KB's experience entries add up to the years it asks
+// for. When the analysis found no requirement sections, each keyword of the
+// JD text becomes a required item.
+func FindGaps(d *Dictionary, analysis *models.JDAnalysis, jdText string, entries []*models.KBEntry, now time.Time) *GapReport {
+	docs := EvidenceDocuments(entries)
+	docCounts := make([]map[string]int, len(docs))
+	for i, doc := range docs {
+		docCounts[i] = d.Count(doc.Text)
+	}
+
+	report := &GapReport{Covered: []Requirement{}, Gaps: []Requirement{}}
+	for _, req := range requirements(d, analysis, jdText) {
+		checkKeywords(&req, docs, docCounts)
+		if exp := experienceFor(analysis, req.Text); exp != nil && (req.Covered || len(req.Keywords) == 0) {
+			checkYears(d, &req, *exp, entries, now)
+		}
+		if req.Covered {
+			report.Covered = append(report.Covered, req)
+		} else {
+			report.Gaps = append(report.Gaps, req)
+		}
+	}
+	return report
+}
+
+func requirements(d *Dictionary, analysis *models.JDAnalysis, jdText string) []Requirement {
+	var reqs []Requirement
+	if analysis != nil {
+		for _, text := range analysis.MustHave {
+			reqs = append(reqs, Requirement{Text: text, Type: RequirementRequired})
+		}
+		for _, text := range analysis.NiceToHave {
+			reqs = append(reqs, Requirement{Text: text, Type: RequirementPreferred})
+		}
+	}
+
+	if len(reqs) == 0 {
+		var names []string
+		for term := range d.Count(jdText) {
+			names = append(names, term)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			reqs = append(reqs, Requirement{Text: name, Type: RequirementRequired})
+		}
+	}
+
+	for i := range reqs {
+		for term := range d.Count(reqs[i].Text) {
+			reqs[i].Keywords = append(reqs[i].Keywords, term)
+		}
+		sort.Strings(reqs[i].Keywords)
+	}
+	return reqs
+}
+
+func checkKeywords(req *Requirement, docs []Document, docCounts []map[string]int) {
+	if len(req.Keywords) == 0 {
+		req.Notes = "No known skill keywords - check manually"
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, kw := range req.Keywords {
+		found := false
+		for i, doc := range docs {
+			if docCounts[i][kw] == 0 {
+				continue
+			}
+			found = true
+			if !seen[doc.ID] {
+				seen[doc.ID] = true
+				req.Evidence = append(req.Evidence, Evidence{EntryID: doc.ID, Summary: doc.Label})
+			}
+		}
+		if !found {
+			req.Missing = append(req.Missing, kw)
+		}
+	}
+
+	anyOf := strings.Contains(" "+strings.ToLower(req.Text)+" ", " or ")
+	switch {
+	case len(req.Missing) == 0:
+		req.Covered = true
+	case anyOf && len(req.Missing) < len(req.Keywords):
+		req.Covered = true
+		req.Missing = nil
+	case len(req.Missing) == len(req.Keywords):
+		req.Evidence = nil
+		req.Notes = "Not found in KB"
+	default:
+		req.Notes = "Missing from KB: " + strings.Join(req.Missing, ", ")
+	}
+}
+
+// experienceFor returns the years-of-experience requirement parsed from a
+// requirement line, if any
+func experienceFor(analysis *models.JDAnalysis, text string) *models.ExperienceRequirement {
+	if analysis == nil {
+		return nil
+	}
+	for i := range analysis.Experience {
+		if analysis.Experience[i].Text == text {
+			return &analysis.Experience[i]
+		}
+	}
+	return nil
+}
+
+// checkYears sums the months of the experience entries that mention the
+// requirement's subject (all experience when there is no known subject)
+// and decides coverage by whether they add up to the required years
+func checkYears(d *Dictionary, req *Requirement, exp models.ExperienceRequirement, entries []*models.KBEntry, now time.Time) {
+	var subjects []string
+	for term := range d.Count(exp.Subject) {
+		subjects = append(subjects, term)
+	}
+
+	months := make(map[int]bool)
+	var evidence []Evidence
+	for _, e := range entries {
+		if e.Type != models.KBTypeProfile || models.ProfileCategory(e.Category) != models.CategoryExperience {
+			continue
+		}
+		if len(subjects) > 0 {
+			doc, ok := entryDocument(e)
+			if !ok {
+				continue
+			}
+			counts := d.Count(doc.Text)
+			relevant := false
+			for _, s := range subjects {
+				if counts[s] > 0 {
+					relevant = true
+				}
+			}
+			if !relevant {
+				continue
+			}
+		}
+
+		entry, ok := e.AsExperience()
+		if !ok {
+			continue
+		}
+		start, ok := monthIndex(entry.StartDate, now)
+		if !ok {
+			continue
+		}
+		end, ok := monthIndex(entry.EndDate, now)
+		if !ok {
+			continue
+		}
+		// Both the start and end months count, and overlapping roles count
+		// once
+		for m := start; m <= end; m++ {
+			months[m] = true
+		}
+		if len(req.Keywords) == 0 {
+			if doc, ok := entryDocument(e); ok {
+				evidence = append(evidence, Evidence{EntryID: e.ID, Summary: doc.Label})
+			}
+		}
+	}
+
+	years := len(months) / 12
+	if years < exp.MinYears {
+		req.Covered = false
+		req.Evidence = nil
+		req.Notes = fmt.Sprintf("Needs %d+ years, KB shows %d", exp.MinYears, years)
+		return
+	}
+	req.Covered = true
+	req.Notes = ""
+	req.Evidence = append(req.Evidence, evidence...)
+}
+
+// monthIndex converts a YYYY-MM or YYYY date to a month count; an empty
+// date or "present" means now
+func monthIndex(date models.Date, now time.Time) (int, bool) {
+	if date == "" || date.IsPresent() {
+		return now.Year()*12 + int(now.Month()) - 1, true
+	}
+	t, ok := date.Time()
+	if !ok {
+		return 0, false
+	}
+	return t.Year()*12 + int(t.Month()) - 1, true
+}
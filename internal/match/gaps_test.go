@@ -0,0 +1,121 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func gapsFixture() ([]*models.KBEntry, time.Time) {
+	skills := models.NewProfileEntry(models.CategorySkills, models.SkillsData{Languages: []string{"Go", "Python"}, Databases: []string{"PostgreSQL"}}, "user")
+	skills.ID = "kb-skills"
+	current := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Acme", Role: "Backend Engineer", StartDate: "2021-01",
+		Highlights: []string{"Built Go services on Kubernetes"},
+	}, "user")
+	current.ID = "kb-acme"
+	previous := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Old Co", Role: "Developer", StartDate: "2018-01", EndDate: "2021-01",
+		Highlights: []string{"Maintained Python tooling"},
+	}, "user")
+	previous.ID = "kb-oldco"
+	note := models.NewContextEntry("achievement", "Mentored four junior engineers", "user")
+	note.ID = "kb-note"
+
+	return []*models.KBEntry{skills, current, previous, note}, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFindGaps(t *testing.T) {
+	entries, now := gapsFixture()
+	analysis := &models.JDAnalysis{
+		MustHave: []string{
+			"5+ years of experience with Go",
+			"Kubernetes and Kafka in production",
+			"PostgreSQL or MySQL",
+			"6+ years of professional experience",
+		},
+		NiceToHave: []string{"Mentoring engineers", "Healthcare background", "A passion for our mission"},
+		Experience: []models.ExperienceRequirement{
+			{MinYears: 5, Subject: "Go", Required: true, Text: "5+ years of experience with Go"},
+			{MinYears: 6, Required: true, Text: "6+ years of professional experience"},
+		},
+	}
+
+	r := FindGaps(DefaultDictionary(), analysis, "", entries, now)
+
+	covered := make(map[string]Requirement)
+	for _, req := range r.Covered {
+		covered[req.Text] = req
+	}
+	gaps := make(map[string]Requirement)
+	for _, req := range r.Gaps {
+		gaps[req.Text] = req
+	}
+
+	// Go is listed, but the only Go role started in 2021
+	if g, ok := gaps["5+ years of experience with Go"]; !ok || g.Notes != "Needs 5+ years, KB shows 4" {
+		t.Errorf("expected years gap for Go, got %+v", g)
+	}
+	if g := gaps["Kubernetes and Kafka in production"]; !reflect.DeepEqual(g.Missing, []string{"Kafka"}) || g.Notes != "Missing from KB: Kafka" {
+		t.Errorf("expected partial gap, got %+v", g)
+	}
+	if c, ok := covered["PostgreSQL or MySQL"]; !ok || c.Evidence[0].EntryID != "kb-skills" || c.Evidence[0].Summary != "Listed in skills" {
+		t.Errorf("expected PostgreSQL covered by skills, got %+v", c)
+	}
+	if c, ok := covered["6+ years of professional experience"]; !ok || len(c.Evidence) != 2 {
+		t.Errorf("expected total experience covered by both roles, got %+v", c)
+	}
+	if c, ok := covered["Mentoring engineers"]; !ok || c.Type != RequirementPreferred || c.Evidence[0].EntryID != "kb-note" {
+		t.Errorf("expected mentoring covered by context entry, got %+v", c)
+	}
+	if g := gaps["Healthcare background"]; g.Notes != "Not found in KB" || g.Evidence != nil {
+		t.Errorf("expected healthcare gap, got %+v", g)
+	}
+	if g := gaps["A passion for our mission"]; g.Notes != "No known skill keywords - check manually" {
+		t.Errorf("expected manual check, got %+v", g)
+	}
+}
+
+func TestFindGapsWithoutSections(t *testing.T) {
+	entries, now := gapsFixture()
+
+	r := FindGaps(DefaultDictionary(), &models.JDAnalysis{}, "We use Go, Rust and Kubernetes.", entries, now)
+
+	if len(r.Covered) != 2 || r.Covered[0].Text != "Go" || r.Covered[1].Text != "Kubernetes" {
+		t.Errorf("Covered = %+v", r.Covered)
+	}
+	if len(r.Gaps) != 1 || r.Gaps[0].Text != "Rust" {
+		t.Errorf("Gaps = %+v", r.Gaps)
+	}
+}
+
+func TestFindGapsYearsBoundary(t *testing.T) {
+	exp := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Acme", Role: "Developer", StartDate: "2020-01", EndDate: "2024-12",
+	}, "user")
+	analysis := &models.JDAnalysis{
+		MustHave:   []string{"5+ years of professional experience"},
+		Experience: []models.ExperienceRequirement{{MinYears: 5, Required: true, Text: "5+ years of professional experience"}},
+	}
+
+	// January 2020 through December 2024 is 60 months
+	r := FindGaps(DefaultDictionary(), analysis, "", []*models.KBEntry{exp}, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if len(r.Gaps) != 0 || len(r.Covered) != 1 {
+		t.Errorf("expected 5 years covered, got gaps %+v", r.Gaps)
+	}
+}
+
+func TestMonthIndex(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	a, _ := monthIndex("2024-03", now)
+	b, _ := monthIndex("present", now)
+	if b-a != 12 {
+		t.Errorf("expected 12 months between 2024-03 and present, got %d", b-a)
+	}
+	if _, ok := monthIndex("March 2020", now); ok {
+		t.Error("expected unsupported format to fail")
+	}
+}
@@ -0,0 +1,254 @@
+package match
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Document is a piece of candidate text that keywords are matched against,
+// e.g. one KB entry
+type Document struct {
+	ID    string
+	Label string // Short human-readable description of the source
+	Text  string
+}
+
+// Keyword is a JD keyword and how often it occurs in each source
+type Keyword struct {
+	Name        string `json:"keyword"`
+	Category    string `json:"category"`
+	JDCount     int    `json:"jd_count"`
+	KBCount     int    `json:"kb_count"`
+	ResumeCount int    `json:"resume_count,omitempty"`
+}
+
+// Score is the share of JD keywords found in a source
+type Score struct {
+	Matched int `json:"matched"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+func newScore(matched, total int) Score {
+	s := Score{Matched: matched, Total: total}
+	if total > 0 {
+		s.Percent = (matched*100 + total/2) / total
+	}
+	return s
+}
+
+// Report is the result of matching a JD against the knowledge base and,
+// optionally, a resume
+type Report struct {
+	Keywords []Keyword `json:"keywords"` // Sorted by JD frequency, then name
+	KB       Score     `json:"kb"`
+	Resume   *Score    `json:"resume,omitempty"`
+}
+
+// Compare extracts keywords from the JD and counts them in the KB documents
+func Compare(d *Dictionary, jdText string, kb []Document) *Report {
+	jdCounts := d.Count(jdText)
+	kbCounts := make(map[string]int)
+	for _, doc := range kb {
+		for term, n := range d.Count(doc.Text) {
+			kbCounts[term] += n
+		}
+	}
+
+	r := &Report{}
+	matched := 0
+	for term, n := range jdCounts {
+		k := Keyword{Name: term, Category: d.Category(term), JDCount: n, KBCount: kbCounts[term]}
+		if k.KBCount > 0 {
+			matched++
+		}
+		r.Keywords = append(r.Keywords, k)
+	}
+	sort.Slice(r.Keywords, func(i, j int) bool {
+		if r.Keywords[i].JDCount != r.Keywords[j].JDCount {
+			return r.Keywords[i].JDCount > r.Keywords[j].JDCount
+		}
+		return r.Keywords[i].Name < r.Keywords[j].Name
+	})
+
+	r.KB = newScore(matched, len(r.Keywords))
+	return r
+}
+
+// ScoreResume counts the JD keywords in a resume's text
+func (r *Report) ScoreResume(d *Dictionary, resumeText string) {
+	counts := d.Count(resumeText)
+	matched := 0
+	for i := range r.Keywords {
+		r.Keywords[i].ResumeCount = counts[r.Keywords[i].Name]
+		if r.Keywords[i].ResumeCount > 0 {
+			matched++
+		}
+	}
+	score := newScore(matched, len(r.Keywords))
+	r.Resume = &score
+}
+
+// Found returns the keywords present in the KB, or in the resume when one was scored
+func (r *Report) Found() []Keyword {
+	return r.filter(true)
+}
+
+// Missing returns the keywords absent from the KB, or from the resume when one was scored
+func (r *Report) Missing() []Keyword {
+	return r.filter(false)
+}
+
+func (r *Report) filter(found bool) []Keyword {
+	var out []Keyword
+	for _, k := range r.Keywords {
+		count := k.KBCount
+		if r.Resume != nil {
+			count = k.ResumeCount
+		}
+		if (count > 0) == found {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// KBDocuments turns the profile entries that describe the candidate's
+// skills and experience into matchable documents
+func KBDocuments(entries []*models.KBEntry) []Document {
+	var docs []Document
+	for _, e := range entries {
+		category := models.ProfileCategory(e.Category)
+		if e.Type != models.KBTypeProfile || (category != models.CategorySkills && category != models.CategoryExperience) {
+			continue
+		}
+		if doc, ok := entryDocument(e); ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// EvidenceDocuments turns every KB entry that can support a requirement
+// (all profile categories except contact, plus context entries) into documents
+func EvidenceDocuments(entries []*models.KBEntry) []Document {
+	var docs []Document
+	for _, e := range entries {
+		if doc, ok := entryDocument(e); ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// entryDocument extracts the matchable text of an entry and a short label
+// describing it, e.g. "Senior Engineer @ Acme (2019-01 - present)"
+func entryDocument(e *models.KBEntry) (Document, bool) {
+	if e.Type == models.KBTypeContext {
+		return Document{ID: e.ID, Label: "Context: " + truncate(e.Content, 60), Text: e.Content}, e.Content != ""
+	}
+
+	var parts []string
+	var label string
+	switch models.ProfileCategory(e.Category) {
+	case models.CategorySkills:
+		s, ok := e.AsSkills()
+		if !ok {
+			return Document{}, false
+		}
+		for _, list := range [][]string{s.Languages, s.Frameworks, s.Tools, s.Databases, s.Cloud, s.Other} {
+			parts = append(parts, list...)
+		}
+		label = "Listed in skills"
+	case models.CategoryExperience:
+		exp, ok := e.AsExperience()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, exp.Role, exp.Description)
+		parts = append(parts, exp.Highlights...)
+		label = fmt.Sprintf("%s @ %s (%s - %s)", exp.Role, exp.Company, exp.StartDate, endDateLabel(exp.EndDate))
+	case models.CategoryEducation:
+		edu, ok := e.AsEducation()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, edu.Degree, edu.Field)
+		label = strings.TrimSpace(edu.Degree + " " + edu.Field + " @ " + edu.Institution)
+	case models.CategoryCertifications:
+		cert, ok := e.AsCertification()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, cert.Name)
+		label = "Certification: " + cert.Name
+	case models.CategoryLanguages:
+		lang, ok := e.AsLanguage()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, lang.Language)
+		label = "Speaks " + lang.Language
+	case models.CategoryProjects:
+		p, ok := e.AsProject()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, p.Name, p.Description)
+		parts = append(parts, p.Technologies...)
+		parts = append(parts, p.Highlights...)
+		label = "Project: " + p.Name
+	case models.CategoryPublications:
+		p, ok := e.AsPublication()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, p.Title, p.Description)
+		label = "Publication: " + p.Title
+	case models.CategoryAwards:
+		a, ok := e.AsAward()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, a.Title, a.Description)
+		label = "Award: " + a.Title
+	case models.CategoryVolunteering:
+		v, ok := e.AsVolunteering()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, v.Role, v.Description)
+		parts = append(parts, v.Highlights...)
+		label = fmt.Sprintf("%s @ %s (volunteer)", v.Role, v.Organization)
+	case models.CategoryTalks:
+		t, ok := e.AsTalk()
+		if !ok {
+			return Document{}, false
+		}
+		parts = append(parts, t.Title, t.Description)
+		label = fmt.Sprintf("Talk: %s @ %s", t.Title, t.Event)
+	default:
+		return Document{}, false
+	}
+
+	// One item per line so adjacent items can't form a phrase
+	return Document{ID: e.ID, Label: label, Text: strings.Join(parts, "\n")}, true
+}
+
+func endDateLabel(end models.Date) string {
+	if end == "" {
+		return string(models.Present)
+	}
+	return string(end)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:n])) + "..."
+}
@@ -0,0 +1,97 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+const sampleJD = `Senior Backend Engineer
+- 5+ years with Go and Kubernetes (k8s)
+- PostgreSQL and Kafka experience
+- Agile team`
+
+func TestCompare(t *testing.T) {
+	d := DefaultDictionary()
+	kb := []Document{
+		{ID: "kb-1", Text: "Go\nPostgres"},
+		{ID: "kb-2", Text: "Migrated services to Go on AWS"},
+	}
+
+	r := Compare(d, sampleJD, kb)
+
+	if r.KB.Total != 6 || r.KB.Matched != 2 || r.KB.Percent != 33 {
+		t.Errorf("KB score = %+v, want 2/6 (33%%)", r.KB)
+	}
+
+	// Most frequent JD keyword first
+	if r.Keywords[0].Name != "Kubernetes" || r.Keywords[0].JDCount != 2 {
+		t.Errorf("first keyword = %+v, want Kubernetes x2", r.Keywords[0])
+	}
+
+	found := r.Found()
+	if len(found) != 2 || found[0].Name != "Go" || found[0].KBCount != 2 {
+		t.Errorf("Found() = %+v", found)
+	}
+	if missing := r.Missing(); len(missing) != 4 {
+		t.Errorf("expected 4 missing keywords, got %+v", missing)
+	}
+}
+
+func TestScoreResume(t *testing.T) {
+	d := DefaultDictionary()
+	r := Compare(d, sampleJD, nil)
+
+	r.ScoreResume(d, "Go engineer running Kubernetes, Kafka and Agile ceremonies")
+
+	if r.Resume == nil || r.Resume.Matched != 4 || r.Resume.Percent != 67 {
+		t.Fatalf("Resume score = %+v, want 4/6 (67%%)", r.Resume)
+	}
+	missing := r.Missing()
+	if len(missing) != 2 {
+		t.Errorf("expected resume-based missing keywords, got %+v", missing)
+	}
+}
+
+func TestKBDocuments(t *testing.T) {
+	entries := []*models.KBEntry{
+		models.NewProfileEntry(models.CategorySkills, models.SkillsData{Languages: []string{"Go"}, Cloud: []string{"AWS"}}, "user"),
+		models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+			Company: "Acme", Role: "Backend Engineer", StartDate: "2020-01",
+			Highlights: []string{"Ran Kafka pipelines"},
+		}, "user"),
+		models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Go Person", Email: "a@b.c"}, "user"),
+		models.NewContextEntry("notes", "Kubernetes fan", "user"),
+	}
+
+	docs := KBDocuments(entries)
+	if len(docs) != 2 {
+		t.Fatalf("expected skills and experience documents, got %d", len(docs))
+	}
+	if docs[0].ID != entries[0].ID || docs[0].Text != "Go\nAWS" {
+		t.Errorf("skills document = %+v", docs[0])
+	}
+	if docs[1].Text != "Backend Engineer\n\nRan Kafka pipelines" {
+		t.Errorf("experience document = %q", docs[1].Text)
+	}
+}
+
+func TestEvidenceDocuments(t *testing.T) {
+	entries := []*models.KBEntry{
+		models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane", Email: "a@b.c"}, "user"),
+		models.NewProfileEntry(models.CategoryProjects, models.ProjectEntry{Name: "bragger", Description: "Job search CLI", Technologies: []string{"Go"}}, "user"),
+		models.NewProfileEntry(models.CategoryTalks, models.TalkEntry{Title: "Kafka in Practice", Event: "GopherCon"}, "user"),
+		models.NewContextEntry("notes", "Kubernetes fan", "user"),
+	}
+
+	docs := EvidenceDocuments(entries)
+	if len(docs) != 3 {
+		t.Fatalf("expected project, talk and context documents, got %d", len(docs))
+	}
+	if docs[0].Label != "Project: bragger" || docs[0].Text != "bragger\nJob search CLI\nGo" {
+		t.Errorf("project document = %+v", docs[0])
+	}
+	if docs[1].Label != "Talk: Kafka in Practice @ GopherCon" {
+		t.Errorf("talk document = %+v", docs[1])
+	}
+}
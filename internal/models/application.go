@@ -6,6 +6,9 @@ import (
 	"time"
 )
 
+// ApplicationSchemaVersion is the schema version of new application records
+const ApplicationSchemaVersion = 3
+
 type Status string
 
 const (
@@ -13,29 +16,42 @@ const (
 	StatusInterviewing Status = "interviewing"
 	StatusRejected     Status = "rejected"
 	StatusOffer        Status = "offer"
+	StatusWishlist     Status = "wishlist" // Saved for later, not yet applied
 )
 
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusApplied, StatusInterviewing, StatusRejected, StatusOffer:
+	case StatusApplied, StatusInterviewing, StatusRejected, StatusOffer, StatusWishlist:
 		return true
 	}
 	return false
 }
 
 type Application struct {
-	ID          string    `json:"id"`
-	Company     string    `json:"company"`
-	Role        string    `json:"role"`
-	Status      Status    `json:"status"`
-	DateApplied string    `json:"date_applied"`
-	JDURL       string    `json:"jd_url,omitempty"`
-	JDContent   string    `json:"jd_content,omitempty"`
-	ResumePath  string    `json:"resume_path,omitempty"`
-	CompanyURL  string    `json:"company_url,omitempty"`
-	Notes       string    `json:"notes,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string      `json:"id"`
+	Company     string      `json:"company"`
+	Role        string      `json:"role"`
+	Status      Status      `json:"status"`
+	DateApplied Date        `json:"date_applied"`
+	JDURL       string      `json:"jd_url,omitempty"`
+	JDContent   string      `json:"jd_content,omitempty"`
+	JDSnapshot  string      `json:"jd_snapshot,omitempty"` // Archived HTML of the fetched JD
+	ResumePath  string      `json:"resume_path,omitempty"`
+	CompanyURL  string      `json:"company_url,omitempty"`
+	Notes       string      `json:"notes,omitempty"`
+	JDAnalysis  *JDAnalysis `json:"jd_analysis,omitempty"` // Structured JD, see 'bragger jd analyze'
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	// ArchivedAt is set on applications hidden from list and stats
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// SchemaVersion is the version of this record's layout; records written
+	// before versioning have none. See storage.Storage.Migrate.
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+// IsArchived reports whether the application is archived
+func (a *Application) IsArchived() bool {
+	return a.ArchivedAt != nil
 }
 
 func GenerateID() string {
@@ -47,12 +63,13 @@ func GenerateID() string {
 func NewApplication(company, role string) *Application {
 	now := time.Now()
 	return &Application{
-		ID:          GenerateID(),
-		Company:     company,
-		Role:        role,
-		Status:      StatusApplied,
-		DateApplied: now.Format("2006-01-02"),
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:            GenerateID(),
+		Company:       company,
+		Role:          role,
+		Status:        StatusApplied,
+		DateApplied:   Date(now.Format("2006-01-02")),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		SchemaVersion: ApplicationSchemaVersion,
 	}
 }
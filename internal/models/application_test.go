@@ -21,7 +21,7 @@ func TestNewApplication(t *testing.T) {
 	if app.Status != StatusApplied {
 		t.Errorf("expected status %q, got %q", StatusApplied, app.Status)
 	}
-	if app.DateApplied != time.Now().Format("2006-01-02") {
+	if string(app.DateApplied) != time.Now().Format("2006-01-02") {
 		t.Errorf("expected date_applied to be today, got %q", app.DateApplied)
 	}
 	if !strings.HasPrefix(app.ID, "app-") {
@@ -30,6 +30,9 @@ func TestNewApplication(t *testing.T) {
 	if len(app.ID) != 12 { // "app-" + 8 hex chars
 		t.Errorf("expected ID length 12, got %d", len(app.ID))
 	}
+	if app.SchemaVersion != ApplicationSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", ApplicationSchemaVersion, app.SchemaVersion)
+	}
 }
 
 func TestGenerateID(t *testing.T) {
@@ -53,6 +56,7 @@ func TestStatusIsValid(t *testing.T) {
 		{StatusInterviewing, true},
 		{StatusRejected, true},
 		{StatusOffer, true},
+		{StatusWishlist, true},
 		{Status("invalid"), false},
 		{Status(""), false},
 		{Status("APPLIED"), false}, // case sensitive
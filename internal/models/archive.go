@@ -0,0 +1,18 @@
+package models
+
+// Archivable is a record that can be archived: hidden by default, but kept
+// until it is purged
+type Archivable interface {
+	IsArchived() bool
+}
+
+// Unarchived returns the records that aren't archived
+func Unarchived[T Archivable](records []T) []T {
+	out := make([]T, 0, len(records))
+	for _, r := range records {
+		if !r.IsArchived() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
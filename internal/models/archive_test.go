@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnarchived(t *testing.T) {
+	now := time.Now()
+	apps := []*Application{NewApplication("A", "Dev"), NewApplication("B", "Dev"), NewApplication("C", "Dev")}
+	apps[1].ArchivedAt = &now
+
+	got := Unarchived(apps)
+	if len(got) != 2 || got[0].Company != "A" || got[1].Company != "C" {
+		t.Errorf("Unarchived = %v", got)
+	}
+	if !apps[1].IsArchived() || apps[0].IsArchived() {
+		t.Error("unexpected IsArchived")
+	}
+}
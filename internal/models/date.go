@@ -0,0 +1,197 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date is a date in a KB entry or application, stored in its canonical form:
+// YYYY, YYYY-MM or YYYY-MM-DD, or "present" for something still ongoing.
+// ParseDate accepts common spellings like "Jan 2020" and "2020/01".
+type Date string
+
+// Present is the end date of an ongoing role or project
+const Present Date = "present"
+
+// DatePrecision is the part of a date that is known
+type DatePrecision int
+
+const (
+	PrecisionYear DatePrecision = iota + 1
+	PrecisionMonth
+	PrecisionDay
+)
+
+// dateLayouts are the accepted input layouts, by precision. Month names are
+// matched case-insensitively by time.Parse.
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{"2006", PrecisionYear},
+	{"2006-01", PrecisionMonth},
+	{"2006-1", PrecisionMonth},
+	{"2006/01", PrecisionMonth},
+	{"2006/1", PrecisionMonth},
+	{"01/2006", PrecisionMonth},
+	{"1/2006", PrecisionMonth},
+	{"Jan 2006", PrecisionMonth},
+	{"January 2006", PrecisionMonth},
+	{"2006-01-02", PrecisionDay},
+	{"2006/01/02", PrecisionDay},
+	{"Jan 2 2006", PrecisionDay},
+	{"January 2 2006", PrecisionDay},
+	{"2 Jan 2006", PrecisionDay},
+	{"2 January 2006", PrecisionDay},
+}
+
+// canonicalLayouts format a date at each precision
+var canonicalLayouts = map[DatePrecision]string{
+	PrecisionYear:  "2006",
+	PrecisionMonth: "2006-01",
+	PrecisionDay:   "2006-01-02",
+}
+
+// ParseDate parses a date in one of the accepted forms and returns it in
+// canonical form. "present" and "current" (in any case) are Present.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "":
+		return "", nil
+	case "present", "current":
+		return Present, nil
+	}
+
+	// "Jan. 5, 2020" -> "Jan 5 2020"
+	cleaned := strings.Join(strings.Fields(strings.NewReplacer(".", " ", ",", " ").Replace(s)), " ")
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, cleaned); err == nil {
+			return Date(t.Format(canonicalLayouts[l.precision])), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q: use YYYY-MM, YYYY-MM-DD, YYYY or \"present\"", s)
+}
+
+// IsPresent reports whether the date is Present
+func (d Date) IsPresent() bool {
+	return d == Present
+}
+
+// parse returns the start of the period the date covers and its precision.
+// Present is the current time, at day precision.
+func (d Date) parse() (time.Time, DatePrecision, bool) {
+	if d.IsPresent() {
+		return time.Now(), PrecisionDay, true
+	}
+	for precision, layout := range canonicalLayouts {
+		if t, err := time.Parse(layout, string(d)); err == nil {
+			return t, precision, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// Time returns the start of the period the date covers, like January 1st for
+// a year, or the current time for Present. It returns false for an empty or
+// non-canonical date.
+func (d Date) Time() (time.Time, bool) {
+	t, _, ok := d.parse()
+	return t, ok
+}
+
+// Precision returns the part of the date that is known, or 0 for an empty or
+// non-canonical date
+func (d Date) Precision() DatePrecision {
+	_, precision, _ := d.parse()
+	return precision
+}
+
+// Month returns the date as YYYY-MM, or "" if the month isn't known
+func (d Date) Month() string {
+	t, precision, ok := d.parse()
+	if !ok || precision < PrecisionMonth {
+		return ""
+	}
+	return t.Format("2006-01")
+}
+
+// Compare returns -1, 0 or +1 as d is before, the same as or after other. Dates
+// are compared at the precision they share, so "2020" and "2020-05" are the
+// same. Empty and non-canonical dates are before every valid date.
+func (d Date) Compare(other Date) int {
+	t1, p1, ok1 := d.parse()
+	t2, p2, ok2 := other.parse()
+	switch {
+	case !ok1 && !ok2:
+		return 0
+	case !ok1:
+		return -1
+	case !ok2:
+		return 1
+	}
+	layout := canonicalLayouts[min(p1, p2)]
+	return strings.Compare(t1.Format(layout), t2.Format(layout))
+}
+
+// Before reports whether d is before other, as in Compare
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// Validate checks that a non-empty date is in canonical form
+func (d Date) Validate() error {
+	if d == "" {
+		return nil
+	}
+	if _, _, ok := d.parse(); !ok {
+		return fmt.Errorf("invalid date %q: use YYYY-MM, YYYY-MM-DD, YYYY or \"present\"", string(d))
+	}
+	return nil
+}
+
+// UnmarshalJSON normalizes dates in an accepted form. Other values are kept as
+// they are, so they aren't lost; Validate reports them.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if parsed, err := ParseDate(s); err == nil {
+		*d = parsed
+	} else {
+		*d = Date(s)
+	}
+	return nil
+}
+
+// validateDateRange checks a start and end date, either of which may be empty.
+// Only end may be Present.
+func validateDateRange(startField string, start Date, endField string, end Date) error {
+	if err := start.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", startField, err)
+	}
+	if start.IsPresent() {
+		return fmt.Errorf("%s can't be %q", startField, Present)
+	}
+	if err := end.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", endField, err)
+	}
+	if start != "" && end != "" && end.Before(start) {
+		return fmt.Errorf("%s %s is before %s %s", endField, end, startField, start)
+	}
+	return nil
+}
+
+// validateDate checks a single date, which may not be Present
+func validateDate(field string, d Date) error {
+	if err := d.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	if d.IsPresent() {
+		return fmt.Errorf("%s can't be %q", field, Present)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := map[string]Date{
+		"2020":             "2020",
+		"2020-01":          "2020-01",
+		"2020-1":           "2020-01",
+		"2020/01":          "2020-01",
+		"01/2020":          "2020-01",
+		"1/2020":           "2020-01",
+		"Jan 2020":         "2020-01",
+		"jan 2020":         "2020-01",
+		"January 2020":     "2020-01",
+		"Sep. 2018":        "2018-09",
+		"2020-01-15":       "2020-01-15",
+		"2020/01/15":       "2020-01-15",
+		"Jan 15, 2020":     "2020-01-15",
+		"15 January 2020":  "2020-01-15",
+		" 2020-01 ":        "2020-01",
+		"present":          Present,
+		"Present":          Present,
+		"CURRENT":          Present,
+		"":                 "",
+		"someday":          "",
+		"2020-13":          "",
+		"15-01-2025":       "",
+		"2020-01-15T10:00": "",
+	}
+	for in, want := range tests {
+		got, err := ParseDate(in)
+		if want == "" && strings.TrimSpace(in) != "" {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %q, want error", in, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("ParseDate(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestDateCompare(t *testing.T) {
+	tests := []struct {
+		a, b Date
+		want int
+	}{
+		{"2020-01", "2020-02", -1},
+		{"2021", "2020-12", 1},
+		{"2020", "2020-05", 0},
+		{"2020-05-01", "2020-05", 0},
+		{"2020-05-01", "2020-05-02", -1},
+		{Present, "2020-01", 1},
+		{"2020-01", Present, -1},
+		{Present, Present, 0},
+		{"", "2020-01", -1},
+		{"someday", "", 0},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%q.Compare(%q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	got, ok := Date("2020-03").Time()
+	if !ok || !got.Equal(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time() = %v, %v", got, ok)
+	}
+	if _, ok := Date("Jan 2020").Time(); ok {
+		t.Error("expected non-canonical date to have no time")
+	}
+	if got, ok := Present.Time(); !ok || time.Since(got) > time.Minute {
+		t.Errorf("expected Present to be now, got %v", got)
+	}
+}
+
+func TestDateMonth(t *testing.T) {
+	tests := map[Date]string{
+		"2020-03-15": "2020-03",
+		"2020-03":    "2020-03",
+		"2020":       "",
+		"":           "",
+		"someday":    "",
+	}
+	for d, want := range tests {
+		if got := d.Month(); got != want {
+			t.Errorf("%q.Month() = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDateValidate(t *testing.T) {
+	for _, d := range []Date{"", "2020", "2020-01", "2020-01-15", Present} {
+		if err := d.Validate(); err != nil {
+			t.Errorf("%q.Validate() = %v", d, err)
+		}
+	}
+	for _, d := range []Date{"Jan 2020", "2020-1", "someday"} {
+		if err := d.Validate(); err == nil {
+			t.Errorf("expected %q to be invalid", d)
+		}
+	}
+}
+
+func TestDateUnmarshalJSON(t *testing.T) {
+	var exp ExperienceEntry
+	if err := json.Unmarshal([]byte(`{"start_date":"Jan 2020","end_date":"current"}`), &exp); err != nil {
+		t.Fatal(err)
+	}
+	if exp.StartDate != "2020-01" || exp.EndDate != Present {
+		t.Errorf("expected normalized dates, got %q - %q", exp.StartDate, exp.EndDate)
+	}
+
+	// Values that aren't dates are kept for Validate to report
+	if err := json.Unmarshal([]byte(`{"start_date":"someday"}`), &exp); err != nil {
+		t.Fatal(err)
+	}
+	if exp.StartDate != "someday" {
+		t.Errorf("expected raw value to be kept, got %q", exp.StartDate)
+	}
+
+	if err := json.Unmarshal([]byte(`{"start_date":2020}`), &exp); err == nil {
+		t.Error("expected error for non-string date")
+	}
+}
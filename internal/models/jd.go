@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// RemotePolicy describes where the work happens
+type RemotePolicy string
+
+const (
+	RemotePolicyRemote RemotePolicy = "remote"
+	RemotePolicyHybrid RemotePolicy = "hybrid"
+	RemotePolicyOnsite RemotePolicy = "onsite"
+)
+
+// SalaryRange holds a compensation range extracted from a job description
+type SalaryRange struct {
+	Min      int    `json:"min,omitempty"`
+	Max      int    `json:"max,omitempty"`
+	Currency string `json:"currency,omitempty"` // ISO 4217 code, e.g. "EUR"
+	Period   string `json:"period,omitempty"`   // "year", "month", "hour"
+}
+
+// ExperienceRequirement is a years-of-experience requirement, e.g.
+// "5+ years of experience with Go"
+type ExperienceRequirement struct {
+	MinYears int    `json:"min_years"`
+	MaxYears int    `json:"max_years,omitempty"`
+	Subject  string `json:"subject,omitempty"` // What the experience is in, e.g. "Go"
+	Required bool   `json:"required"`          // False for nice-to-have requirements
+	Text     string `json:"text"`              // The line the requirement came from
+}
+
+// JDAnalysis is the structured form of a job description
+type JDAnalysis struct {
+	Responsibilities []string                `json:"responsibilities,omitempty"`
+	MustHave         []string                `json:"must_have,omitempty"`
+	NiceToHave       []string                `json:"nice_to_have,omitempty"`
+	Benefits         []string                `json:"benefits,omitempty"`
+	Seniority        string                  `json:"seniority,omitempty"` // e.g. "senior", "staff"
+	Location         string                  `json:"location,omitempty"`
+	RemotePolicy     RemotePolicy            `json:"remote_policy,omitempty"`
+	Salary           *SalaryRange            `json:"salary,omitempty"`
+	Experience       []ExperienceRequirement `json:"experience,omitempty"`
+	AnalyzedAt       time.Time               `json:"analyzed_at"`
+}
+
+// MinYears returns the highest minimum years of experience among the required
+// experience requirements (0 if there are none)
+func (a *JDAnalysis) MinYears() int {
+	years := 0
+	for _, req := range a.Experience {
+		if req.Required && req.MinYears > years {
+			years = req.MinYears
+		}
+	}
+	return years
+}
@@ -1,9 +1,14 @@
 package models
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -19,6 +24,9 @@ func (t KBEntryType) IsValid() bool {
 	return t == KBTypeProfile || t == KBTypeContext
 }
 
+// KBSchemaVersion is the schema version of new KB records
+const KBSchemaVersion = 3
+
 // ProfileCategory defines the categories for structured profile data
 type ProfileCategory string
 
@@ -29,11 +37,23 @@ const (
 	CategorySkills         ProfileCategory = "skills"
 	CategoryCertifications ProfileCategory = "certifications"
 	CategoryLanguages      ProfileCategory = "languages"
+	CategoryProjects       ProfileCategory = "projects"
+	CategoryPublications   ProfileCategory = "publications"
+	CategoryAwards         ProfileCategory = "awards"
+	CategoryVolunteering   ProfileCategory = "volunteering"
+	CategoryTalks          ProfileCategory = "talks"
 )
 
 var validProfileCategories = []ProfileCategory{
 	CategoryContact, CategoryExperience, CategoryEducation,
 	CategorySkills, CategoryCertifications, CategoryLanguages,
+	CategoryProjects, CategoryPublications, CategoryAwards,
+	CategoryVolunteering, CategoryTalks,
+}
+
+// ProfileCategories returns the valid profile categories
+func ProfileCategories() []ProfileCategory {
+	return append([]ProfileCategory(nil), validProfileCategories...)
 }
 
 func (c ProfileCategory) IsValid() bool {
@@ -45,6 +65,43 @@ func (c ProfileCategory) IsValid() bool {
 	return false
 }
 
+// singletonCategories are the categories a KB holds at most one entry of;
+// every other category holds any number
+var singletonCategories = map[ProfileCategory]bool{
+	CategoryContact: true,
+	CategorySkills:  true,
+}
+
+// IsSingleton reports whether a KB holds at most one entry of the category
+func (c ProfileCategory) IsSingleton() bool {
+	return singletonCategories[c]
+}
+
+// SingletonError is returned when adding a second entry of a singleton
+// category
+type SingletonError struct {
+	Category   ProfileCategory
+	ExistingID string
+}
+
+func (e *SingletonError) Error() string {
+	return fmt.Sprintf("the knowledge base already has a %s entry (%s) and can only have one", e.Category, e.ExistingID)
+}
+
+// CheckCardinality returns a *SingletonError if adding entry to entries would
+// give a singleton category a second entry
+func CheckCardinality(entries []*KBEntry, entry *KBEntry) error {
+	if entry.Type != KBTypeProfile || !ProfileCategory(entry.Category).IsSingleton() || entry.IsArchived() {
+		return nil
+	}
+	for _, e := range entries {
+		if e.Type == KBTypeProfile && e.Category == entry.Category && e.ID != entry.ID && !e.IsArchived() {
+			return &SingletonError{Category: ProfileCategory(entry.Category), ExistingID: e.ID}
+		}
+	}
+	return nil
+}
+
 // ContactData holds candidate contact information
 type ContactData struct {
 	Name     string `json:"name"`
@@ -56,7 +113,7 @@ type ContactData struct {
 	Website  string `json:"website,omitempty"`
 }
 
-func (c *ContactData) Validate() error {
+func (c ContactData) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("name is required")
 	}
@@ -70,14 +127,14 @@ func (c *ContactData) Validate() error {
 type ExperienceEntry struct {
 	Company     string   `json:"company"`
 	Role        string   `json:"role"`
-	StartDate   string   `json:"start_date"`         // YYYY-MM format
-	EndDate     string   `json:"end_date,omitempty"` // YYYY-MM or "present"
+	StartDate   Date     `json:"start_date"`         // YYYY-MM format
+	EndDate     Date     `json:"end_date,omitempty"` // YYYY-MM or "present"
 	Location    string   `json:"location,omitempty"`
 	Description string   `json:"description,omitempty"`
 	Highlights  []string `json:"highlights,omitempty"`
 }
 
-func (e *ExperienceEntry) Validate() error {
+func (e ExperienceEntry) Validate() error {
 	if e.Company == "" {
 		return fmt.Errorf("company is required")
 	}
@@ -87,7 +144,25 @@ func (e *ExperienceEntry) Validate() error {
 	if e.StartDate == "" {
 		return fmt.Errorf("start_date is required")
 	}
-	return nil
+	return validateDateRange("start_date", e.StartDate, "end_date", e.EndDate)
+}
+
+// CompareExperience orders experience newest first: by start date, then with
+// ongoing roles before those that ended, and later end dates first. It returns
+// a negative number when a comes first.
+func CompareExperience(a, b ExperienceEntry) int {
+	if c := b.StartDate.Compare(a.StartDate); c != 0 {
+		return c
+	}
+	return ongoingEnd(b.EndDate).Compare(ongoingEnd(a.EndDate))
+}
+
+// ongoingEnd treats a missing end date as Present
+func ongoingEnd(end Date) Date {
+	if end == "" {
+		return Present
+	}
+	return end
 }
 
 // EducationEntry holds a single education entry
@@ -95,19 +170,19 @@ type EducationEntry struct {
 	Institution string `json:"institution"`
 	Degree      string `json:"degree"`
 	Field       string `json:"field,omitempty"`
-	StartDate   string `json:"start_date,omitempty"`
-	EndDate     string `json:"end_date,omitempty"`
+	StartDate   Date   `json:"start_date,omitempty"`
+	EndDate     Date   `json:"end_date,omitempty"`
 	GPA         string `json:"gpa,omitempty"`
 }
 
-func (e *EducationEntry) Validate() error {
+func (e EducationEntry) Validate() error {
 	if e.Institution == "" {
 		return fmt.Errorf("institution is required")
 	}
 	if e.Degree == "" {
 		return fmt.Errorf("degree is required")
 	}
-	return nil
+	return validateDateRange("start_date", e.StartDate, "end_date", e.EndDate)
 }
 
 // SkillsData holds categorized skills
@@ -120,7 +195,7 @@ type SkillsData struct {
 	Other      []string `json:"other,omitempty"`
 }
 
-func (s *SkillsData) Validate() error {
+func (s SkillsData) Validate() error {
 	// Skills can be partially filled, no required fields
 	return nil
 }
@@ -135,15 +210,24 @@ func (s *SkillsData) IsEmpty() bool {
 type CertificationEntry struct {
 	Name         string `json:"name"`
 	Issuer       string `json:"issuer,omitempty"`
-	Date         string `json:"date,omitempty"`        // YYYY-MM format
-	ExpiryDate   string `json:"expiry_date,omitempty"` // YYYY-MM format
+	Date         Date   `json:"date,omitempty"`        // YYYY-MM format
+	ExpiryDate   Date   `json:"expiry_date,omitempty"` // YYYY-MM format
 	CredentialID string `json:"credential_id,omitempty"`
 }
 
-func (c *CertificationEntry) Validate() error {
+func (c CertificationEntry) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("certification name is required")
 	}
+	if err := validateDate("date", c.Date); err != nil {
+		return err
+	}
+	if err := validateDate("expiry_date", c.ExpiryDate); err != nil {
+		return err
+	}
+	if c.Date != "" && c.ExpiryDate != "" && c.ExpiryDate.Before(c.Date) {
+		return fmt.Errorf("expiry_date %s is before date %s", c.ExpiryDate, c.Date)
+	}
 	return nil
 }
 
@@ -153,13 +237,106 @@ type LanguageEntry struct {
 	Proficiency string `json:"proficiency,omitempty"` // e.g., "native", "fluent", "intermediate", "basic"
 }
 
-func (l *LanguageEntry) Validate() error {
+func (l LanguageEntry) Validate() error {
 	if l.Language == "" {
 		return fmt.Errorf("language is required")
 	}
 	return nil
 }
 
+// ProjectEntry holds a side project or open-source contribution
+type ProjectEntry struct {
+	Name         string   `json:"name"`
+	Role         string   `json:"role,omitempty"` // e.g., "author", "maintainer", "contributor"
+	URL          string   `json:"url,omitempty"`
+	StartDate    Date     `json:"start_date,omitempty"` // YYYY-MM format
+	EndDate      Date     `json:"end_date,omitempty"`   // YYYY-MM or "present"
+	Description  string   `json:"description,omitempty"`
+	Technologies []string `json:"technologies,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+func (p ProjectEntry) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("project name is required")
+	}
+	return validateDateRange("start_date", p.StartDate, "end_date", p.EndDate)
+}
+
+// PublicationEntry holds a paper, article, book or patent
+type PublicationEntry struct {
+	Title       string   `json:"title"`
+	Type        string   `json:"type,omitempty"`      // e.g., "paper", "article", "book", "patent"
+	Publisher   string   `json:"publisher,omitempty"` // Journal, conference, publisher or patent office
+	Date        Date     `json:"date,omitempty"`      // YYYY-MM format
+	URL         string   `json:"url,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func (p PublicationEntry) Validate() error {
+	if p.Title == "" {
+		return fmt.Errorf("publication title is required")
+	}
+	return validateDate("date", p.Date)
+}
+
+// AwardEntry holds an award or honor
+type AwardEntry struct {
+	Title       string `json:"title"`
+	Issuer      string `json:"issuer,omitempty"`
+	Date        Date   `json:"date,omitempty"` // YYYY-MM format
+	Description string `json:"description,omitempty"`
+}
+
+func (a AwardEntry) Validate() error {
+	if a.Title == "" {
+		return fmt.Errorf("award title is required")
+	}
+	return validateDate("date", a.Date)
+}
+
+// VolunteeringEntry holds a volunteer role
+type VolunteeringEntry struct {
+	Organization string   `json:"organization"`
+	Role         string   `json:"role"`
+	StartDate    Date     `json:"start_date,omitempty"` // YYYY-MM format
+	EndDate      Date     `json:"end_date,omitempty"`   // YYYY-MM or "present"
+	Location     string   `json:"location,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+func (v VolunteeringEntry) Validate() error {
+	if v.Organization == "" {
+		return fmt.Errorf("organization is required")
+	}
+	if v.Role == "" {
+		return fmt.Errorf("role is required")
+	}
+	return validateDateRange("start_date", v.StartDate, "end_date", v.EndDate)
+}
+
+// TalkEntry holds a conference talk, meetup presentation or workshop
+type TalkEntry struct {
+	Title       string `json:"title"`
+	Event       string `json:"event"`
+	Date        Date   `json:"date,omitempty"` // YYYY-MM format
+	Location    string `json:"location,omitempty"`
+	URL         string `json:"url,omitempty"` // Recording or slides
+	Description string `json:"description,omitempty"`
+}
+
+func (t TalkEntry) Validate() error {
+	if t.Title == "" {
+		return fmt.Errorf("talk title is required")
+	}
+	if t.Event == "" {
+		return fmt.Errorf("event is required")
+	}
+	return validateDate("date", t.Date)
+}
+
 // KBEntry is the unified wrapper for all knowledge base entries
 type KBEntry struct {
 	ID        string      `json:"id"`
@@ -170,6 +347,20 @@ type KBEntry struct {
 	Source    string      `json:"source,omitempty"`  // "cv-import", "user", "app-xxx"
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
+	// ArchivedAt is set on entries retired from the context and resumes
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// SchemaVersion is the version of this record's layout; records written
+	// before versioning have none. See storage.KBStorage.Migrate.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// ExtraData holds the members of a profile entry's data that the struct
+	// for its category has no field for, like ones added by hand. They are
+	// written back after the struct's fields.
+	ExtraData map[string]json.RawMessage `json:"-"`
+}
+
+// IsArchived reports whether the entry is archived
+func (e *KBEntry) IsArchived() bool {
+	return e.ArchivedAt != nil
 }
 
 // GenerateKBID generates a unique ID for KB entries
@@ -183,13 +374,14 @@ func GenerateKBID() string {
 func NewProfileEntry(category ProfileCategory, data any, source string) *KBEntry {
 	now := time.Now()
 	return &KBEntry{
-		ID:        GenerateKBID(),
-		Type:      KBTypeProfile,
-		Category:  string(category),
-		Data:      data,
-		Source:    source,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:            GenerateKBID(),
+		Type:          KBTypeProfile,
+		Category:      string(category),
+		Data:          data,
+		Source:        source,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		SchemaVersion: KBSchemaVersion,
 	}
 }
 
@@ -197,13 +389,14 @@ func NewProfileEntry(category ProfileCategory, data any, source string) *KBEntry
 func NewContextEntry(category string, content string, source string) *KBEntry {
 	now := time.Now()
 	return &KBEntry{
-		ID:        GenerateKBID(),
-		Type:      KBTypeContext,
-		Category:  category,
-		Content:   content,
-		Source:    source,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:            GenerateKBID(),
+		Type:          KBTypeContext,
+		Category:      category,
+		Content:       content,
+		Source:        source,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		SchemaVersion: KBSchemaVersion,
 	}
 }
 
@@ -225,6 +418,13 @@ func (e *KBEntry) Validate() error {
 		if e.Data == nil {
 			return fmt.Errorf("data is required for profile entries")
 		}
+		data, err := e.profileData()
+		if err != nil {
+			return err
+		}
+		if err := data.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if e.Type == KBTypeContext {
@@ -235,3 +435,288 @@ func (e *KBEntry) Validate() error {
 
 	return nil
 }
+
+// ProfileData is the typed data of a profile entry, e.g. ContactData or
+// ExperienceEntry
+type ProfileData interface {
+	Validate() error
+}
+
+// DecodeProfileData decodes JSON into the data struct for a profile category.
+// It does not validate the data.
+func DecodeProfileData(category ProfileCategory, raw []byte) (ProfileData, error) {
+	switch category {
+	case CategoryContact:
+		return decodeAs[ContactData](raw)
+	case CategoryExperience:
+		return decodeAs[ExperienceEntry](raw)
+	case CategoryEducation:
+		return decodeAs[EducationEntry](raw)
+	case CategorySkills:
+		return decodeAs[SkillsData](raw)
+	case CategoryCertifications:
+		return decodeAs[CertificationEntry](raw)
+	case CategoryLanguages:
+		return decodeAs[LanguageEntry](raw)
+	case CategoryProjects:
+		return decodeAs[ProjectEntry](raw)
+	case CategoryPublications:
+		return decodeAs[PublicationEntry](raw)
+	case CategoryAwards:
+		return decodeAs[AwardEntry](raw)
+	case CategoryVolunteering:
+		return decodeAs[VolunteeringEntry](raw)
+	case CategoryTalks:
+		return decodeAs[TalkEntry](raw)
+	default:
+		return nil, fmt.Errorf("unknown category: %s", category)
+	}
+}
+
+func decodeAs[T ProfileData](raw []byte) (ProfileData, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// profileData returns the entry's data as the struct for its category,
+// converting it if it is held in another form (like a map)
+func (e *KBEntry) profileData() (ProfileData, error) {
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", e.Category, err)
+	}
+	data, err := DecodeProfileData(ProfileCategory(e.Category), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", e.Category, err)
+	}
+	return data, nil
+}
+
+// SplitProfileData decodes JSON into the data struct for a profile category,
+// like DecodeProfileData, and also returns the members of the JSON object
+// the struct has no field for.
+func SplitProfileData(category ProfileCategory, raw []byte) (ProfileData, map[string]json.RawMessage, error) {
+	data, err := DecodeProfileData(category, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, nil, err
+	}
+	known := jsonFields(reflect.TypeOf(data))
+	var extra map[string]json.RawMessage
+	for name, value := range members {
+		if !known[name] {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[name] = value
+		}
+	}
+	return data, extra, nil
+}
+
+// jsonFields returns the JSON names of a struct's fields
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		if name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// DataJSON returns the JSON of a profile entry's data as it is stored: the
+// struct for its category followed by its ExtraData. Characters like "&"
+// are left unescaped, so it reads like the data it holds.
+func (e *KBEntry) DataJSON() ([]byte, error) {
+	raw, err := marshalUnescaped(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", e.Category, err)
+	}
+	if e.Type != KBTypeProfile || e.Data == nil {
+		return raw, nil
+	}
+	data, extra, err := SplitProfileData(ProfileCategory(e.Category), raw)
+	if err != nil {
+		// Data that doesn't fit the struct is written as it is
+		return raw, nil
+	}
+	if raw, err = marshalUnescaped(data); err != nil {
+		return nil, err
+	}
+	// Members of data held as a map win over the ExtraData of the record
+	// it was loaded from
+	for name, value := range e.ExtraData {
+		if _, ok := extra[name]; !ok {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[name] = value
+		}
+	}
+	if len(extra) == 0 {
+		return raw, nil
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	b.Write(raw[:len(raw)-1])
+	for i, name := range names {
+		if i > 0 || len(raw) > 2 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(extra[name])
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func marshalUnescaped(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON decodes the data of a profile entry into the struct for its
+// category, and the members the struct has no field for into ExtraData, so
+// they aren't lost when the entry is saved. Data that doesn't fit the struct
+// is kept as decoded JSON; Validate reports it.
+func (e *KBEntry) UnmarshalJSON(b []byte) error {
+	type plain KBEntry
+	aux := struct {
+		*plain
+		Data json.RawMessage `json:"data,omitempty"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	e.Data = nil
+	e.ExtraData = nil
+	if len(aux.Data) == 0 || string(aux.Data) == "null" {
+		return nil
+	}
+	if e.Type == KBTypeProfile {
+		if data, extra, err := SplitProfileData(ProfileCategory(e.Category), aux.Data); err == nil {
+			e.Data = data
+			e.ExtraData = extra
+			return nil
+		}
+	}
+	return json.Unmarshal(aux.Data, &e.Data)
+}
+
+// MarshalJSON writes the data of a profile entry in the form of the struct
+// for its category, whatever form it is held in, followed by its ExtraData
+func (e KBEntry) MarshalJSON() ([]byte, error) {
+	type plain KBEntry
+	p := plain(e)
+	if e.Type == KBTypeProfile && e.Data != nil {
+		data, err := e.DataJSON()
+		if err != nil {
+			return nil, err
+		}
+		p.Data = json.RawMessage(data)
+	}
+	return json.Marshal(p)
+}
+
+// dataAs returns the entry's data as T if the entry is a profile entry of
+// the category
+func dataAs[T ProfileData](e *KBEntry, category ProfileCategory) (T, bool) {
+	var zero T
+	if e.Type != KBTypeProfile || e.Category != string(category) || e.Data == nil {
+		return zero, false
+	}
+	switch d := e.Data.(type) {
+	case T:
+		return d, true
+	case *T:
+		if d == nil {
+			return zero, false
+		}
+		return *d, true
+	}
+	data, err := e.profileData()
+	if err != nil {
+		return zero, false
+	}
+	v, ok := data.(T)
+	return v, ok
+}
+
+// AsContact returns the data of a contact entry
+func (e *KBEntry) AsContact() (ContactData, bool) {
+	return dataAs[ContactData](e, CategoryContact)
+}
+
+// AsExperience returns the data of an experience entry
+func (e *KBEntry) AsExperience() (ExperienceEntry, bool) {
+	return dataAs[ExperienceEntry](e, CategoryExperience)
+}
+
+// AsEducation returns the data of an education entry
+func (e *KBEntry) AsEducation() (EducationEntry, bool) {
+	return dataAs[EducationEntry](e, CategoryEducation)
+}
+
+// AsSkills returns the data of a skills entry
+func (e *KBEntry) AsSkills() (SkillsData, bool) {
+	return dataAs[SkillsData](e, CategorySkills)
+}
+
+// AsCertification returns the data of a certifications entry
+func (e *KBEntry) AsCertification() (CertificationEntry, bool) {
+	return dataAs[CertificationEntry](e, CategoryCertifications)
+}
+
+// AsLanguage returns the data of a languages entry
+func (e *KBEntry) AsLanguage() (LanguageEntry, bool) {
+	return dataAs[LanguageEntry](e, CategoryLanguages)
+}
+
+// AsProject returns the data of a projects entry
+func (e *KBEntry) AsProject() (ProjectEntry, bool) {
+	return dataAs[ProjectEntry](e, CategoryProjects)
+}
+
+// AsPublication returns the data of a publications entry
+func (e *KBEntry) AsPublication() (PublicationEntry, bool) {
+	return dataAs[PublicationEntry](e, CategoryPublications)
+}
+
+// AsAward returns the data of an awards entry
+func (e *KBEntry) AsAward() (AwardEntry, bool) {
+	return dataAs[AwardEntry](e, CategoryAwards)
+}
+
+// AsVolunteering returns the data of a volunteering entry
+func (e *KBEntry) AsVolunteering() (VolunteeringEntry, bool) {
+	return dataAs[VolunteeringEntry](e, CategoryVolunteering)
+}
+
+// AsTalk returns the data of a talks entry
+func (e *KBEntry) AsTalk() (TalkEntry, bool) {
+	return dataAs[TalkEntry](e, CategoryTalks)
+}
@@ -1,8 +1,12 @@
 package models
 
 import (
+	"encoding/json"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestKBEntryTypeIsValid(t *testing.T) {
@@ -36,6 +40,11 @@ func TestProfileCategoryIsValid(t *testing.T) {
 		{CategorySkills, true},
 		{CategoryCertifications, true},
 		{CategoryLanguages, true},
+		{CategoryProjects, true},
+		{CategoryPublications, true},
+		{CategoryAwards, true},
+		{CategoryVolunteering, true},
+		{CategoryTalks, true},
 		{ProfileCategory("invalid"), false},
 		{ProfileCategory(""), false},
 	}
@@ -123,6 +132,26 @@ func TestExperienceEntryValidate(t *testing.T) {
 			data:    ExperienceEntry{Company: "Acme", Role: "Engineer"},
 			wantErr: true,
 		},
+		{
+			name:    "non-canonical start_date",
+			data:    ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "Jan 2020"},
+			wantErr: true,
+		},
+		{
+			name:    "start_date present",
+			data:    ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: Present},
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			data:    ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2020-05", EndDate: "2019-12"},
+			wantErr: true,
+		},
+		{
+			name:    "end in the start month's year",
+			data:    ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2020-05", EndDate: "2020"},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -156,6 +185,16 @@ func TestEducationEntryValidate(t *testing.T) {
 			data:    EducationEntry{Institution: "MIT"},
 			wantErr: true,
 		},
+		{
+			name:    "valid years",
+			data:    EducationEntry{Institution: "MIT", Degree: "BS", StartDate: "2012", EndDate: "2016"},
+			wantErr: false,
+		},
+		{
+			name:    "end before start",
+			data:    EducationEntry{Institution: "MIT", Degree: "BS", StartDate: "2016", EndDate: "2012"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,6 +228,16 @@ func TestCertificationEntryValidate(t *testing.T) {
 			data:    CertificationEntry{Issuer: "Amazon"},
 			wantErr: true,
 		},
+		{
+			name:    "expiry before date",
+			data:    CertificationEntry{Name: "AWS SA", Date: "2023-01", ExpiryDate: "2022-01"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid expiry_date",
+			data:    CertificationEntry{Name: "AWS SA", ExpiryDate: "never"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,6 +283,218 @@ func TestLanguageEntryValidate(t *testing.T) {
 	}
 }
 
+func TestProjectEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    ProjectEntry
+		wantErr bool
+	}{
+		{
+			name:    "valid project",
+			data:    ProjectEntry{Name: "bragger"},
+			wantErr: false,
+		},
+		{
+			name:    "valid with all fields",
+			data:    ProjectEntry{Name: "bragger", Role: "maintainer", URL: "https://github.com/ewurch/bragger", StartDate: "2024-01", Technologies: []string{"Go"}},
+			wantErr: false,
+		},
+		{
+			name:    "missing name",
+			data:    ProjectEntry{Description: "A CLI"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProjectEntry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublicationEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    PublicationEntry
+		wantErr bool
+	}{
+		{
+			name:    "valid publication",
+			data:    PublicationEntry{Title: "Consensus at Scale"},
+			wantErr: false,
+		},
+		{
+			name:    "valid patent",
+			data:    PublicationEntry{Title: "Method for caching", Type: "patent", Publisher: "USPTO", Date: "2022-05"},
+			wantErr: false,
+		},
+		{
+			name:    "missing title",
+			data:    PublicationEntry{Publisher: "ACM"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublicationEntry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAwardEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    AwardEntry
+		wantErr bool
+	}{
+		{
+			name:    "valid award",
+			data:    AwardEntry{Title: "Engineer of the Year", Issuer: "Acme", Date: "2023-12"},
+			wantErr: false,
+		},
+		{
+			name:    "missing title",
+			data:    AwardEntry{Issuer: "Acme"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AwardEntry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVolunteeringEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    VolunteeringEntry
+		wantErr bool
+	}{
+		{
+			name:    "valid volunteering",
+			data:    VolunteeringEntry{Organization: "Code Club", Role: "Mentor"},
+			wantErr: false,
+		},
+		{
+			name:    "missing organization",
+			data:    VolunteeringEntry{Role: "Mentor"},
+			wantErr: true,
+		},
+		{
+			name:    "missing role",
+			data:    VolunteeringEntry{Organization: "Code Club"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VolunteeringEntry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTalkEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    TalkEntry
+		wantErr bool
+	}{
+		{
+			name:    "valid talk",
+			data:    TalkEntry{Title: "Go at Scale", Event: "GopherCon EU"},
+			wantErr: false,
+		},
+		{
+			name:    "missing title",
+			data:    TalkEntry{Event: "GopherCon EU"},
+			wantErr: true,
+		},
+		{
+			name:    "missing event",
+			data:    TalkEntry{Title: "Go at Scale"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TalkEntry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckCardinality(t *testing.T) {
+	contact := NewProfileEntry(CategoryContact, ContactData{Name: "Jane", Email: "j@e.com"}, "")
+	exp := NewProfileEntry(CategoryExperience, ExperienceEntry{Company: "Acme", Role: "Dev", StartDate: "2020-01"}, "")
+	entries := []*KBEntry{contact, exp}
+
+	err := CheckCardinality(entries, NewProfileEntry(CategoryContact, ContactData{Name: "J", Email: "j@x.com"}, ""))
+	singleton, ok := err.(*SingletonError)
+	if !ok || singleton.ExistingID != contact.ID || singleton.Category != CategoryContact {
+		t.Errorf("expected a SingletonError for a second contact, got %v", err)
+	}
+	if err := CheckCardinality(entries, NewProfileEntry(CategoryExperience, ExperienceEntry{Company: "B", Role: "Dev", StartDate: "2021-01"}, "")); err != nil {
+		t.Errorf("expected experience to allow many entries, got %v", err)
+	}
+	if err := CheckCardinality(entries, NewProfileEntry(CategorySkills, SkillsData{}, "")); err != nil {
+		t.Errorf("expected a first skills entry to be allowed, got %v", err)
+	}
+	if err := CheckCardinality(entries, contact); err != nil {
+		t.Errorf("expected an entry not to conflict with itself, got %v", err)
+	}
+	if err := CheckCardinality(entries, NewContextEntry("contact", "Prefers email", "")); err != nil {
+		t.Errorf("expected context entries to allow many, got %v", err)
+	}
+
+	// Archived entries don't count
+	archivedAt := time.Now()
+	contact.ArchivedAt = &archivedAt
+	if err := CheckCardinality(entries, NewProfileEntry(CategoryContact, ContactData{Name: "J", Email: "j@x.com"}, "")); err != nil {
+		t.Errorf("expected an archived contact not to count, got %v", err)
+	}
+}
+
+func TestCompareExperience(t *testing.T) {
+	entries := []ExperienceEntry{
+		{Company: "Old", StartDate: "2015-01", EndDate: "2017-06"},
+		{Company: "Side", StartDate: "2021-03", EndDate: "2022-01"},
+		{Company: "Current", StartDate: "2021-03"},
+		{Company: "Undated"},
+		{Company: "Recent", StartDate: "2023-01", EndDate: Present},
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return CompareExperience(entries[i], entries[j]) < 0
+	})
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Company)
+	}
+	if want := "Recent,Current,Side,Old,Undated"; strings.Join(got, ",") != want {
+		t.Errorf("order = %v, want %s", got, want)
+	}
+}
+
 func TestSkillsDataIsEmpty(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -297,6 +558,9 @@ func TestNewProfileEntry(t *testing.T) {
 	if !strings.HasPrefix(entry.ID, "kb-") {
 		t.Errorf("expected ID to start with 'kb-', got %q", entry.ID)
 	}
+	if entry.SchemaVersion != KBSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", KBSchemaVersion, entry.SchemaVersion)
+	}
 }
 
 func TestNewContextEntry(t *testing.T) {
@@ -375,6 +639,26 @@ func TestKBEntryValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "profile with invalid data",
+			entry: KBEntry{
+				ID:       "kb-test",
+				Type:     KBTypeProfile,
+				Category: string(CategoryExperience),
+				Data:     ExperienceEntry{Company: "Acme", Role: "Engineer"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "profile with data of another category",
+			entry: KBEntry{
+				ID:       "kb-test",
+				Type:     KBTypeProfile,
+				Category: string(CategoryExperience),
+				Data:     map[string]any{"company": "Acme", "role": "Engineer", "start_date": 2020},
+			},
+			wantErr: true,
+		},
 		{
 			name: "context without content",
 			entry: KBEntry{
@@ -395,3 +679,130 @@ func TestKBEntryValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestKBEntryUnmarshalJSON(t *testing.T) {
+	t.Run("profile data is typed", func(t *testing.T) {
+		var e KBEntry
+		err := json.Unmarshal([]byte(`{"id":"kb-1","type":"profile","category":"experience","data":{"company":"Acme","role":"Engineer","start_date":"2020-01","highlights":["Shipped"]}}`), &e)
+		if err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		want := ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2020-01", Highlights: []string{"Shipped"}}
+		if got, ok := e.Data.(ExperienceEntry); !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("Data = %#v, want %#v", e.Data, want)
+		}
+	})
+
+	t.Run("data that doesn't fit is kept", func(t *testing.T) {
+		var e KBEntry
+		err := json.Unmarshal([]byte(`{"id":"kb-1","type":"profile","category":"experience","data":{"company":"Acme","start_date":2020}}`), &e)
+		if err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if m, ok := e.Data.(map[string]any); !ok || m["company"] != "Acme" {
+			t.Errorf("Data = %#v, want the decoded JSON", e.Data)
+		}
+		if err := e.Validate(); err == nil || !strings.Contains(err.Error(), "invalid experience data") {
+			t.Errorf("Validate() = %v, want invalid data error", err)
+		}
+	})
+
+	t.Run("context entry", func(t *testing.T) {
+		var e KBEntry
+		if err := json.Unmarshal([]byte(`{"id":"kb-1","type":"context","category":"notes","content":"Hi"}`), &e); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if e.Data != nil || e.Content != "Hi" || e.ID != "kb-1" {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	})
+}
+
+func TestKBEntryMarshalJSON(t *testing.T) {
+	typed := NewProfileEntry(CategoryLanguages, LanguageEntry{Language: "German", Proficiency: "fluent"}, "user")
+	untyped := *typed
+	untyped.Data = map[string]any{"proficiency": "fluent", "language": "German"}
+
+	a, err := json.Marshal(typed)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	b, _ := json.Marshal(untyped)
+	if string(a) != string(b) {
+		t.Errorf("map data should be written like the struct:\n%s\n%s", b, a)
+	}
+
+	untyped.Data = map[string]any{"proficiency": "fluent", "language": "German", "unknown": true}
+	b, _ = json.Marshal(untyped)
+	if !strings.Contains(string(b), `"data":{"language":"German","proficiency":"fluent","unknown":true}`) {
+		t.Errorf("expected members the struct has no field for to follow it, got %s", b)
+	}
+
+	var back KBEntry
+	if err := json.Unmarshal(a, &back); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if back.Data != typed.Data || back.ID != typed.ID || !back.CreatedAt.Equal(typed.CreatedAt) {
+		t.Errorf("round trip = %+v, want %+v", back, typed)
+	}
+}
+
+func TestKBEntryExtraDataRoundTrip(t *testing.T) {
+	line := `{"id":"kb-1","type":"profile","category":"experience","data":{"company":"Acme","role":"Engineer","start_date":"2020-01","team_size":6,"stack_notes":{"lang":"Go"}}}`
+	var e KBEntry
+	if err := json.Unmarshal([]byte(line), &e); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if _, ok := e.Data.(ExperienceEntry); !ok {
+		t.Fatalf("Data = %#v, want an ExperienceEntry", e.Data)
+	}
+	if string(e.ExtraData["team_size"]) != "6" || len(e.ExtraData) != 2 {
+		t.Errorf("ExtraData = %s", e.ExtraData)
+	}
+	if err := e.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	// Changing the typed data keeps the extra members
+	exp, _ := e.AsExperience()
+	exp.Role = "Lead"
+	e.Data = exp
+	out, err := json.Marshal(&e)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `"data":{"company":"Acme","role":"Lead","start_date":"2020-01","stack_notes":{"lang":"Go"},"team_size":6}`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("Marshal() = %s\nwant it to contain %s", out, want)
+	}
+}
+
+func TestKBEntryAccessors(t *testing.T) {
+	exp := ExperienceEntry{Company: "Acme", Role: "Engineer", StartDate: "2020-01"}
+
+	e := NewProfileEntry(CategoryExperience, exp, "user")
+	if got, ok := e.AsExperience(); !ok || got.Company != "Acme" {
+		t.Errorf("AsExperience() = %+v, %v", got, ok)
+	}
+	if _, ok := e.AsEducation(); ok {
+		t.Error("AsEducation() should fail for an experience entry")
+	}
+
+	e.Data = &exp
+	if got, ok := e.AsExperience(); !ok || got.Role != "Engineer" {
+		t.Errorf("AsExperience() with pointer data = %+v, %v", got, ok)
+	}
+
+	e.Data = map[string]any{"company": "Acme", "role": "Engineer", "start_date": "2020-01"}
+	if got, ok := e.AsExperience(); !ok || !reflect.DeepEqual(got, exp) {
+		t.Errorf("AsExperience() with map data = %+v, %v", got, ok)
+	}
+
+	talk := NewProfileEntry(CategoryTalks, TalkEntry{Title: "Go", Event: "GopherCon"}, "user")
+	if got, ok := talk.AsTalk(); !ok || got.Event != "GopherCon" {
+		t.Errorf("AsTalk() = %+v, %v", got, ok)
+	}
+	if _, ok := NewContextEntry("notes", "Hi", "user").AsContact(); ok {
+		t.Error("AsContact() should fail for a context entry")
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import "strings"
+
+// MergeSkills unions two skill lists per group, keeping a's order and
+// dropping case-insensitive duplicates
+func MergeSkills(a, b SkillsData) SkillsData {
+	return SkillsData{
+		Languages:  unionFold(a.Languages, b.Languages),
+		Frameworks: unionFold(a.Frameworks, b.Frameworks),
+		Tools:      unionFold(a.Tools, b.Tools),
+		Databases:  unionFold(a.Databases, b.Databases),
+		Cloud:      unionFold(a.Cloud, b.Cloud),
+		Other:      unionFold(a.Other, b.Other),
+	}
+}
+
+// RemoveSkills drops the skills in b from a, per group and ignoring case
+func RemoveSkills(a, b SkillsData) SkillsData {
+	return SkillsData{
+		Languages:  differenceFold(a.Languages, b.Languages),
+		Frameworks: differenceFold(a.Frameworks, b.Frameworks),
+		Tools:      differenceFold(a.Tools, b.Tools),
+		Databases:  differenceFold(a.Databases, b.Databases),
+		Cloud:      differenceFold(a.Cloud, b.Cloud),
+		Other:      differenceFold(a.Other, b.Other),
+	}
+}
+
+// contactFields returns pointers to the fields of contact data, by JSON name
+func contactFields(c *ContactData) []struct {
+	name  string
+	value *string
+} {
+	return []struct {
+		name  string
+		value *string
+	}{
+		{"name", &c.Name}, {"email", &c.Email}, {"phone", &c.Phone}, {"location", &c.Location},
+		{"linkedin", &c.LinkedIn}, {"github", &c.GitHub}, {"website", &c.Website},
+	}
+}
+
+// MergeContact combines two contacts. Fields set in only one are kept;
+// choose picks the value of fields set to different values in both.
+func MergeContact(a, b ContactData, choose func(field, a, b string) string) ContactData {
+	merged := a
+	fields, other := contactFields(&merged), contactFields(&b)
+	for i, f := range fields {
+		theirs := *other[i].value
+		switch {
+		case theirs == "" || theirs == *f.value:
+		case *f.value == "":
+			*f.value = theirs
+		default:
+			*f.value = choose(f.name, *f.value, theirs)
+		}
+	}
+	return merged
+}
+
+// MergeSources combines the Source values of merged entries, so the merged
+// entry keeps the provenance of both, e.g. "cv-import, linkedin-import"
+func MergeSources(sources ...string) string {
+	var parts []string
+	for _, source := range sources {
+		for _, part := range strings.Split(source, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return strings.Join(unionFold(parts, nil), ", ")
+}
+
+func unionFold(a, b []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			key := strings.ToLower(strings.TrimSpace(s))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func differenceFold(a, b []string) []string {
+	remove := make(map[string]bool)
+	for _, s := range b {
+		remove[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !remove[strings.ToLower(strings.TrimSpace(s))] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSkills(t *testing.T) {
+	a := SkillsData{Languages: []string{"Go", "Python"}, Tools: []string{"Docker"}}
+	b := SkillsData{Languages: []string{"go", "Rust"}, Cloud: []string{"AWS"}, Other: []string{" "}}
+	want := SkillsData{Languages: []string{"Go", "Python", "Rust"}, Tools: []string{"Docker"}, Cloud: []string{"AWS"}}
+	if got := MergeSkills(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeSkills = %+v, want %+v", got, want)
+	}
+}
+
+func TestRemoveSkills(t *testing.T) {
+	a := SkillsData{Languages: []string{"Go", "Python", "Rust"}, Tools: []string{"Docker"}}
+	b := SkillsData{Languages: []string{"python", "Java"}, Tools: []string{"Docker"}}
+	want := SkillsData{Languages: []string{"Go", "Rust"}}
+	if got := RemoveSkills(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("RemoveSkills = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeContact(t *testing.T) {
+	a := ContactData{Name: "Jane Doe", Email: "jane@old.com", Location: "Berlin"}
+	b := ContactData{Name: "Jane Doe", Email: "jane@new.com", Phone: "+49 123"}
+
+	var asked []string
+	got := MergeContact(a, b, func(field, ours, theirs string) string {
+		asked = append(asked, field)
+		return theirs
+	})
+	want := ContactData{Name: "Jane Doe", Email: "jane@new.com", Phone: "+49 123", Location: "Berlin"}
+	if got != want {
+		t.Errorf("MergeContact = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(asked, []string{"email"}) {
+		t.Errorf("expected to be asked about email only, got %v", asked)
+	}
+}
+
+func TestMergeSources(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"cv-import", "linkedin-import"}, "cv-import, linkedin-import"},
+		{[]string{"cv-import, user", "user"}, "cv-import, user"},
+		{[]string{"", "user"}, "user"},
+		{[]string{"", ""}, ""},
+	}
+	for _, tt := range tests {
+		if got := MergeSources(tt.in...); got != tt.want {
+			t.Errorf("MergeSources(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package pdf
+
+import (
+	"regexp"
+	"strings"
+)
+
+// declaration is a single "property: value" pair
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// rule is a style rule with one selector (selector lists are split)
+type rule struct {
+	selector    selector
+	specificity int
+	order       int
+	decls       []declaration
+}
+
+// stylesheet holds the rules that apply to print output and the @page rules
+type stylesheet struct {
+	rules []rule
+	page  []declaration
+}
+
+var cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// parseStylesheet parses CSS, keeping rules for print media. Rules inside
+// @media blocks are kept when the media query mentions print or all, and
+// @page rules are collected separately. Rules are numbered across calls, so
+// later sheets win specificity ties.
+func parseStylesheet(css string, sheet *stylesheet) {
+	css = cssCommentPattern.ReplaceAllString(css, "")
+	parseRules(css, sheet)
+}
+
+func parseRules(css string, sheet *stylesheet) {
+	for {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			return
+		}
+
+		open := strings.IndexByte(css, '{')
+		semi := strings.IndexByte(css, ';')
+		if strings.HasPrefix(css, "@") && semi >= 0 && (open < 0 || semi < open) {
+			// Statement at-rule such as @import or @charset
+			css = css[semi+1:]
+			continue
+		}
+		if open < 0 {
+			return
+		}
+
+		prelude := strings.TrimSpace(css[:open])
+		end := matchingBrace(css, open)
+		body := css[open+1 : end]
+		if end < len(css) {
+			css = css[end+1:]
+		} else {
+			css = ""
+		}
+
+		switch {
+		case strings.HasPrefix(prelude, "@media"):
+			if mediaApplies(strings.TrimPrefix(prelude, "@media")) {
+				parseRules(body, sheet)
+			}
+		case strings.HasPrefix(prelude, "@page"):
+			sheet.page = append(sheet.page, parseDeclarations(body)...)
+		case strings.HasPrefix(prelude, "@"):
+			// @font-face, @keyframes, @supports, ...: not supported
+		default:
+			decls := parseDeclarations(body)
+			for _, part := range strings.Split(prelude, ",") {
+				sel, ok := parseSelector(part)
+				if !ok {
+					continue
+				}
+				sheet.rules = append(sheet.rules, rule{
+					selector:    sel,
+					specificity: sel.specificity(),
+					order:       len(sheet.rules),
+					decls:       decls,
+				})
+			}
+		}
+	}
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or
+// len(s) when it is missing
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+func mediaApplies(query string) bool {
+	query = strings.ToLower(query)
+	for _, part := range strings.Split(query, ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "not ") {
+			continue
+		}
+		if strings.Contains(part, "print") || strings.Contains(part, "all") || strings.HasPrefix(part, "(") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDeclarations parses the body of a rule or a style attribute
+func parseDeclarations(body string) []declaration {
+	var decls []declaration
+	for _, part := range strings.Split(body, ";") {
+		colon := strings.IndexByte(part, ':')
+		if colon < 0 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(part[:colon]))
+		value := strings.TrimSpace(part[colon+1:])
+		if prop == "" || value == "" || strings.ContainsAny(prop, "{}") {
+			continue
+		}
+		important := false
+		if i := strings.Index(strings.ToLower(value), "!important"); i >= 0 {
+			important = true
+			value = strings.TrimSpace(value[:i])
+		}
+		decls = append(decls, declaration{property: prop, value: value, important: important})
+	}
+	return decls
+}
+
+// compound is a simple selector sequence such as "div.job-header" and the
+// combinator linking it to the compound on its left
+type compound struct {
+	tag        string // Empty or "*" matches any element
+	id         string
+	classes    []string
+	firstChild bool
+	lastChild  bool
+	child      bool // Combinator to the left is ">" rather than a space
+}
+
+// selector is a list of compounds, leftmost first
+type selector []compound
+
+var selectorTokenPattern = regexp.MustCompile(`\s*>\s*|\s+`)
+
+// parseSelector parses the supported selector subset: type, class, id and
+// universal selectors, :first-child and :last-child, and the descendant and
+// child combinators. Anything else makes the selector unsupported.
+func parseSelector(s string) (selector, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+
+	var sel selector
+	child := false
+	rest := s
+	for rest != "" {
+		loc := selectorTokenPattern.FindStringIndex(rest)
+		part := rest
+		next := ""
+		combinator := ""
+		if loc != nil {
+			part = rest[:loc[0]]
+			combinator = rest[loc[0]:loc[1]]
+			next = rest[loc[1]:]
+		}
+		if part == "" {
+			// Leading combinator
+			return nil, false
+		}
+
+		c, ok := parseCompound(part)
+		if !ok {
+			return nil, false
+		}
+		c.child = child
+		sel = append(sel, c)
+
+		child = strings.Contains(combinator, ">")
+		rest = next
+	}
+	return sel, len(sel) > 0
+}
+
+func parseCompound(s string) (compound, bool) {
+	var c compound
+	i := 0
+	readName := func() string {
+		start := i
+		for i < len(s) && (isNameChar(s[i])) {
+			i++
+		}
+		return s[start:i]
+	}
+
+	if i < len(s) && s[i] == '*' {
+		c.tag = "*"
+		i++
+	} else if i < len(s) && isNameChar(s[i]) {
+		c.tag = strings.ToLower(readName())
+	}
+
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			i++
+			name := readName()
+			if name == "" {
+				return c, false
+			}
+			c.classes = append(c.classes, name)
+		case '#':
+			i++
+			c.id = readName()
+			if c.id == "" {
+				return c, false
+			}
+		case ':':
+			i++
+			for i < len(s) && s[i] == ':' {
+				i++
+			}
+			switch strings.ToLower(readName()) {
+			case "first-child":
+				c.firstChild = true
+			case "last-child":
+				c.lastChild = true
+			default:
+				return c, false
+			}
+		default:
+			return c, false
+		}
+	}
+	return c, true
+}
+
+func isNameChar(b byte) bool {
+	return b == '-' || b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b >= 0x80
+}
+
+// specificity packs (ids, classes, types) into one comparable number
+func (sel selector) specificity() int {
+	spec := 0
+	for _, c := range sel {
+		if c.id != "" {
+			spec += 10000
+		}
+		spec += 100 * len(c.classes)
+		if c.firstChild || c.lastChild {
+			spec += 100
+		}
+		if c.tag != "" && c.tag != "*" {
+			spec++
+		}
+	}
+	return spec
+}
+
+func (sel selector) matches(n *node) bool {
+	return sel.matchFrom(len(sel)-1, n)
+}
+
+func (sel selector) matchFrom(i int, n *node) bool {
+	if !sel[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if sel[i].child {
+		return n.parent != nil && sel.matchFrom(i-1, n.parent)
+	}
+	for p := n.parent; p != nil; p = p.parent {
+		if sel.matchFrom(i-1, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compound) matches(n *node) bool {
+	if n.isText() || n.tag == "#document" {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.tag {
+		return false
+	}
+	if c.id != "" && n.attrs["id"] != c.id {
+		return false
+	}
+	for _, class := range c.classes {
+		if !n.hasClass(class) {
+			return false
+		}
+	}
+	if c.firstChild || c.lastChild {
+		siblings := n.elementSiblings()
+		if c.firstChild && siblings[0] != n {
+			return false
+		}
+		if c.lastChild && siblings[len(siblings)-1] != n {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStylesheet(t *testing.T) {
+	css := `
+@charset "utf-8";
+/* comment { not: a rule } */
+body { font-size: 11pt; color: #333 !important }
+h1, .name { font-weight: 700 }
+@media screen { body { padding: 20px } }
+@media print {
+    body { padding: 15mm }
+    @page { size: A4; margin: 0 }
+}
+@font-face { font-family: X; src: url(x.woff) }
+a:hover { color: red }
+`
+	sheet := &stylesheet{}
+	parseStylesheet(css, sheet)
+
+	var selectors [][]compound
+	for _, r := range sheet.rules {
+		selectors = append(selectors, r.selector)
+	}
+	if len(sheet.rules) != 4 {
+		t.Fatalf("got %d rules: %+v", len(sheet.rules), selectors)
+	}
+
+	body := sheet.rules[0]
+	want := []declaration{{"font-size", "11pt", false}, {"color", "#333", true}}
+	if !reflect.DeepEqual(body.decls, want) {
+		t.Errorf("body declarations = %+v", body.decls)
+	}
+	if sheet.rules[1].selector[0].tag != "h1" || sheet.rules[2].selector[0].classes[0] != "name" {
+		t.Error("selector lists should be split into separate rules")
+	}
+	if got := sheet.rules[3].decls; len(got) != 1 || got[0].value != "15mm" {
+		t.Errorf("print rule = %+v, screen rules should be skipped", got)
+	}
+	for i, r := range sheet.rules {
+		if r.order != i {
+			t.Errorf("rule %d has order %d", i, r.order)
+		}
+	}
+
+	wantPage := []declaration{{"size", "A4", false}, {"margin", "0", false}}
+	if !reflect.DeepEqual(sheet.page, wantPage) {
+		t.Errorf("page declarations = %+v", sheet.page)
+	}
+}
+
+func TestSelectorMatching(t *testing.T) {
+	root, _, err := parseHTML(`<div class="job"><div class="job-header"><span class="title">A</span><span>B</span></div></div><span>C</span>`)
+	if err != nil {
+		t.Fatalf("parseHTML failed: %v", err)
+	}
+	title := root.find("span")
+	second := title.parent.children[1]
+	outside := root.children[1]
+
+	tests := []struct {
+		selector string
+		n        *node
+		want     bool
+	}{
+		{"span", title, true},
+		{"*", title, true},
+		{".title", title, true},
+		{"span.title", second, false},
+		{".job span", second, true},
+		{".job span", outside, false},
+		{".job > span", title, false},
+		{".job-header > span", title, true},
+		{".job > .job-header span:first-child", title, true},
+		{"span:first-child", second, false},
+		{"span:last-child", second, true},
+		{"div span:last-child", outside, false},
+	}
+	for _, tt := range tests {
+		sel, ok := parseSelector(tt.selector)
+		if !ok {
+			t.Errorf("parseSelector(%q) failed", tt.selector)
+			continue
+		}
+		if got := sel.matches(tt.n); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.selector, tt.n.textContent(), got, tt.want)
+		}
+	}
+
+	for _, unsupported := range []string{"a:hover", "input[type=text]", "a + b", "> a", "", "p::before"} {
+		if _, ok := parseSelector(unsupported); ok {
+			t.Errorf("parseSelector(%q) should fail", unsupported)
+		}
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	spec := func(s string) int {
+		sel, _ := parseSelector(s)
+		return sel.specificity()
+	}
+	if !(spec("#a") > spec(".a .b .c") && spec(".a") > spec("div p span") && spec("li:first-child") > spec("li") && spec("*") == 0) {
+		t.Error("specificity ordering is wrong")
+	}
+}
@@ -0,0 +1,195 @@
+package pdf
+
+import (
+	"fmt"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontKey selects one of the embedded font faces
+type fontKey struct {
+	mono   bool
+	bold   bool
+	italic bool
+}
+
+// faces maps each font key to its TrueType data and PostScript name. The Go
+// fonts are used for everything: they are BSD licensed, so they can be
+// embedded in every PDF, and cover Latin, Greek and Cyrillic.
+var faces = map[fontKey]struct {
+	name string
+	ttf  []byte
+}{
+	{false, false, false}: {"GoRegular", goregular.TTF},
+	{false, true, false}:  {"GoBold", gobold.TTF},
+	{false, false, true}:  {"GoItalic", goitalic.TTF},
+	{false, true, true}:   {"GoBoldItalic", gobolditalic.TTF},
+	{true, false, false}:  {"GoMono", gomono.TTF},
+	{true, true, false}:   {"GoMonoBold", gomonobold.TTF},
+	{true, false, true}:   {"GoMonoItalic", gomonoitalic.TTF},
+	{true, true, true}:    {"GoMonoBoldItalic", gomonobolditalic.TTF},
+}
+
+// fontFace is a parsed TrueType font and the glyphs a document uses from it.
+// Metrics are in thousandths of an em, the unit of PDF glyph widths.
+type fontFace struct {
+	key     fontKey
+	name    string
+	ttf     []byte
+	sf      *sfnt.Font
+	buf     sfnt.Buffer
+	upem    fixed.Int26_6
+	ascent  int
+	descent int // Negative, as in the PDF font descriptor
+	capH    int
+	bbox    [4]int
+	italicA float64
+
+	widths map[sfnt.GlyphIndex]int
+	runes  map[sfnt.GlyphIndex]rune // First rune shown with each glyph, for ToUnicode
+	cache  map[rune]sfnt.GlyphIndex
+}
+
+func loadFace(key fontKey) (*fontFace, error) {
+	face, ok := faces[key]
+	if !ok {
+		return nil, fmt.Errorf("no font for %+v", key)
+	}
+	sf, err := sfnt.Parse(face.ttf)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font %s: %w", face.name, err)
+	}
+
+	f := &fontFace{
+		key:    key,
+		name:   face.name,
+		ttf:    face.ttf,
+		sf:     sf,
+		upem:   fixed.Int26_6(sf.UnitsPerEm()) << 6,
+		widths: make(map[sfnt.GlyphIndex]int),
+		runes:  make(map[sfnt.GlyphIndex]rune),
+		cache:  make(map[rune]sfnt.GlyphIndex),
+	}
+
+	metrics, err := sf.Metrics(&f.buf, f.upem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("reading metrics of %s: %w", face.name, err)
+	}
+	f.ascent = f.scale(metrics.Ascent)
+	f.descent = -f.scale(metrics.Descent)
+	f.capH = f.scale(metrics.CapHeight)
+	if f.capH == 0 {
+		f.capH = f.ascent
+	}
+
+	bounds, err := sf.Bounds(&f.buf, f.upem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("reading bounds of %s: %w", face.name, err)
+	}
+	// sfnt uses a y-down coordinate system; PDF bounding boxes are y-up
+	f.bbox = [4]int{f.scale(bounds.Min.X), -f.scale(bounds.Max.Y), f.scale(bounds.Max.X), -f.scale(bounds.Min.Y)}
+	if key.italic {
+		f.italicA = -12
+	}
+	return f, nil
+}
+
+// scale converts a value in font units (26.6 at ppem = unitsPerEm) to
+// thousandths of an em
+func (f *fontFace) scale(v fixed.Int26_6) int {
+	return int((int64(v)*1000 + int64(f.upem)/2) / int64(f.upem))
+}
+
+// lookup returns the glyph for r and its advance width. Characters the font
+// lacks are shown as "?".
+func (f *fontFace) lookup(r rune) (sfnt.GlyphIndex, int) {
+	gid, ok := f.cache[r]
+	if !ok {
+		gid, _ = f.sf.GlyphIndex(&f.buf, r)
+		if gid == 0 && r != '?' {
+			gid, _ = f.sf.GlyphIndex(&f.buf, '?')
+		}
+		f.cache[r] = gid
+	}
+
+	w, ok := f.widths[gid]
+	if !ok {
+		if adv, err := f.sf.GlyphAdvance(&f.buf, gid, f.upem, font.HintingNone); err == nil {
+			w = f.scale(adv)
+		}
+		f.widths[gid] = w
+	}
+	return gid, w
+}
+
+// has reports whether the font has a glyph for r
+func (f *fontFace) has(r rune) bool {
+	gid, err := f.sf.GlyphIndex(&f.buf, r)
+	return err == nil && gid != 0
+}
+
+// glyph is lookup for text that is drawn: it records the glyph as used, so
+// it is embedded and mapped back to r for copy and paste
+func (f *fontFace) glyph(r rune) (sfnt.GlyphIndex, int) {
+	gid, w := f.lookup(r)
+	if _, ok := f.runes[gid]; !ok {
+		if !f.has(r) {
+			r = '?'
+		}
+		f.runes[gid] = r
+	}
+	return gid, w
+}
+
+// measure returns the advance width of s in thousandths of an em
+func (f *fontFace) measure(s string) int {
+	total := 0
+	for _, r := range s {
+		_, w := f.lookup(r)
+		total += w
+	}
+	return total
+}
+
+// fontSet loads faces on first use and keeps the order they were first
+// used in, so output is deterministic
+type fontSet struct {
+	faces map[fontKey]*fontFace
+	order []*fontFace
+	err   error
+}
+
+func newFontSet() *fontSet {
+	return &fontSet{faces: make(map[fontKey]*fontFace)}
+}
+
+func (s *fontSet) get(key fontKey) *fontFace {
+	if f, ok := s.faces[key]; ok {
+		return f
+	}
+	f, err := loadFace(key)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		// Fall back to the regular face so layout can continue; the error
+		// is reported once rendering finishes
+		if key != (fontKey{}) {
+			return s.get(fontKey{})
+		}
+		return nil
+	}
+	s.faces[key] = f
+	s.order = append(s.order, f)
+	return f
+}
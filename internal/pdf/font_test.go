@@ -0,0 +1,70 @@
+package pdf
+
+import "testing"
+
+func TestLoadFace(t *testing.T) {
+	for key, face := range faces {
+		f, err := loadFace(key)
+		if err != nil {
+			t.Fatalf("loadFace(%s) failed: %v", face.name, err)
+		}
+		if f.ascent <= 0 || f.descent >= 0 || f.capH <= 0 {
+			t.Errorf("%s: ascent %d, descent %d, cap height %d", f.name, f.ascent, f.descent, f.capH)
+		}
+		if f.bbox[0] >= f.bbox[2] || f.bbox[1] >= f.bbox[3] {
+			t.Errorf("%s: bad bounding box %v", f.name, f.bbox)
+		}
+		if (f.italicA != 0) != key.italic {
+			t.Errorf("%s: italic angle %v", f.name, f.italicA)
+		}
+	}
+}
+
+func TestFontFaceGlyphs(t *testing.T) {
+	f, err := loadFace(fontKey{})
+	if err != nil {
+		t.Fatalf("loadFace failed: %v", err)
+	}
+
+	_, wa := f.lookup('a')
+	if wa <= 0 || wa >= 1000 {
+		t.Errorf("width of 'a' = %d", wa)
+	}
+	if got := f.measure("aa"); got != 2*wa {
+		t.Errorf("measure(aa) = %d, want %d", got, 2*wa)
+	}
+	if len(f.runes) != 0 {
+		t.Error("lookup and measure should not mark glyphs as used")
+	}
+
+	gid, _ := f.glyph('é')
+	if f.runes[gid] != 'é' {
+		t.Errorf("glyph(é) recorded %q", f.runes[gid])
+	}
+
+	// Characters the font lacks fall back to "?", and map back to it
+	if f.has('\U0001F600') {
+		t.Fatal("expected the Go font to lack emoji")
+	}
+	missing, _ := f.glyph('\U0001F600')
+	question, _ := f.lookup('?')
+	if missing != question || f.runes[missing] != '?' {
+		t.Errorf("missing glyph = %d (rune %q), want %d", missing, f.runes[missing], question)
+	}
+}
+
+func TestFontSet(t *testing.T) {
+	fonts := newFontSet()
+	bold := fontKey{bold: true}
+	a := fonts.get(bold)
+	b := fonts.get(fontKey{})
+	if fonts.get(bold) != a {
+		t.Error("faces should be loaded once")
+	}
+	if len(fonts.order) != 2 || fonts.order[0] != a || fonts.order[1] != b {
+		t.Errorf("faces not kept in first-use order")
+	}
+	if a.name != "GoBold" || fonts.err != nil {
+		t.Errorf("got %s, err %v", a.name, fonts.err)
+	}
+}
@@ -0,0 +1,128 @@
+package pdf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// node is an element or text node of a parsed HTML document
+type node struct {
+	tag      string // Lowercase element name; empty for text nodes
+	text     string
+	attrs    map[string]string
+	classes  []string
+	children []*node
+	parent   *node
+}
+
+func (n *node) isText() bool {
+	return n.tag == ""
+}
+
+func (n *node) hasClass(class string) bool {
+	for _, c := range n.classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// elementSiblings returns the element children of n's parent (or just n)
+func (n *node) elementSiblings() []*node {
+	if n.parent == nil {
+		return []*node{n}
+	}
+	var siblings []*node
+	for _, c := range n.parent.children {
+		if !c.isText() {
+			siblings = append(siblings, c)
+		}
+	}
+	return siblings
+}
+
+// find returns the first element named tag in document order
+func (n *node) find(tag string) *node {
+	if n.tag == tag {
+		return n
+	}
+	for _, c := range n.children {
+		if found := c.find(tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent returns the concatenated text below n
+func (n *node) textContent() string {
+	if n.isText() {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+var (
+	styleBlockPattern  = regexp.MustCompile(`(?is)<style[^>]*>(.*?)</style\s*>`)
+	scriptBlockPattern = regexp.MustCompile(`(?is)<script[^>]*>.*?</script\s*>`)
+	commentPattern     = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// parseHTML parses an HTML document into a node tree and returns the
+// contents of its <style> blocks. Style and script blocks are taken out
+// before parsing, since CSS and JavaScript are not valid markup; the rest is
+// read with encoding/xml in non-strict mode, which closes void elements and
+// unclosed tags the way HTML does.
+func parseHTML(doc string) (*node, []string, error) {
+	var styles []string
+	for _, m := range styleBlockPattern.FindAllStringSubmatch(doc, -1) {
+		styles = append(styles, m[1])
+	}
+	doc = styleBlockPattern.ReplaceAllString(doc, "")
+	doc = scriptBlockPattern.ReplaceAllString(doc, "")
+	doc = commentPattern.ReplaceAllString(doc, "")
+
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	root := &node{tag: "#document"}
+	current := root
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing HTML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &node{tag: strings.ToLower(t.Name.Local), attrs: make(map[string]string), parent: current}
+			for _, a := range t.Attr {
+				el.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			el.classes = strings.Fields(el.attrs["class"])
+			current.children = append(current.children, el)
+			current = el
+		case xml.EndElement:
+			if current.parent != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			current.children = append(current.children, &node{text: string(t), parent: current})
+		}
+	}
+
+	return root, styles, nil
+}
@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	doc := `<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><title>Jane &amp; Co</title>
+<style>p { color: red; } /* <b>not markup</b> */</style>
+<script>if (a < b) { document.write("<p>x</p>") }</script>
+</head>
+<body>
+<!-- <p>commented out</p> -->
+<p class="intro lead" id="first">Hello<br>World &ndash; caf&eacute;</p>
+<ul><li>One<li>Two</ul>
+</body></html>`
+
+	root, styles, err := parseHTML(doc)
+	if err != nil {
+		t.Fatalf("parseHTML failed: %v", err)
+	}
+	if len(styles) != 1 || !strings.Contains(styles[0], "color: red") {
+		t.Errorf("styles = %q", styles)
+	}
+
+	if got := root.find("title").textContent(); got != "Jane & Co" {
+		t.Errorf("title = %q", got)
+	}
+	if root.find("script") != nil {
+		t.Error("script should be dropped")
+	}
+
+	p := root.find("p")
+	if p == nil {
+		t.Fatal("no <p> found")
+	}
+	if !reflect.DeepEqual(p.classes, []string{"intro", "lead"}) || p.attrs["id"] != "first" || !p.hasClass("lead") {
+		t.Errorf("p classes %q, attrs %v", p.classes, p.attrs)
+	}
+	if got := p.textContent(); got != "HelloWorld – café" {
+		t.Errorf("p text = %q", got)
+	}
+	if br := p.find("br"); br == nil || len(br.children) != 0 {
+		t.Error("<br> should be a childless element inside <p>")
+	}
+	if strings.Contains(root.textContent(), "commented out") {
+		t.Error("comments should be dropped")
+	}
+
+	ul := root.find("ul")
+	if ul == nil {
+		t.Fatal("no <ul> found")
+	}
+	if got := strings.TrimSpace(ul.textContent()); got != "OneTwo" {
+		t.Errorf("ul text = %q", got)
+	}
+	if ul.parent.tag != "body" {
+		t.Errorf("ul parent = %q, want body", ul.parent.tag)
+	}
+}
+
+func TestElementSiblings(t *testing.T) {
+	root, _, err := parseHTML(`<div> <a></a> text <b></b> </div>`)
+	if err != nil {
+		t.Fatalf("parseHTML failed: %v", err)
+	}
+	b := root.find("b")
+	siblings := b.elementSiblings()
+	if len(siblings) != 2 || siblings[0].tag != "a" || siblings[1] != b {
+		t.Errorf("siblings of <b> = %v", siblings)
+	}
+	if got := root.elementSiblings(); len(got) != 1 || got[0] != root {
+		t.Error("the root is its own only sibling")
+	}
+}
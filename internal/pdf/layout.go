@@ -0,0 +1,982 @@
+package pdf
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Drawing operations. Coordinates are in points from the top-left corner of
+// the page, y growing downwards; the writer flips them into PDF space.
+type op interface {
+	translate(dx, dy float64) op
+}
+
+type rectOp struct {
+	x, y, w, h float64
+	color      rgb
+}
+
+// textOp draws glyphs from x along the baseline y
+type textOp struct {
+	x, y    float64
+	face    *fontFace
+	size    float64
+	color   rgb
+	spacing float64 // Letter spacing (Tc)
+	glyphs  []placedGlyph
+}
+
+type placedGlyph struct {
+	gid    uint16
+	adjust float64 // Extra space after the glyph, in points
+}
+
+type linkOp struct {
+	x, y, w, h float64
+	uri        string
+}
+
+func (o rectOp) translate(dx, dy float64) op {
+	o.x += dx
+	o.y += dy
+	return o
+}
+
+func (o *textOp) translate(dx, dy float64) op {
+	moved := *o
+	moved.x += dx
+	moved.y += dy
+	return &moved
+}
+
+func (o linkOp) translate(dx, dy float64) op {
+	o.x += dx
+	o.y += dy
+	return o
+}
+
+type page struct {
+	ops []op
+}
+
+const epsilon = 0.01
+
+// layouter places the boxes of a document in normal flow. A paginating
+// layouter breaks to a new page when a line or flex row does not fit; the
+// layouters used for flex items have a single page of unbounded height.
+type layouter struct {
+	fonts    *fontSet
+	styles   map[*node]*style
+	paginate bool
+	top      float64
+	bottom   float64
+
+	pages         []*page
+	y             float64
+	pendingMargin float64 // Collapsed vertical margin not yet applied
+	atTop         bool    // Nothing placed on the current page yet
+	marker        *marker // List marker waiting for the first line of its item
+
+	hasBaseline   bool
+	firstBaseline float64
+}
+
+// marker is a list item bullet or number, drawn left of the first line
+type marker struct {
+	text  string
+	style *style
+	x     float64
+}
+
+func newLayouter(fonts *fontSet, styles map[*node]*style, top, bottom float64, paginate bool) *layouter {
+	return &layouter{
+		fonts:    fonts,
+		styles:   styles,
+		paginate: paginate,
+		top:      top,
+		bottom:   bottom,
+		pages:    []*page{{}},
+		y:        top,
+		atTop:    true,
+	}
+}
+
+func (l *layouter) sub() *layouter {
+	return newLayouter(l.fonts, l.styles, 0, math.Inf(1), false)
+}
+
+func (l *layouter) page() *page {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *layouter) emit(o op) {
+	l.page().ops = append(l.page().ops, o)
+}
+
+func (l *layouter) newPage() {
+	l.pages = append(l.pages, &page{})
+	l.y = l.top
+	l.pendingMargin = 0
+	l.atTop = true
+}
+
+// place reserves h points of height after any pending margin, starting a
+// new page when it does not fit, and returns the top of the reserved space.
+// Margins at the top of a page following a break are dropped.
+func (l *layouter) place(h float64) float64 {
+	if l.atTop && len(l.pages) > 1 {
+		l.pendingMargin = 0
+	}
+	y := l.y + l.pendingMargin
+	if l.paginate && !l.atTop && y+h > l.bottom+epsilon {
+		l.newPage()
+		y = l.y
+	}
+	l.pendingMargin = 0
+	l.y = y + h
+	l.atTop = false
+	return y
+}
+
+// styleOf returns the style of an element, or the style text inherits
+func (l *layouter) styleOf(n *node) *style {
+	if n.isText() {
+		return l.styles[n.parent]
+	}
+	return l.styles[n]
+}
+
+// collapse combines two adjoining vertical margins
+func collapse(a, b float64) float64 {
+	switch {
+	case a >= 0 && b >= 0:
+		return math.Max(a, b)
+	case a < 0 && b < 0:
+		return math.Min(a, b)
+	}
+	return a + b
+}
+
+func (s *style) decorated() bool {
+	return s.background != nil || s.border != [4]float64{}
+}
+
+func (s *style) horizontalExtras() float64 {
+	return s.margin[left] + s.margin[right] + s.border[left] + s.border[right] + s.padding[left] + s.padding[right]
+}
+
+// decoration is a block background and border waiting for the block to end
+type decoration struct {
+	style     *style
+	x, w      float64
+	startPage int
+	startY    float64
+	opIndex   int
+}
+
+// block lays out a block-level element whose margin box spans width
+func (l *layouter) block(n *node, x, width float64) {
+	s := l.styles[n]
+	if s.breakBefore && l.paginate && !l.atTop {
+		l.newPage()
+	}
+	if s.avoidBreak && l.paginate && !l.atTop {
+		// Measure the block; move it to the next page when it would fit
+		// there but not in the space left on this one
+		trial := l.sub()
+		trial.block(n, x, width)
+		height := trial.y + trial.pendingMargin - s.margin[bottom]
+		if l.y+l.pendingMargin+height > l.bottom+epsilon && height <= l.bottom-l.top {
+			l.newPage()
+		}
+	}
+
+	l.pendingMargin = collapse(l.pendingMargin, s.margin[top])
+	bx := x + s.margin[left]
+	bw := width - s.margin[left] - s.margin[right]
+	cx := bx + s.border[left] + s.padding[left]
+	cw := bw - s.border[left] - s.border[right] - s.padding[left] - s.padding[right]
+
+	var dec *decoration
+	if lead := s.border[top] + s.padding[top]; lead > 0 || s.decorated() {
+		y := l.place(lead)
+		if s.decorated() {
+			dec = &decoration{style: s, x: bx, w: bw, startPage: len(l.pages) - 1, startY: y}
+			dec.opIndex = len(l.page().ops)
+		}
+	}
+
+	var mark *marker
+	if s.display == "list-item" && s.listStyle != "none" {
+		mark = &marker{text: listMarker(n, s), style: s, x: cx}
+		l.marker = mark
+	}
+
+	l.contents(n, s, cx, cw)
+
+	if l.marker == mark {
+		l.marker = nil
+	}
+	if trail := s.padding[bottom] + s.border[bottom]; trail > 0 {
+		l.place(trail)
+	}
+	if dec != nil {
+		l.finish(dec)
+	}
+	l.pendingMargin = collapse(l.pendingMargin, s.margin[bottom])
+}
+
+// finish draws a block's background and borders behind its content, on
+// every page the block spans
+func (l *layouter) finish(d *decoration) {
+	s := d.style
+	last := len(l.pages) - 1
+	for i := d.startPage; i <= last; i++ {
+		y0, y1, index := l.top, l.bottom, 0
+		if i == d.startPage {
+			y0, index = d.startY, d.opIndex
+		}
+		if i == last {
+			y1 = l.y
+		}
+		ops := boxOps(s, d.x, y0, d.w, y1-y0, i == d.startPage, i == last)
+		l.pages[i].ops = slices.Insert(l.pages[i].ops, index, ops...)
+	}
+}
+
+// boxOps returns the background and border rectangles of a box. Top and
+// bottom borders are only drawn on the first and last fragment.
+func boxOps(s *style, x, y, w, h float64, first, last bool) []op {
+	var ops []op
+	if s.background != nil {
+		ops = append(ops, rectOp{x, y, w, h, *s.background})
+	}
+	if first && s.border[top] > 0 {
+		ops = append(ops, rectOp{x, y, w, s.border[top], s.borderColor[top]})
+	}
+	if last && s.border[bottom] > 0 {
+		ops = append(ops, rectOp{x, y + h - s.border[bottom], w, s.border[bottom], s.borderColor[bottom]})
+	}
+	if s.border[left] > 0 {
+		ops = append(ops, rectOp{x, y, s.border[left], h, s.borderColor[left]})
+	}
+	if s.border[right] > 0 {
+		ops = append(ops, rectOp{x + w - s.border[right], y, s.border[right], h, s.borderColor[right]})
+	}
+	return ops
+}
+
+// contents lays out the children of an element in its content box
+func (l *layouter) contents(n *node, s *style, x, width float64) {
+	if (s.display == "flex" && !s.column) || s.display == "table-row" {
+		l.flexRow(n, s, x, width)
+		return
+	}
+	if !l.hasBlockChildren(n) {
+		l.inline(n.children, s, x, width)
+		return
+	}
+
+	var run []*node
+	flush := func() {
+		if len(run) > 0 {
+			l.inline(run, s, x, width)
+			run = nil
+		}
+	}
+	placed := false
+	for _, c := range n.children {
+		cs := l.styleOf(c)
+		if cs.display == "none" {
+			continue
+		}
+		if c.isText() || !cs.isBlock() {
+			run = append(run, c)
+			continue
+		}
+		flush()
+		if placed && s.display == "flex" {
+			l.pendingMargin += s.rowGap
+		}
+		l.block(c, x, width)
+		placed = true
+	}
+	flush()
+}
+
+func (l *layouter) hasBlockChildren(n *node) bool {
+	for _, c := range n.children {
+		if !c.isText() && l.styles[c].isBlock() {
+			return true
+		}
+	}
+	return false
+}
+
+// listMarker returns the bullet or number of a list item
+func listMarker(n *node, s *style) string {
+	switch s.listStyle {
+	case "decimal":
+		number := 1
+		if n.parent != nil {
+			if start, err := strconv.Atoi(n.parent.attrs["start"]); err == nil {
+				number = start
+			}
+			for _, sib := range n.elementSiblings() {
+				if sib == n {
+					break
+				}
+				if sib.tag == "li" {
+					number++
+				}
+			}
+		}
+		return strconv.Itoa(number) + ". "
+	case "circle":
+		return "◦ "
+	case "square":
+		return "▪ "
+	}
+	return "• "
+}
+
+// Inline content
+
+const (
+	itemText = iota
+	itemSpace
+	itemGlue  // Horizontal space from inline margins, borders and padding
+	itemBreak // <br> or a newline in preformatted text
+)
+
+type inlineItem struct {
+	kind  int
+	text  string
+	style *style
+	face  *fontFace
+	width float64
+	href  string
+}
+
+// collectInline flattens inline content into words, collapsible spaces,
+// glue and forced breaks
+func (l *layouter) collectInline(nodes []*node) []inlineItem {
+	var items []inlineItem
+	var walk func(n *node, href string)
+	walk = func(n *node, href string) {
+		if n.isText() {
+			items = l.appendText(items, n.text, l.styles[n.parent], href)
+			return
+		}
+		s := l.styles[n]
+		if s.display == "none" {
+			return
+		}
+		switch n.tag {
+		case "br":
+			items = append(items, inlineItem{kind: itemBreak, style: s})
+			return
+		case "a":
+			if h := n.attrs["href"]; h != "" {
+				href = h
+			}
+		}
+		if w := s.margin[left] + s.border[left] + s.padding[left]; w != 0 {
+			items = append(items, inlineItem{kind: itemGlue, style: s, width: w})
+		}
+		for _, c := range n.children {
+			walk(c, href)
+		}
+		if w := s.margin[right] + s.border[right] + s.padding[right]; w != 0 {
+			items = append(items, inlineItem{kind: itemGlue, style: s, width: w})
+		}
+	}
+	for _, n := range nodes {
+		walk(n, "")
+	}
+	return items
+}
+
+func (l *layouter) appendText(items []inlineItem, text string, s *style, href string) []inlineItem {
+	face := l.fonts.get(s.fontKey())
+	word := func(w string) inlineItem {
+		w = transformText(w, s.textTransform, len(items) == 0 || items[len(items)-1].kind != itemText)
+		return inlineItem{kind: itemText, text: w, style: s, face: face, width: textWidth(face, s, w), href: href}
+	}
+
+	if s.pre {
+		for i, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+			if i > 0 {
+				items = append(items, inlineItem{kind: itemBreak, style: s})
+			}
+			if line != "" {
+				items = append(items, word(line))
+			}
+		}
+		return items
+	}
+
+	start := -1
+	for i, r := range text {
+		if !unicode.IsSpace(r) || r == ' ' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			items = append(items, word(text[start:i]))
+			start = -1
+		}
+		if len(items) == 0 || items[len(items)-1].kind != itemSpace {
+			items = append(items, inlineItem{kind: itemSpace, text: " ", style: s, face: face, width: textWidth(face, s, " "), href: href})
+		}
+	}
+	if start >= 0 {
+		items = append(items, word(text[start:]))
+	}
+	return items
+}
+
+func transformText(s, transform string, wordStart bool) string {
+	switch transform {
+	case "uppercase":
+		return strings.ToUpper(s)
+	case "lowercase":
+		return strings.ToLower(s)
+	case "capitalize":
+		if wordStart {
+			r, size := utf8.DecodeRuneInString(s)
+			return string(unicode.ToUpper(r)) + s[size:]
+		}
+	}
+	return s
+}
+
+func textWidth(face *fontFace, s *style, text string) float64 {
+	return float64(face.measure(text))*s.fontSize/1000 + s.letterSpacing*float64(utf8.RuneCountInString(text))
+}
+
+// textLine is one line box of inline content
+type textLine struct {
+	items []inlineItem
+	last  bool // Last line of a paragraph or ended by a forced break: not justified
+}
+
+// breakLines fills lines greedily, breaking at spaces. Words wider than a
+// line are broken between characters.
+func breakLines(items []inlineItem, width float64) []textLine {
+	var lines []textLine
+	var line []inlineItem
+	lineWidth := 0.0
+
+	end := func(last bool) {
+		line = trimSpaces(line)
+		if len(line) > 0 || last {
+			lines = append(lines, textLine{items: line, last: last})
+		}
+		line, lineWidth = nil, 0
+	}
+
+	for i := 0; i < len(items); {
+		it := items[i]
+		switch it.kind {
+		case itemBreak:
+			end(true)
+			i++
+			continue
+		case itemSpace:
+			if len(line) > 0 {
+				line = append(line, it)
+				lineWidth += it.width
+			}
+			i++
+			continue
+		}
+
+		// A chunk is a run of items with no break opportunity inside
+		j := i
+		chunkWidth := 0.0
+		for j < len(items) && (items[j].kind == itemText || items[j].kind == itemGlue) {
+			chunkWidth += items[j].width
+			j++
+		}
+		chunk := items[i:j]
+		i = j
+
+		if len(trimSpaces(line)) > 0 && lineWidth+chunkWidth > width+epsilon {
+			end(false)
+		}
+		if len(line) == 0 && chunkWidth > width+epsilon {
+			for _, piece := range splitChunk(chunk) {
+				if len(line) > 0 && lineWidth+piece.width > width+epsilon {
+					end(false)
+				}
+				line = append(line, piece)
+				lineWidth += piece.width
+			}
+			continue
+		}
+		line = append(line, chunk...)
+		lineWidth += chunkWidth
+	}
+	if len(trimSpaces(line)) > 0 {
+		end(true)
+	} else if len(lines) > 0 {
+		lines[len(lines)-1].last = true
+	}
+	return lines
+}
+
+// splitChunk breaks a chunk into one item per character
+func splitChunk(chunk []inlineItem) []inlineItem {
+	var pieces []inlineItem
+	for _, it := range chunk {
+		if it.kind != itemText {
+			pieces = append(pieces, it)
+			continue
+		}
+		for _, r := range it.text {
+			piece := it
+			piece.text = string(r)
+			piece.width = textWidth(it.face, it.style, piece.text)
+			pieces = append(pieces, piece)
+		}
+	}
+	return pieces
+}
+
+func trimSpaces(line []inlineItem) []inlineItem {
+	for len(line) > 0 && line[0].kind == itemSpace {
+		line = line[1:]
+	}
+	for len(line) > 0 && line[len(line)-1].kind == itemSpace {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// strut returns the space a style needs above and below the baseline, with
+// the leading split evenly between the two
+func (l *layouter) strut(s *style) (above, below float64) {
+	face := l.fonts.get(s.fontKey())
+	ascent := float64(face.ascent) * s.fontSize / 1000
+	descent := -float64(face.descent) * s.fontSize / 1000
+	half := (s.lineHeightPt() - ascent - descent) / 2
+	return ascent + half, descent + half
+}
+
+// inline lays out inline content as line boxes
+func (l *layouter) inline(nodes []*node, s *style, x, width float64) {
+	items := l.collectInline(nodes)
+	for _, line := range breakLines(items, width) {
+		l.line(line, s, x, width)
+	}
+}
+
+func (l *layouter) line(line textLine, s *style, x, width float64) {
+	above, below := l.strut(s)
+	lineWidth, spaces := 0.0, 0
+	for _, it := range line.items {
+		lineWidth += it.width
+		if it.kind == itemSpace {
+			spaces++
+		}
+		if it.kind == itemText || it.kind == itemSpace {
+			a, b := l.strut(it.style)
+			above, below = math.Max(above, a), math.Max(below, b)
+		}
+	}
+
+	offset, extra := 0.0, 0.0
+	switch s.textAlign {
+	case "right":
+		offset = width - lineWidth
+	case "center":
+		offset = (width - lineWidth) / 2
+	case "justify":
+		if !line.last && spaces > 0 && lineWidth < width {
+			extra = (width - lineWidth) / float64(spaces)
+		}
+	}
+
+	y := l.place(above + below)
+	baseline := y + above
+	if !l.hasBaseline {
+		l.hasBaseline, l.firstBaseline = true, baseline
+	}
+	l.drawMarker(baseline)
+
+	cx := x + offset
+	var run *textOp
+	var link *linkOp
+	closeLink := func() {
+		if link != nil {
+			l.emit(*link)
+			link = nil
+		}
+	}
+	for _, it := range line.items {
+		if it.kind == itemGlue {
+			run = nil
+			closeLink()
+			cx += it.width
+			continue
+		}
+
+		start := cx
+		if run == nil || run.face != it.face || run.size != it.style.fontSize || run.color != it.style.color || run.spacing != it.style.letterSpacing {
+			run = &textOp{x: cx, y: baseline, face: it.face, size: it.style.fontSize, color: it.style.color, spacing: it.style.letterSpacing}
+			l.emit(run)
+		}
+		for _, r := range it.text {
+			gid, _ := it.face.glyph(r)
+			run.glyphs = append(run.glyphs, placedGlyph{gid: uint16(gid)})
+		}
+		cx += it.width
+		if it.kind == itemSpace && extra > 0 {
+			run.glyphs[len(run.glyphs)-1].adjust += extra
+			cx += extra
+		}
+
+		fs := it.style.fontSize
+		if it.style.underline {
+			l.emit(rectOp{start, baseline + fs*0.1, cx - start, math.Max(fs*0.05, 0.5), it.style.color})
+		}
+		if it.href != "" && linkTarget(it.href) {
+			a, b := l.strut(it.style)
+			if link != nil && link.uri == it.href {
+				link.w = cx - link.x
+			} else {
+				closeLink()
+				link = &linkOp{x: start, y: baseline - a, w: cx - start, h: a + b, uri: it.href}
+			}
+		} else {
+			closeLink()
+		}
+	}
+	closeLink()
+}
+
+// linkTarget reports whether a link can be followed from a PDF
+func linkTarget(href string) bool {
+	h := strings.ToLower(href)
+	for _, scheme := range []string{"http://", "https://", "mailto:", "tel:"} {
+		if strings.HasPrefix(h, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *layouter) drawMarker(baseline float64) {
+	m := l.marker
+	if m == nil {
+		return
+	}
+	l.marker = nil
+	face := l.fonts.get(m.style.fontKey())
+	text := m.text
+	if r, _ := utf8.DecodeRuneInString(text); !face.has(r) {
+		text = "• "
+	}
+	mark := &textOp{y: baseline, face: face, size: m.style.fontSize, color: m.style.color}
+	width := 0.0
+	for _, r := range text {
+		gid, w := face.glyph(r)
+		mark.glyphs = append(mark.glyphs, placedGlyph{gid: uint16(gid)})
+		width += float64(w) * m.style.fontSize / 1000
+	}
+	mark.x = m.x - width
+	l.emit(mark)
+}
+
+// Flex rows
+
+type flexItem struct {
+	n      *node
+	style  *style
+	anon   bool // Text directly inside the flex container
+	basis  float64
+	min    float64
+	width  float64
+	sub    *layouter
+	height float64 // Margin box height
+	base   float64 // Baseline from the top of the margin box
+}
+
+// flexRow lays out the children of a row flex container, or the cells of a
+// table row as equal columns
+func (l *layouter) flexRow(n *node, s *style, x, width float64) {
+	var items []*flexItem
+	for _, c := range n.children {
+		if c.isText() {
+			if strings.TrimSpace(c.text) != "" {
+				items = append(items, &flexItem{n: c, style: blockified(l.styles[n]), anon: true})
+			}
+			continue
+		}
+		if cs := l.styles[c]; cs.display != "none" {
+			items = append(items, &flexItem{n: c, style: cs})
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	table := s.display == "table-row"
+	for _, it := range items {
+		if table {
+			it.basis = (width - s.gap*float64(len(items)-1)) / float64(len(items))
+			it.min = it.basis
+			continue
+		}
+		it.basis = l.intrinsic(it.n, false)
+		it.min = l.intrinsic(it.n, true)
+	}
+
+	var rows [][]*flexItem
+	if s.wrap {
+		var row []*flexItem
+		used := 0.0
+		for _, it := range items {
+			if len(row) > 0 && used+s.gap+it.basis > width+epsilon {
+				rows = append(rows, row)
+				row, used = nil, 0
+			}
+			if len(row) > 0 {
+				used += s.gap
+			}
+			row = append(row, it)
+			used += it.basis
+		}
+		rows = append(rows, row)
+	} else {
+		rows = [][]*flexItem{items}
+	}
+
+	for i, row := range rows {
+		if i > 0 {
+			l.pendingMargin += s.rowGap
+		}
+		l.flexLine(row, s, x, width)
+	}
+}
+
+// blockified returns the style of an anonymous flex item
+func blockified(parent *style) *style {
+	s := parent.inherit()
+	s.display = "block"
+	return s
+}
+
+func (l *layouter) flexLine(row []*flexItem, s *style, x, width float64) {
+	free := width - s.gap*float64(len(row)-1)
+	totalGrow := 0.0
+	for _, it := range row {
+		it.width = it.basis
+		free -= it.basis
+		totalGrow += it.style.grow
+	}
+
+	switch {
+	case free > 0 && totalGrow > 0:
+		for _, it := range row {
+			it.width += free * it.style.grow / totalGrow
+		}
+		free = 0
+	case free < 0:
+		free = shrink(row, -free)
+	}
+
+	start, between := 0.0, s.gap
+	n := float64(len(row))
+	switch s.justify {
+	case "flex-end", "end", "right":
+		start = free
+	case "center":
+		start = free / 2
+	case "space-between":
+		if len(row) > 1 {
+			between += free / (n - 1)
+		}
+	case "space-around":
+		start = free / n / 2
+		between += free / n
+	case "space-evenly":
+		start = free / (n + 1)
+		between += free / (n + 1)
+	}
+
+	// Lay out each item at its width, then align the row
+	rowHeight, maxAbove, maxBelow := 0.0, 0.0, 0.0
+	for _, it := range row {
+		is := it.style
+		it.sub = l.sub()
+		contentWidth := it.width - is.horizontalExtras()
+		if it.anon {
+			it.sub.inline([]*node{it.n}, is, 0, contentWidth)
+		} else {
+			it.sub.contents(it.n, is, 0, contentWidth)
+		}
+		inner := is.margin[top] + is.border[top] + is.padding[top]
+		it.height = inner + it.sub.y + it.sub.pendingMargin + is.padding[bottom] + is.border[bottom] + is.margin[bottom]
+		it.base = it.height - is.margin[bottom]
+		if it.sub.hasBaseline {
+			it.base = inner + it.sub.firstBaseline
+		}
+		rowHeight = math.Max(rowHeight, it.height)
+		maxAbove = math.Max(maxAbove, it.base)
+		maxBelow = math.Max(maxBelow, it.height-it.base)
+	}
+	baselineAligned := s.alignItems == "baseline" || s.alignItems == "first baseline"
+	if baselineAligned {
+		rowHeight = maxAbove + maxBelow
+	}
+
+	y := l.place(rowHeight)
+	rowBaseline := y + maxAbove
+	if !baselineAligned && len(row) > 0 {
+		rowBaseline = y + row[0].base
+	}
+	if !l.hasBaseline {
+		l.hasBaseline, l.firstBaseline = true, rowBaseline
+	}
+	l.drawMarker(rowBaseline)
+
+	cx := x + start
+	for _, it := range row {
+		is := it.style
+		dy, boxHeight := 0.0, it.height
+		switch s.alignItems {
+		case "baseline", "first baseline":
+			dy = maxAbove - it.base
+		case "center":
+			dy = (rowHeight - it.height) / 2
+		case "flex-end", "end":
+			dy = rowHeight - it.height
+		case "flex-start", "start":
+		default:
+			boxHeight = rowHeight
+		}
+
+		bx := cx + is.margin[left]
+		by := y + dy + is.margin[top]
+		bw := it.width - is.margin[left] - is.margin[right]
+		bh := boxHeight - is.margin[top] - is.margin[bottom]
+		if !it.anon {
+			l.page().ops = append(l.page().ops, boxOps(is, bx, by, bw, bh, true, true)...)
+		}
+		dx, dyContent := bx+is.border[left]+is.padding[left], by+is.border[top]+is.padding[top]
+		for _, o := range it.sub.pages[0].ops {
+			l.emit(o.translate(dx, dyContent))
+		}
+		cx += it.width + between
+	}
+}
+
+// shrink narrows the items of an overflowing row in proportion to their
+// widths, but not below their min-content width, and returns the free space
+// left (negative if the row still overflows)
+func shrink(row []*flexItem, deficit float64) float64 {
+	for pass := 0; pass < len(row) && deficit > epsilon; pass++ {
+		total := 0.0
+		for _, it := range row {
+			if it.width > it.min {
+				total += it.width
+			}
+		}
+		if total == 0 {
+			break
+		}
+		taken := 0.0
+		for _, it := range row {
+			if it.width <= it.min {
+				continue
+			}
+			cut := math.Min(deficit*it.width/total, it.width-it.min)
+			it.width -= cut
+			taken += cut
+		}
+		deficit -= taken
+	}
+	return -deficit
+}
+
+// intrinsic returns the max-content (or min-content) width of a node's
+// margin box: its width without wrapping, or its widest unbreakable part
+func (l *layouter) intrinsic(n *node, min bool) float64 {
+	if n.isText() {
+		return inlineWidth(l.collectInline([]*node{n}), min)
+	}
+	s := l.styles[n]
+	if s.display == "none" {
+		return 0
+	}
+
+	inner := 0.0
+	switch {
+	case (s.display == "flex" && !s.column) || s.display == "table-row":
+		count := 0
+		for _, c := range n.children {
+			if c.isText() && strings.TrimSpace(c.text) == "" || !c.isText() && l.styles[c].display == "none" {
+				continue
+			}
+			w := l.intrinsic(c, min)
+			if min && s.wrap {
+				inner = math.Max(inner, w)
+			} else {
+				inner += w
+				count++
+			}
+		}
+		if count > 1 {
+			inner += s.gap * float64(count-1)
+		}
+	case l.hasBlockChildren(n):
+		var run []*node
+		flush := func() {
+			inner = math.Max(inner, inlineWidth(l.collectInline(run), min))
+			run = nil
+		}
+		for _, c := range n.children {
+			if !c.isText() && l.styles[c].isBlock() {
+				flush()
+				inner = math.Max(inner, l.intrinsic(c, min))
+				continue
+			}
+			run = append(run, c)
+		}
+		flush()
+	default:
+		inner = inlineWidth(l.collectInline(n.children), min)
+	}
+	return inner + s.horizontalExtras()
+}
+
+// inlineWidth returns the widest line of inline content when lines only
+// break at forced breaks, or the widest chunk when min is set
+func inlineWidth(items []inlineItem, min bool) float64 {
+	widest, current, pending := 0.0, 0.0, 0.0
+	for _, it := range items {
+		switch it.kind {
+		case itemBreak:
+			current, pending = 0, 0
+		case itemSpace:
+			if min {
+				current = 0
+			} else if current > 0 {
+				pending += it.width
+			}
+		default:
+			current += pending + it.width
+			pending = 0
+		}
+		widest = math.Max(widest, current)
+	}
+	return widest
+}
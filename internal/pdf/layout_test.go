@@ -0,0 +1,305 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// layoutDoc renders a document with 10mm A4 margins and no body spacing
+func layoutDoc(t *testing.T, body, css string) *document {
+	t.Helper()
+	html := `<html><head><style>@page { size: A4; margin: 10mm } body { margin: 0; font-size: 10pt } ` + css + `</style></head><body>` + body + `</body></html>`
+	doc, err := render([]byte(html), Options{})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	return doc
+}
+
+// texts returns the text ops of a page
+func texts(p *page) []*textOp {
+	var out []*textOp
+	for _, o := range p.ops {
+		if t, ok := o.(*textOp); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (o *textOp) String() string {
+	var b strings.Builder
+	for _, g := range o.glyphs {
+		b.WriteRune(o.face.runes[sfnt.GlyphIndex(g.gid)])
+	}
+	return b.String()
+}
+
+// findText returns the first text op on a page containing s
+func findText(t *testing.T, p *page, s string) *textOp {
+	t.Helper()
+	for _, o := range texts(p) {
+		if strings.Contains(o.String(), s) {
+			return o
+		}
+	}
+	t.Fatalf("no text %q on page", s)
+	return nil
+}
+
+func (o *textOp) width() float64 {
+	w := 0.0
+	for _, g := range o.glyphs {
+		w += float64(o.face.widths[sfnt.GlyphIndex(g.gid)])*o.size/1000 + o.spacing + g.adjust
+	}
+	return w
+}
+
+func TestBreakLines(t *testing.T) {
+	word := func(s string) inlineItem { return inlineItem{kind: itemText, text: s, width: 10} }
+	space := inlineItem{kind: itemSpace, text: " ", width: 2}
+	text := func(line textLine) string {
+		var b strings.Builder
+		for _, it := range line.items {
+			b.WriteString(it.text)
+		}
+		return b.String()
+	}
+
+	items := []inlineItem{space, word("a"), space, word("b"), word("c"), space, space, word("d"), {kind: itemBreak}, word("e"), space}
+	lines := breakLines(items, 35)
+	var got []string
+	for _, l := range lines {
+		got = append(got, text(l))
+	}
+	// "bc" has no break opportunity inside; leading and trailing spaces go
+	want := []string{"a bc", "d", "e"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if lines[0].last || !lines[1].last || !lines[2].last {
+		t.Error("lines ending a paragraph or at a forced break should be marked last")
+	}
+
+	// A word wider than the line is broken between characters
+	face, err := loadFace(fontKey{})
+	if err != nil {
+		t.Fatalf("loadFace failed: %v", err)
+	}
+	s := rootStyle()
+	long := inlineItem{kind: itemText, text: "abcdef", style: s, face: face, width: textWidth(face, s, "abcdef")}
+	lines = breakLines([]inlineItem{long}, long.width/2.5)
+	if len(lines) != 3 || text(lines[0])+text(lines[1])+text(lines[2]) != "abcdef" {
+		t.Errorf("long word split into %d lines", len(lines))
+	}
+}
+
+func TestInlineWidth(t *testing.T) {
+	items := []inlineItem{
+		{kind: itemText, width: 10}, {kind: itemSpace, width: 2}, {kind: itemText, width: 30},
+		{kind: itemBreak}, {kind: itemText, width: 5}, {kind: itemSpace, width: 2},
+	}
+	if got := inlineWidth(items, false); got != 42 {
+		t.Errorf("max-content width = %v, want 42", got)
+	}
+	if got := inlineWidth(items, true); got != 30 {
+		t.Errorf("min-content width = %v, want 30", got)
+	}
+}
+
+func TestLayoutBlocks(t *testing.T) {
+	doc := layoutDoc(t, `<div class="box">Hello</div><p>Second</p>`,
+		`.box { margin: 10pt 0; padding: 5pt; border-top: 2pt solid black; background: #eee; text-align: center } p { margin: 20pt 0 }`)
+	p := doc.pages[0]
+	marginTop, marginLeft := doc.margins[top], doc.margins[left]
+	contentWidth := doc.width - 2*marginLeft
+
+	// Background first, then the border, then the text
+	bg, ok := p.ops[0].(rectOp)
+	if !ok || bg.color.r > 0.95 || !near(bg.y, marginTop+10) || !near(bg.w, contentWidth) {
+		t.Fatalf("first op = %+v, want the background", p.ops[0])
+	}
+	border, ok := p.ops[1].(rectOp)
+	if !ok || !near(border.h, 2) || !near(border.y, bg.y) {
+		t.Errorf("second op = %+v, want the top border", p.ops[1])
+	}
+
+	hello := findText(t, p, "Hello")
+	if center := hello.x + hello.width()/2; !near(center, marginLeft+contentWidth/2) {
+		t.Errorf("centered text midpoint at %v, want %v", center, marginLeft+contentWidth/2)
+	}
+	if hello.y < bg.y+7 || hello.y > bg.y+bg.h {
+		t.Errorf("text baseline %v outside its box %v..%v", hello.y, bg.y, bg.y+bg.h)
+	}
+
+	// Adjoining margins collapse: 20pt between the box and the paragraph
+	second := findText(t, p, "Second")
+	lineHeight := 10 * normalLineHeight
+	boxBottom := bg.y + bg.h
+	if gap := second.y - boxBottom; gap < 20 || gap > 20+lineHeight {
+		t.Errorf("second paragraph baseline %v, box bottom %v", second.y, boxBottom)
+	}
+}
+
+func TestLayoutJustify(t *testing.T) {
+	doc := layoutDoc(t, `<p>`+strings.Repeat("word ", 60)+`</p>`, `p { text-align: justify; margin: 0 }`)
+	ops := texts(doc.pages[0])
+	if len(ops) < 2 {
+		t.Fatalf("expected several lines, got %d", len(ops))
+	}
+	contentWidth := doc.width - doc.margins[left] - doc.margins[right]
+	if w := ops[0].width(); !near(w, contentWidth) {
+		t.Errorf("justified line width %v, want %v", w, contentWidth)
+	}
+	last := ops[len(ops)-1]
+	for _, g := range last.glyphs {
+		if g.adjust != 0 {
+			t.Error("the last line should not be justified")
+			break
+		}
+	}
+}
+
+func TestLayoutFlex(t *testing.T) {
+	doc := layoutDoc(t, `<div class="row"><div>Left side</div><div class="meta">Jan 2020 - Present</div></div>
+<div class="tags"><span>Go</span><span>Python</span></div>`,
+		`.row { display: flex; justify-content: space-between; align-items: baseline }
+.meta { font-size: 8pt }
+.tags { display: flex; gap: 6pt } .tags span { padding: 2pt 4pt; background: #ddd }`)
+	p := doc.pages[0]
+	right := doc.width - doc.margins[right]
+
+	leftText := findText(t, p, "Left side")
+	meta := findText(t, p, "Present")
+	if !near(leftText.x, doc.margins[left]) {
+		t.Errorf("first item at x=%v, want %v", leftText.x, doc.margins[left])
+	}
+	if end := meta.x + meta.width(); !near(end, right) {
+		t.Errorf("last item ends at %v, want %v", end, right)
+	}
+	if !near(leftText.y, meta.y) {
+		t.Errorf("baselines %v and %v should be aligned", leftText.y, meta.y)
+	}
+
+	goTag, python := findText(t, p, "Go"), findText(t, p, "Python")
+	var tags []rectOp
+	for _, o := range p.ops {
+		if r, ok := o.(rectOp); ok && r.y > meta.y {
+			tags = append(tags, r)
+		}
+	}
+	if len(tags) != 2 {
+		t.Fatalf("got %d tag backgrounds, want 2", len(tags))
+	}
+	if !near(goTag.x, tags[0].x+4) || !near(tags[1].x, tags[0].x+tags[0].w+6) || !near(python.x, tags[1].x+4) {
+		t.Errorf("tags at %v and %v, backgrounds %+v", goTag.x, python.x, tags)
+	}
+}
+
+func TestLayoutFlexWrapAndShrink(t *testing.T) {
+	long := strings.Repeat("long words here ", 12)
+	doc := layoutDoc(t, `<div class="wrap"><div>`+long+`</div><div>Dates</div></div><div class="nowrap"><div>`+long+`</div><div>Dates</div></div>`,
+		`.wrap { display: flex; flex-wrap: wrap } .nowrap { display: flex; margin-top: 20pt }`)
+	p := doc.pages[0]
+	var dates []*textOp
+	for _, o := range texts(p) {
+		if o.String() == "Dates" {
+			dates = append(dates, o)
+		}
+	}
+	if len(dates) != 2 {
+		t.Fatalf("found %d date texts", len(dates))
+	}
+	if !near(dates[0].x, doc.margins[left]) {
+		t.Errorf("wrapped item at x=%v, want start of a new row", dates[0].x)
+	}
+	if dates[1].x+dates[1].width() > doc.width-doc.margins[right]+epsilon || dates[1].x < doc.width/2 {
+		t.Errorf("shrunk row item at x=%v should stay on the line, inside the margin", dates[1].x)
+	}
+}
+
+func TestLayoutLists(t *testing.T) {
+	doc := layoutDoc(t, `<ul><li>One</li></ul><ol start="3"><li>Three</li><li>Four</li></ol><ul class="none"><li>Plain</li></ul>`, `.none { list-style: none }`)
+	p := doc.pages[0]
+	one := findText(t, p, "One")
+	bullet := findText(t, p, "•")
+	if bullet.x >= one.x || !near(bullet.y, one.y) {
+		t.Errorf("bullet at %v,%v; item text at %v,%v", bullet.x, bullet.y, one.x, one.y)
+	}
+	if !near(one.x, doc.margins[left]+30) {
+		t.Errorf("list item text at x=%v, want the 40px list indent", one.x)
+	}
+	findText(t, p, "3.")
+	findText(t, p, "4.")
+	for _, o := range texts(p) {
+		if o.String() == "• " && near(o.y, findText(t, p, "Plain").y) {
+			t.Error("list-style: none should have no marker")
+		}
+	}
+}
+
+func TestLayoutPagination(t *testing.T) {
+	doc := layoutDoc(t, strings.Repeat("<p>Paragraph</p>", 60)+`<p class="break">Fresh page</p>`, `p { margin: 10pt 0 } .break { page-break-before: always }`)
+	if len(doc.pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(doc.pages))
+	}
+	bottomLimit := doc.height - doc.margins[bottom]
+	for i, p := range doc.pages {
+		for _, o := range texts(p) {
+			if o.y > bottomLimit {
+				t.Errorf("page %d: text at %v below the margin %v", i+1, o.y, bottomLimit)
+			}
+		}
+	}
+
+	// The margin before the first paragraph on a new page is dropped
+	first := texts(doc.pages[1])[0]
+	if first.y > doc.margins[top]+10*normalLineHeight {
+		t.Errorf("first baseline on page 2 at %v, margin not truncated", first.y)
+	}
+
+	fresh := texts(doc.pages[2])
+	if len(fresh) != 1 || fresh[0].String() != "Fresh page" {
+		t.Errorf("page 3 = %v, want only the forced break paragraph", fresh)
+	}
+}
+
+func TestLayoutAvoidBreakInside(t *testing.T) {
+	doc := layoutDoc(t, strings.Repeat("<p>Filler</p>", 45)+`<div class="keep"><p>Title</p><p>Details</p></div>`, `p { margin: 0; line-height: 17pt } .keep { page-break-inside: avoid }`)
+	if len(doc.pages) != 2 {
+		t.Fatalf("got %d pages, want 2", len(doc.pages))
+	}
+	got := texts(doc.pages[1])
+	if len(got) != 2 || got[0].String() != "Title" {
+		t.Errorf("page 2 = %v, the kept block should move together", got)
+	}
+}
+
+func TestLayoutInlineStyles(t *testing.T) {
+	doc := layoutDoc(t, `<p>Plain <b>bold</b> <a href="https://example.com">link</a> <span class="up">caps</span><br>next</p>`,
+		`.up { text-transform: uppercase; letter-spacing: 1pt }`)
+	p := doc.pages[0]
+	plain, bold := findText(t, p, "Plain"), findText(t, p, "bold")
+	if plain.face == bold.face || !bold.face.key.bold {
+		t.Error("bold text should use the bold face")
+	}
+	if caps := findText(t, p, "CAPS"); caps.spacing != 1 {
+		t.Errorf("letter spacing = %v", caps.spacing)
+	}
+	if next := findText(t, p, "next"); next.y <= plain.y || !near(next.x, doc.margins[left]) {
+		t.Errorf("<br> should start a new line, got %v,%v", next.x, next.y)
+	}
+
+	var links []linkOp
+	for _, o := range p.ops {
+		if l, ok := o.(linkOp); ok {
+			links = append(links, l)
+		}
+	}
+	link := findText(t, p, "link")
+	if len(links) != 1 || links[0].uri != "https://example.com" || !near(links[0].x, link.x) {
+		t.Errorf("links = %+v", links)
+	}
+}
@@ -0,0 +1,189 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PageSize is a paper size in points
+type PageSize struct {
+	Name   string
+	Width  float64
+	Height float64
+}
+
+var (
+	A4     = PageSize{Name: "A4", Width: 595.28, Height: 841.89}
+	Letter = PageSize{Name: "Letter", Width: 612, Height: 792}
+)
+
+// PageSizeByName returns the page size for "a4" or "letter"
+func PageSizeByName(name string) (PageSize, error) {
+	switch strings.ToLower(name) {
+	case "a4":
+		return A4, nil
+	case "letter":
+		return Letter, nil
+	}
+	return PageSize{}, fmt.Errorf("unknown page size %q (use a4 or letter)", name)
+}
+
+// Options controls PDF output
+type Options struct {
+	// PageSize overrides the size from the document's @page rule. Without
+	// either, pages are A4.
+	PageSize *PageSize
+	// CreationDate is recorded in the document info when set. Leaving it
+	// unset makes the output depend only on the HTML.
+	CreationDate time.Time
+}
+
+// FromHTML renders an HTML document with embedded CSS to PDF, the way a
+// browser prints it. It supports the subset of HTML and CSS used by resume
+// and cover letter layouts: block and inline text, flexbox rows, lists,
+// backgrounds, borders, print media rules, @page size and margins, and page
+// breaks. Text is set in the embedded Go fonts and stays selectable.
+func FromHTML(html []byte, opts Options) ([]byte, error) {
+	doc, err := render(html, opts)
+	if err != nil {
+		return nil, err
+	}
+	return doc.write()
+}
+
+// render lays out an HTML document onto pages
+func render(html []byte, opts Options) (*document, error) {
+	root, styleBlocks, err := parseHTML(string(html))
+	if err != nil {
+		return nil, err
+	}
+
+	ua := &stylesheet{}
+	parseStylesheet(uaStylesheet, ua)
+	author := &stylesheet{}
+	for _, css := range styleBlocks {
+		parseStylesheet(css, author)
+	}
+
+	size, margins := pageSetup(author.page)
+	if opts.PageSize != nil {
+		size = *opts.PageSize
+	}
+
+	styles := make(map[*node]*style)
+	var compute func(n *node, parent *style)
+	compute = func(n *node, parent *style) {
+		s := parent
+		if !n.isText() {
+			s = computeStyle(n, parent, ua, author)
+			styles[n] = s
+		}
+		for _, c := range n.children {
+			compute(c, s)
+		}
+	}
+	rootStyle := rootStyle()
+	styles[root] = rootStyle
+	for _, c := range root.children {
+		compute(c, rootStyle)
+	}
+
+	// The body's margins and padding become page margins, so they repeat on
+	// every page instead of only framing the first and last
+	var canvas *rgb
+	for _, tag := range []string{"html", "body"} {
+		n := root.find(tag)
+		if n == nil {
+			continue
+		}
+		s := styles[n]
+		for side := range margins {
+			margins[side] += s.margin[side] + s.padding[side]
+		}
+		s.margin, s.padding = [4]float64{}, [4]float64{}
+		if s.background != nil {
+			canvas = s.background
+			s.background = nil
+		}
+	}
+
+	fonts := newFontSet()
+	l := newLayouter(fonts, styles, margins[top], size.Height-margins[bottom], true)
+	l.contents(root, rootStyle, margins[left], size.Width-margins[left]-margins[right])
+	if fonts.err != nil {
+		return nil, fonts.err
+	}
+
+	if canvas != nil && *canvas != (rgb{1, 1, 1}) {
+		for _, p := range l.pages {
+			p.ops = append([]op{rectOp{0, 0, size.Width, size.Height, *canvas}}, p.ops...)
+		}
+	}
+
+	title := ""
+	if n := root.find("title"); n != nil {
+		title = strings.Join(strings.Fields(n.textContent()), " ")
+	}
+
+	return &document{
+		pages:        l.pages,
+		fonts:        fonts,
+		width:        size.Width,
+		height:       size.Height,
+		margins:      margins,
+		title:        title,
+		creationDate: opts.CreationDate,
+	}, nil
+}
+
+// pageSetup reads the page size and margins from @page declarations
+func pageSetup(decls []declaration) (PageSize, [4]float64) {
+	size := A4
+	m := defaultMarginMM * pointsPerMM
+	margins := [4]float64{m, m, m, m}
+
+	for _, d := range decls {
+		v := strings.ToLower(d.value)
+		switch d.property {
+		case "size":
+			size = parsePageSize(v, size)
+		case "margin":
+			applyBox(&margins, v, rootFontSize)
+		case "margin-top", "margin-right", "margin-bottom", "margin-left":
+			setSide(&margins, d.property, "margin-", v, rootFontSize)
+		}
+	}
+	return size, margins
+}
+
+// parsePageSize handles "A4", "letter landscape" and "210mm 297mm"
+func parsePageSize(v string, current PageSize) PageSize {
+	parts := strings.Fields(v)
+	size := current
+	var lengths []float64
+	landscape := false
+	for _, part := range parts {
+		switch part {
+		case "landscape":
+			landscape = true
+		case "portrait", "auto":
+		default:
+			if named, err := PageSizeByName(part); err == nil {
+				size = named
+			} else if l, ok := parseLength(part, rootFontSize); ok && l > 0 {
+				lengths = append(lengths, l)
+			}
+		}
+	}
+	switch len(lengths) {
+	case 1:
+		size = PageSize{Name: "Custom", Width: lengths[0], Height: lengths[0]}
+	case 2:
+		size = PageSize{Name: "Custom", Width: lengths[0], Height: lengths[1]}
+	}
+	if landscape && size.Width < size.Height {
+		size.Width, size.Height = size.Height, size.Width
+	}
+	return size
+}
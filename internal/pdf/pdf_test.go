@@ -0,0 +1,147 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Jane Doe - Resume</title>
+    <style>
+        * { margin: 0; padding: 0; box-sizing: border-box; }
+        body { font-family: 'Helvetica Neue', Arial, sans-serif; font-size: 11pt; line-height: 1.4; color: #333; }
+        @media print {
+            body { padding: 15mm; }
+            @page { size: A4; margin: 0; }
+        }
+        @media screen {
+            body { max-width: 210mm; margin: 20px auto; }
+        }
+        .name { font-size: 24pt; font-weight: 700; }
+        .skills-list { display: flex; flex-wrap: wrap; gap: 8px; }
+        .skill-tag { background: #edf2f7; padding: 4px 10px; }
+    </style>
+</head>
+<body>
+    <div class="name">Jane Doe</div>
+    <div class="contact-info"><a href="mailto:jane@example.com">jane@example.com</a></div>
+    <div class="skills-list"><span class="skill-tag">Go</span><span class="skill-tag">Kubernetes</span></div>
+</body>
+</html>`
+
+func TestFromHTML(t *testing.T) {
+	out, err := FromHTML([]byte(sampleHTML), Options{})
+	if err != nil {
+		t.Fatalf("FromHTML failed: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Error("output is not framed as a PDF file")
+	}
+	for _, want := range []string{
+		"/Type /Pages /Kids [",
+		"/Count 1",
+		"/MediaBox [0 0 595.28 841.89]",
+		"/Title (Jane Doe - Resume)",
+		"/Producer (Bragger)",
+		"/Subtype /Type0",
+		"/Encoding /Identity-H",
+		"/ToUnicode",
+		"/URI (mailto:jane@example.com)",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if bytes.Contains(out, []byte("/CreationDate")) {
+		t.Error("no creation date should be written unless requested")
+	}
+
+	again, _ := FromHTML([]byte(sampleHTML), Options{})
+	if !bytes.Equal(out, again) {
+		t.Error("output should be deterministic")
+	}
+
+	dated, _ := FromHTML([]byte(sampleHTML), Options{CreationDate: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)})
+	if !bytes.Contains(dated, []byte("/CreationDate (D:20250301123000Z)")) {
+		t.Error("creation date not written")
+	}
+}
+
+func TestFromHTMLPageSetup(t *testing.T) {
+	doc, err := render([]byte(sampleHTML), Options{})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	// @page margin 0 plus the print body padding of 15mm
+	if !near(doc.margins[top], 15*pointsPerMM) || !near(doc.margins[left], 15*pointsPerMM) {
+		t.Errorf("margins = %v, want 15mm", doc.margins)
+	}
+	name := findText(t, doc.pages[0], "Jane Doe")
+	if !near(name.x, 15*pointsPerMM) {
+		t.Errorf("name at x=%v", name.x)
+	}
+
+	letter := Letter
+	doc, _ = render([]byte(sampleHTML), Options{PageSize: &letter})
+	if doc.width != 612 || doc.height != 792 {
+		t.Errorf("page size override = %vx%v", doc.width, doc.height)
+	}
+
+	tests := []struct {
+		css           string
+		width, height float64
+	}{
+		{"@page { size: letter }", 612, 792},
+		{"@page { size: A4 landscape }", 841.89, 595.28},
+		{"@page { size: 100mm 50mm }", 100 * pointsPerMM, 50 * pointsPerMM},
+		{"", 595.28, 841.89},
+	}
+	for _, tt := range tests {
+		doc, err := render([]byte("<style>"+tt.css+"</style><p>x</p>"), Options{})
+		if err != nil {
+			t.Fatalf("render failed: %v", err)
+		}
+		if !near(doc.width, tt.width) || !near(doc.height, tt.height) {
+			t.Errorf("%q: page %vx%v, want %vx%v", tt.css, doc.width, doc.height, tt.width, tt.height)
+		}
+	}
+
+	// Without @page margins the default is 15mm, plus the body's 8px
+	doc, _ = render([]byte("<html><body><p>x</p></body></html>"), Options{})
+	if want := 15*pointsPerMM + 6; !near(doc.margins[left], want) {
+		t.Errorf("default margin = %v, want %v", doc.margins[left], want)
+	}
+}
+
+func TestFromHTMLBodyBackground(t *testing.T) {
+	doc, err := render([]byte(`<body style="background: #000; padding: 0">`+strings.Repeat("<p>x</p>", 80)+`</body>`), Options{})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if len(doc.pages) < 2 {
+		t.Fatalf("got %d pages, want several", len(doc.pages))
+	}
+	for i, p := range doc.pages {
+		bg, ok := p.ops[0].(rectOp)
+		if !ok || bg.w != doc.width || bg.h != doc.height || bg.color != (rgb{}) {
+			t.Errorf("page %d: first op %+v, want a full-page background", i+1, p.ops[0])
+		}
+	}
+}
+
+func TestPageSizeByName(t *testing.T) {
+	if got, err := PageSizeByName("A4"); err != nil || got != A4 {
+		t.Errorf("PageSizeByName(A4) = %v, %v", got, err)
+	}
+	if got, err := PageSizeByName("letter"); err != nil || got != Letter {
+		t.Errorf("PageSizeByName(letter) = %v, %v", got, err)
+	}
+	if _, err := PageSizeByName("legal"); err == nil {
+		t.Error("expected error for unknown size")
+	}
+}
@@ -0,0 +1,655 @@
+package pdf
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Box sides, in CSS order
+const (
+	top = iota
+	right
+	bottom
+	left
+)
+
+const (
+	rootFontSize     = 12.0 // pt; the 16px browser default
+	normalLineHeight = 1.2
+	pointsPerPixel   = 0.75
+	pointsPerMM      = 72 / 25.4
+	defaultMarginMM  = 15.0
+)
+
+type rgb struct {
+	r, g, b float64 // 0..1
+}
+
+var black = rgb{}
+
+// style is the computed style of an element, in points
+type style struct {
+	display       string // block, inline, flex, list-item, table-row, table-cell or none
+	fontSize      float64
+	bold          bool
+	italic        bool
+	mono          bool
+	color         rgb
+	background    *rgb
+	textAlign     string
+	textTransform string
+	lineHeight    float64 // Multiple of the font size, unless lineHeightAbs is set
+	lineHeightAbs float64
+	letterSpacing float64
+	underline     bool
+	pre           bool // white-space: pre or pre-wrap
+	listStyle     string
+	margin        [4]float64
+	padding       [4]float64
+	border        [4]float64
+	borderColor   [4]rgb
+	justify       string // justify-content
+	alignItems    string
+	wrap          bool // flex-wrap: wrap
+	column        bool // flex-direction: column
+	gap           float64
+	rowGap        float64
+	grow          float64
+	breakBefore   bool
+	avoidBreak    bool // break-inside: avoid
+}
+
+// inherit returns the style a child starts from: inherited properties are
+// copied, the others reset to their initial values
+func (s *style) inherit() *style {
+	return &style{
+		display:       "inline",
+		fontSize:      s.fontSize,
+		bold:          s.bold,
+		italic:        s.italic,
+		mono:          s.mono,
+		color:         s.color,
+		textAlign:     s.textAlign,
+		textTransform: s.textTransform,
+		lineHeight:    s.lineHeight,
+		lineHeightAbs: s.lineHeightAbs,
+		letterSpacing: s.letterSpacing,
+		underline:     s.underline,
+		pre:           s.pre,
+		listStyle:     s.listStyle,
+	}
+}
+
+func rootStyle() *style {
+	return &style{display: "block", fontSize: rootFontSize, color: black, textAlign: "left", lineHeight: normalLineHeight, listStyle: "disc"}
+}
+
+// lineHeightPt returns the line height in points
+func (s *style) lineHeightPt() float64 {
+	if s.lineHeightAbs > 0 {
+		return s.lineHeightAbs
+	}
+	return s.lineHeight * s.fontSize
+}
+
+func (s *style) fontKey() fontKey {
+	return fontKey{mono: s.mono, bold: s.bold, italic: s.italic}
+}
+
+// isBlock reports whether the element starts a new block in normal flow
+func (s *style) isBlock() bool {
+	switch s.display {
+	case "block", "flex", "list-item", "table-row", "table-cell":
+		return true
+	}
+	return false
+}
+
+// uaStylesheet approximates the browser defaults that generated resumes
+// rely on
+const uaStylesheet = `
+html, body, div, p, h1, h2, h3, h4, h5, h6, ul, ol, dl, dt, dd, section, article,
+header, footer, main, nav, aside, address, blockquote, pre, hr, table, tbody, thead,
+tfoot, form, fieldset, figure, figcaption, center { display: block }
+li { display: list-item }
+tr { display: table-row }
+td, th { display: table-cell }
+head, style, script, title, meta, link, template, noscript { display: none }
+body { margin: 8px }
+h1 { font-size: 2em; margin: 0.67em 0; font-weight: bold }
+h2 { font-size: 1.5em; margin: 0.83em 0; font-weight: bold }
+h3 { font-size: 1.17em; margin: 1em 0; font-weight: bold }
+h4 { margin: 1.33em 0; font-weight: bold }
+h5 { font-size: 0.83em; margin: 1.67em 0; font-weight: bold }
+h6 { font-size: 0.67em; margin: 2.33em 0; font-weight: bold }
+p, dl, figure, pre { margin: 1em 0 }
+blockquote { margin: 1em 40px }
+ul, ol { margin: 1em 0; padding-left: 40px }
+ul { list-style-type: disc }
+ol { list-style-type: decimal }
+ul ul, ol ul { list-style-type: circle; margin: 0 }
+ol ol, ul ol { margin: 0 }
+dd { margin-left: 40px }
+b, strong, th { font-weight: bold }
+i, em, cite, var, address, dfn { font-style: italic }
+code, pre, kbd, samp, tt { font-family: monospace }
+pre { white-space: pre }
+small { font-size: 0.83em }
+u, ins { text-decoration: underline }
+a { color: #0000ee; text-decoration: underline }
+hr { border-top: 1px solid #808080; margin: 0.5em 0 }
+center, th { text-align: center }
+td, th { padding: 1px }
+`
+
+// matchedDecl is a declaration with the precedence of the rule it came from
+type matchedDecl struct {
+	declaration
+	origin      int // 0 user agent, 1 author, 2 style attribute
+	specificity int
+	order       int
+}
+
+// computeStyle resolves the cascade for an element
+func computeStyle(n *node, parent *style, ua, author *stylesheet) *style {
+	var decls []matchedDecl
+	for origin, sheet := range []*stylesheet{ua, author} {
+		for _, r := range sheet.rules {
+			if !r.selector.matches(n) {
+				continue
+			}
+			for _, d := range r.decls {
+				decls = append(decls, matchedDecl{d, origin, r.specificity, r.order})
+			}
+		}
+	}
+	if inline, ok := n.attrs["style"]; ok {
+		for _, d := range parseDeclarations(inline) {
+			decls = append(decls, matchedDecl{d, 2, 0, 0})
+		}
+	}
+
+	sort.SliceStable(decls, func(i, j int) bool {
+		a, b := decls[i], decls[j]
+		if a.important != b.important {
+			return !a.important
+		}
+		if a.origin != b.origin {
+			return a.origin < b.origin
+		}
+		if a.specificity != b.specificity {
+			return a.specificity < b.specificity
+		}
+		return a.order < b.order
+	})
+
+	s := parent.inherit()
+	// Font size first: em lengths in other properties depend on it
+	for _, d := range decls {
+		switch d.property {
+		case "font-size":
+			if size, ok := parseFontSize(d.value, parent.fontSize); ok {
+				s.fontSize = size
+			}
+		case "font":
+			applyFontShorthand(s, d.value, parent.fontSize, false)
+		}
+	}
+	for _, d := range decls {
+		applyDeclaration(s, d.declaration)
+	}
+	return s
+}
+
+func applyDeclaration(s *style, d declaration) {
+	v := strings.ToLower(d.value)
+	switch d.property {
+	case "display":
+		switch v {
+		case "inline-block", "inline-flex", "inline-grid", "contents":
+			if v == "inline-flex" {
+				s.display = "flex"
+			} else {
+				s.display = "inline"
+			}
+		case "grid", "table", "table-row-group", "table-header-group", "table-footer-group", "flow-root":
+			s.display = "block"
+		default:
+			s.display = v
+		}
+	case "font-weight":
+		s.bold = parseBold(v, s.bold)
+	case "font-style":
+		s.italic = v == "italic" || v == "oblique"
+	case "font-family":
+		s.mono = isMonoFamily(v)
+	case "font":
+		applyFontShorthand(s, v, s.fontSize, true)
+	case "color":
+		if c, ok := parseColor(v); ok && c != nil {
+			s.color = *c
+		}
+	case "background", "background-color":
+		for _, part := range splitValue(v) {
+			if c, ok := parseColor(part); ok {
+				s.background = c
+			}
+		}
+	case "text-align":
+		switch v {
+		case "left", "right", "center", "justify":
+			s.textAlign = v
+		case "start":
+			s.textAlign = "left"
+		case "end":
+			s.textAlign = "right"
+		}
+	case "text-transform":
+		s.textTransform = v
+	case "text-decoration", "text-decoration-line":
+		s.underline = strings.Contains(v, "underline")
+	case "line-height":
+		if v == "normal" {
+			s.lineHeight, s.lineHeightAbs = normalLineHeight, 0
+		} else if f, err := strconv.ParseFloat(v, 64); err == nil {
+			s.lineHeight, s.lineHeightAbs = f, 0
+		} else if strings.HasSuffix(v, "%") {
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64); err == nil {
+				s.lineHeight, s.lineHeightAbs = f/100, 0
+			}
+		} else if l, ok := parseLength(v, s.fontSize); ok {
+			s.lineHeightAbs = l
+		}
+	case "letter-spacing":
+		if v == "normal" {
+			s.letterSpacing = 0
+		} else if l, ok := parseLength(v, s.fontSize); ok {
+			s.letterSpacing = l
+		}
+	case "white-space":
+		s.pre = v == "pre" || v == "pre-wrap" || v == "pre-line" || v == "break-spaces"
+	case "list-style", "list-style-type":
+		for _, part := range splitValue(v) {
+			switch part {
+			case "none", "disc", "circle", "square", "decimal":
+				s.listStyle = part
+			}
+		}
+	case "margin":
+		applyBox(&s.margin, v, s.fontSize)
+	case "margin-top", "margin-right", "margin-bottom", "margin-left":
+		setSide(&s.margin, d.property, "margin-", v, s.fontSize)
+	case "padding":
+		applyBox(&s.padding, v, s.fontSize)
+	case "padding-top", "padding-right", "padding-bottom", "padding-left":
+		setSide(&s.padding, d.property, "padding-", v, s.fontSize)
+	case "border":
+		for side := range s.border {
+			applyBorder(s, side, v)
+		}
+	case "border-top", "border-right", "border-bottom", "border-left":
+		applyBorder(s, sideIndex(strings.TrimPrefix(d.property, "border-")), v)
+	case "border-width":
+		applyBox(&s.border, v, s.fontSize)
+	case "border-top-width", "border-right-width", "border-bottom-width", "border-left-width":
+		side := sideIndex(strings.TrimSuffix(strings.TrimPrefix(d.property, "border-"), "-width"))
+		if w, ok := parseBorderWidth(v, s.fontSize); ok {
+			s.border[side] = w
+		}
+	case "border-color":
+		if c, ok := parseColor(v); ok && c != nil {
+			s.borderColor = [4]rgb{*c, *c, *c, *c}
+		}
+	case "border-top-color", "border-right-color", "border-bottom-color", "border-left-color":
+		side := sideIndex(strings.TrimSuffix(strings.TrimPrefix(d.property, "border-"), "-color"))
+		if c, ok := parseColor(v); ok && c != nil {
+			s.borderColor[side] = *c
+		}
+	case "border-style":
+		if v == "none" || v == "hidden" {
+			s.border = [4]float64{}
+		}
+	case "justify-content":
+		s.justify = v
+	case "align-items":
+		s.alignItems = v
+	case "flex-wrap":
+		s.wrap = v == "wrap" || v == "wrap-reverse"
+	case "flex-direction":
+		s.column = strings.HasPrefix(v, "column")
+	case "flex-flow":
+		for _, part := range splitValue(v) {
+			if strings.HasPrefix(part, "column") {
+				s.column = true
+			}
+			if strings.HasPrefix(part, "wrap") {
+				s.wrap = true
+			}
+		}
+	case "flex", "flex-grow":
+		if v == "auto" {
+			s.grow = 1
+		} else if f, err := strconv.ParseFloat(strings.Fields(v + " ")[0], 64); err == nil {
+			s.grow = f
+		}
+	case "gap":
+		parts := splitValue(v)
+		if l, ok := parseLength(parts[0], s.fontSize); ok {
+			s.rowGap, s.gap = l, l
+		}
+		if len(parts) > 1 {
+			if l, ok := parseLength(parts[1], s.fontSize); ok {
+				s.gap = l
+			}
+		}
+	case "column-gap":
+		if l, ok := parseLength(v, s.fontSize); ok {
+			s.gap = l
+		}
+	case "row-gap":
+		if l, ok := parseLength(v, s.fontSize); ok {
+			s.rowGap = l
+		}
+	case "page-break-before", "break-before":
+		s.breakBefore = v == "always" || v == "page" || v == "left" || v == "right"
+	case "page-break-inside", "break-inside":
+		s.avoidBreak = strings.HasPrefix(v, "avoid")
+	}
+}
+
+func parseBold(v string, current bool) bool {
+	switch v {
+	case "bold", "bolder":
+		return true
+	case "normal", "lighter":
+		return false
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		return n >= 600
+	}
+	return current
+}
+
+func isMonoFamily(v string) bool {
+	for _, mono := range []string{"mono", "courier", "consolas", "menlo", "monaco"} {
+		if strings.Contains(v, mono) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFontShorthand handles "font: italic bold 12px/1.5 Arial, sans-serif".
+// The size is resolved in the font-size pass; other parts only when full is set.
+func applyFontShorthand(s *style, v string, parentSize float64, full bool) {
+	parts := splitValue(strings.ToLower(v))
+	for i, part := range parts {
+		size := part
+		lineHeight := ""
+		if slash := strings.IndexByte(part, '/'); slash > 0 {
+			size, lineHeight = part[:slash], part[slash+1:]
+		}
+		if fs, ok := parseFontSize(size, parentSize); ok {
+			if !full {
+				s.fontSize = fs
+				return
+			}
+			if lineHeight != "" {
+				applyDeclaration(s, declaration{property: "line-height", value: lineHeight})
+			}
+			s.mono = isMonoFamily(strings.Join(parts[i+1:], " "))
+			return
+		}
+		if full {
+			if part == "italic" || part == "oblique" {
+				s.italic = true
+			}
+			s.bold = parseBold(part, s.bold)
+		}
+	}
+}
+
+func parseFontSize(v string, parentSize float64) (float64, bool) {
+	switch v {
+	case "xx-small":
+		return 7, true
+	case "x-small":
+		return 7.5, true
+	case "small":
+		return 10, true
+	case "medium":
+		return 12, true
+	case "large":
+		return 13.5, true
+	case "x-large":
+		return 18, true
+	case "xx-large":
+		return 24, true
+	case "smaller":
+		return parentSize * 0.83, true
+	case "larger":
+		return parentSize * 1.2, true
+	}
+	if strings.HasSuffix(v, "%") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		return parentSize * f / 100, err == nil
+	}
+	return parseLength(v, parentSize)
+}
+
+// parseLength converts a CSS length to points; em is relative to fontSize
+func parseLength(v string, fontSize float64) (float64, bool) {
+	v = strings.TrimSpace(v)
+	if v == "0" || v == "auto" {
+		return 0, true
+	}
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"rem", rootFontSize},
+		{"em", fontSize},
+		{"px", pointsPerPixel},
+		{"pt", 1},
+		{"pc", 12},
+		{"mm", pointsPerMM},
+		{"cm", 10 * pointsPerMM},
+		{"in", 72},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(v, u.suffix) {
+			f, err := strconv.ParseFloat(strings.TrimSuffix(v, u.suffix), 64)
+			if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+				return 0, false
+			}
+			return f * u.scale, true
+		}
+	}
+	return 0, false
+}
+
+func applyBox(box *[4]float64, v string, fontSize float64) {
+	var vals []float64
+	for _, part := range splitValue(v) {
+		l, ok := parseLength(part, fontSize)
+		if !ok {
+			return
+		}
+		vals = append(vals, l)
+	}
+	switch len(vals) {
+	case 1:
+		*box = [4]float64{vals[0], vals[0], vals[0], vals[0]}
+	case 2:
+		*box = [4]float64{vals[0], vals[1], vals[0], vals[1]}
+	case 3:
+		*box = [4]float64{vals[0], vals[1], vals[2], vals[1]}
+	case 4:
+		*box = [4]float64{vals[0], vals[1], vals[2], vals[3]}
+	}
+}
+
+func setSide(box *[4]float64, property, prefix, v string, fontSize float64) {
+	if l, ok := parseLength(v, fontSize); ok {
+		box[sideIndex(strings.TrimPrefix(property, prefix))] = l
+	}
+}
+
+func sideIndex(side string) int {
+	switch side {
+	case "right":
+		return right
+	case "bottom":
+		return bottom
+	case "left":
+		return left
+	}
+	return top
+}
+
+// applyBorder handles "1px solid #ccc" for one side
+func applyBorder(s *style, side int, v string) {
+	width := 3.0 * pointsPerPixel // medium
+	color := s.color
+	for _, part := range splitValue(v) {
+		switch part {
+		case "none", "hidden":
+			s.border[side] = 0
+			return
+		case "solid", "dashed", "dotted", "double", "groove", "ridge", "inset", "outset":
+			continue
+		}
+		if w, ok := parseBorderWidth(part, s.fontSize); ok {
+			width = w
+		} else if c, ok := parseColor(part); ok && c != nil {
+			color = *c
+		}
+	}
+	s.border[side] = width
+	s.borderColor[side] = color
+}
+
+func parseBorderWidth(v string, fontSize float64) (float64, bool) {
+	switch v {
+	case "thin":
+		return 1 * pointsPerPixel, true
+	case "medium":
+		return 3 * pointsPerPixel, true
+	case "thick":
+		return 5 * pointsPerPixel, true
+	}
+	return parseLength(v, fontSize)
+}
+
+// splitValue splits a CSS value on whitespace, keeping function arguments
+// such as rgb(1, 2, 3) together
+func splitValue(v string) []string {
+	var parts []string
+	depth := 0
+	start := -1
+	for i, r := range v {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case (r == ' ' || r == '\t' || r == '\n') && depth == 0:
+			if start >= 0 {
+				parts = append(parts, v[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, v[start:])
+	}
+	if len(parts) == 0 {
+		parts = []string{""}
+	}
+	return parts
+}
+
+var namedColors = map[string]rgb{
+	"black":  {0, 0, 0},
+	"white":  {1, 1, 1},
+	"red":    {1, 0, 0},
+	"green":  {0, 0.5, 0},
+	"blue":   {0, 0, 1},
+	"navy":   {0, 0, 0.5},
+	"gray":   {0.5, 0.5, 0.5},
+	"grey":   {0.5, 0.5, 0.5},
+	"silver": {0.75, 0.75, 0.75},
+	"maroon": {0.5, 0, 0},
+	"purple": {0.5, 0, 0.5},
+	"teal":   {0, 0.5, 0.5},
+	"olive":  {0.5, 0.5, 0},
+	"orange": {1, 0.65, 0},
+}
+
+// parseColor parses hex, rgb()/rgba() and basic named colors. Transparent
+// colors parse to nil; translucent ones are blended onto white paper.
+func parseColor(v string) (*rgb, bool) {
+	v = strings.TrimSpace(strings.ToLower(v))
+	if v == "transparent" || v == "none" {
+		return nil, true
+	}
+	if c, ok := namedColors[v]; ok {
+		return &c, true
+	}
+
+	if strings.HasPrefix(v, "#") {
+		hex := v[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 8 {
+			hex = hex[:6]
+		}
+		if len(hex) != 6 {
+			return nil, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, false
+		}
+		return &rgb{float64(n>>16&0xff) / 255, float64(n>>8&0xff) / 255, float64(n&0xff) / 255}, true
+	}
+
+	if strings.HasPrefix(v, "rgb") && strings.HasSuffix(v, ")") {
+		open := strings.IndexByte(v, '(')
+		args := strings.FieldsFunc(v[open+1:len(v)-1], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(args) < 3 {
+			return nil, false
+		}
+		var ch [4]float64
+		ch[3] = 1
+		for i := 0; i < len(args) && i < 4; i++ {
+			a := args[i]
+			pct := strings.HasSuffix(a, "%")
+			f, err := strconv.ParseFloat(strings.TrimSuffix(a, "%"), 64)
+			if err != nil {
+				return nil, false
+			}
+			switch {
+			case pct:
+				f /= 100
+			case i < 3:
+				f /= 255
+			}
+			ch[i] = math.Max(0, math.Min(1, f))
+		}
+		if ch[3] == 0 {
+			return nil, true
+		}
+		blend := func(c float64) float64 { return c*ch[3] + (1 - ch[3]) }
+		return &rgb{blend(ch[0]), blend(ch[1]), blend(ch[2])}, true
+	}
+
+	return nil, false
+}
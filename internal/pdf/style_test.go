@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"10pt", 10, true},
+		{"16px", 12, true},
+		{"25.4mm", 72, true},
+		{"1in", 72, true},
+		{"2.54cm", 72, true},
+		{"1pc", 12, true},
+		{"1.5em", 15, true},
+		{"2rem", 24, true},
+		{"auto", 0, true},
+		{"10", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLength(tt.in, 10)
+		if ok != tt.ok || !near(got, tt.want) {
+			t.Errorf("parseLength(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *rgb
+		ok   bool
+	}{
+		{"#fff", &rgb{1, 1, 1}, true},
+		{"#2C5282", &rgb{0x2c / 255.0, 0x52 / 255.0, 0x82 / 255.0}, true},
+		{"#ff000080", &rgb{1, 0, 0}, true},
+		{"rgb(255, 0, 0)", &rgb{1, 0, 0}, true},
+		{"rgb(100% 0% 0%)", &rgb{1, 0, 0}, true},
+		{"rgba(0,0,0,0.5)", &rgb{0.5, 0.5, 0.5}, true},
+		{"rgba(0,0,0,0)", nil, true},
+		{"transparent", nil, true},
+		{"White", &rgb{1, 1, 1}, true},
+		{"#12", nil, false},
+		{"url(x.png)", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseColor(tt.in)
+		if ok != tt.ok || (got == nil) != (tt.want == nil) {
+			t.Errorf("parseColor(%q) = %v, %v", tt.in, got, ok)
+			continue
+		}
+		if got != nil && !(near(got.r, tt.want.r) && near(got.g, tt.want.g) && near(got.b, tt.want.b)) {
+			t.Errorf("parseColor(%q) = %v, want %v", tt.in, *got, *tt.want)
+		}
+	}
+}
+
+func TestComputeStyle(t *testing.T) {
+	root, _, err := parseHTML(`<div class="section" id="main"><p class="summary" style="color: #00f">Text <b>bold</b> <span class="tag">x</span></p><ul><li>a</li></ul></div>`)
+	if err != nil {
+		t.Fatalf("parseHTML failed: %v", err)
+	}
+	ua := &stylesheet{}
+	parseStylesheet(uaStylesheet, ua)
+	author := &stylesheet{}
+	parseStylesheet(`
+div { font-size: 10pt; line-height: 1.5; letter-spacing: 1px; text-transform: uppercase }
+#main { font-size: 20pt }
+.section { font-size: 12pt; margin: 1em 2em; border-bottom: 2px solid #2c5282; padding: 4px 8px 0 }
+p { color: red !important; font: italic 600 0.5em/2 'Courier New', monospace }
+.tag { display: inline-block; background: #edf2f7; text-transform: none }
+`, author)
+	get := func(n *node, parent *style) *style { return computeStyle(n, parent, ua, author) }
+	div := root.find("div")
+	ds := get(div, rootStyle())
+	if ds.fontSize != 20 || ds.display != "block" {
+		t.Errorf("div font size %v, display %q: id selectors beat classes", ds.fontSize, ds.display)
+	}
+	if ds.margin != [4]float64{20, 40, 20, 40} {
+		t.Errorf("div margin = %v, em is relative to the element's own font size", ds.margin)
+	}
+	if !near(ds.padding[top], 3) || !near(ds.padding[right], 6) || ds.padding[bottom] != 0 || !near(ds.padding[left], 6) {
+		t.Errorf("div padding = %v", ds.padding)
+	}
+	if !near(ds.border[bottom], 1.5) || ds.border[top] != 0 || ds.borderColor[bottom].b < 0.5 {
+		t.Errorf("div border = %v %v", ds.border, ds.borderColor)
+	}
+
+	p := root.find("p")
+	ps := get(p, ds)
+	if ps.color != (rgb{1, 0, 0}) {
+		t.Errorf("p color = %v: !important beats the style attribute", ps.color)
+	}
+	if ps.fontSize != 10 || !ps.italic || !ps.bold || !ps.mono || ps.lineHeightPt() != 20 {
+		t.Errorf("font shorthand: size %v italic %v bold %v mono %v line height %v", ps.fontSize, ps.italic, ps.bold, ps.mono, ps.lineHeightPt())
+	}
+	if ps.textTransform != "uppercase" || !near(ps.letterSpacing, 0.75) || ps.border != [4]float64{} {
+		t.Errorf("inheritance: transform %q, spacing %v, border %v", ps.textTransform, ps.letterSpacing, ps.border)
+	}
+
+	tag := get(root.find("span"), ps)
+	if tag.display != "inline" || tag.background == nil || tag.textTransform != "none" {
+		t.Errorf("span: display %q, background %v, transform %q", tag.display, tag.background, tag.textTransform)
+	}
+
+	ul := get(root.find("ul"), ds)
+	li := get(root.find("li"), ul)
+	if li.display != "list-item" || li.listStyle != "disc" || ul.padding[left] != 30 {
+		t.Errorf("ua list styles: %q %q %v", li.display, li.listStyle, ul.padding)
+	}
+}
+
+func TestSplitValue(t *testing.T) {
+	got := splitValue("1px  solid rgb(1, 2, 3)")
+	if len(got) != 3 || got[2] != "rgb(1, 2, 3)" {
+		t.Errorf("splitValue = %q", got)
+	}
+}
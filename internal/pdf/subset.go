@@ -0,0 +1,238 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// subsetTables are the TrueType tables kept in an embedded font. Layout
+// tables (GSUB, GPOS, kern, ...) are dropped: the PDF positions every glyph.
+// The post table is kept without glyph names.
+var subsetTables = map[string]bool{
+	"cmap": true, "cvt ": true, "fpgm": true, "glyf": true, "head": true, "hhea": true,
+	"hmtx": true, "loca": true, "maxp": true, "name": true, "OS/2": true, "post": true, "prep": true,
+}
+
+// subsetTrueType returns a copy of a TrueType font in which every glyph not
+// in keep (or needed by a composite glyph in keep) has empty outlines. Glyph
+// IDs are unchanged, so the PDF can map CIDs to glyphs with /Identity.
+func subsetTrueType(data []byte, keep map[uint16]bool) ([]byte, error) {
+	tables, err := readTables(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "maxp", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("font has no %s table", tag)
+		}
+	}
+
+	head, maxp, loca, glyf := tables["head"], tables["maxp"], tables["loca"], tables["glyf"]
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, fmt.Errorf("font tables are truncated")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if longLoca {
+			if len(loca) < 4*(i+1) {
+				return nil, fmt.Errorf("loca table is truncated")
+			}
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			if len(loca) < 2*(i+1) {
+				return nil, fmt.Errorf("loca table is truncated")
+			}
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+	glyphData := func(gid int) []byte {
+		if gid >= numGlyphs || offsets[gid] >= offsets[gid+1] || offsets[gid+1] > len(glyf) {
+			return nil
+		}
+		return glyf[offsets[gid]:offsets[gid+1]]
+	}
+
+	// Glyph 0 (.notdef) is always kept; composite glyphs pull in their parts
+	used := make(map[int]bool)
+	queue := []int{0}
+	for gid := range keep {
+		queue = append(queue, int(gid))
+	}
+	for len(queue) > 0 {
+		gid := queue[0]
+		queue = queue[1:]
+		if used[gid] || gid >= numGlyphs {
+			continue
+		}
+		used[gid] = true
+		queue = append(queue, compositeComponents(glyphData(gid))...)
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(newLoca[4*gid:], uint32(len(newGlyf)))
+		if used[gid] {
+			newGlyf = append(newGlyf, glyphData(gid)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint16(newHead[50:], 1) // indexToLocFormat: long offsets
+	binary.BigEndian.PutUint32(newHead[8:], 0)  // checkSumAdjustment, set below
+
+	out := make(map[string][]byte)
+	for tag, table := range tables {
+		if subsetTables[tag] {
+			out[tag] = table
+		}
+	}
+	out["head"] = newHead
+	if post, ok := out["post"]; ok && len(post) >= 32 {
+		newPost := append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(newPost, 0x00030000) // Version 3: no glyph names
+		out["post"] = newPost
+	}
+	out["loca"] = newLoca
+	out["glyf"] = newGlyf
+
+	font := writeTables(out)
+	// head.checkSumAdjustment makes the whole font sum to 0xB1B0AFBA
+	headOffset := tableOffset(font, "head")
+	binary.BigEndian.PutUint32(font[headOffset+8:], 0xB1B0AFBA-checksum(font))
+	return font, nil
+}
+
+// compositeComponents returns the glyphs a composite glyph is built from
+func compositeComponents(g []byte) []int {
+	if len(g) < 10 || int16(binary.BigEndian.Uint16(g)) >= 0 {
+		return nil
+	}
+
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+
+	var components []int
+	p := 10
+	for p+4 <= len(g) {
+		flags := binary.BigEndian.Uint16(g[p:])
+		components = append(components, int(binary.BigEndian.Uint16(g[p+2:])))
+		p += 4
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// readTables splits a TrueType font into its tables
+func readTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("font is truncated")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, fmt.Errorf("font table directory is truncated")
+	}
+
+	tables := make(map[string][]byte)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		tag := string(rec[:4])
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("font table %q is out of bounds", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// writeTables assembles a TrueType font from its tables, sorted by tag and
+// aligned to 4 bytes
+func writeTables(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+	searchRange *= 16
+
+	out := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(out[0:], 0x00010000)
+	binary.BigEndian.PutUint16(out[4:], uint16(n))
+	binary.BigEndian.PutUint16(out[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(n*16-searchRange))
+
+	for i, tag := range tags {
+		table := tables[tag]
+		rec := out[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(table))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(table)))
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+func tableOffset(font []byte, tag string) int {
+	n := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < n; i++ {
+		rec := font[12+16*i:]
+		if string(rec[:4]) == tag {
+			return int(binary.BigEndian.Uint32(rec[8:]))
+		}
+	}
+	return -1
+}
+
+// checksum is the TrueType table checksum: the sum of big-endian uint32s,
+// with the data zero-padded to a multiple of 4 bytes
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestSubsetTrueType(t *testing.T) {
+	orig, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatalf("parsing font: %v", err)
+	}
+	var buf sfnt.Buffer
+	gidA, _ := orig.GlyphIndex(&buf, 'A')
+	gidB, _ := orig.GlyphIndex(&buf, 'B')
+	// é is a composite of e and the acute accent in the Go fonts
+	gidE, _ := orig.GlyphIndex(&buf, 'é')
+
+	data, err := subsetTrueType(goregular.TTF, map[uint16]bool{uint16(gidA): true, uint16(gidE): true})
+	if err != nil {
+		t.Fatalf("subsetTrueType failed: %v", err)
+	}
+	if len(data) >= len(goregular.TTF) {
+		t.Errorf("subset is %d bytes, original %d", len(data), len(goregular.TTF))
+	}
+	if sum := checksum(data); sum != 0xB1B0AFBA {
+		t.Errorf("font checksum = %#x, want 0xB1B0AFBA", sum)
+	}
+
+	sub, err := sfnt.Parse(data)
+	if err != nil {
+		t.Fatalf("parsing subset: %v", err)
+	}
+	if sub.NumGlyphs() != orig.NumGlyphs() {
+		t.Errorf("glyph count changed: %d != %d", sub.NumGlyphs(), orig.NumGlyphs())
+	}
+
+	ppem := fixed.I(1000)
+	outline := func(gid sfnt.GlyphIndex) int {
+		segs, err := sub.LoadGlyph(&buf, gid, ppem, nil)
+		if err != nil {
+			t.Fatalf("loading glyph %d: %v", gid, err)
+		}
+		return len(segs)
+	}
+	if outline(gidA) == 0 {
+		t.Error("kept glyph A has no outline")
+	}
+	if outline(gidE) == 0 {
+		t.Error("kept composite glyph é has no outline")
+	}
+	if outline(gidB) != 0 {
+		t.Error("dropped glyph B still has an outline")
+	}
+
+	// Advance widths are kept for every glyph
+	want, _ := orig.GlyphAdvance(&buf, gidB, ppem, font.HintingNone)
+	got, _ := sub.GlyphAdvance(&buf, gidB, ppem, font.HintingNone)
+	if got != want {
+		t.Errorf("advance of B = %v, want %v", got, want)
+	}
+}
+
+func TestSubsetTrueTypeErrors(t *testing.T) {
+	if _, err := subsetTrueType([]byte("nope"), nil); err == nil {
+		t.Error("expected error for truncated font")
+	}
+
+	tables, _ := readTables(goregular.TTF)
+	delete(tables, "glyf")
+	if _, err := subsetTrueType(writeTables(tables), nil); err == nil {
+		t.Error("expected error for font without glyf table")
+	}
+}
@@ -0,0 +1,306 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// document is laid out content ready to be written
+type document struct {
+	pages        []*page
+	fonts        *fontSet
+	width        float64
+	height       float64
+	margins      [4]float64
+	title        string
+	creationDate time.Time
+}
+
+// writer assembles PDF objects and the cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int // Byte offset of each object, by object number - 1
+}
+
+// alloc reserves an object number, so objects can refer to each other
+// before they are written
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(id int, body string) {
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a Flate-compressed stream object; dict holds any entries
+// besides /Length and /Filter
+func (w *writer) stream(id int, dict string, data []byte) {
+	var z bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&z, zlib.BestCompression)
+	zw.Write(data)
+	zw.Close()
+
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode%s >>\nstream\n", id, z.Len(), dict)
+	w.buf.Write(z.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// write serializes a document. Only fonts with glyphs on the page are
+// embedded, each subset to those glyphs.
+func (d *document) write() ([]byte, error) {
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	catalog, pagesID := w.alloc(), w.alloc()
+
+	var used []*fontFace
+	for _, f := range d.fonts.order {
+		if len(f.runes) > 0 {
+			used = append(used, f)
+		}
+	}
+	fontNames := make(map[*fontFace]string)
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	for i, f := range used {
+		id, err := w.font(f)
+		if err != nil {
+			return nil, err
+		}
+		fontNames[f] = fmt.Sprintf("F%d", i+1)
+		fmt.Fprintf(&resources, " /%s %d 0 R", fontNames[f], id)
+	}
+	resources.WriteString(" >> >>")
+
+	var kids []string
+	for _, p := range d.pages {
+		pageID, contentID := w.alloc(), w.alloc()
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		w.stream(contentID, "", d.content(p, fontNames))
+
+		var annots []string
+		for _, o := range p.ops {
+			if link, ok := o.(linkOp); ok {
+				id := w.alloc()
+				x0, y0 := link.x, d.height-link.y-link.h
+				w.object(id, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI %s >> >>",
+					num(x0), num(y0), num(x0+link.w), num(y0+link.h), pdfString(link.uri)))
+				annots = append(annots, fmt.Sprintf("%d 0 R", id))
+			}
+		}
+		annotEntry := ""
+		if len(annots) > 0 {
+			annotEntry = " /Annots [" + strings.Join(annots, " ") + "]"
+		}
+		w.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R%s >>",
+			pagesID, num(d.width), num(d.height), resources.String(), contentID, annotEntry))
+	}
+	w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+
+	info := w.alloc()
+	var infoDict strings.Builder
+	infoDict.WriteString("<< /Producer (Bragger)")
+	if d.title != "" {
+		infoDict.WriteString(" /Title " + pdfString(d.title))
+	}
+	if !d.creationDate.IsZero() {
+		infoDict.WriteString(" /CreationDate (D:" + d.creationDate.UTC().Format("20060102150405") + "Z)")
+	}
+	infoDict.WriteString(" >>")
+	w.object(info, infoDict.String())
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	id := fmt.Sprintf("%x", md5.Sum(w.buf.Bytes()))
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R /ID [<%s> <%s>] >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, catalog, info, id, id, xref)
+
+	return w.buf.Bytes(), nil
+}
+
+// content returns the content stream of a page
+func (d *document) content(p *page, fontNames map[*fontFace]string) []byte {
+	var b bytes.Buffer
+	charSpacing := 0.0
+	for _, o := range p.ops {
+		switch o := o.(type) {
+		case rectOp:
+			fmt.Fprintf(&b, "%s rg\n%s %s %s %s re f\n", color(o.color), num(o.x), num(d.height-o.y-o.h), num(o.w), num(o.h))
+		case *textOp:
+			if len(o.glyphs) == 0 {
+				continue
+			}
+			b.WriteString("BT\n")
+			fmt.Fprintf(&b, "/%s %s Tf\n%s rg\n", fontNames[o.face], num(o.size), color(o.color))
+			if o.spacing != charSpacing {
+				charSpacing = o.spacing
+				fmt.Fprintf(&b, "%s Tc\n", num(charSpacing))
+			}
+			fmt.Fprintf(&b, "1 0 0 1 %s %s Tm\n[<", num(o.x), num(d.height-o.y))
+			for i, g := range o.glyphs {
+				fmt.Fprintf(&b, "%04x", g.gid)
+				if g.adjust != 0 && i < len(o.glyphs)-1 {
+					// TJ adjustments are in thousandths of the font size,
+					// positive values moving left
+					fmt.Fprintf(&b, "> %s <", num(-g.adjust*1000/o.size))
+				}
+			}
+			b.WriteString(">] TJ\nET\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// font writes a face as a Type 0 font with an Identity-H encoding, glyph
+// IDs as character codes, and returns its object number
+func (w *writer) font(f *fontFace) (int, error) {
+	gids := make([]int, 0, len(f.runes))
+	keep := make(map[uint16]bool)
+	for gid := range f.runes {
+		gids = append(gids, int(gid))
+		keep[uint16(gid)] = true
+	}
+	sort.Ints(gids)
+
+	data, err := subsetTrueType(f.ttf, keep)
+	if err != nil {
+		return 0, fmt.Errorf("subsetting font %s: %w", f.name, err)
+	}
+	baseFont := subsetTag(f.name, gids) + "+" + f.name
+
+	fontFile := w.alloc()
+	w.stream(fontFile, fmt.Sprintf(" /Length1 %d", len(data)), data)
+
+	flags := 32 // Nonsymbolic
+	if f.key.mono {
+		flags |= 1
+	}
+	if f.key.italic {
+		flags |= 64
+	}
+	stemV := 80
+	if f.key.bold {
+		stemV = 120
+	}
+	descriptor := w.alloc()
+	w.object(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%d %d %d %d] /ItalicAngle %s /Ascent %d /Descent %d /CapHeight %d /StemV %d /FontFile2 %d 0 R >>",
+		baseFont, flags, f.bbox[0], f.bbox[1], f.bbox[2], f.bbox[3], num(f.italicA), f.ascent, f.descent, f.capH, stemV, fontFile))
+
+	// Widths of runs of consecutive glyph IDs: [first [w1 w2 ...] ...]
+	var widths strings.Builder
+	for i := 0; i < len(gids); {
+		j := i
+		fmt.Fprintf(&widths, "%d [", gids[i])
+		for ; j < len(gids) && gids[j] == gids[i]+(j-i); j++ {
+			if j > i {
+				widths.WriteByte(' ')
+			}
+			fmt.Fprintf(&widths, "%d", f.widths[sfnt.GlyphIndex(gids[j])])
+		}
+		widths.WriteString("] ")
+		i = j
+	}
+	cidFont := w.alloc()
+	w.object(cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, descriptor, strings.TrimSpace(widths.String())))
+
+	toUnicode := w.alloc()
+	w.stream(toUnicode, "", toUnicodeCMap(f, gids))
+
+	font := w.alloc()
+	w.object(font, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseFont, cidFont, toUnicode))
+	return font, nil
+}
+
+// subsetTag derives the six-letter subset prefix from the glyphs used, so
+// the same document always gets the same font names
+func subsetTag(name string, gids []int) string {
+	h := md5.New()
+	h.Write([]byte(name))
+	for _, gid := range gids {
+		fmt.Fprintf(h, ",%d", gid)
+	}
+	sum := h.Sum(nil)
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + sum[i]%26
+	}
+	return string(tag)
+}
+
+// toUnicodeCMap maps glyph IDs back to text, for search and copy
+func toUnicodeCMap(f *fontFace, gids []int) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(gids); start += 100 {
+		end := min(start+100, len(gids))
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&b, "<%04x> <", gid)
+			for _, u := range utf16.Encode([]rune{f.runes[sfnt.GlyphIndex(gid)]}) {
+				fmt.Fprintf(&b, "%04x", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// num formats a coordinate with at most three decimals
+func num(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func color(c rgb) string {
+	return num(c.r) + " " + num(c.g) + " " + num(c.b)
+}
+
+// pdfString encodes text as a PDF string: a literal when it is printable
+// ASCII, UTF-16 otherwise
+func pdfString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+		return "(" + r.Replace(s) + ")"
+	}
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
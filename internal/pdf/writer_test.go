@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNum(t *testing.T) {
+	tests := map[float64]string{
+		0:         "0",
+		10:        "10",
+		1.23456:   "1.235",
+		-0.0001:   "0",
+		-2.5:      "-2.5",
+		595.27559: "595.276",
+	}
+	for in, want := range tests {
+		if got := num(in); got != want {
+			t.Errorf("num(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := map[string]string{
+		"Jane Doe - Resume": "(Jane Doe - Resume)",
+		`a(b)\c`:            `(a\(b\)\\c)`,
+		"Café":              "<FEFF00430061006600E9>",
+		"line\nbreak":       "<FEFF006C0069006E0065000A0062007200650061006B>",
+	}
+	for in, want := range tests {
+		if got := pdfString(in); got != want {
+			t.Errorf("pdfString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSubsetTag(t *testing.T) {
+	a := subsetTag("GoRegular", []int{1, 2, 3})
+	if len(a) != 6 || strings.ToUpper(a) != a {
+		t.Errorf("subsetTag = %q, want six capital letters", a)
+	}
+	if subsetTag("GoRegular", []int{1, 2, 3}) != a {
+		t.Error("subsetTag should be deterministic")
+	}
+	if subsetTag("GoRegular", []int{1, 2, 4}) == a {
+		t.Error("different glyph sets should get different tags")
+	}
+}
+
+func TestContentStream(t *testing.T) {
+	doc := layoutDoc(t, `<p class="p">A <span>B</span></p>`, `.p { background: #ff0000; margin: 0 } span { letter-spacing: 2pt }`)
+	names := map[*fontFace]string{doc.fonts.order[0]: "F1"}
+	content := string(doc.content(doc.pages[0], names))
+
+	for _, want := range []string{"1 0 0 rg\n", " re f\n", "BT\n/F1 10 Tf\n", "2 Tc\n", " Tm\n[<", ">] TJ\nET\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content stream missing %q:\n%s", want, content)
+		}
+	}
+	// Rectangles are flipped into PDF space: y is measured from the bottom
+	rect := doc.pages[0].ops[0].(rectOp)
+	want := fmt.Sprintf("%s %s %s %s re f", num(rect.x), num(doc.height-rect.y-rect.h), num(rect.w), num(rect.h))
+	if !strings.Contains(content, want) {
+		t.Errorf("content stream missing %q", want)
+	}
+}
+
+func TestToUnicodeCMap(t *testing.T) {
+	f, err := loadFace(fontKey{})
+	if err != nil {
+		t.Fatalf("loadFace failed: %v", err)
+	}
+	a, _ := f.glyph('A')
+	e, _ := f.glyph('é')
+	cmap := string(toUnicodeCMap(f, []int{int(a), int(e)}))
+	for _, want := range []string{fmt.Sprintf("<%04x> <0041>", a), fmt.Sprintf("<%04x> <00e9>", e), "2 beginbfchar", "endcmap"} {
+		if !strings.Contains(cmap, want) {
+			t.Errorf("CMap missing %q:\n%s", want, cmap)
+		}
+	}
+}
+
+func TestWriteXref(t *testing.T) {
+	out, err := layoutDoc(t, `<p>Hello <b>world</b></p>`, "").write()
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if m == nil {
+		t.Fatalf("no startxref trailer:\n%s", out[len(out)-200:])
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(out[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for id := 1; id < count; id++ {
+		entry := lines[2+id]
+		if len(entry) != 19 {
+			t.Errorf("xref entry %q is not 20 bytes", entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if !bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj\n", id))) {
+			t.Errorf("xref entry for object %d points at %q", id, out[offset:offset+10])
+		}
+	}
+
+	// Two faces are used, so two fonts are embedded
+	if n := bytes.Count(out, []byte("/FontFile2")); n != 2 {
+		t.Errorf("embedded %d fonts, want 2", n)
+	}
+}
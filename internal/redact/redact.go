@@ -0,0 +1,230 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+// DefaultMapPath is where the real values behind the placeholders are kept,
+// relative to the workspace
+const DefaultMapPath = ".bragger/redactions.json"
+
+// Placeholders for the contact fields. They're the same on every export,
+// so a prompt or template can refer to them.
+const (
+	Email    = "{{EMAIL}}"
+	Phone    = "{{PHONE}}"
+	Location = "{{LOCATION}}"
+	LinkedIn = "{{LINKEDIN}}"
+	GitHub   = "{{GITHUB}}"
+	Website  = "{{WEBSITE}}"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{[A-Z][A-Z0-9_]*\}\}`)
+
+// Map maps placeholders to the values they stand for
+type Map map[string]string
+
+// Contact returns the placeholders for a contact's details. The name is
+// kept, since a resume can't be written without it.
+func Contact(c models.ContactData) Map {
+	m := make(Map)
+	for placeholder, value := range map[string]string{
+		Email:    c.Email,
+		Phone:    c.Phone,
+		Location: c.Location,
+		LinkedIn: c.LinkedIn,
+		GitHub:   c.GitHub,
+		Website:  c.Website,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			m[placeholder] = value
+		}
+	}
+	return m
+}
+
+// Redact replaces every occurrence of the map's values in text with their
+// placeholders. Values are matched whole, so a location like "NY" doesn't
+// match inside "NYSE", and longer values win over ones they contain.
+func (m Map) Redact(text string) string {
+	if len(m) == 0 {
+		return text
+	}
+	byValue := make(map[string]string, len(m))
+	values := make([]string, 0, len(m))
+	for placeholder, value := range m {
+		if _, dup := byValue[value]; !dup {
+			values = append(values, value)
+		}
+		byValue[value] = placeholder
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	patterns := make([]string, len(values))
+	for i, v := range values {
+		patterns[i] = regexp.QuoteMeta(v)
+		if first, _ := utf8.DecodeRuneInString(v); isWord(first) {
+			patterns[i] = `\b` + patterns[i]
+		}
+		if last, _ := utf8.DecodeLastRuneInString(v); isWord(last) {
+			patterns[i] += `\b`
+		}
+	}
+	re := regexp.MustCompile(strings.Join(patterns, "|"))
+	return re.ReplaceAllStringFunc(text, func(match string) string {
+		return byValue[match]
+	})
+}
+
+// RedactEntries returns copies of the entries with the map's values
+// redacted from their content and every string in their data, so formats
+// that quote their strings, like YAML, stay valid
+func (m Map) RedactEntries(entries []*models.KBEntry) []*models.KBEntry {
+	out := make([]*models.KBEntry, len(entries))
+	for i, e := range entries {
+		c := *e
+		c.Content = m.Redact(e.Content)
+		if e.Data != nil {
+			c.Data, c.ExtraData = m.redactData(e)
+		}
+		out[i] = &c
+	}
+	return out
+}
+
+// redactData returns an entry's data with its strings redacted, typed if it
+// still fits the struct for the entry's category
+func (m Map) redactData(e *models.KBEntry) (any, map[string]json.RawMessage) {
+	raw, err := e.DataJSON()
+	if err != nil {
+		return e.Data, e.ExtraData
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return e.Data, e.ExtraData
+	}
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch v := v.(type) {
+		case string:
+			return m.Redact(v)
+		case []any:
+			for i := range v {
+				v[i] = walk(v[i])
+			}
+		case map[string]any:
+			for k := range v {
+				v[k] = walk(v[k])
+			}
+		}
+		return v
+	}
+	data = walk(data)
+	if e.Type == models.KBTypeProfile {
+		if raw, err = json.Marshal(data); err == nil {
+			if typed, extra, err := models.SplitProfileData(models.ProfileCategory(e.Category), raw); err == nil {
+				return typed, extra
+			}
+		}
+	}
+	return data, nil
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Unredact replaces the placeholders in text with their values, passed
+// through escape if it isn't nil, and returns how many it replaced
+func (m Map) Unredact(text string, escape func(string) string) (string, int) {
+	n := 0
+	out := placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		value, ok := m[placeholder]
+		if !ok {
+			return placeholder
+		}
+		n++
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+	return out, n
+}
+
+// Placeholders returns the distinct placeholders in text, in order of
+// first appearance
+func Placeholders(text string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, p := range placeholderPattern.FindAllString(text, -1) {
+		if !seen[p] {
+			seen[p] = true
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// Load reads a map saved by Save, decrypting it with key if it's encrypted
+func Load(path string, key *vault.Key) (Map, error) {
+	if path == "" {
+		path = DefaultMapPath
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if vault.IsSealed(data) {
+		if key == nil {
+			return nil, fmt.Errorf("%s: %w", path, vault.ErrNoKey)
+		}
+		if data, err = key.Open(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Save writes the map, readable only by its user, and encrypted with key
+// if it isn't nil
+func (m Map) Save(path string, key *vault.Key) error {
+	if path == "" {
+		path = DefaultMapPath
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		data = key.Seal(data)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
@@ -0,0 +1,117 @@
+package redact
+
+import (
+	"errors"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+func TestContact(t *testing.T) {
+	m := Contact(models.ContactData{Name: "Jane Doe", Email: "jane@example.com", Phone: " +1 555 0100 ", LinkedIn: "linkedin.com/in/jane"})
+	want := Map{Email: "jane@example.com", Phone: "+1 555 0100", LinkedIn: "linkedin.com/in/jane"}
+	if len(m) != len(want) {
+		t.Fatalf("Contact() = %v, want %v", m, want)
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %q, want %q", k, m[k], v)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	m := Map{
+		Email:    "jane@example.com",
+		Location: "NY",
+		Website:  "https://jane.dev",
+		GitHub:   "https://jane.dev/code",
+	}
+	text := "Email jane@example.com, based in NY (not NYSE). Site: https://jane.dev, code: https://jane.dev/code."
+	want := "Email {{EMAIL}}, based in {{LOCATION}} (not NYSE). Site: {{WEBSITE}}, code: {{GITHUB}}."
+	if got := m.Redact(text); got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+	if got := (Map{}).Redact(text); got != text {
+		t.Errorf("expected an empty map to change nothing, got %q", got)
+	}
+}
+
+func TestRedactEntries(t *testing.T) {
+	m := Map{Email: "jane@example.com", Location: "Berlin"}
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@example.com", Location: "Berlin"}, "user")
+	exp := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Acme", Role: "Dev", StartDate: "2020-01", Location: "Berlin",
+		Highlights: []string{"Moved the Berlin office"},
+	}, "user")
+	note := models.NewContextEntry("preference", "Write to jane@example.com", "user")
+	entries := []*models.KBEntry{contact, exp, note}
+
+	out := m.RedactEntries(entries)
+	if c, ok := out[0].AsContact(); !ok || c.Email != Email || c.Location != Location || c.Name != "Jane" {
+		t.Errorf("unexpected contact %+v", out[0].Data)
+	}
+	if e, ok := out[1].AsExperience(); !ok || e.Location != Location || e.Highlights[0] != "Moved the {{LOCATION}} office" {
+		t.Errorf("unexpected experience %+v", out[1].Data)
+	}
+	if out[2].Content != "Write to {{EMAIL}}" {
+		t.Errorf("unexpected content %q", out[2].Content)
+	}
+	if c, _ := contact.AsContact(); c.Email != "jane@example.com" || note.Content != "Write to jane@example.com" {
+		t.Error("expected the entries to be left alone")
+	}
+}
+
+func TestUnredact(t *testing.T) {
+	m := Map{Email: "jane@example.com", Website: "https://jane.dev/?a=1&b=2"}
+	text := `<a href="{{WEBSITE}}">{{EMAIL}}</a> {{EMAIL}} {{SIGNATURE}}`
+
+	got, n := m.Unredact(text, html.EscapeString)
+	want := `<a href="https://jane.dev/?a=1&amp;b=2">jane@example.com</a> jane@example.com {{SIGNATURE}}`
+	if got != want || n != 3 {
+		t.Errorf("Unredact() = %q, %d, want %q, 3", got, n, want)
+	}
+	if left := Placeholders(got); len(left) != 1 || left[0] != "{{SIGNATURE}}" {
+		t.Errorf("Placeholders() = %v", left)
+	}
+	if got, _ := m.Unredact("{{WEBSITE}}", nil); got != "https://jane.dev/?a=1&b=2" {
+		t.Errorf("expected the raw value without an escape, got %q", got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	m := Map{Email: "jane@example.com"}
+
+	path := filepath.Join(dir, "plain", "redactions.json")
+	if err := m.Save(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	if loaded, err := Load(path, nil); err != nil || loaded[Email] != "jane@example.com" {
+		t.Errorf("Load() = %v, %v", loaded, err)
+	}
+
+	_, key, _ := vault.NewParams("correct horse")
+	path = filepath.Join(dir, "sealed", "redactions.json")
+	if err := m.Save(path, key); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "jane") {
+		t.Errorf("expected the map to be encrypted, got %s", data)
+	}
+	if _, err := Load(path, nil); !errors.Is(err, vault.ErrNoKey) {
+		t.Errorf("expected ErrNoKey, got %v", err)
+	}
+	if loaded, err := Load(path, key); err != nil || loaded[Email] != "jane@example.com" {
+		t.Errorf("Load() = %v, %v", loaded, err)
+	}
+}
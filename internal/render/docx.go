@@ -0,0 +1,100 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ewurch/bragger/internal/docx"
+)
+
+// RenderDOCX writes the resume as a Word document with the same sections as
+// the HTML themes. It uses Word's title, heading and list styles rather than
+// layout tricks, so ATS parsers pick up the structure.
+func RenderDOCX(w io.Writer, r *Resume) error {
+	d := &docx.Document{Title: r.Name + " - Resume", Author: r.Name}
+	d.Add(docx.StyleTitle, docx.Run{Text: r.Name})
+	if r.Headline != "" {
+		d.Add(docx.StyleSubtitle, docx.Run{Text: r.Headline})
+	}
+	if len(r.ContactItems) > 0 {
+		d.Add(docx.StyleNormal, docx.Run{Text: strings.Join(r.ContactItems, " | ")})
+	}
+
+	if r.Summary != "" {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Professional Summary"})
+		d.Add(docx.StyleNormal, docx.Run{Text: r.Summary})
+	}
+
+	if len(r.Experience) > 0 {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Work Experience"})
+		for _, exp := range r.Experience {
+			d.Add(docx.StyleHeading2, docx.Run{Text: exp.Role + ", " + exp.Company})
+			if meta := joinNonEmpty(" | ", exp.Dates, exp.Location); meta != "" {
+				d.Add(docx.StyleNormal, docx.Run{Text: meta, Italic: true})
+			}
+			if exp.Description != "" {
+				d.Add(docx.StyleNormal, docx.Run{Text: exp.Description})
+			}
+			for _, h := range exp.Highlights {
+				d.Add(docx.StyleListBullet, docx.Run{Text: h})
+			}
+		}
+	}
+
+	if len(r.Skills) > 0 {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Skills"})
+		for _, g := range r.Skills {
+			d.Add(docx.StyleNormal, docx.Run{Text: g.Name + ": ", Bold: true}, docx.Run{Text: strings.Join(g.Items, ", ")})
+		}
+	}
+
+	if len(r.Education) > 0 {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Education"})
+		for _, edu := range r.Education {
+			degree := edu.Degree
+			if edu.Field != "" {
+				degree += " in " + edu.Field
+			}
+			d.Add(docx.StyleHeading2, docx.Run{Text: joinNonEmpty(", ", degree, edu.Institution)})
+			if edu.Dates != "" {
+				d.Add(docx.StyleNormal, docx.Run{Text: edu.Dates, Italic: true})
+			}
+		}
+	}
+
+	if len(r.Certifications) > 0 {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Certifications"})
+		for _, c := range r.Certifications {
+			text := joinNonEmpty(", ", c.Name, c.Issuer)
+			if c.Date != "" {
+				text += " (" + c.Date + ")"
+			}
+			d.Add(docx.StyleListBullet, docx.Run{Text: text})
+		}
+	}
+
+	if len(r.Languages) > 0 {
+		d.Add(docx.StyleHeading1, docx.Run{Text: "Languages"})
+		var langs []string
+		for _, l := range r.Languages {
+			if l.Proficiency != "" {
+				langs = append(langs, l.Language+" ("+l.Proficiency+")")
+			} else {
+				langs = append(langs, l.Language)
+			}
+		}
+		d.Add(docx.StyleNormal, docx.Run{Text: strings.Join(langs, ", ")})
+	}
+
+	return d.Write(w)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderDOCX(t *testing.T) {
+	r, err := BuildResume(resumeFixture(), &Selection{Summary: "Backend engineer", Headline: "Go & Kubernetes"})
+	if err != nil {
+		t.Fatalf("BuildResume failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderDOCX(&buf, r); err != nil {
+		t.Fatalf("RenderDOCX failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		t.Fatalf("no document part: %v", err)
+	}
+	content, _ := io.ReadAll(f)
+	document := string(content)
+
+	// Sections appear in the same order as the HTML themes
+	var last int
+	for _, want := range []string{
+		`<w:pStyle w:val="Title"></w:pStyle></w:pPr><w:r><w:t>Jane Doe</w:t>`,
+		`<w:t>Go &amp; Kubernetes</w:t>`,
+		`<w:t>Berlin | jane@example.com</w:t>`,
+		`<w:t>Professional Summary</w:t>`,
+		`<w:t>Senior Engineer, Acme</w:t>`,
+		`<w:t>Jan 2020 - Present | Remote</w:t>`,
+		`<w:pStyle w:val="ListBullet"></w:pStyle><w:numPr><w:ilvl w:val="0"></w:ilvl><w:numId w:val="1"></w:numId></w:numPr></w:pPr><w:r><w:t>Cut latency by 40%</w:t>`,
+		`<w:t>Developer, Old Co</w:t>`,
+		`<w:t xml:space="preserve">Languages: </w:t></w:r><w:r><w:t>Go, Python</w:t>`,
+		`<w:t>BSc in Computer Science, TU Berlin</w:t>`,
+	} {
+		i := strings.Index(document, want)
+		if i < 0 {
+			t.Errorf("document missing %q", want)
+			continue
+		}
+		if i < last {
+			t.Errorf("%q is out of order", want)
+		}
+		last = i
+	}
+	if strings.Contains(document, "Not on the resume") {
+		t.Error("context entries should not be rendered")
+	}
+}
@@ -0,0 +1,227 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Resume is the data passed to resume themes. Everything in it comes from
+// the knowledge base, except the summary and headline of a selection file.
+type Resume struct {
+	Name           string
+	Headline       string
+	Contact        models.ContactData
+	ContactItems   []string // Location, email, phone and links, in display order
+	Summary        string
+	Experience     []Experience
+	Skills         []SkillGroup
+	Education      []Education
+	Certifications []Certification
+	Languages      []Language
+}
+
+// Experience is a work experience entry with display-ready dates
+type Experience struct {
+	ID          string
+	Role        string
+	Company     string
+	Location    string
+	Dates       string // e.g. "Jan 2020 - Present"
+	Description string
+	Highlights  []string
+}
+
+// SkillGroup is one category of the skills entry, e.g. "Languages"
+type SkillGroup struct {
+	Name  string
+	Items []string
+}
+
+// Education is an education entry with display-ready dates
+type Education struct {
+	ID          string
+	Institution string
+	Degree      string
+	Field       string
+	Dates       string
+}
+
+// Certification is a certification with a display-ready date
+type Certification struct {
+	ID     string
+	Name   string
+	Issuer string
+	Date   string
+}
+
+// Language is a spoken language and its proficiency
+type Language struct {
+	ID          string
+	Language    string
+	Proficiency string
+}
+
+// BuildResume assembles the resume from the KB profile entries. Without a
+// selection, every entry is included, with experience and education newest
+// first. A selection picks and orders entries and highlights by ID.
+func BuildResume(entries []*models.KBEntry, sel *Selection) (*Resume, error) {
+	r := &Resume{}
+	var skills *models.SkillsData
+	experiences := make(map[string]Experience)
+	experienceData := make(map[string]models.ExperienceEntry)
+	var experienceIDs []string
+	educationDates := make(map[string][2]models.Date)
+
+	for _, e := range entries {
+		if e.Type != models.KBTypeProfile {
+			continue
+		}
+		switch models.ProfileCategory(e.Category) {
+		case models.CategoryContact:
+			contact, ok := e.AsContact()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			r.Contact = contact
+		case models.CategoryExperience:
+			exp, ok := e.AsExperience()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			experiences[e.ID] = Experience{
+				ID:          e.ID,
+				Role:        exp.Role,
+				Company:     exp.Company,
+				Location:    exp.Location,
+				Dates:       formatRange(exp.StartDate, exp.EndDate, true),
+				Description: exp.Description,
+				Highlights:  exp.Highlights,
+			}
+			experienceData[e.ID] = exp
+			experienceIDs = append(experienceIDs, e.ID)
+		case models.CategorySkills:
+			s, ok := e.AsSkills()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			skills = &s
+		case models.CategoryEducation:
+			edu, ok := e.AsEducation()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			educationDates[e.ID] = [2]models.Date{edu.EndDate, edu.StartDate}
+			r.Education = append(r.Education, Education{
+				ID:          e.ID,
+				Institution: edu.Institution,
+				Degree:      edu.Degree,
+				Field:       edu.Field,
+				Dates:       formatRange(edu.StartDate, edu.EndDate, false),
+			})
+		case models.CategoryCertifications:
+			cert, ok := e.AsCertification()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			r.Certifications = append(r.Certifications, Certification{
+				ID: e.ID, Name: cert.Name, Issuer: cert.Issuer, Date: formatMonth(cert.Date),
+			})
+		case models.CategoryLanguages:
+			lang, ok := e.AsLanguage()
+			if !ok {
+				return nil, unreadable(e)
+			}
+			r.Languages = append(r.Languages, Language{ID: e.ID, Language: lang.Language, Proficiency: lang.Proficiency})
+		}
+	}
+
+	if r.Contact.Name == "" {
+		return nil, fmt.Errorf("the knowledge base has no contact entry; add one with 'bragger kb add --type profile --category contact'")
+	}
+	r.Name = r.Contact.Name
+	for _, item := range []string{r.Contact.Location, r.Contact.Email, r.Contact.Phone, r.Contact.LinkedIn, r.Contact.GitHub, r.Contact.Website} {
+		if item != "" {
+			r.ContactItems = append(r.ContactItems, item)
+		}
+	}
+
+	// Newest first by default; a selection overrides the order
+	sort.SliceStable(experienceIDs, func(i, j int) bool {
+		return models.CompareExperience(experienceData[experienceIDs[i]], experienceData[experienceIDs[j]]) < 0
+	})
+	for _, id := range experienceIDs {
+		r.Experience = append(r.Experience, experiences[id])
+	}
+	sort.SliceStable(r.Education, func(i, j int) bool {
+		a, b := educationDates[r.Education[i].ID], educationDates[r.Education[j].ID]
+		if c := a[0].Compare(b[0]); c != 0 {
+			return c > 0
+		}
+		return a[1].Compare(b[1]) > 0
+	})
+	r.Skills = skillGroups(skills)
+
+	if sel != nil {
+		if err := sel.apply(r, experiences); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func skillGroups(s *models.SkillsData) []SkillGroup {
+	if s == nil {
+		return nil
+	}
+	var groups []SkillGroup
+	for _, g := range []SkillGroup{
+		{"Languages", s.Languages},
+		{"Frameworks", s.Frameworks},
+		{"Tools", s.Tools},
+		{"Databases", s.Databases},
+		{"Cloud", s.Cloud},
+		{"Other", s.Other},
+	} {
+		if len(g.Items) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// formatRange renders "2020-01" and "present" as "Jan 2020 - Present". Open
+// ranges read as ongoing only for experience; education shows the start alone.
+func formatRange(startDate, endDate models.Date, ongoing bool) string {
+	start, end := formatMonth(startDate), formatMonth(endDate)
+	switch {
+	case start == "" && end == "":
+		return ""
+	case start == "":
+		return end
+	case end == "" && ongoing:
+		return start + " - Present"
+	case end == "":
+		return start
+	}
+	return start + " - " + end
+}
+
+// formatMonth renders a YYYY-MM date as "Jan 2020"; years and other values
+// are kept as is
+func formatMonth(date models.Date) string {
+	if date.IsPresent() {
+		return "Present"
+	}
+	if date.Precision() >= models.PrecisionMonth {
+		t, _ := date.Time()
+		return t.Format("Jan 2006")
+	}
+	return string(date)
+}
+
+// unreadable is the error for an entry whose data doesn't fit its category
+func unreadable(e *models.KBEntry) error {
+	return fmt.Errorf("entry %s: can't read its %s data", e.ID, e.Category)
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func resumeFixture() []*models.KBEntry {
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane Doe", Email: "jane@example.com", Location: "Berlin"}, "user")
+	old := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Old Co", Role: "Developer", StartDate: "2016-03", EndDate: "2019-12",
+		Highlights: []string{"Shipped the billing system"},
+	}, "user")
+	old.ID = "kb-old"
+	current := models.NewProfileEntry(models.CategoryExperience, models.ExperienceEntry{
+		Company: "Acme", Role: "Senior Engineer", StartDate: "2020-01", Location: "Remote",
+		Highlights: []string{"Cut latency by 40%", "Led a team of 4", "Migrated to Kubernetes"},
+	}, "user")
+	current.ID = "kb-acme"
+	skills := models.NewProfileEntry(models.CategorySkills, models.SkillsData{Languages: []string{"Go", "Python"}, Cloud: []string{"AWS"}}, "user")
+	edu := models.NewProfileEntry(models.CategoryEducation, models.EducationEntry{Institution: "TU Berlin", Degree: "BSc", Field: "Computer Science", EndDate: "2016"}, "user")
+	edu.ID = "kb-edu"
+	note := models.NewContextEntry("notes", "Not on the resume", "user")
+
+	return []*models.KBEntry{contact, old, current, skills, edu, note}
+}
+
+func TestBuildResumeDefaults(t *testing.T) {
+	r, err := BuildResume(resumeFixture(), nil)
+	if err != nil {
+		t.Fatalf("BuildResume failed: %v", err)
+	}
+
+	if r.Name != "Jane Doe" || !reflect.DeepEqual(r.ContactItems, []string{"Berlin", "jane@example.com"}) {
+		t.Errorf("unexpected contact: %q %q", r.Name, r.ContactItems)
+	}
+	if len(r.Experience) != 2 || r.Experience[0].ID != "kb-acme" {
+		t.Fatalf("expected newest experience first, got %+v", r.Experience)
+	}
+	if r.Experience[0].Dates != "Jan 2020 - Present" || r.Experience[1].Dates != "Mar 2016 - Dec 2019" {
+		t.Errorf("unexpected dates: %q, %q", r.Experience[0].Dates, r.Experience[1].Dates)
+	}
+	wantSkills := []SkillGroup{{"Languages", []string{"Go", "Python"}}, {"Cloud", []string{"AWS"}}}
+	if !reflect.DeepEqual(r.Skills, wantSkills) {
+		t.Errorf("Skills = %+v, want %+v", r.Skills, wantSkills)
+	}
+	if len(r.Education) != 1 || r.Education[0].Dates != "2016" {
+		t.Errorf("unexpected education: %+v", r.Education)
+	}
+}
+
+func TestBuildResumeSelection(t *testing.T) {
+	sel := &Selection{
+		Summary:    "Backend engineer",
+		Experience: []ExperienceSelection{{ID: "kb-old"}, {ID: "kb-acme", Highlights: []int{2, 0}}},
+		Skills:     []string{"aws", "Go"},
+		Education:  []string{},
+	}
+
+	r, err := BuildResume(resumeFixture(), sel)
+	if err != nil {
+		t.Fatalf("BuildResume failed: %v", err)
+	}
+
+	if r.Summary != "Backend engineer" {
+		t.Errorf("Summary = %q", r.Summary)
+	}
+	if len(r.Experience) != 2 || r.Experience[0].ID != "kb-old" {
+		t.Fatalf("expected selection order, got %+v", r.Experience)
+	}
+	if want := []string{"Migrated to Kubernetes", "Cut latency by 40%"}; !reflect.DeepEqual(r.Experience[1].Highlights, want) {
+		t.Errorf("Highlights = %q, want %q", r.Experience[1].Highlights, want)
+	}
+	wantSkills := []SkillGroup{{"Languages", []string{"Go"}}, {"Cloud", []string{"AWS"}}}
+	if !reflect.DeepEqual(r.Skills, wantSkills) {
+		t.Errorf("Skills = %+v, want %+v", r.Skills, wantSkills)
+	}
+	if len(r.Education) != 0 {
+		t.Errorf("expected education to be deselected, got %+v", r.Education)
+	}
+}
+
+func TestBuildResumeSelectionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sel  *Selection
+		want string
+	}{
+		{"unknown experience", &Selection{Experience: []ExperienceSelection{{ID: "kb-missing"}}}, "experience entry not found: kb-missing"},
+		{"highlight out of range", &Selection{Experience: []ExperienceSelection{{ID: "kb-old", Highlights: []int{3}}}}, "has no highlight 3"},
+		{"skill not in KB", &Selection{Skills: []string{"Rust"}}, "skill not in the knowledge base: Rust"},
+		{"unknown education", &Selection{Education: []string{"kb-nope"}}, "education entry not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildResume(resumeFixture(), tt.sel)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBuildResumeRequiresContact(t *testing.T) {
+	_, err := BuildResume(resumeFixture()[1:], nil)
+	if err == nil || !strings.Contains(err.Error(), "no contact entry") {
+		t.Errorf("expected missing contact error, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultSelectionFile is looked up in the output directory when no
+// selection file is given
+const DefaultSelectionFile = "selection.json"
+
+// Selection chooses which KB content goes into a resume. Omitted fields
+// keep everything. Highlights are referenced by index so a resume can only
+// contain wording that exists in the KB.
+//
+//	{
+//	  "headline": "Senior Backend Engineer",
+//	  "summary": "Backend engineer with 8 years of experience...",
+//	  "experience": [
+//	    {"id": "kb-a1b2c3d4", "highlights": [0, 2]},
+//	    {"id": "kb-e5f6g7h8"}
+//	  ],
+//	  "skills": ["Go", "PostgreSQL", "Kubernetes"],
+//	  "education": ["kb-11223344"],
+//	  "certifications": [],
+//	  "languages": ["kb-55667788"]
+//	}
+type Selection struct {
+	Headline       string                `json:"headline,omitempty"`
+	Summary        string                `json:"summary,omitempty"`
+	Experience     []ExperienceSelection `json:"experience,omitempty"`
+	Skills         []string              `json:"skills,omitempty"`
+	Education      []string              `json:"education,omitempty"`
+	Certifications []string              `json:"certifications,omitempty"`
+	Languages      []string              `json:"languages,omitempty"`
+}
+
+// ExperienceSelection picks an experience entry and, optionally, a subset of
+// its highlights (0-based indices, in the order they should appear)
+type ExperienceSelection struct {
+	ID         string `json:"id"`
+	Highlights []int  `json:"highlights,omitempty"`
+}
+
+// LoadSelection reads a selection file
+func LoadSelection(path string) (*Selection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sel Selection
+	if err := json.Unmarshal(data, &sel); err != nil {
+		return nil, fmt.Errorf("invalid selection file %s: %w", path, err)
+	}
+	return &sel, nil
+}
+
+func (s *Selection) apply(r *Resume, experiences map[string]Experience) error {
+	r.Headline = s.Headline
+	r.Summary = s.Summary
+
+	if s.Experience != nil {
+		var selected []Experience
+		for _, pick := range s.Experience {
+			exp, ok := experiences[pick.ID]
+			if !ok {
+				return fmt.Errorf("selection: experience entry not found: %s", pick.ID)
+			}
+			if pick.Highlights != nil {
+				var highlights []string
+				for _, i := range pick.Highlights {
+					if i < 0 || i >= len(exp.Highlights) {
+						return fmt.Errorf("selection: experience %s has no highlight %d (it has %d)", pick.ID, i, len(exp.Highlights))
+					}
+					highlights = append(highlights, exp.Highlights[i])
+				}
+				exp.Highlights = highlights
+			}
+			selected = append(selected, exp)
+		}
+		r.Experience = selected
+	}
+
+	if s.Skills != nil {
+		groups, err := selectSkills(r.Skills, s.Skills)
+		if err != nil {
+			return err
+		}
+		r.Skills = groups
+	}
+
+	if s.Education != nil {
+		var selected []Education
+		for _, id := range s.Education {
+			i := indexByID(len(r.Education), func(i int) string { return r.Education[i].ID }, id)
+			if i < 0 {
+				return fmt.Errorf("selection: education entry not found: %s", id)
+			}
+			selected = append(selected, r.Education[i])
+		}
+		r.Education = selected
+	}
+
+	if s.Certifications != nil {
+		var selected []Certification
+		for _, id := range s.Certifications {
+			i := indexByID(len(r.Certifications), func(i int) string { return r.Certifications[i].ID }, id)
+			if i < 0 {
+				return fmt.Errorf("selection: certification entry not found: %s", id)
+			}
+			selected = append(selected, r.Certifications[i])
+		}
+		r.Certifications = selected
+	}
+
+	if s.Languages != nil {
+		var selected []Language
+		for _, id := range s.Languages {
+			i := indexByID(len(r.Languages), func(i int) string { return r.Languages[i].ID }, id)
+			if i < 0 {
+				return fmt.Errorf("selection: language entry not found: %s", id)
+			}
+			selected = append(selected, r.Languages[i])
+		}
+		r.Languages = selected
+	}
+
+	return nil
+}
+
+// selectSkills keeps the named skills (case-insensitive) in their KB groups,
+// in the order the selection lists them
+func selectSkills(groups []SkillGroup, names []string) ([]SkillGroup, error) {
+	picked := make([][]string, len(groups))
+	for _, name := range names {
+		found := false
+		for gi, g := range groups {
+			for _, item := range g.Items {
+				if strings.EqualFold(item, name) {
+					picked[gi] = append(picked[gi], item)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("selection: skill not in the knowledge base: %s", name)
+		}
+	}
+
+	var selected []SkillGroup
+	for gi, items := range picked {
+		if len(items) > 0 {
+			selected = append(selected, SkillGroup{Name: groups[gi].Name, Items: items})
+		}
+	}
+	return selected, nil
+}
+
+func indexByID(n int, id func(int) string, want string) int {
+	for i := 0; i < n; i++ {
+		if id(i) == want {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSelection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selection.json")
+	os.WriteFile(path, []byte(`{"summary":"Hi","experience":[{"id":"kb-1","highlights":[1,0]}],"skills":["Go"]}`), 0644)
+
+	sel, err := LoadSelection(path)
+	if err != nil {
+		t.Fatalf("LoadSelection failed: %v", err)
+	}
+	want := &Selection{
+		Summary:    "Hi",
+		Experience: []ExperienceSelection{{ID: "kb-1", Highlights: []int{1, 0}}},
+		Skills:     []string{"Go"},
+	}
+	if !reflect.DeepEqual(sel, want) {
+		t.Errorf("LoadSelection = %+v, want %+v", sel, want)
+	}
+
+	os.WriteFile(path, []byte(`{"experience": "kb-1"}`), 0644)
+	if _, err := LoadSelection(path); err == nil {
+		t.Error("expected error for invalid selection file")
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ewurch/bragger/templates"
+)
+
+// DefaultTheme is used when no theme is given
+const DefaultTheme = "classic"
+
+// resumeTemplate is the file each theme directory provides
+const resumeTemplate = "resume.html.tmpl"
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Themes lists the built-in theme names
+func Themes() []string {
+	dirs, _ := fs.ReadDir(templates.Themes, "themes")
+	var names []string
+	for _, d := range dirs {
+		if d.IsDir() {
+			names = append(names, d.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme parses a resume theme. The name is either a built-in theme or a
+// directory containing a resume.html.tmpl, for custom themes.
+func LoadTheme(name string) (*template.Template, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	custom := filepath.Join(name, resumeTemplate)
+	if _, err := os.Stat(custom); err == nil {
+		return template.New(resumeTemplate).Funcs(funcs).ParseFiles(custom)
+	}
+
+	path := "themes/" + name + "/" + resumeTemplate
+	if _, err := fs.Stat(templates.Themes, path); err != nil {
+		return nil, fmt.Errorf("unknown theme %q (available: %s, or a directory containing %s)", name, strings.Join(Themes(), ", "), resumeTemplate)
+	}
+	return template.New(resumeTemplate).Funcs(funcs).ParseFS(templates.Themes, path)
+}
+
+// RenderHTML writes the resume as HTML using a theme
+func RenderHTML(w io.Writer, theme string, r *Resume) error {
+	tmpl, err := LoadTheme(theme)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, r)
+}
+
+// OutputDir returns the outputs/company_role directory the skills use for an
+// application, e.g. "outputs/acme_senior_backend_engineer"
+func OutputDir(company, role string) string {
+	return filepath.Join("outputs", slug(company)+"_"+slug(role))
+}
+
+func slug(s string) string {
+	var b strings.Builder
+	pendingSep := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingSep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pendingSep = false
+			b.WriteRune(r)
+		} else {
+			pendingSep = true
+		}
+	}
+	return b.String()
+}
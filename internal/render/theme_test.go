@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTMLThemes(t *testing.T) {
+	r, err := BuildResume(resumeFixture(), &Selection{Headline: "Backend <Engineer>"})
+	if err != nil {
+		t.Fatalf("BuildResume failed: %v", err)
+	}
+
+	for _, theme := range Themes() {
+		t.Run(theme, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderHTML(&buf, theme, r); err != nil {
+				t.Fatalf("RenderHTML failed: %v", err)
+			}
+			html := buf.String()
+			for _, want := range []string{"<title>Jane Doe - Resume</title>", "Cut latency by 40%", "Jan 2020 - Present", "Backend &lt;Engineer&gt;"} {
+				if !strings.Contains(html, want) {
+					t.Errorf("expected %q in output", want)
+				}
+			}
+			if strings.Contains(html, "Not on the resume") {
+				t.Error("context entries should not be rendered")
+			}
+		})
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	if got := Themes(); len(got) < 2 || got[0] != "classic" {
+		t.Errorf("Themes() = %q", got)
+	}
+
+	if _, err := LoadTheme("nope"); err == nil || !strings.Contains(err.Error(), "unknown theme") {
+		t.Errorf("expected unknown theme error, got %v", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, resumeTemplate), []byte(`<h1>{{.Name}}</h1>`), 0644)
+	tmpl, err := LoadTheme(dir)
+	if err != nil {
+		t.Fatalf("LoadTheme(dir) failed: %v", err)
+	}
+	var buf bytes.Buffer
+	tmpl.Execute(&buf, &Resume{Name: "Custom"})
+	if buf.String() != "<h1>Custom</h1>" {
+		t.Errorf("custom theme output = %q", buf.String())
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	tests := []struct {
+		company, role, want string
+	}{
+		{"EPAM", "GenAI Python Engineer", filepath.Join("outputs", "epam_genai_python_engineer")},
+		{"Acme, Inc.", "Sr. Backend Engineer (Go)", filepath.Join("outputs", "acme_inc_sr_backend_engineer_go")},
+		{"Zürich Labs", "Dev", filepath.Join("outputs", "zürich_labs_dev")},
+	}
+	for _, tt := range tests {
+		if got := OutputDir(tt.company, tt.role); got != tt.want {
+			t.Errorf("OutputDir(%q, %q) = %q, want %q", tt.company, tt.role, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Highlight marks matches in a snippet, markdown-style
+const Highlight = "**"
+
+// snippetRunes is about how long a snippet is, not counting ellipses and
+// highlight marks
+const snippetRunes = 160
+
+// ErrEmptyQuery is returned for a query without any words
+var ErrEmptyQuery = errors.New("empty query")
+
+// Query is a parsed search query: every term must match. A term is a
+// word, or a quoted phrase whose words must appear together.
+type Query struct {
+	Terms [][]string // Stems of each term's words
+}
+
+// ParseQuery parses a query like `kafka "event driven"`. Words are matched
+// case-insensitively by their stems.
+func ParseQuery(s string) (Query, error) {
+	var q Query
+	for i, part := range strings.Split(s, `"`) {
+		if i%2 == 1 {
+			// Inside quotes: a phrase
+			if stems := tokenStems(tokenize(part)); len(stems) > 0 {
+				q.Terms = append(q.Terms, stems)
+			}
+			continue
+		}
+		for _, stem := range tokenStems(tokenize(part)) {
+			q.Terms = append(q.Terms, []string{stem})
+		}
+	}
+	if len(q.Terms) == 0 {
+		return q, ErrEmptyQuery
+	}
+	return q, nil
+}
+
+// Result is an entry that matches a query
+type Result struct {
+	Entry   *models.KBEntry
+	Score   float64
+	Matches int    // Number of matches in the entry
+	Field   string // Field the snippet is from, e.g. "highlights[2]"
+	Snippet string // Text around the matches, which are marked with Highlight
+}
+
+// field is a string in an entry: a context entry's content, or any string
+// in a profile entry's data
+type field struct {
+	name   string
+	text   string
+	tokens []token
+}
+
+type token struct {
+	stem       string
+	start, end int // Byte offsets in the text
+}
+
+// Search returns the entries matching every term of the query, most
+// relevant first. An entry scores more for terms it matches more often,
+// and for terms few entries match; equally relevant entries keep their
+// order.
+func Search(entries []*models.KBEntry, q Query) []Result {
+	type match struct {
+		entry  *models.KBEntry
+		fields []field
+		spans  [][][2]int // Per field, the spans of all terms' matches
+		counts []int      // Per term, the matches in the entry
+	}
+
+	var matches []match
+	df := make([]int, len(q.Terms))
+	for _, e := range entries {
+		m := match{entry: e, fields: entryFields(e), counts: make([]int, len(q.Terms))}
+		m.spans = make([][][2]int, len(m.fields))
+		for t, term := range q.Terms {
+			for f := range m.fields {
+				spans := find(m.fields[f].tokens, term)
+				m.counts[t] += len(spans)
+				m.spans[f] = append(m.spans[f], spans...)
+			}
+		}
+		all := true
+		for t, n := range m.counts {
+			if n == 0 {
+				all = false
+			} else {
+				df[t]++
+			}
+		}
+		if all {
+			matches = append(matches, m)
+		}
+	}
+
+	results := make([]Result, len(matches))
+	for i, m := range matches {
+		r := Result{Entry: m.entry}
+		for t, n := range m.counts {
+			idf := math.Log(1 + float64(len(entries))/float64(df[t]))
+			r.Score += (1 + math.Log(float64(n))) * idf
+			r.Matches += n
+		}
+		// The snippet comes from the field with the most matches
+		best := 0
+		for f := range m.fields {
+			if len(m.spans[f]) > len(m.spans[best]) {
+				best = f
+			}
+		}
+		r.Field = m.fields[best].name
+		r.Snippet = snippet(m.fields[best].text, m.spans[best])
+		results[i] = r
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+// find returns the spans where a term's stems appear in a row
+func find(tokens []token, term []string) [][2]int {
+	var spans [][2]int
+	for i := 0; i+len(term) <= len(tokens); i++ {
+		matched := true
+		for k, stem := range term {
+			if tokens[i+k].stem != stem {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			spans = append(spans, [2]int{tokens[i].start, tokens[i+len(term)-1].end})
+		}
+	}
+	return spans
+}
+
+// tokenize splits text into words: runs of letters and digits, with any
+// "+" or "#" straight after them, as in "C++" and "C#"
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case word && (text[i-1] == '+' || text[i-1] == '#'):
+			// A letter straight after "C#" starts another word
+			tokens = append(tokens, newToken(text, start, i))
+			start = i
+		case !word && start >= 0 && r != '+' && r != '#':
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+func newToken(text string, start, end int) token {
+	word := strings.ToLower(text[start:end])
+	if !strings.ContainsAny(word, "+#") {
+		word = Stem(word)
+	}
+	return token{stem: word, start: start, end: end}
+}
+
+func tokenStems(tokens []token) []string {
+	stems := make([]string, len(tokens))
+	for i, t := range tokens {
+		stems[i] = t.stem
+	}
+	return stems
+}
+
+// entryFields returns the text of an entry to search, field by field
+func entryFields(e *models.KBEntry) []field {
+	var fields []field
+	add := func(name, text string) {
+		if text != "" {
+			fields = append(fields, field{name: name, text: text, tokens: tokenize(text)})
+		}
+	}
+	if e.Type == models.KBTypeContext {
+		add("content", e.Content)
+		return fields
+	}
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil
+	}
+	// The data is walked token by token, so fields come in the order they
+	// are stored in, rather than a map's random order
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			for i := 0; dec.More(); i++ {
+				name := fmt.Sprintf("%s[%d]", path, i)
+				if v == '{' {
+					key, err := dec.Token()
+					if err != nil {
+						return err
+					}
+					name = strings.TrimPrefix(path+"."+key.(string), ".")
+				}
+				if err := walk(name); err != nil {
+					return err
+				}
+			}
+			_, err := dec.Token()
+			return err
+		case string:
+			add(path, v)
+		}
+		return nil
+	}
+	walk("")
+	return fields
+}
+
+// snippet returns the text around the first match, with every match in it
+// marked. Spans may overlap, when terms share words.
+func snippet(text string, spans [][2]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var merged [][2]int
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], s[1])
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	from, to := 0, len(text)
+	if utf8.RuneCountInString(text) > snippetRunes {
+		// Start a little before the first match, at a word
+		if len(merged) > 0 {
+			from = moveRunes(text, merged[0][0], -snippetRunes/4)
+		}
+		if from > 0 {
+			if i := strings.IndexFunc(text[from:], unicode.IsSpace); i >= 0 && from+i < merged[0][0] {
+				from += i + 1
+			}
+		}
+		to = moveRunes(text, from, snippetRunes)
+		if to < len(text) {
+			if i := strings.LastIndexFunc(text[from:to], unicode.IsSpace); i > 0 {
+				to = from + i
+			}
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("...")
+	}
+	pos := from
+	for _, s := range merged {
+		if s[1] <= from || s[0] >= to {
+			continue
+		}
+		start, end := max(s[0], from), min(s[1], to)
+		b.WriteString(text[pos:start])
+		b.WriteString(Highlight + text[start:end] + Highlight)
+		pos = end
+	}
+	b.WriteString(text[pos:to])
+	if to < len(text) {
+		b.WriteString("...")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// moveRunes returns the byte offset n runes from i, within the text
+func moveRunes(text string, i, n int) int {
+	for ; n < 0 && i > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:i])
+		i -= size
+	}
+	for ; n > 0 && i < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return i
+}
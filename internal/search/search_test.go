@@ -0,0 +1,140 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func profile(id string, category models.ProfileCategory, data any) *models.KBEntry {
+	e := models.NewProfileEntry(category, data, "user")
+	e.ID = id
+	return e
+}
+
+func contextEntry(id, category, content string) *models.KBEntry {
+	e := models.NewContextEntry(category, content, "user")
+	e.ID = id
+	return e
+}
+
+func testEntries() []*models.KBEntry {
+	return []*models.KBEntry{
+		profile("exp", models.CategoryExperience, models.ExperienceEntry{
+			Company: "Acme", Role: "Engineer", StartDate: "2021-03",
+			Highlights: []string{"Ran the book club", "Migrated billing to Kafka and built event-driven services"},
+		}),
+		profile("skills", models.CategorySkills, models.SkillsData{Languages: []string{"Go", "C++", "C#"}, Tools: []string{"Kafka"}}),
+		contextEntry("lag", "achievement", "Cut Kafka consumer lag by 90% after migrating to Kafka Streams"),
+		contextEntry("driven", "preference", "Driven by event planning for the team"),
+	}
+}
+
+func resultIDs(results []Result) string {
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.Entry.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`Kafka "Event Driven" migrations`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"kafka"}, {"event", "driven"}, {Stem("migration")}}
+	if len(q.Terms) != len(want) {
+		t.Fatalf("ParseQuery() = %v, want %v", q.Terms, want)
+	}
+	for i := range want {
+		if strings.Join(q.Terms[i], " ") != strings.Join(want[i], " ") {
+			t.Errorf("term %d = %v, want %v", i, q.Terms[i], want[i])
+		}
+	}
+
+	for _, s := range []string{"", `  "" `, "!?"} {
+		if _, err := ParseQuery(s); !errors.Is(err, ErrEmptyQuery) {
+			t.Errorf("ParseQuery(%q): expected ErrEmptyQuery, got %v", s, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := testEntries()
+	search := func(s string) []Result {
+		t.Helper()
+		q, err := ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Search(entries, q)
+	}
+
+	// The entry mentioning Kafka twice ranks first
+	results := search("kafka")
+	if got := resultIDs(results); got != "lag,exp,skills" {
+		t.Errorf("search(kafka) = %s", got)
+	}
+	if r := results[0]; r.Matches != 2 || r.Field != "content" ||
+		r.Snippet != "Cut **Kafka** consumer lag by 90% after migrating to **Kafka** Streams" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r := results[1]; r.Field != "highlights[1]" || !strings.Contains(r.Snippet, "to **Kafka** and") {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r := results[2]; r.Field != "tools[0]" || r.Snippet != "**Kafka**" {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	// Every term must match, case-insensitively and by stem
+	if got := resultIDs(search("MIGRATION kafka")); got != "lag,exp" {
+		t.Errorf("search(migration kafka) = %s", got)
+	}
+	if r := search("migrate")[0]; !strings.Contains(r.Snippet, "**Migrated**") {
+		t.Errorf("expected the stemmed match highlighted, got %q", r.Snippet)
+	}
+
+	// A phrase's words must appear together
+	if got := resultIDs(search(`"event driven"`)); got != "exp" {
+		t.Errorf(`search("event driven") = %s`, got)
+	}
+	if got := resultIDs(search("event driven")); got != "exp,driven" {
+		t.Errorf("search(event driven) = %s", got)
+	}
+	if r := search(`"event driven"`)[0]; !strings.Contains(r.Snippet, "built **event-driven** services") {
+		t.Errorf("expected the phrase highlighted, got %q", r.Snippet)
+	}
+
+	if got := resultIDs(search("c++")); got != "skills" {
+		t.Errorf("search(c++) = %s", got)
+	}
+	if got := resultIDs(search("rust")); got != "" {
+		t.Errorf("search(rust) = %s", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("filler words here ", 20) + "the Kafka cluster " + strings.Repeat("more words after ", 20)
+	q, _ := ParseQuery("kafka")
+	results := Search([]*models.KBEntry{contextEntry("long", "note", text)}, q)
+	if len(results) != 1 {
+		t.Fatalf("expected a result, got %d", len(results))
+	}
+	s := results[0].Snippet
+	if !strings.HasPrefix(s, "...") || !strings.HasSuffix(s, "...") || !strings.Contains(s, "the **Kafka** cluster") {
+		t.Errorf("unexpected snippet %q", s)
+	}
+	if n := len([]rune(s)); n > snippetRunes+10 {
+		t.Errorf("expected a short snippet, got %d runes", n)
+	}
+	// Snippets start and end at words
+	words := strings.Fields(strings.Trim(s, "."))
+	for _, w := range []string{words[0], words[len(words)-1]} {
+		if !strings.Contains(" filler words here more after ", " "+w+" ") {
+			t.Errorf("expected the snippet to start and end at words, got %q", s)
+		}
+	}
+}
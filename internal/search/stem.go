@@ -0,0 +1,67 @@
+package search
+
+import "strings"
+
+// Stem reduces a lowercase English word to a stem, so that "migrate",
+// "migrated", "migrating" and "migration" all become "migrat". It's a
+// light suffix stripper, not a full Porter stemmer: what matters is that a
+// word and the query term meet at the same stem, not that the stem is a
+// real word. Short words and words with digits are left alone.
+func Stem(word string) string {
+	if len(word) <= 3 || strings.ContainsAny(word, "0123456789") {
+		return word
+	}
+	w := word
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") &&
+		!strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		w = strings.TrimSuffix(w, "s")
+	}
+
+	// Nouns made from verbs: "optimization" meets "optimize", "automation"
+	// meets "automate"
+	switch {
+	case strings.HasSuffix(w, "ization"):
+		w = strings.TrimSuffix(w, "ation") + "e"
+	case strings.HasSuffix(w, "ation") && len(w) > 7:
+		w = strings.TrimSuffix(w, "ion") + "e"
+	}
+
+	// Verb forms: "running" and "runs" meet "run"
+	for _, suffix := range []string{"ing", "ed"} {
+		stem := strings.TrimSuffix(w, suffix)
+		if stem != w && len(stem) >= 3 && hasVowel(stem) {
+			w = undouble(stem)
+			break
+		}
+	}
+
+	if stem := strings.TrimSuffix(w, "ly"); stem != w && len(stem) >= 4 {
+		w = stem
+	}
+	if stem := strings.TrimSuffix(w, "e"); stem != w && len(stem) >= 3 {
+		w = stem
+	}
+	return w
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+// undouble drops the second of a doubled final consonant left by a
+// suffix, as in "runn" from "running", except for ll, ss and zz, which
+// words end in anyway
+func undouble(s string) string {
+	n := len(s)
+	if n < 2 || s[n-1] != s[n-2] || strings.IndexByte("aeiouylsz", s[n-1]) >= 0 {
+		return s
+	}
+	return s[:n-1]
+}
@@ -0,0 +1,43 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	// Each group of words must meet at one stem
+	groups := [][]string{
+		{"migrate", "migrated", "migrating", "migration", "migrations"},
+		{"optimize", "optimized", "optimizing", "optimization"},
+		{"automate", "automated", "automation"},
+		{"run", "runs", "running"},
+		{"test", "tests", "tested", "testing"},
+		{"scale", "scaled", "scaling", "scales"},
+		{"process", "processes", "processing", "processed"},
+		{"technology", "technologies"},
+		{"pipeline", "pipelines"},
+		{"lead", "leads", "leading"},
+		{"quick", "quickly"},
+	}
+	for _, words := range groups {
+		want := Stem(words[0])
+		for _, w := range words[1:] {
+			if got := Stem(w); got != want {
+				t.Errorf("Stem(%q) = %q, want %q like %q", w, got, want, words[0])
+			}
+		}
+	}
+
+	// Words that must be left alone
+	for _, tt := range []struct{ word, want string }{
+		{"status", "status"},
+		{"analysis", "analysis"},
+		{"class", "class"},
+		{"k8s", "k8s"},
+		{"feed", "feed"},
+		{"kafka", "kafka"},
+		{"go", "go"},
+	} {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+var (
+	ErrAlreadyArchived = errors.New("already archived")
+	ErrNotArchived     = errors.New("not archived")
+)
+
+// Archive hides an application from list and stats without removing it
+func (s *Storage) Archive(id string) error {
+	app, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if app.IsArchived() {
+		return ErrAlreadyArchived
+	}
+	now := time.Now()
+	return s.Update(id, func(a *models.Application) { a.ArchivedAt = &now })
+}
+
+// Unarchive restores an archived application
+func (s *Storage) Unarchive(id string) error {
+	app, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !app.IsArchived() {
+		return ErrNotArchived
+	}
+	return s.Update(id, func(a *models.Application) { a.ArchivedAt = nil })
+}
+
+// Purge removes archived applications for good: the given ones, or every
+// archived one if no IDs are given. It returns the applications removed.
+func (s *Storage) Purge(ids ...string) ([]*models.Application, error) {
+	apps, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	purge, err := purgeSet(ids, func(id string) (bool, bool) {
+		for _, app := range apps {
+			if app.ID == id {
+				return true, app.IsArchived()
+			}
+		}
+		return false, false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, purged []*models.Application
+	for _, app := range apps {
+		if app.IsArchived() && (purge == nil || purge[app.ID]) {
+			purged = append(purged, app)
+		} else {
+			kept = append(kept, app)
+		}
+	}
+	if len(purged) == 0 {
+		return nil, nil
+	}
+	return purged, s.Save(kept)
+}
+
+// Archive retires a KB entry from the context and resumes without removing
+// it
+func (s *KBStorage) Archive(id string) error {
+	entry, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if entry.IsArchived() {
+		return ErrAlreadyArchived
+	}
+	now := time.Now()
+	return s.Update(id, func(e *models.KBEntry) { e.ArchivedAt = &now })
+}
+
+// Unarchive restores an archived KB entry. Restoring a second contact or
+// skills entry returns a *models.SingletonError.
+func (s *KBStorage) Unarchive(id string) error {
+	entry, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !entry.IsArchived() {
+		return ErrNotArchived
+	}
+	return s.Update(id, func(e *models.KBEntry) { e.ArchivedAt = nil })
+}
+
+// Purge removes archived KB entries for good: the given ones, or every
+// archived one if no IDs are given. It returns the entries removed.
+func (s *KBStorage) Purge(ids ...string) ([]*models.KBEntry, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	purge, err := purgeSet(ids, func(id string) (bool, bool) {
+		for _, e := range entries {
+			if e.ID == id {
+				return true, e.IsArchived()
+			}
+		}
+		return false, false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, purged []*models.KBEntry
+	for _, e := range entries {
+		if e.IsArchived() && (purge == nil || purge[e.ID]) {
+			purged = append(purged, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	if len(purged) == 0 {
+		return nil, nil
+	}
+	return purged, s.Save(kept)
+}
+
+// purgeSet checks that the IDs to purge exist and are archived, and returns
+// them as a set; nil means every archived record
+func purgeSet(ids []string, lookup func(id string) (found, archived bool)) (map[string]bool, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	set := make(map[string]bool)
+	for _, id := range ids {
+		found, archived := lookup(id)
+		if !found {
+			return nil, fmt.Errorf("%s: %w", id, os.ErrNotExist)
+		}
+		if !archived {
+			return nil, fmt.Errorf("%s: %w", id, ErrNotArchived)
+		}
+		set[id] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestStorageArchive(t *testing.T) {
+	store, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	a, b := models.NewApplication("A", "Dev"), models.NewApplication("B", "Dev")
+	store.Add(a)
+	store.Add(b)
+
+	if err := store.Archive(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Archive(a.ID); !errors.Is(err, ErrAlreadyArchived) {
+		t.Errorf("expected ErrAlreadyArchived, got %v", err)
+	}
+	if got, _ := store.Get(a.ID); !got.IsArchived() {
+		t.Error("expected the application to be archived")
+	}
+
+	if err := store.Unarchive(b.ID); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("expected ErrNotArchived, got %v", err)
+	}
+	if err := store.Unarchive(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get(a.ID); got.IsArchived() {
+		t.Error("expected the application to be restored")
+	}
+	if err := store.Archive("app-missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestStoragePurge(t *testing.T) {
+	store, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	a, b, c := models.NewApplication("A", "Dev"), models.NewApplication("B", "Dev"), models.NewApplication("C", "Dev")
+	store.Add(a)
+	store.Add(b)
+	store.Add(c)
+	store.Archive(a.ID)
+	store.Archive(b.ID)
+
+	if _, err := store.Purge(c.ID); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("expected ErrNotArchived for an active application, got %v", err)
+	}
+	if _, err := store.Purge("app-missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+
+	purged, err := store.Purge(a.ID)
+	if err != nil || len(purged) != 1 || purged[0].ID != a.ID {
+		t.Fatalf("Purge(a) = %v, %v", purged, err)
+	}
+	purged, err = store.Purge()
+	if err != nil || len(purged) != 1 || purged[0].ID != b.ID {
+		t.Fatalf("Purge() = %v, %v", purged, err)
+	}
+	if purged, err := store.Purge(); err != nil || purged != nil {
+		t.Errorf("expected nothing left to purge, got %v, %v", purged, err)
+	}
+
+	apps, _ := store.Load()
+	if len(apps) != 1 || apps[0].ID != c.ID {
+		t.Errorf("expected only C to remain, got %v", apps)
+	}
+}
+
+func TestKBStorageArchive(t *testing.T) {
+	store, _, cleanup := setupKBTestStorage(t)
+	defer cleanup()
+
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@old.com"}, "")
+	store.Add(contact)
+	if err := store.Archive(contact.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Archived entries are left out of the getters and don't count as the
+	// contact entry
+	if _, err := store.GetContact(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no active contact, got %v", err)
+	}
+	replacement := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane", Email: "jane@new.com"}, "")
+	if err := store.Add(replacement); err != nil {
+		t.Fatalf("expected a new contact to be allowed, got %v", err)
+	}
+
+	var singleton *models.SingletonError
+	if err := store.Unarchive(contact.ID); !errors.As(err, &singleton) {
+		t.Errorf("expected a SingletonError restoring a second contact, got %v", err)
+	}
+
+	purged, err := store.Purge()
+	if err != nil || len(purged) != 1 || purged[0].ID != contact.ID {
+		t.Fatalf("Purge() = %v, %v", purged, err)
+	}
+	entries, _ := store.Load()
+	if len(entries) != 1 || entries[0].ID != replacement.ID {
+		t.Errorf("expected only the new contact, got %v", entries)
+	}
+}
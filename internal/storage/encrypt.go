@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+// dataFile is a data file and the key it's encrypted with, if any
+type dataFile struct {
+	path string
+	key  *vault.Key
+}
+
+func (s *Storage) file() dataFile {
+	return dataFile{path: s.filePath, key: s.key}
+}
+
+func (s *KBStorage) file() dataFile {
+	return dataFile{path: s.filePath, key: s.key}
+}
+
+// read returns the file's lines, decrypting them if the file is encrypted.
+// A plaintext file is read as is, key or not.
+func (f dataFile) read() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil || !vault.IsSealed(data) {
+		return data, err
+	}
+	if f.key == nil {
+		return nil, fmt.Errorf("%s: %w", f.path, vault.ErrNoKey)
+	}
+	data, err = f.key.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	return data, nil
+}
+
+// writeLines replaces the file through a temporary file, so a failed write
+// leaves the original intact. With a key, the lines are encrypted together.
+func (f dataFile) writeLines(lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	data := buf.Bytes()
+	if f.key != nil {
+		data = append(f.key.Seal(data), '\n')
+	}
+	return writeFile(f.path, data)
+}
+
+// writeFile replaces a file through a temporary file, keeping its mode
+func writeFile(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Chmod(mode)
+
+	w := bufio.NewWriter(tmp)
+	w.Write(data)
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SetKey reads and writes the applications file with key; nil reads and
+// writes plaintext
+func (s *Storage) SetKey(key *vault.Key) {
+	s.key = key
+}
+
+// Rekey rewrites the applications file with a new key, or in plaintext if
+// key is nil, and uses it from then on. Lines are kept byte for byte.
+func (s *Storage) Rekey(key *vault.Key) error {
+	if err := rekeyFile(s.file(), key); err != nil {
+		return err
+	}
+	s.key = key
+	return nil
+}
+
+// SetKey reads and writes the KB file with key; nil reads and writes
+// plaintext
+func (s *KBStorage) SetKey(key *vault.Key) {
+	s.key = key
+}
+
+// Key returns the key the KB is encrypted with, or nil, so files derived
+// from it can be encrypted the same way
+func (s *KBStorage) Key() *vault.Key {
+	return s.key
+}
+
+// Rekey rewrites the KB file with a new key, or in plaintext if key is nil,
+// and uses it from then on. Lines are kept byte for byte.
+func (s *KBStorage) Rekey(key *vault.Key) error {
+	if err := rekeyFile(s.file(), key); err != nil {
+		return err
+	}
+	s.key = key
+	return nil
+}
+
+func rekeyFile(f dataFile, key *vault.Key) error {
+	data, err := f.read()
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	if len(data) == 0 {
+		lines = nil
+	}
+	return dataFile{path: f.path, key: key}.writeLines(lines)
+}
+
+// SetKey encrypts the journal's new lines with key, and lets it read lines
+// encrypted with it; nil writes plaintext
+func (j *Journal) SetKey(key *vault.Key) {
+	j.key = key
+}
+
+// Rekey rewrites every journal line with a new key, or in plaintext if key
+// is nil, and uses it from then on
+func (j *Journal) Rekey(key *vault.Key) error {
+	path := filepath.Join(j.dir, journalFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		j.key = key
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if line, err = j.openLine(line); err != nil {
+			return err
+		}
+		buf.Write(sealLine(line, key))
+		buf.WriteByte('\n')
+	}
+	if err := writeFile(path, buf.Bytes()); err != nil {
+		return err
+	}
+	j.key = key
+	return nil
+}
+
+// openLine decrypts a journal line if it's encrypted
+func (j *Journal) openLine(line []byte) ([]byte, error) {
+	if !vault.IsSealed(line) {
+		return line, nil
+	}
+	if j.key == nil {
+		return nil, fmt.Errorf("journal: %w", vault.ErrNoKey)
+	}
+	data, err := j.key.Open(line)
+	if err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	return data, nil
+}
+
+// sealLine encrypts a journal line if there's a key
+func sealLine(line []byte, key *vault.Key) []byte {
+	if key == nil {
+		return line
+	}
+	return key.Seal(line)
+}
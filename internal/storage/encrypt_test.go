@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+func TestEncryptedStores(t *testing.T) {
+	j, apps, kb, dir := setupJournal(t)
+	_, key, err := vault.NewParams("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plaintext data from before encryption is rewritten encrypted
+	app := models.NewApplication("Acme", "Dev")
+	apps.Add(app)
+	kb.Add(models.NewContextEntry("note", "salary expectations", "user"))
+	for _, rekey := range []func(*vault.Key) error{apps.Rekey, kb.Rekey, j.Rekey} {
+		if err := rekey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	apps.Update(app.ID, func(a *models.Application) { a.Notes = "asked for 120k" })
+
+	for _, name := range []string{"applications.jsonl", "candidate-kb.jsonl", filepath.Join(".bragger", "journal", "journal.jsonl")} {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		for _, secret := range []string{"Acme", "salary", "120k"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s has %q in plaintext", name, secret)
+			}
+		}
+	}
+
+	loaded, err := apps.Get(app.ID)
+	if err != nil || loaded.Notes != "asked for 120k" {
+		t.Fatalf("Get() = %+v, %v", loaded, err)
+	}
+	if steps, err := j.Steps(); err != nil || len(steps) != 3 {
+		t.Errorf("expected 3 journaled steps, got %d, %v", len(steps), err)
+	}
+	if _, err := j.Undo(1, apps, kb); err != nil {
+		t.Errorf("expected undo to work on encrypted files, got %v", err)
+	}
+
+	// Without the key the data can't be read
+	locked := New(filepath.Join(dir, "applications.jsonl"))
+	if _, err := locked.Load(); !errors.Is(err, vault.ErrNoKey) {
+		t.Errorf("expected ErrNoKey, got %v", err)
+	}
+	lockedJournal := NewJournal(filepath.Join(dir, ".bragger", "journal"))
+	if _, err := lockedJournal.Changes(); !errors.Is(err, vault.ErrNoKey) {
+		t.Errorf("expected ErrNoKey for the journal, got %v", err)
+	}
+
+	// Decrypting leaves readable plaintext
+	for _, rekey := range []func(*vault.Key) error{apps.Rekey, kb.Rekey, j.Rekey} {
+		if err := rekey(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "applications.jsonl")); !strings.Contains(string(data), "Acme") {
+		t.Errorf("expected plaintext after decrypting, got %s", data)
+	}
+	if loaded, err := locked.Load(); err != nil || len(loaded) != 1 {
+		t.Errorf("expected the plaintext file to load without a key, got %v, %v", loaded, err)
+	}
+	if steps, err := lockedJournal.Steps(); err != nil || len(steps) != 4 {
+		t.Errorf("expected the plaintext journal to read, got %d steps, %v", len(steps), err)
+	}
+}
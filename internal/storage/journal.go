@@ -0,0 +1,626 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/vault"
+)
+
+// DefaultJournalDir is where the change journal is kept, relative to the
+// workspace
+const DefaultJournalDir = ".bragger/journal"
+
+const journalFile = "journal.jsonl"
+
+// Stores recorded in the journal
+const (
+	StoreApplications = "applications"
+	StoreKB           = "kb"
+)
+
+// Change operations
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+	OpRemove = "remove"
+)
+
+// Actors
+const (
+	ActorHuman = "human"
+	ActorAgent = "agent"
+)
+
+// Change is one record's mutation, as recorded in the journal
+type Change struct {
+	Step  string    `json:"step"`
+	Time  time.Time `json:"time"`
+	Store string    `json:"store"`
+	ID    string    `json:"id"`
+	Op    string    `json:"op"`
+	// Index is the record's position in the file before it was removed, so
+	// undo can put it back
+	Index int `json:"index,omitempty"`
+	// Before and After are the record before and after the change. For an
+	// update they hold only the top-level fields that changed, so a large
+	// field like jd_content isn't journaled again on every update.
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+	Command string          `json:"command,omitempty"`
+	Actor   string          `json:"actor,omitempty"`
+	// Undoes is the step this change reverts
+	Undoes string `json:"undoes,omitempty"`
+}
+
+// Step is the changes made by one save, which is usually one command
+type Step struct {
+	ID      string
+	Time    time.Time
+	Command string
+	Actor   string
+	Undoes  string
+	Changes []Change
+}
+
+// Journal is an append-only log of the changes saved to the data files, so
+// they can be shown and undone. Set it on a store with SetJournal.
+type Journal struct {
+	dir string
+	// Command and Actor are recorded with each change
+	Command string
+	Actor   string
+	// OnRecord, if set, is called with the changes of each step once they
+	// are journaled
+	OnRecord func(changes []Change)
+	key      *vault.Key
+}
+
+func NewJournal(dir string) *Journal {
+	if dir == "" {
+		dir = DefaultJournalDir
+	}
+	return &Journal{dir: dir}
+}
+
+// DetectActor returns the actor named by BRAGGER_ACTOR ("human" or "agent"),
+// or else agent when stdin isn't a terminal, since agents run commands
+// non-interactively
+func DetectActor() string {
+	switch strings.ToLower(os.Getenv("BRAGGER_ACTOR")) {
+	case ActorHuman:
+		return ActorHuman
+	case ActorAgent:
+		return ActorAgent
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ActorAgent
+	}
+	// /dev/null is a character device but not a terminal
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return ActorAgent
+	}
+	return ActorHuman
+}
+
+func newStepID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "step-" + hex.EncodeToString(b)
+}
+
+// Changes returns every change in the journal, oldest first
+func (j *Journal) Changes() ([]Change, error) {
+	file, err := os.Open(filepath.Join(j.dir, journalFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var changes []Change
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+	for scanner.Scan() {
+		line, err := j.openLine(scanner.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		var c Change
+		if err := json.Unmarshal(line, &c); err != nil {
+			continue // Skip malformed lines
+		}
+		changes = append(changes, c)
+	}
+	return changes, scanner.Err()
+}
+
+// Steps returns the journal's changes grouped by step, oldest first
+func (j *Journal) Steps() ([]Step, error) {
+	changes, err := j.Changes()
+	if err != nil {
+		return nil, err
+	}
+	var steps []Step
+	index := make(map[string]int)
+	for _, c := range changes {
+		i, ok := index[c.Step]
+		if !ok {
+			i = len(steps)
+			index[c.Step] = i
+			steps = append(steps, Step{ID: c.Step, Time: c.Time, Command: c.Command, Actor: c.Actor, Undoes: c.Undoes})
+		}
+		steps[i].Changes = append(steps[i].Changes, c)
+	}
+	return steps, nil
+}
+
+func (j *Journal) record(changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(j.dir, journalFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	for _, c := range changes {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		buf.Write(sealLine(data, j.key))
+		buf.WriteByte('\n')
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if j.OnRecord != nil {
+		j.OnRecord(changes)
+	}
+	return nil
+}
+
+// record is one line of a data file
+type record struct {
+	id  string
+	raw json.RawMessage
+}
+
+// readRecords reads the records of a data file, skipping malformed lines
+func readRecords(f dataFile) ([]record, error) {
+	data, err := f.read()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var head struct {
+			ID string `json:"id"`
+		}
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &head) != nil {
+			continue
+		}
+		var compact bytes.Buffer
+		json.Compact(&compact, line)
+		records = append(records, record{id: head.ID, raw: compact.Bytes()})
+	}
+	return records, nil
+}
+
+// saveRecords writes the lines of a data file and journals how its records
+// changed, as one step
+func saveRecords(f dataFile, store string, lines [][]byte, j *Journal) error {
+	var before []record
+	if j != nil {
+		var err error
+		if before, err = readRecords(f); err != nil {
+			return err
+		}
+	}
+	if err := f.writeLines(lines); err != nil {
+		return err
+	}
+	if j == nil {
+		return nil
+	}
+
+	// Lines that aren't records, which a migration keeps as they are, are
+	// skipped like readRecords does
+	after := make([]record, 0, len(lines))
+	for _, line := range lines {
+		var head struct {
+			ID string `json:"id"`
+		}
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &head) != nil {
+			continue
+		}
+		after = append(after, record{id: head.ID, raw: line})
+	}
+	if err := j.record(diffRecords(store, before, after, j.stepFor(""))); err != nil {
+		return fmt.Errorf("saved, but the change could not be journaled: %w", err)
+	}
+	return nil
+}
+
+// stepFor stamps new changes with a step, the command and the actor
+func (j *Journal) stepFor(undoes string) Change {
+	return Change{Step: newStepID(), Time: time.Now().UTC(), Command: j.Command, Actor: j.Actor, Undoes: undoes}
+}
+
+// diffRecords returns the changes between two versions of a data file:
+// removed records, then updated and added ones in file order
+func diffRecords(store string, before, after []record, step Change) []Change {
+	old := make(map[string]record)
+	for _, r := range before {
+		if _, ok := old[r.id]; !ok {
+			old[r.id] = r
+		}
+	}
+	current := make(map[string]bool)
+	for _, r := range after {
+		current[r.id] = true
+	}
+
+	var changes []Change
+	change := func(id, op string, index int, before, after json.RawMessage) {
+		c := step
+		c.Store, c.ID, c.Op, c.Index, c.Before, c.After = store, id, op, index, before, after
+		changes = append(changes, c)
+	}
+	for i, r := range before {
+		if !current[r.id] {
+			change(r.id, OpRemove, i, r.raw, nil)
+		}
+	}
+	for _, r := range after {
+		prev, ok := old[r.id]
+		switch {
+		case !ok:
+			change(r.id, OpAdd, 0, nil, r.raw)
+		case !sameJSON(prev.raw, r.raw):
+			b, a := changedMembers(prev.raw, r.raw)
+			change(r.id, OpUpdate, 0, b, a)
+		}
+	}
+	return changes
+}
+
+// member is a member of a JSON object
+type member struct {
+	name  string
+	value json.RawMessage
+}
+
+// objectMembers returns the members of a JSON object, in order
+func objectMembers(raw json.RawMessage) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	var members []member
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, member{name: tok.(string), value: value})
+	}
+	return members, nil
+}
+
+// marshalMembers writes members as a JSON object
+func marshalMembers(members []member) json.RawMessage {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(m.name)
+		b.Write(name)
+		b.WriteByte(':')
+		json.Compact(&b, m.value)
+	}
+	b.WriteByte('}')
+	return b.Bytes()
+}
+
+func findMember(members []member, name string) (json.RawMessage, bool) {
+	for _, m := range members {
+		if m.name == name {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+// changedMembers returns the top-level members that differ between two
+// versions of a record, as they were before and after. A member missing
+// from one side was unset there. Records that aren't objects are returned
+// whole.
+func changedMembers(before, after json.RawMessage) (json.RawMessage, json.RawMessage) {
+	b, errB := objectMembers(before)
+	a, errA := objectMembers(after)
+	if errB != nil || errA != nil {
+		return before, after
+	}
+	var changedB, changedA []member
+	for _, m := range b {
+		if v, ok := findMember(a, m.name); !ok || !sameJSON(m.value, v) {
+			changedB = append(changedB, m)
+		}
+	}
+	for _, m := range a {
+		if v, ok := findMember(b, m.name); !ok || !sameJSON(m.value, v) {
+			changedA = append(changedA, m)
+		}
+	}
+	return marshalMembers(changedB), marshalMembers(changedA)
+}
+
+// sameJSON compares JSON values, ignoring formatting and member order
+func sameJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// ErrNothingToUndo is returned by Undo when every step has been undone
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Undo reverts the last steps that haven't been undone, newest first. Each
+// revert is journaled as a new step, so undo itself can't be undone: it walks
+// further back instead. A step isn't reverted if a record it changed has
+// changed since; Undo returns the steps reverted before that.
+func (j *Journal) Undo(steps int, apps *Storage, kb *KBStorage) ([]Step, error) {
+	all, err := j.Steps()
+	if err != nil {
+		return nil, err
+	}
+
+	undone := make(map[string]bool)
+	for _, s := range all {
+		if s.Undoes != "" {
+			undone[s.Undoes] = true
+		}
+	}
+	var targets []Step
+	for i := len(all) - 1; i >= 0 && len(targets) < steps; i-- {
+		if s := all[i]; s.Undoes == "" && !undone[s.ID] {
+			targets = append(targets, s)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	files := map[string]dataFile{StoreApplications: apps.file(), StoreKB: kb.file()}
+	var reverted []Step
+	for _, target := range targets {
+		if err := j.revert(target, files); err != nil {
+			return reverted, fmt.Errorf("can't undo %s: %w", target.ID, err)
+		}
+		reverted = append(reverted, target)
+	}
+	return reverted, nil
+}
+
+// revert restores the records a step changed to their state before it
+func (j *Journal) revert(target Step, files map[string]dataFile) error {
+	byStore := make(map[string][]Change)
+	var stores []string
+	for _, c := range target.Changes {
+		if _, ok := files[c.Store]; !ok {
+			return fmt.Errorf("unknown store %q", c.Store)
+		}
+		if len(byStore[c.Store]) == 0 {
+			stores = append(stores, c.Store)
+		}
+		byStore[c.Store] = append(byStore[c.Store], c)
+	}
+	sort.Strings(stores)
+
+	// Check every store before writing any
+	reverted := make(map[string][]record)
+	for _, store := range stores {
+		records, err := readRecords(files[store])
+		if err != nil {
+			return err
+		}
+		changes := byStore[store]
+		for i := len(changes) - 1; i >= 0; i-- {
+			if records, err = revertChange(records, changes[i]); err != nil {
+				return err
+			}
+		}
+		reverted[store] = records
+	}
+
+	step := j.stepFor(target.ID)
+	for _, store := range stores {
+		before, err := readRecords(files[store])
+		if err != nil {
+			return err
+		}
+		lines := make([][]byte, len(reverted[store]))
+		for i, r := range reverted[store] {
+			lines[i] = r.raw
+		}
+		if err := files[store].writeLines(lines); err != nil {
+			return err
+		}
+		if err := j.record(diffRecords(store, before, reverted[store], step)); err != nil {
+			return fmt.Errorf("reverted, but the change could not be journaled: %w", err)
+		}
+	}
+	return nil
+}
+
+func revertChange(records []record, c Change) ([]record, error) {
+	at := -1
+	for i, r := range records {
+		if r.id == c.ID {
+			at = i
+			break
+		}
+	}
+	var current json.RawMessage
+	if at >= 0 {
+		current = records[at].raw
+	}
+
+	if c.Before != nil && c.After != nil {
+		// An update: its fields are set back, if they're still as it left
+		// them
+		var reverted json.RawMessage
+		if at >= 0 {
+			reverted = revertMembers(current, c.Before, c.After)
+		}
+		if reverted == nil {
+			return nil, fmt.Errorf("%s has changed since", c.ID)
+		}
+		out := append([]record(nil), records...)
+		out[at] = record{id: c.ID, raw: reverted}
+		return out, nil
+	}
+	if !sameJSON(current, c.After) {
+		return nil, fmt.Errorf("%s has changed since", c.ID)
+	}
+	if c.Before == nil {
+		return append(records[:at:at], records[at+1:]...), nil
+	}
+	index := min(c.Index, len(records))
+	out := append([]record(nil), records[:index]...)
+	out = append(out, record{id: c.ID, raw: c.Before})
+	return append(out, records[index:]...), nil
+}
+
+// revertMembers returns the record with the members an update changed set
+// back to before, keeping the order of the rest, or nil if any of them has
+// changed since. It works on whole records too, as journaled before updates
+// held only their changes.
+func revertMembers(current, before, after json.RawMessage) json.RawMessage {
+	cur, errC := objectMembers(current)
+	b, errB := objectMembers(before)
+	a, errA := objectMembers(after)
+	if errC != nil || errB != nil || errA != nil {
+		if sameJSON(current, after) {
+			return before
+		}
+		return nil
+	}
+	for _, side := range [][]member{b, a} {
+		for _, m := range side {
+			want, wantOK := findMember(a, m.name)
+			got, gotOK := findMember(cur, m.name)
+			if wantOK != gotOK || (wantOK && !sameJSON(want, got)) {
+				return nil
+			}
+		}
+	}
+
+	var out []member
+	for _, m := range cur {
+		if v, ok := findMember(b, m.name); ok {
+			out = append(out, member{name: m.name, value: v})
+		} else if _, ok := findMember(a, m.name); !ok {
+			out = append(out, m)
+		}
+	}
+	for _, m := range b {
+		if _, ok := findMember(cur, m.name); !ok {
+			out = append(out, m)
+		}
+	}
+	return marshalMembers(out)
+}
+
+// FieldChange is a field that differs between two versions of a record
+type FieldChange struct {
+	Path   string
+	Before string // JSON, or empty if the field was not set
+	After  string
+}
+
+// Fields returns the fields a change set, changed or cleared, by dotted
+// path, ignoring updated_at
+func (c Change) Fields() []FieldChange {
+	before, after := make(map[string]string), make(map[string]string)
+	flattenJSON(c.Before, "", before)
+	flattenJSON(c.After, "", after)
+
+	var paths []string
+	for p := range before {
+		paths = append(paths, p)
+	}
+	for p := range after {
+		if _, ok := before[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var fields []FieldChange
+	for _, p := range paths {
+		if p == "updated_at" || before[p] == after[p] {
+			continue
+		}
+		fields = append(fields, FieldChange{Path: p, Before: before[p], After: after[p]})
+	}
+	return fields
+}
+
+// flattenJSON maps the leaves of a JSON object to their JSON values by
+// dotted path; arrays are leaves
+func flattenJSON(raw json.RawMessage, prefix string, out map[string]string) {
+	if raw == nil {
+		return
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		var compact bytes.Buffer
+		json.Compact(&compact, raw)
+		out[prefix] = compact.String()
+		return
+	}
+	for k, v := range obj {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		flattenJSON(v, path, out)
+	}
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// setupJournal returns journaled stores in a temp dir
+func setupJournal(t *testing.T) (*Journal, *Storage, *KBStorage, string) {
+	t.Helper()
+	dir := t.TempDir()
+	j := NewJournal(filepath.Join(dir, ".bragger", "journal"))
+	j.Command, j.Actor = "bragger test", ActorAgent
+	apps := New(filepath.Join(dir, "applications.jsonl"))
+	apps.SetJournal(j)
+	kb := NewKBStorage(filepath.Join(dir, "candidate-kb.jsonl"))
+	kb.SetJournal(j)
+	return j, apps, kb, dir
+}
+
+func TestJournalRecordsChanges(t *testing.T) {
+	j, apps, kb, _ := setupJournal(t)
+
+	app := models.NewApplication("Acme", "Dev")
+	if err := apps.Add(app); err != nil {
+		t.Fatal(err)
+	}
+	apps.Update(app.ID, func(a *models.Application) { a.Status = models.StatusInterviewing })
+	entry := models.NewContextEntry("note", "hello", "user")
+	kb.Add(entry)
+	kb.Remove(entry.ID)
+	// Saving unchanged records records nothing
+	loaded, _ := apps.Load()
+	apps.Save(loaded)
+
+	steps, err := j.Steps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range steps {
+		if len(s.Changes) != 1 || s.Command != "bragger test" || s.Actor != ActorAgent {
+			t.Errorf("unexpected step: %+v", s)
+		}
+		c := s.Changes[0]
+		got = append(got, c.Store+" "+c.Op+" "+c.ID)
+	}
+	want := []string{
+		"applications add " + app.ID,
+		"applications update " + app.ID,
+		"kb add " + entry.ID,
+		"kb remove " + entry.ID,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+
+	fields := steps[1].Changes[0].Fields()
+	if len(fields) != 1 || fields[0].Path != "status" || fields[0].Before != `"applied"` || fields[0].After != `"interviewing"` {
+		t.Errorf("unexpected fields: %+v", fields)
+	}
+}
+
+func TestJournalUndo(t *testing.T) {
+	j, apps, kb, dir := setupJournal(t)
+
+	first := models.NewContextEntry("note", "first", "user")
+	second := models.NewContextEntry("note", "second", "user")
+	kb.Add(first)
+	kb.Add(second)
+	kb.Update(second.ID, func(e *models.KBEntry) { e.Content = "changed" })
+	kb.Remove(first.ID)
+
+	// Undo the remove: the entry comes back in its place
+	reverted, err := j.Undo(1, apps, kb)
+	if err != nil || len(reverted) != 1 || reverted[0].Changes[0].Op != OpRemove {
+		t.Fatalf("Undo = %+v, %v", reverted, err)
+	}
+	entries, _ := kb.Load()
+	if len(entries) != 2 || entries[0].ID != first.ID {
+		t.Fatalf("expected the first entry back first, got %v", entries)
+	}
+
+	// Undo walks back past its own steps
+	if _, err := j.Undo(2, apps, kb); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = kb.Load()
+	if len(entries) != 1 || entries[0].ID != first.ID {
+		t.Fatalf("expected only the first entry, got %v", entries)
+	}
+
+	if _, err := j.Undo(5, apps, kb); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "candidate-kb.jsonl"))
+	if len(data) != 0 {
+		t.Errorf("expected an empty KB, got %s", data)
+	}
+	if _, err := j.Undo(1, apps, kb); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestJournalUndoConflict(t *testing.T) {
+	j, apps, kb, dir := setupJournal(t)
+
+	app := models.NewApplication("Acme", "Dev")
+	apps.Add(app)
+
+	// An edit the journal didn't see
+	path := filepath.Join(dir, "applications.jsonl")
+	data, _ := os.ReadFile(path)
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	raw["notes"] = "edited by hand"
+	data, _ = json.Marshal(raw)
+	os.WriteFile(path, append(data, '\n'), 0644)
+
+	_, err := j.Undo(1, apps, kb)
+	if err == nil || !strings.Contains(err.Error(), app.ID+" has changed since") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(data)+"\n" {
+		t.Errorf("expected the file to be unchanged, got %s", got)
+	}
+}
+
+func TestJournalUpdateKeepsOnlyChanges(t *testing.T) {
+	j, apps, kb, dir := setupJournal(t)
+
+	app := models.NewApplication("Acme", "Dev")
+	app.JDContent = strings.Repeat("a long job description ", 1000)
+	apps.Add(app)
+	apps.Update(app.ID, func(a *models.Application) { a.Status = models.StatusInterviewing })
+
+	steps, _ := j.Steps()
+	c := steps[len(steps)-1].Changes[0]
+	if strings.Contains(string(c.Before)+string(c.After), "job description") {
+		t.Errorf("expected the unchanged jd_content not to be journaled, got %s -> %s", c.Before, c.After)
+	}
+	if !strings.Contains(string(c.After), `"interviewing"`) {
+		t.Errorf("expected the new status, got %s", c.After)
+	}
+
+	// A field the update didn't touch can change without blocking the undo
+	path := filepath.Join(dir, "applications.jsonl")
+	data, _ := os.ReadFile(path)
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	raw["notes"] = "edited by hand"
+	data, _ = json.Marshal(raw)
+	os.WriteFile(path, append(data, '\n'), 0644)
+
+	if _, err := j.Undo(1, apps, kb); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := apps.Get(app.ID)
+	if loaded.Status != models.StatusApplied || loaded.Notes != "edited by hand" || loaded.JDContent != app.JDContent {
+		t.Errorf("unexpected application after undo: %+v", loaded)
+	}
+}
+
+func TestJournalOnRecord(t *testing.T) {
+	j, apps, _, _ := setupJournal(t)
+	var recorded [][]Change
+	j.OnRecord = func(changes []Change) { recorded = append(recorded, changes) }
+
+	app := models.NewApplication("Acme", "Dev")
+	apps.Add(app)
+	loaded, _ := apps.Load()
+	apps.Save(loaded)
+	apps.Remove(app.ID)
+
+	if len(recorded) != 2 || recorded[0][0].Op != OpAdd || recorded[1][0].Op != OpRemove {
+		t.Errorf("expected the add and remove steps, got %+v", recorded)
+	}
+}
+
+func TestSaveWithoutJournal(t *testing.T) {
+	dir := t.TempDir()
+	apps := New(filepath.Join(dir, "applications.jsonl"))
+	if err := apps.Add(models.NewApplication("Acme", "Dev")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".bragger")); !os.IsNotExist(err) {
+		t.Errorf("expected no journal, got %v", err)
+	}
+}
+
+func TestDetectActor(t *testing.T) {
+	t.Setenv("BRAGGER_ACTOR", "Human")
+	if got := DetectActor(); got != ActorHuman {
+		t.Errorf("DetectActor() = %q, want human", got)
+	}
+	t.Setenv("BRAGGER_ACTOR", "agent")
+	if got := DetectActor(); got != ActorAgent {
+		t.Errorf("DetectActor() = %q, want agent", got)
+	}
+}
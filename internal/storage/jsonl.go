@@ -2,17 +2,21 @@ package storage
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"os"
 	"time"
 
 	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/vault"
 )
 
 const DefaultFilePath = "applications.jsonl"
 
 type Storage struct {
 	filePath string
+	journal  *Journal
+	key      *vault.Key
 }
 
 func New(filePath string) *Storage {
@@ -23,17 +27,16 @@ func New(filePath string) *Storage {
 }
 
 func (s *Storage) Load() ([]*models.Application, error) {
-	file, err := os.Open(s.filePath)
+	data, err := s.file().read()
 	if os.IsNotExist(err) {
 		return []*models.Application{}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	var apps []*models.Application
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 
 	// Increase buffer size for large JD content
 	buf := make([]byte, 0, 1024*1024)
@@ -49,22 +52,21 @@ func (s *Storage) Load() ([]*models.Application, error) {
 	return apps, scanner.Err()
 }
 
-func (s *Storage) Save(apps []*models.Application) error {
-	file, err := os.Create(s.filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// SetJournal records every save in the journal
+func (s *Storage) SetJournal(j *Journal) {
+	s.journal = j
+}
 
+func (s *Storage) Save(apps []*models.Application) error {
+	lines := make([][]byte, 0, len(apps))
 	for _, app := range apps {
 		data, err := json.Marshal(app)
 		if err != nil {
 			return err
 		}
-		file.Write(data)
-		file.WriteString("\n")
+		lines = append(lines, data)
 	}
-	return nil
+	return saveRecords(s.file(), StoreApplications, lines, s.journal)
 }
 
 func (s *Storage) Add(app *models.Application) error {
@@ -2,17 +2,39 @@ package storage
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/ewurch/bragger/internal/models"
+	"github.com/ewurch/bragger/internal/vault"
 )
 
 const DefaultKBFilePath = "candidate-kb.jsonl"
 
 type KBStorage struct {
 	filePath string
+	problems []LoadProblem
+	journal  *Journal
+	key      *vault.Key
+}
+
+// LoadProblem is a line of the KB file that could not be read, or an entry
+// that fails validation
+type LoadProblem struct {
+	Line int
+	// ID is empty when the line could not be read
+	ID  string
+	Err error
+}
+
+func (p LoadProblem) String() string {
+	if p.ID == "" {
+		return fmt.Sprintf("line %d: %v (the line is skipped)", p.Line, p.Err)
+	}
+	return fmt.Sprintf("line %d (%s): %v", p.Line, p.ID, p.Err)
 }
 
 func NewKBStorage(filePath string) *KBStorage {
@@ -23,55 +45,76 @@ func NewKBStorage(filePath string) *KBStorage {
 }
 
 func (s *KBStorage) Load() ([]*models.KBEntry, error) {
-	file, err := os.Open(s.filePath)
+	s.problems = nil
+	data, err := s.file().read()
 	if os.IsNotExist(err) {
 		return []*models.KBEntry{}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	var entries []*models.KBEntry
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 
 	// Increase buffer size for large entries
 	buf := make([]byte, 0, 1024*1024)
 	scanner.Buffer(buf, 10*1024*1024)
 
+	line := 0
 	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
 		var entry models.KBEntry
 		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue // Skip malformed lines
+			// Skip malformed lines
+			s.problems = append(s.problems, LoadProblem{Line: line, Err: err})
+			continue
+		}
+		// Invalid entries are still loaded, so they can be fixed or removed
+		if err := entry.Validate(); err != nil {
+			s.problems = append(s.problems, LoadProblem{Line: line, ID: entry.ID, Err: fmt.Errorf("%s: %w", entry.Category, err)})
 		}
 		entries = append(entries, &entry)
 	}
 	return entries, scanner.Err()
 }
 
-func (s *KBStorage) Save(entries []*models.KBEntry) error {
-	file, err := os.Create(s.filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// Problems returns the lines the last Load skipped and the invalid entries it
+// found
+func (s *KBStorage) Problems() []LoadProblem {
+	return s.problems
+}
+
+// SetJournal records every save in the journal
+func (s *KBStorage) SetJournal(j *Journal) {
+	s.journal = j
+}
 
+func (s *KBStorage) Save(entries []*models.KBEntry) error {
+	lines := make([][]byte, 0, len(entries))
 	for _, entry := range entries {
 		data, err := json.Marshal(entry)
 		if err != nil {
 			return err
 		}
-		file.Write(data)
-		file.WriteString("\n")
+		lines = append(lines, data)
 	}
-	return nil
+	return saveRecords(s.file(), StoreKB, lines, s.journal)
 }
 
+// Add appends an entry. Adding a second entry of a singleton category, like
+// contact, returns a *models.SingletonError.
 func (s *KBStorage) Add(entry *models.KBEntry) error {
 	entries, err := s.Load()
 	if err != nil {
 		return err
 	}
+	if err := models.CheckCardinality(entries, entry); err != nil {
+		return err
+	}
 	entries = append(entries, entry)
 	return s.Save(entries)
 }
@@ -96,19 +139,30 @@ func (s *KBStorage) Update(id string, updateFn func(*models.KBEntry)) error {
 		return err
 	}
 
-	found := false
+	var updated *models.KBEntry
+	var before models.KBEntry
 	for _, entry := range entries {
 		if entry.ID == id {
+			before = *entry
 			updateFn(entry)
 			entry.UpdatedAt = time.Now()
-			found = true
+			updated = entry
 			break
 		}
 	}
 
-	if !found {
+	if updated == nil {
 		return os.ErrNotExist
 	}
+	// Only an entry moving into a category is checked, so an entry can still
+	// be fixed while its category has a duplicate to merge
+	moved := updated.Type != before.Type || updated.Category != before.Category ||
+		(before.IsArchived() && !updated.IsArchived())
+	if moved {
+		if err := models.CheckCardinality(entries, updated); err != nil {
+			return err
+		}
+	}
 
 	return s.Save(entries)
 }
@@ -145,14 +199,15 @@ func (s *KBStorage) GetByType(entryType models.KBEntryType) ([]*models.KBEntry,
 
 	var filtered []*models.KBEntry
 	for _, entry := range entries {
-		if entry.Type == entryType {
+		if entry.Type == entryType && !entry.IsArchived() {
 			filtered = append(filtered, entry)
 		}
 	}
 	return filtered, nil
 }
 
-// GetByCategory returns all entries of a specific category
+// GetByCategory returns all entries of a specific category. Like the other
+// getters, it leaves out archived entries, which only Load returns.
 func (s *KBStorage) GetByCategory(category string) ([]*models.KBEntry, error) {
 	entries, err := s.Load()
 	if err != nil {
@@ -161,7 +216,7 @@ func (s *KBStorage) GetByCategory(category string) ([]*models.KBEntry, error) {
 
 	var filtered []*models.KBEntry
 	for _, entry := range entries {
-		if entry.Category == category {
+		if entry.Category == category && !entry.IsArchived() {
 			filtered = append(filtered, entry)
 		}
 	}
@@ -178,7 +233,8 @@ func (s *KBStorage) GetContext() ([]*models.KBEntry, error) {
 	return s.GetByType(models.KBTypeContext)
 }
 
-// GetContact returns the contact profile entry (there should be at most one)
+// GetContact returns the contact profile entry. Add and Update keep it to one;
+// of duplicates written before that, the first is returned.
 func (s *KBStorage) GetContact() (*models.KBEntry, error) {
 	entries, err := s.GetByCategory(string(models.CategoryContact))
 	if err != nil {
@@ -200,7 +256,7 @@ func (s *KBStorage) GetEducation() ([]*models.KBEntry, error) {
 	return s.GetByCategory(string(models.CategoryEducation))
 }
 
-// GetSkills returns the skills profile entry (there should be at most one)
+// GetSkills returns the skills profile entry, the first of any duplicates
 func (s *KBStorage) GetSkills() (*models.KBEntry, error) {
 	entries, err := s.GetByCategory(string(models.CategorySkills))
 	if err != nil {
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ewurch/bragger/internal/models"
@@ -93,6 +95,66 @@ func TestKBStorageLoadEmpty(t *testing.T) {
 	}
 }
 
+func TestKBStorageLoadProblems(t *testing.T) {
+	store, filePath, cleanup := setupKBTestStorage(t)
+	defer cleanup()
+
+	lines := `{"id":"kb-1","type":"profile","category":"contact","data":{"name":"Jane","email":"jane@example.com"}}
+not json
+
+{"id":"kb-2","type":"profile","category":"experience","data":{"company":"Acme","role":"Engineer"}}
+{"id":"kb-3","type":"context","category":"notes","content":"Hi"}
+`
+	os.WriteFile(filePath, []byte(lines), 0644)
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected the 3 readable entries, got %d", len(entries))
+	}
+	if c, ok := entries[0].AsContact(); !ok || c.Name != "Jane" {
+		t.Errorf("expected typed contact data, got %#v", entries[0].Data)
+	}
+
+	problems := store.Problems()
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+	if problems[0].Line != 2 || problems[0].ID != "" || !strings.Contains(problems[0].String(), "line 2: invalid character") {
+		t.Errorf("unexpected problem %q", problems[0])
+	}
+	if problems[1].String() != "line 4 (kb-2): experience: start_date is required" {
+		t.Errorf("unexpected problem %q", problems[1])
+	}
+
+	// A clean load clears them
+	os.Remove(filePath)
+	store.Load()
+	if len(store.Problems()) != 0 {
+		t.Errorf("expected no problems, got %v", store.Problems())
+	}
+}
+
+func TestKBStorageKeepsUnknownData(t *testing.T) {
+	store, filePath, cleanup := setupKBTestStorage(t)
+	defer cleanup()
+
+	line := `{"id":"kb-1","type":"profile","category":"experience","data":{"company":"Acme","role":"Engineer","start_date":"2020-01","team_size":6,"stack_notes":"Go, Kafka"},"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z","schema_version":3}` + "\n"
+	os.WriteFile(filePath, []byte(line), 0644)
+
+	// An unrelated change saves every entry
+	if err := store.Add(models.NewContextEntry("notes", "Hi", "user")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	data, _ := os.ReadFile(filePath)
+	first, _, _ := strings.Cut(string(data), "\n")
+	if first+"\n" != strings.Replace(line, `"team_size":6,"stack_notes":"Go, Kafka"`, `"stack_notes":"Go, Kafka","team_size":6`, 1) {
+		t.Errorf("expected the entry saved with its unknown data, got %s", first)
+	}
+}
+
 func TestKBStorageGet(t *testing.T) {
 	store, _, cleanup := setupKBTestStorage(t)
 	defer cleanup()
@@ -149,6 +211,73 @@ func TestKBStorageUpdate(t *testing.T) {
 	})
 }
 
+func TestKBStorageSingletons(t *testing.T) {
+	store, _, cleanup := setupKBTestStorage(t)
+	defer cleanup()
+
+	contact := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "Jane", Email: "j@e.com"}, "user")
+	if err := store.Add(contact); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	t.Run("add a second contact", func(t *testing.T) {
+		err := store.Add(models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "J", Email: "j@x.com"}, "user"))
+		var singleton *models.SingletonError
+		if !errors.As(err, &singleton) || singleton.ExistingID != contact.ID {
+			t.Errorf("expected a SingletonError, got %v", err)
+		}
+		if entries, _ := store.Load(); len(entries) != 1 {
+			t.Errorf("expected the second contact not to be saved, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("update another entry into a contact", func(t *testing.T) {
+		skills := models.NewProfileEntry(models.CategorySkills, models.SkillsData{}, "user")
+		store.Add(skills)
+		err := store.Update(skills.ID, func(e *models.KBEntry) {
+			e.Category = string(models.CategoryContact)
+		})
+		var singleton *models.SingletonError
+		if !errors.As(err, &singleton) {
+			t.Errorf("expected a SingletonError, got %v", err)
+		}
+	})
+
+	t.Run("update the contact", func(t *testing.T) {
+		err := store.Update(contact.ID, func(e *models.KBEntry) {
+			e.Source = "cv-import"
+		})
+		if err != nil {
+			t.Errorf("expected the contact to be updatable, got %v", err)
+		}
+	})
+
+	t.Run("update a duplicate", func(t *testing.T) {
+		// Duplicates from before singletons were enforced can still be fixed
+		// before merging them
+		entries, _ := store.Load()
+		dup := models.NewProfileEntry(models.CategoryContact, models.ContactData{Name: "J", Email: "j@x.com"}, "user")
+		if err := store.Save(append(entries, dup)); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		err := store.Update(dup.ID, func(e *models.KBEntry) {
+			e.Data = models.ContactData{Name: "Jane", Email: "j@x.com"}
+		})
+		if err != nil {
+			t.Errorf("expected the duplicate to be updatable, got %v", err)
+		}
+		if err := store.Update(contact.ID, func(e *models.KBEntry) { e.Source = "user" }); err != nil {
+			t.Errorf("expected the first contact to be updatable, got %v", err)
+		}
+
+		store.Archive(dup.ID)
+		var singleton *models.SingletonError
+		if err := store.Unarchive(dup.ID); !errors.As(err, &singleton) {
+			t.Errorf("expected unarchiving a second contact to fail, got %v", err)
+		}
+	})
+}
+
 func TestKBStorageRemove(t *testing.T) {
 	store, _, cleanup := setupKBTestStorage(t)
 	defer cleanup()
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+// Migration upgrades a record from schema version From to From+1. Records
+// are migrated as decoded JSON, so a migration can handle shapes the current
+// models can no longer read.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(record map[string]any)
+}
+
+// applicationMigrations upgrade records in applications.jsonl to
+// models.ApplicationSchemaVersion
+var applicationMigrations = []Migration{
+	{1, "normalize status and fill in date_applied", func(r map[string]any) {
+		if status, ok := r["status"].(string); ok {
+			r["status"] = strings.ToLower(strings.TrimSpace(status))
+		}
+		if date, _ := r["date_applied"].(string); date == "" {
+			if created, ok := r["created_at"].(string); ok {
+				if t, err := time.Parse(time.RFC3339Nano, created); err == nil {
+					r["date_applied"] = t.Format("2006-01-02")
+				}
+			}
+		}
+	}},
+	{2, "normalize date_applied", func(r map[string]any) {
+		normalizeDates(r, "date_applied")
+	}},
+}
+
+// kbMigrations upgrade records in candidate-kb.jsonl to
+// models.KBSchemaVersion
+var kbMigrations = []Migration{
+	{1, "lower-case category", func(r map[string]any) {
+		if category, ok := r["category"].(string); ok {
+			r["category"] = strings.ToLower(strings.TrimSpace(category))
+		}
+	}},
+	{2, "normalize dates", func(r map[string]any) {
+		if data, ok := r["data"].(map[string]any); ok {
+			normalizeDates(data, "start_date", "end_date", "date", "expiry_date")
+		}
+	}},
+}
+
+// normalizeDates rewrites the given date fields in canonical form. Values
+// that aren't dates are left for validation to report.
+func normalizeDates(record map[string]any, keys ...string) {
+	for _, key := range keys {
+		if s, ok := record[key].(string); ok {
+			if date, err := models.ParseDate(s); err == nil {
+				record[key] = string(date)
+			}
+		}
+	}
+}
+
+// MigrationResult reports what migrating a data file changes
+type MigrationResult struct {
+	Path    string
+	Records int
+	// Migrated lists the records below the current schema version
+	Migrated []RecordMigration
+	// Problems lists lines left as they are, like records written by a newer
+	// version of bragger
+	Problems []string
+}
+
+// RecordMigration describes the migration of one record
+type RecordMigration struct {
+	Line int
+	ID   string
+	From int
+	To   int
+	// Changes are the descriptions of the migrations that changed the
+	// record, besides its schema version
+	Changes []string
+}
+
+// Migrate upgrades the records in applications.jsonl to the current schema,
+// as one journaled step. With dryRun the file is left unchanged.
+func (s *Storage) Migrate(dryRun bool) (*MigrationResult, error) {
+	return migrateFile(s.file(), StoreApplications, s.journal, models.ApplicationSchemaVersion, applicationMigrations, dryRun, func(data []byte) ([]byte, error) {
+		var app models.Application
+		if err := json.Unmarshal(data, &app); err != nil {
+			return nil, err
+		}
+		return json.Marshal(app)
+	})
+}
+
+// Migrate upgrades the records in the KB file to the current schema, as one
+// journaled step. With dryRun the file is left unchanged. Profile data the
+// models have no field for is kept; see models.KBEntry.ExtraData.
+func (s *KBStorage) Migrate(dryRun bool) (*MigrationResult, error) {
+	return migrateFile(s.file(), StoreKB, s.journal, models.KBSchemaVersion, kbMigrations, dryRun, func(data []byte) ([]byte, error) {
+		var entry models.KBEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+		return json.Marshal(&entry)
+	})
+}
+
+// migrateFile applies migrations to the records of a JSONL file below the
+// current version. Migrated records are rewritten in the model's field order
+// by normalize; every other line is kept byte for byte. The migration is
+// journaled like a save, so it can be undone.
+func migrateFile(f dataFile, store string, j *Journal, current int, migrations []Migration, dryRun bool, normalize func([]byte) ([]byte, error)) (*MigrationResult, error) {
+	result := &MigrationResult{Path: f.path}
+	data, err := f.read()
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		result.Records++
+
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("line %d: %v", i+1, err))
+			continue
+		}
+		id, _ := record["id"].(string)
+		version := schemaVersion(record)
+		if version > current {
+			result.Problems = append(result.Problems, fmt.Sprintf("line %d (%s): schema version %d is newer than this bragger's %d", i+1, id, version, current))
+			continue
+		}
+		if version == current {
+			continue
+		}
+
+		m := RecordMigration{Line: i + 1, ID: id, From: version, To: current}
+		for _, migration := range migrations {
+			if migration.From < version || migration.From >= current {
+				continue
+			}
+			before, _ := json.Marshal(record)
+			migration.Apply(record)
+			if after, _ := json.Marshal(record); !bytes.Equal(before, after) {
+				m.Changes = append(m.Changes, migration.Description)
+			}
+		}
+		record["schema_version"] = current
+
+		data, err := json.Marshal(record)
+		if err == nil {
+			data, err = normalize(data)
+		}
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("line %d (%s): %v", i+1, id, err))
+			continue
+		}
+		lines[i] = data
+		result.Migrated = append(result.Migrated, m)
+	}
+
+	if dryRun || len(result.Migrated) == 0 {
+		return result, nil
+	}
+	return result, saveRecords(f, store, lines, j)
+}
+
+// schemaVersion returns a record's schema version. Records written before
+// versioning have none and are version 1.
+func schemaVersion(record map[string]any) int {
+	if v, ok := record["schema_version"].(float64); ok && v >= 1 {
+		return int(v)
+	}
+	return 1
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ewurch/bragger/internal/models"
+)
+
+func TestStorageMigrate(t *testing.T) {
+	store, filePath, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	current := fmt.Sprintf(`{"id":"app-new","company":"New","role":"Dev","status":"applied","date_applied":"2025-02-01","created_at":"2025-02-01T10:00:00Z","updated_at":"2025-02-01T10:00:00Z","schema_version":%d}`, models.ApplicationSchemaVersion)
+	content := `{"id":"app-old","company":"Old","role":"Dev","status":" Interviewing ","date_applied":"","created_at":"2024-03-05T10:00:00Z","updated_at":"2024-03-05T10:00:00Z"}` + "\n" +
+		current + "\n" +
+		"not json\n"
+	os.WriteFile(filePath, []byte(content), 0644)
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := store.Migrate(true)
+		if err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		if result.Records != 3 || len(result.Migrated) != 1 || len(result.Problems) != 1 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		m := result.Migrated[0]
+		if m.Line != 1 || m.ID != "app-old" || m.From != 1 || m.To != models.ApplicationSchemaVersion || len(m.Changes) != 1 {
+			t.Errorf("unexpected migration: %+v", m)
+		}
+		if data, _ := os.ReadFile(filePath); string(data) != content {
+			t.Errorf("dry run changed the file:\n%s", data)
+		}
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		if _, err := store.Migrate(false); err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		data, _ := os.ReadFile(filePath)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != 3 || lines[1] != current || lines[2] != "not json" {
+			t.Fatalf("expected other lines to be kept, got:\n%s", data)
+		}
+
+		app, err := store.Get("app-old")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if app.Status != models.StatusInterviewing || app.DateApplied != "2024-03-05" || app.SchemaVersion != models.ApplicationSchemaVersion {
+			t.Errorf("unexpected migrated application: %+v", app)
+		}
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		result, err := store.Migrate(false)
+		if err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		if len(result.Migrated) != 0 {
+			t.Errorf("expected nothing to migrate, got %+v", result.Migrated)
+		}
+	})
+}
+
+func TestKBStorageMigrate(t *testing.T) {
+	store, filePath, cleanup := setupKBTestStorage(t)
+	defer cleanup()
+
+	content := `{"id":"kb-1","type":"context","category":"Achievement ","content":"Led a team","source":"user","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}` + "\n" +
+		`{"id":"kb-2","type":"profile","category":"experience","data":{"company":"Acme","role":"Dev","start_date":"Jan 2020","end_date":"Current"},"source":"user","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z","schema_version":2}` + "\n" +
+		`{"id":"kb-3","type":"context","category":"note","content":"From the future","source":"user","created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z","schema_version":99}` + "\n"
+	os.WriteFile(filePath, []byte(content), 0644)
+
+	result, err := store.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(result.Migrated) != 2 {
+		t.Fatalf("expected 2 migrated records, got %+v", result.Migrated)
+	}
+	if len(result.Migrated[0].Changes) != 1 || result.Migrated[1].From != 2 || len(result.Migrated[1].Changes) != 1 {
+		t.Errorf("unexpected changes: %+v", result.Migrated)
+	}
+	if len(result.Problems) != 1 || !strings.Contains(result.Problems[0], "schema version 99 is newer") {
+		t.Errorf("expected a newer version problem, got %v", result.Problems)
+	}
+
+	if data, _ := os.ReadFile(filePath); !strings.Contains(string(data), `"start_date":"2020-01","end_date":"present"`) {
+		t.Errorf("expected dates to be rewritten, got:\n%s", data)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if entries[0].Category != "achievement" || entries[0].SchemaVersion != models.KBSchemaVersion {
+		t.Errorf("unexpected migrated entry: %+v", entries[0])
+	}
+	if exp, ok := entries[1].AsExperience(); !ok || exp.StartDate != "2020-01" || exp.EndDate != models.Present {
+		t.Errorf("expected normalized dates, got %+v", entries[1].Data)
+	}
+	if entries[2].SchemaVersion != 99 {
+		t.Errorf("expected newer record to be kept, got %+v", entries[2])
+	}
+}
+
+func TestKBStorageMigrateKeepsDataAndJournals(t *testing.T) {
+	j, apps, kb, dir := setupJournal(t)
+	path := filepath.Join(dir, "candidate-kb.jsonl")
+	content := `{"id":"kb-1","type":"profile","category":"experience","data":{"company":"Acme","role":"Dev","start_date":"Jan 2020","team_size":6,"stack_notes":"Go"},"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z"}` + "\n" +
+		"not json\n"
+	os.WriteFile(path, []byte(content), 0644)
+
+	if _, err := kb.Migrate(false); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"start_date":"2020-01","stack_notes":"Go","team_size":6}`) {
+		t.Errorf("expected the data the model has no field for to be kept, got:\n%s", data)
+	}
+
+	steps, _ := j.Steps()
+	if len(steps) != 1 || len(steps[0].Changes) != 1 || steps[0].Changes[0].Op != OpUpdate {
+		t.Fatalf("expected the migration journaled as one update, got %+v", steps)
+	}
+	if _, err := j.Undo(1, apps, kb); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	record, _, _ := strings.Cut(content, "\n")
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), record+"\n") {
+		t.Errorf("expected undo to restore the record, got:\n%s", data)
+	}
+}
+
+func TestMigrateMissingFile(t *testing.T) {
+	store := NewKBStorage(os.TempDir() + "/does-not-exist-kb.jsonl")
+	result, err := store.Migrate(false)
+	if err != nil || result.Records != 0 {
+		t.Errorf("expected empty result, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAgentSocket is where the key agent listens, relative to the
+// workspace
+const DefaultAgentSocket = ".bragger/agent.sock"
+
+// ErrNoAgent is returned when no agent is listening
+var ErrNoAgent = errors.New("no key agent running")
+
+// agentTimeout bounds a request to the agent
+const agentTimeout = 2 * time.Second
+
+// Agent holds a key in memory and hands it to bragger commands over a Unix
+// socket only its user can connect to, until it times out or is stopped
+type Agent struct {
+	listener net.Listener
+	socket   string
+	key      *Key
+	done     chan struct{}
+}
+
+// Listen starts an agent for key on socket. A socket left behind by an
+// agent that has exited is replaced.
+func Listen(socket string, key *Key) (*Agent, error) {
+	if socket == "" {
+		socket = DefaultAgentSocket
+	}
+	if _, err := request(socket, "ping"); err == nil {
+		return nil, errors.New("a key agent is already running")
+	}
+	os.Remove(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &Agent{listener: listener, socket: socket, key: key, done: make(chan struct{})}, nil
+}
+
+// Serve answers requests until the timeout passes or the agent is stopped,
+// then removes the socket
+func (a *Agent) Serve(timeout time.Duration) {
+	defer os.Remove(a.socket)
+	timer := time.AfterFunc(timeout, a.stop)
+	defer timer.Stop()
+
+	for {
+		conn, err := a.listener.Accept()
+		if err != nil {
+			return
+		}
+		a.handle(conn)
+	}
+}
+
+func (a *Agent) stop() {
+	select {
+	case <-a.done:
+	default:
+		close(a.done)
+		a.listener.Close()
+	}
+}
+
+func (a *Agent) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	switch strings.TrimSpace(line) {
+	case "ping":
+		fmt.Fprintln(conn, "ok")
+	case "key":
+		fmt.Fprintln(conn, a.key.String())
+	case "stop":
+		fmt.Fprintln(conn, "ok")
+		a.stop()
+	default:
+		fmt.Fprintln(conn, "error unknown request")
+	}
+}
+
+// request sends one request to the agent and returns its reply
+func request(socket, req string) (string, error) {
+	conn, err := net.DialTimeout("unix", socket, agentTimeout)
+	if err != nil {
+		return "", ErrNoAgent
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentTimeout))
+	if _, err := fmt.Fprintln(conn, req); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("key agent: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if msg, ok := strings.CutPrefix(reply, "error "); ok {
+		return "", fmt.Errorf("key agent: %s", msg)
+	}
+	return reply, nil
+}
+
+// AgentKey asks the agent on socket for its key
+func AgentKey(socket string) (*Key, error) {
+	if socket == "" {
+		socket = DefaultAgentSocket
+	}
+	reply, err := request(socket, "key")
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(reply)
+}
+
+// StopAgent stops the agent on socket
+func StopAgent(socket string) error {
+	if socket == "" {
+		socket = DefaultAgentSocket
+	}
+	_, err := request(socket, "stop")
+	return err
+}
@@ -0,0 +1,60 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAgent(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	_, key, _ := NewParams("correct horse")
+
+	if _, err := AgentKey(socket); !errors.Is(err, ErrNoAgent) {
+		t.Fatalf("expected ErrNoAgent, got %v", err)
+	}
+
+	agent, err := Listen(socket, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		agent.Serve(time.Minute)
+		close(done)
+	}()
+
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a socket only its user can use, got %v, %v", info, err)
+	}
+	got, err := AgentKey(socket)
+	if err != nil || got.String() != key.String() {
+		t.Fatalf("AgentKey() = %v, %v", got, err)
+	}
+	if _, err := Listen(socket, key); err == nil {
+		t.Error("expected a second agent to be refused")
+	}
+
+	if err := StopAgent(socket); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected the socket to be removed, got %v", err)
+	}
+}
+
+func TestAgentTimeout(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	_, key, _ := NewParams("correct horse")
+	agent, err := Listen(socket, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent.Serve(50 * time.Millisecond)
+	if _, err := AgentKey(socket); !errors.Is(err, ErrNoAgent) {
+		t.Errorf("expected the agent to be gone, got %v", err)
+	}
+}
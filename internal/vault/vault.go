@@ -0,0 +1,212 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// DefaultParamsPath is where an encrypted workspace keeps its key
+// parameters, relative to the workspace. They hold no secret, and are
+// synced with the data so every machine derives the same key.
+const DefaultParamsPath = ".bragger/key.json"
+
+// KeySize is the size of an AES-256 key
+const KeySize = 32
+
+// prefix starts every sealed line
+const prefix = "bragger-encrypted:v1:"
+
+// Default scrypt cost, as recommended for interactive logins
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// checkText is sealed in the parameters, so a wrong passphrase is told
+// apart from corrupted data
+const checkText = "bragger"
+
+var (
+	// ErrNoKey is returned when sealed data is read without a key
+	ErrNoKey = errors.New("data is encrypted and no key is set")
+	// ErrWrongKey is returned for a passphrase or key that doesn't match
+	// the workspace
+	ErrWrongKey = errors.New("wrong passphrase or key")
+)
+
+// Key is a derived encryption key
+type Key struct {
+	aead cipher.AEAD
+	raw  []byte
+}
+
+func newKey(raw []byte) (*Key, error) {
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead, raw: raw}, nil
+}
+
+// ParseKey reads a key in the form String returns
+func ParseKey(s string) (*Key, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return newKey(raw)
+}
+
+// String encodes the key, for BRAGGER_KEY and the agent
+func (k *Key) String() string {
+	return base64.StdEncoding.EncodeToString(k.raw)
+}
+
+// IsSealed reports whether data starts with a sealed line
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(prefix))
+}
+
+// Seal encrypts data as a single line of text, without a newline, so
+// sealed files and journal lines stay line-based
+func (k *Key) Seal(data []byte) []byte {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	sealed := k.aead.Seal(nonce, nonce, data, nil)
+	out := make([]byte, len(prefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(out, prefix)
+	base64.StdEncoding.Encode(out[len(prefix):], sealed)
+	return out
+}
+
+// Open decrypts a line Seal wrote
+func (k *Key) Open(line []byte) ([]byte, error) {
+	line = bytes.TrimSpace(line)
+	if !IsSealed(line) {
+		return nil, errors.New("not encrypted data")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(line[len(prefix):]))
+	if err != nil {
+		return nil, fmt.Errorf("corrupted encrypted data: %w", err)
+	}
+	size := k.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("corrupted encrypted data: too short")
+	}
+	data, err := k.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return data, nil
+}
+
+// Params are the settings a workspace's key is derived with
+type Params struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	// Check is a known text sealed with the key
+	Check string `json:"check"`
+}
+
+// NewParams derives a key from a passphrase with a new random salt, and
+// returns it with the parameters to derive it again
+func NewParams(passphrase string) (*Params, *Key, error) {
+	if passphrase == "" {
+		return nil, nil, errors.New("passphrase must not be empty")
+	}
+	p := &Params{Version: 1, KDF: "scrypt", Salt: make([]byte, 16), N: scryptN, R: scryptR, P: scryptP}
+	if _, err := rand.Read(p.Salt); err != nil {
+		return nil, nil, err
+	}
+	key, err := p.derive(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Check = string(key.Seal([]byte(checkText)))
+	return p, key, nil
+}
+
+// Derive returns the key for a passphrase, or ErrWrongKey if it doesn't
+// match the workspace
+func (p *Params) Derive(passphrase string) (*Key, error) {
+	key, err := p.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return key, p.Verify(key)
+}
+
+func (p *Params) derive(passphrase string) (*Key, error) {
+	if p.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation %q", p.KDF)
+	}
+	raw, err := scrypt.Key([]byte(passphrase), p.Salt, p.N, p.R, p.P, KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(raw)
+}
+
+// Verify checks that a key is the workspace's
+func (p *Params) Verify(key *Key) error {
+	data, err := key.Open([]byte(p.Check))
+	if err != nil || string(data) != checkText {
+		return ErrWrongKey
+	}
+	return nil
+}
+
+// LoadParams reads the key parameters of an encrypted workspace
+func LoadParams(path string) (*Params, error) {
+	if path == "" {
+		path = DefaultParamsPath
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Params
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Save writes the key parameters
+func (p *Params) Save(path string) error {
+	if path == "" {
+		path = DefaultParamsPath
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	_, key, err := NewParams("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("{\"id\":\"app-1\"}\n{\"id\":\"app-2\"}\n")
+
+	sealed := key.Seal(plaintext)
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("app-1")) || bytes.Contains(sealed, []byte("\n")) {
+		t.Fatalf("expected a single opaque line, got %q", sealed)
+	}
+	if bytes.Equal(sealed, key.Seal(plaintext)) {
+		t.Error("expected a fresh nonce for each seal")
+	}
+	got, err := key.Open(append(sealed, '\n'))
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() = %q, %v", got, err)
+	}
+
+	_, other, _ := NewParams("correct horse")
+	if _, err := other.Open(sealed); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey with another salt's key, got %v", err)
+	}
+	if _, err := key.Open([]byte("plain text")); err == nil {
+		t.Error("expected an error opening plaintext")
+	}
+}
+
+func TestParams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bragger", "key.json")
+	params, key, err := NewParams("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadParams(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived, err := loaded.Derive("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if derived.String() != key.String() {
+		t.Error("expected the same key from the saved parameters")
+	}
+	if _, err := loaded.Derive("wrong"); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey, got %v", err)
+	}
+
+	parsed, err := ParseKey(key.String())
+	if err != nil || loaded.Verify(parsed) != nil {
+		t.Errorf("expected the encoded key to verify, got %v", err)
+	}
+	if _, err := ParseKey("c2hvcnQ="); err == nil || !strings.Contains(err.Error(), "32 bytes") {
+		t.Errorf("expected a key size error, got %v", err)
+	}
+	if _, _, err := NewParams(""); err == nil {
+		t.Error("expected an empty passphrase to be refused")
+	}
+}
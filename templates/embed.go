@@ -2,8 +2,13 @@ package templates
 
 import "embed"
 
-//go:embed AGENTS.md package.json scripts/* skills/*/SKILL.md
+//go:embed AGENTS.md skills/*/SKILL.md
 var Files embed.FS
 
+// Themes holds the built-in resume themes, one directory per theme
+//
+//go:embed themes/*/*.tmpl
+var Themes embed.FS
+
 // Version is the current version of Bragger
-const Version = "0.4.0"
+const Version = "0.5.0"